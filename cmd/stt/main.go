@@ -14,13 +14,20 @@ import (
 	"github.com/facebookincubator/go-belt/tool/logger/implementation/logrus"
 	"github.com/lazybeaver/entropy"
 	"github.com/spf13/pflag"
+	"github.com/xaionaro-go/audio/pkg/audio"
 	"github.com/xaionaro-go/observability"
 	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/speech/pkg/speech/diarization/mfcc"
+	"github.com/xaionaro-go/speech/pkg/speech/phrasewatcher"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/client"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/multichannel"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/preprocessing"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/hallucination"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/goconv"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/session"
 )
 
 func syntaxExit(message string) {
@@ -35,16 +42,44 @@ func main() {
 	langFlag := pflag.String("language", "en-US", "")
 	alignmentAheadPresentFlag := types.AlignmentAheadsPreset(speechtotext_grpc.WhisperAlignmentAheadsPreset_WhisperAlignmentAheadsPresetNone)
 	pflag.Var(&alignmentAheadPresentFlag, "alignment-aheads-preset", "")
+	var alignmentHeadsFlag types.AlignmentHeads
+	pflag.Var(&alignmentHeadsFlag, "alignment-heads", "the attention heads to align the tokens with DTW, in format 'layer:head,layer:head' (requires --alignment-aheads-preset=custom)")
+	alignmentHeadsNTopFlag := pflag.Uint32("alignment-heads-n-top", 0, "the amount of the top text layers to align the tokens with DTW (requires --alignment-aheads-preset=n_top_most)")
+	wordTimestampsFlag := pflag.Bool("word-timestamps", false, "merge the sub-word tokens into words (the timestamps are accurate only with --alignment-aheads-preset)")
 	gpuFlag := pflag.Int("gpu", -1, "")
 	useGPUFlag := pflag.Bool("use-gpu", true, "")
 	remoteFlag := pflag.String("remote-addr", "", "use a remote speech-to-text engine, instead of running it locally")
 	shouldTranslateFlag := pflag.Bool("translate", false, "")
+	emitTranslationFlag := pflag.Bool("emit-translation", false, "print the English translation after each transcript (instead of translating only, like --translate)")
 	vadThreshold := pflag.Float64("vad-threshold", 0.5, "set to <=0 to disable VAD")
 	printTimestampsFlag := pflag.Bool("print-timestamps", false, "")
-	printTokenTimestampsFlag := pflag.Bool("print-token-timestamps", false, "")
+	printTokenTimestampsFlag := pflag.Bool("print-token-timestamps", false, "print the timestamps of each token; the ones aligned by DTW are marked with '*'")
 	printConfidencesFlag := pflag.Bool("print-confidences", false, "")
 	printEntropyFlag := pflag.Bool("print-entropy", false, "")
 	printNoSpeechProbabilityFlag := pflag.Bool("print-no-speech-probability", false, "")
+	diarizeFlag := pflag.Bool("diarize", false, "identify speakers (instead of just marking speaker turns)")
+	hallucinationRulesFlag := pflag.StringSlice("hallucination-rules", nil, "paths to JSON files with additional rules to suppress hallucinated segments")
+	noDefaultHallucinationRulesFlag := pflag.Bool("no-default-hallucination-rules", false, "use only the rules from --hallucination-rules")
+	emitAnnotationsFlag := pflag.Bool("emit-annotations", false, "print sound annotations (like \"[music]\") and suspected hallucinations instead of dropping them")
+	decodingParamsFlags := types.AddDecodingParamsFlags(pflag.CommandLine)
+	vadParamsFlags := types.AddVADParamsFlags(pflag.CommandLine)
+	endpointingParamsFlags := types.AddEndpointingParamsFlags(pflag.CommandLine)
+	preprocessingParamsFlags := preprocessing.AddParamsFlags(pflag.CommandLine)
+	initialPromptFlag := pflag.String("initial-prompt", "", "the text to prime the recognizer with (e.g. to hint names, terminology or the punctuation style)")
+	boostPhrasesFlag := pflag.StringSlice("boost-phrases", nil, "phrases (product names, jargon, etc) to be recognized more likely")
+	allowedLanguagesFlag := pflag.StringSlice("allowed-languages", nil, "restrict the language auto-detection to these languages (requires --language='')")
+	carryForwardPromptFlag := pflag.Bool("carry-forward-prompt", false, "use the tail of the committed text as the prompt for the next iterations")
+	commitPolicyFlag := types.CommitPolicyPresetBalanced
+	pflag.Var(&commitPolicyFlag, "commit-policy", "when to finalize the recognized segments: low-latency, balanced or accuracy")
+	audioChannelsFlag := pflag.Uint("audio-channels", 1, "the amount of interleaved channels in the input; each channel is transcribed separately")
+	onPhraseFlag := pflag.StringArray("on-phrase", nil, "run a command or post to a webhook when a phrase is said, in format 'phrase=command' or 'phrase=http://host/path' (the command gets the match in the environment variables STT_PHRASE, STT_TEXT, etc)")
+	onPhraseMatchFlag := phrasewatcher.MatchTypeFuzzy
+	pflag.Var(&onPhraseMatchFlag, "on-phrase-match", "how the --on-phrase phrases are matched: exact, fuzzy, phonetic or regex")
+	onPhrasePartialsFlag := pflag.Bool("on-phrase-partials", false, "match the --on-phrase phrases in the non-final transcripts as well (lower latency, but more false positives)")
+	onPhraseDebounceFlag := pflag.Duration("on-phrase-debounce", phrasewatcher.DefaultDebounce, "the minimal gap in the audio time between the triggers of the same --on-phrase phrase (besides, a phrase triggers at most once per segment)")
+	replayFlag := pflag.String("replay", "", "read the audio from a session file (recorded by 'sttd --record-dir') instead of stdin")
+	replaySpeedFlag := pflag.Float64("replay-speed", 1, "the speed of --replay relatively to the original one; should be positive (the audio the backend could not keep up with is dropped)")
+	replayTrailingSilenceFlag := pflag.Duration("replay-trailing-silence", 5*time.Second, "the duration of silence written after the --replay audio to let the backend finalize the last transcript")
 	netPprofAddr := pflag.String("net-pprof-listen-addr", "", "an address to listen for incoming net/pprof connections")
 	pflag.Parse()
 	if pflag.NArg() != 1 {
@@ -78,28 +113,113 @@ func main() {
 		opts = append(opts, whisper.OptionGPUDeviceID(*gpuFlag))
 	}
 	opts = append(opts, whisper.OptionUseGPU(*useGPUFlag))
+	opts = append(opts, whisper.OptionEmitAnnotations(*emitAnnotationsFlag))
+	opts = append(opts, goconv.DecodingParamsFromGRPC(decodingParamsFlags.GRPC())...)
+	commitPolicy, err := whisper.CommitPolicyFromPreset(commitPolicyFlag)
+	if err != nil {
+		syntaxExit(err.Error())
+	}
+	vadOpts, err := goconv.VADParamsFromGRPC(vadParamsFlags.GRPC(), commitPolicy)
+	if err != nil {
+		syntaxExit(err.Error())
+	}
+	opts = append(opts, vadOpts...)
+	opts = append(opts, goconv.EndpointingParamsFromGRPC(endpointingParamsFlags.GRPC())...)
+	if *initialPromptFlag != "" {
+		opts = append(opts, whisper.OptionInitialPrompt(*initialPromptFlag))
+	}
+	opts = append(opts, whisper.OptionBoostPhrases(*boostPhrasesFlag))
+	opts = append(opts, whisper.OptionCarryForwardPrompt(*carryForwardPromptFlag))
+	opts = append(opts, whisper.OptionEmitTranslation(*emitTranslationFlag))
+	opts = append(opts, whisper.OptionAlignmentHeads(alignmentHeadsFlag))
+	opts = append(opts, whisper.OptionAlignmentHeadsNTop(*alignmentHeadsNTopFlag))
+	opts = append(opts, whisper.OptionWordTimestamps(*wordTimestampsFlag))
+	var allowedLanguages []speech.Language
+	for _, lang := range *allowedLanguagesFlag {
+		allowedLanguages = append(allowedLanguages, speech.Language(lang))
+	}
+	if len(allowedLanguages) > 0 {
+		opts = append(opts, whisper.OptionAllowedLanguages(allowedLanguages))
+	}
+	opts = append(opts, whisper.OptionCommitPolicy(commitPolicy))
+
+	var hallucinationRules []hallucination.Rule
+	for _, path := range *hallucinationRulesFlag {
+		rules, err := hallucination.LoadRulesFile(path)
+		if err != nil {
+			panic(err)
+		}
+		hallucinationRules = append(hallucinationRules, rules...)
+	}
+	if *noDefaultHallucinationRulesFlag {
+		opts = append(opts, whisper.OptionHallucinationRules(hallucinationRules))
+	} else {
+		opts = append(opts, whisper.OptionExtraHallucinationRules(hallucinationRules))
+	}
 
 	var (
-		stt speech.ToText
-		err error
+		phrases       []phrasewatcher.Phrase
+		phraseHooks   []phrasewatcher.Hook
+		phraseWatcher *phrasewatcher.Watcher
 	)
-	if *remoteFlag != "" {
-		logger.Debugf(ctx, "initializing a remote context")
-		stt, err = client.New(ctx, *remoteFlag, &speechtotext_grpc.NewContextRequest{
-			ModelBytes:      whisperModel,
-			Language:        *langFlag,
-			ShouldTranslate: *shouldTranslateFlag,
-			VadThreshold:    float32(*vadThreshold),
-			Backend: &speechtotext_grpc.NewContextRequest_Whisper{
-				Whisper: &speechtotext_grpc.WhisperOptions{
-					SamplingStrategy:      goconv.SamplingStrategyToGRPC(types.SamplingStrategyGreedy),
-					AlignmentAheadsPreset: speechtotext_grpc.WhisperAlignmentAheadsPreset(alignmentAheadPresentFlag),
+	for _, onPhrase := range *onPhraseFlag {
+		phrase, hook, err := phrasewatcher.ParsePhraseHook(onPhrase, onPhraseMatchFlag)
+		if err != nil {
+			syntaxExit(err.Error())
+		}
+		phrases = append(phrases, phrase)
+		phraseHooks = append(phraseHooks, hook)
+	}
+	if len(phrases) > 0 {
+		phraseWatcher, err = phrasewatcher.New(
+			phrases,
+			phrasewatcher.OptionFireOnPartials(*onPhrasePartialsFlag),
+			phrasewatcher.OptionDebounce(*onPhraseDebounceFlag),
+		)
+		if err != nil {
+			syntaxExit(err.Error())
+		}
+	}
+
+	newSTT := func(ctx context.Context) (speech.ToText, error) {
+		if *remoteFlag != "" {
+			logger.Debugf(ctx, "initializing a remote context")
+			return client.New(ctx, *remoteFlag, &speechtotext_grpc.NewContextRequest{
+				ModelBytes:         whisperModel,
+				Language:           *langFlag,
+				ShouldTranslate:    *shouldTranslateFlag,
+				VadThreshold:       float32(*vadThreshold),
+				InitialPrompt:      *initialPromptFlag,
+				BoostPhrases:       *boostPhrasesFlag,
+				CarryForwardPrompt: *carryForwardPromptFlag,
+				AllowedLanguages:   *allowedLanguagesFlag,
+				EmitTranslation:    *emitTranslationFlag,
+				Preprocessing:      preprocessingParamsFlags.GRPC(),
+				Backend: &speechtotext_grpc.NewContextRequest_Whisper{
+					Whisper: &speechtotext_grpc.WhisperOptions{
+						SamplingStrategy:           goconv.SamplingStrategyToGRPC(types.SamplingStrategyGreedy),
+						AlignmentAheadsPreset:      speechtotext_grpc.WhisperAlignmentAheadsPreset(alignmentAheadPresentFlag),
+						Diarize:                    *diarizeFlag,
+						OverrideHallucinationRules: *noDefaultHallucinationRulesFlag,
+						HallucinationRules:         goconv.HallucinationRulesToGRPC(hallucinationRules),
+						EmitAnnotations:            *emitAnnotationsFlag,
+						DecodingParams:             decodingParamsFlags.GRPC(),
+						CommitPolicyPreset:         goconv.CommitPolicyPresetToGRPC(commitPolicyFlag),
+						VadParams:                  vadParamsFlags.GRPC(),
+						Endpointing:                endpointingParamsFlags.GRPC(),
+						AlignmentHeads:             goconv.AlignmentHeadsToGRPC(alignmentHeadsFlag),
+						AlignmentHeadsNTop:         *alignmentHeadsNTopFlag,
+						WordTimestamps:             *wordTimestampsFlag,
+					},
 				},
-			},
-		})
-	} else {
+			})
+		}
 		logger.Debugf(ctx, "initializing a local context")
-		stt, err = whisper.New(
+		opts := opts
+		if *diarizeFlag {
+			opts = append(opts[:len(opts):len(opts)], whisper.OptionDiarizer{Diarizer: mfcc.New()})
+		}
+		stt, err := whisper.New(
 			ctx,
 			whisperModel,
 			speech.Language(*langFlag),
@@ -109,6 +229,28 @@ func main() {
 			*vadThreshold,
 			opts...,
 		)
+		if err != nil {
+			return nil, err
+		}
+		preprocessingOpts := goconv.PreprocessingParamsFromGRPC(preprocessingParamsFlags.GRPC())
+		if len(preprocessingOpts) == 0 {
+			return stt, nil
+		}
+		preprocessed, err := preprocessing.New(ctx, stt, preprocessingOpts...)
+		if err != nil {
+			stt.Close()
+			return nil, fmt.Errorf("unable to initialize the preprocessing: %w", err)
+		}
+		return preprocessed, nil
+	}
+
+	var stt speech.ToText
+	if *audioChannelsFlag > 1 {
+		stt, err = multichannel.New(ctx, audio.Channel(*audioChannelsFlag), func(ctx context.Context, _ audio.Channel) (speech.ToText, error) {
+			return newSTT(ctx)
+		})
+	} else {
+		stt, err = newSTT(ctx)
 	}
 	if err != nil {
 		logger.Fatal(ctx, err)
@@ -121,14 +263,44 @@ func main() {
 		logger.Fatal(ctx, err)
 	}
 
+	readerDone := make(chan struct{})
 	observability.Go(ctx, func() {
+		defer close(readerDone)
 		defer logger.Infof(ctx, "stopped reader")
 		logger.Infof(ctx, "started reader")
 		previousMessageLength := 0
 		for t := range ch {
+			if phraseWatcher != nil {
+				for _, match := range phraseWatcher.Process(ctx, t) {
+					hook := phraseHooks[match.PhraseIdx]
+					observability.Go(ctx, func() {
+						logger.Debugf(ctx, "the phrase '%s' is matched: '%s'", match.Phrase.GetName(), match.Text)
+						if err := hook.Fire(ctx, match); err != nil {
+							logger.Errorf(ctx, "unable to run the hook of the phrase '%s': %v", match.Phrase.GetName(), err)
+						}
+					})
+				}
+			}
+			if t.IsRetracted {
+				// the line is rewritten by the next transcript anyway
+				continue
+			}
+			if t.IsTranslation && !t.IsFinal {
+				// the line is occupied by the transcript itself
+				continue
+			}
 			variant := t.Variants[0]
 			fmt.Printf("\r%s", strings.Repeat(" ", previousMessageLength))
 			text := strings.ReplaceAll(string(variant.Text), "\n", "|")
+			if t.Kind != speech.TranscriptKindSpeech {
+				text = fmt.Sprintf("[%s] %s", t.Kind, text)
+			}
+			if t.IsTranslation {
+				text = fmt.Sprintf("[%s] %s", t.Language, text)
+			}
+			if *audioChannelsFlag > 1 {
+				text = fmt.Sprintf("[ch%d] %s", t.AudioChannelNum, text)
+			}
 			if *printTimestampsFlag {
 				text = fmt.Sprintf(
 					"%8s - %8s: %s",
@@ -147,7 +319,11 @@ func main() {
 			if *printTokenTimestampsFlag {
 				var tss []string
 				for _, token := range variant.TranscriptTokens {
-					tss = append(tss, fmt.Sprintf("%s-%s", token.StartTime, token.EndTime))
+					ts := fmt.Sprintf("%s-%s", token.StartTime, token.EndTime)
+					if token.IsDTWAligned {
+						ts += "*"
+					}
+					tss = append(tss, ts)
 				}
 				text += fmt.Sprintf(" | %s", strings.Join(tss, ", "))
 			}
@@ -168,6 +344,35 @@ func main() {
 
 	defer logger.Infof(ctx, "stopped writer")
 	logger.Infof(ctx, "started writer")
+	if *replayFlag != "" {
+		f, err := os.Open(*replayFlag)
+		if err != nil {
+			logger.Fatal(ctx, err)
+		}
+		defer f.Close()
+		r, err := session.NewReader(f)
+		if err != nil {
+			logger.Fatal(ctx, err)
+		}
+		logger.Infof(ctx, "replaying the session started at %v (%v)", r.Header.StartedAt, r.Header.Metadata)
+		err = session.Replay(ctx, r, stt,
+			session.OptionSpeed(*replaySpeedFlag),
+			session.OptionTrailingSilence(*replayTrailingSilenceFlag),
+		)
+		if err != nil {
+			logger.Fatal(ctx, err)
+		}
+
+		// closing the input and waiting until the remaining transcripts are printed
+		if err := stt.Close(); err != nil {
+			logger.Errorf(ctx, "unable to close the Speech-To-Text engine: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-readerDone:
+		}
+		return
+	}
 	buf := make([]byte, 1024*1024)
 	for {
 		n, err := os.Stdin.Read(buf)
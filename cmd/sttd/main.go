@@ -13,7 +13,10 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/xaionaro-go/observability"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/hallucination"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/goconv"
 )
 
 func syntaxExit(message string) {
@@ -31,6 +34,9 @@ func main() {
 	cacheContextsFlag := pflag.Uint("cache-contexts", 0, "")
 	netPprofAddr := pflag.String("net-pprof-listen-addr", "", "an address to listen for incoming net/pprof connections")
 	defaultModelFlag := pflag.String("default-model-file", "", "")
+	decodingParamsFlags := types.AddDecodingParamsFlags(pflag.CommandLine)
+	recordDirFlag := pflag.String("record-dir", "", "record the audio and the transcripts of each context into a session file in this directory (to be replayed by 'stt --replay')")
+	hallucinationRulesFlag := pflag.StringSlice("hallucination-rules", nil, "paths to JSON files with additional rules to suppress hallucinated segments")
 	pflag.Parse()
 	if pflag.NArg() != 1 {
 		syntaxExit("expected one argument (bind address)")
@@ -58,6 +64,14 @@ func main() {
 		opts = append(opts, whisper.OptionGPUDeviceID(*gpuFlag))
 	}
 	opts = append(opts, whisper.OptionUseGPU(*useGPUFlag))
+	opts = append(opts, goconv.DecodingParamsFromGRPC(decodingParamsFlags.GRPC())...)
+	for _, path := range *hallucinationRulesFlag {
+		rules, err := hallucination.LoadRulesFile(path)
+		if err != nil {
+			logger.Fatal(ctx, err)
+		}
+		opts = append(opts, whisper.OptionExtraHallucinationRules(rules))
+	}
 
 	var defaultModel []byte
 
@@ -69,7 +83,15 @@ func main() {
 		}
 	}
 
-	srv := server.NewServer(defaultModel, *contextsFlag, *cacheContextsFlag, server.OptionWhisperOptions(opts))
+	srvOpts := server.Options{server.OptionWhisperOptions(opts)}
+	if *recordDirFlag != "" {
+		if err := os.MkdirAll(*recordDirFlag, 0755); err != nil {
+			logger.Fatal(ctx, err)
+		}
+		srvOpts = append(srvOpts, server.OptionRecordDir(*recordDirFlag))
+	}
+
+	srv := server.NewServer(defaultModel, *contextsFlag, *cacheContextsFlag, srvOpts...)
 
 	logger.Infof(ctx, "started at %v", listener.Addr())
 	err = srv.Serve(ctx, listener)
@@ -21,6 +21,8 @@ import (
 	"github.com/xaionaro-go/player/pkg/player/builtin"
 	"github.com/xaionaro-go/speech/pkg/speech"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/consts"
+	"github.com/xaionaro-go/speech/pkg/speech/translation/implementations/fake"
+	"github.com/xaionaro-go/speech/pkg/speech/translation/implementations/libretranslate"
 	"github.com/xaionaro-go/speech/pkg/subtitleswindow"
 )
 
@@ -35,13 +37,18 @@ func main() {
 	pflag.Var(&loggerLevel, "log-level", "Log level")
 	langFlag := pflag.String("language", "en-US", "")
 	shouldTranslateFlag := pflag.Bool("translate", false, "")
-	translateOnlyFromFlag := pflag.StringSlice("translate-only-from", nil, "")
+	translateToFlag := pflag.String("translate-to", "", "translate the subtitles to the language by the translator (see --translator)")
+	translatorFlag := pflag.String("translator", "libretranslate", "allowed values: libretranslate, fake")
+	libreTranslateURLFlag := pflag.String("libretranslate-url", libretranslate.DefaultURL, "")
+	libreTranslateAPIKeyFlag := pflag.String("libretranslate-api-key", "", "")
 	netPprofAddr := pflag.String("net-pprof-listen-addr", "", "an address to listen for incoming net/pprof connections")
 	playbackFlag := pflag.Bool("audio-loopback", false, "[debug] instead of running a subtitles window, playback the audio")
 	remoteFlag := pflag.String("remote-addr", "", "use a remote speech-to-text engine, instead of running it locally")
 	textAlignmentFlag := pflag.String("text-align", "center", "allowed values: left, center, right")
 	vadThreshold := pflag.Float64("vad-threshold", 0.99, "set to <=0 to disable VAD")
 	gpuFlag := pflag.Int("gpu", -1, "")
+	showSoundEventsFlag := pflag.Bool("show-sound-events", false, "show sound annotations like \"[music]\" or \"(door opens)\" (SDH)")
+	bilingualFlag := pflag.Bool("bilingual", false, "show the translation (to --translate-to, or to English if it is not set) under each subtitle (instead of translating only)")
 	pflag.Parse()
 	if pflag.NArg() < 1 || pflag.NArg() > 2 {
 		syntaxExit("expected one or two arguments: whisper-model-path [input]")
@@ -131,9 +138,16 @@ func main() {
 		os.Exit(0)
 	}
 
-	var translateOnlyFrom []speech.Language
-	for _, lang := range *translateOnlyFromFlag {
-		translateOnlyFrom = append(translateOnlyFrom, speech.Language(lang))
+	var translator speech.Translator
+	if *translateToFlag != "" {
+		switch *translatorFlag {
+		case "libretranslate":
+			translator = libretranslate.New(*libreTranslateURLFlag, libretranslate.OptionAPIKey(*libreTranslateAPIKeyFlag))
+		case "fake":
+			translator = fake.New(nil)
+		default:
+			syntaxExit(fmt.Sprintf("unknown translator '%s'", *translatorFlag))
+		}
 	}
 
 	app := app.New()
@@ -148,8 +162,11 @@ func main() {
 		whisperModel,
 		speech.Language(*langFlag),
 		*shouldTranslateFlag,
-		translateOnlyFrom,
+		translator,
+		speech.Language(*translateToFlag),
 		*vadThreshold,
+		*showSoundEventsFlag,
+		*bilingualFlag,
 	)
 	if err != nil {
 		panic(err)
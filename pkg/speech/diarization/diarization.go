@@ -0,0 +1,73 @@
+package diarization
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/xsync"
+)
+
+// SpeakerID is an identifier of a speaker, which is stable within a stream.
+type SpeakerID uint
+
+const (
+	SpeakerIDUndefined = SpeakerID(0)
+)
+
+func (id SpeakerID) String() string {
+	if id == SpeakerIDUndefined {
+		return ""
+	}
+	return fmt.Sprintf("S%d", uint(id))
+}
+
+// Diarizer tells who speaks. It is stateful: the same speaker is expected
+// to get the same SpeakerID during the whole stream, thus a Diarizer
+// should not be shared between streams.
+type Diarizer interface {
+	io.Closer
+
+	// IdentifySpeaker returns the speaker of the given piece of mono audio.
+	IdentifySpeaker(ctx context.Context, samples []float32, sampleRate audio.SampleRate) (SpeakerID, error)
+}
+
+// SpeakerNames maps speakers to human-readable names.
+type SpeakerNames struct {
+	locker xsync.Mutex
+	names  map[SpeakerID]string
+}
+
+func NewSpeakerNames(names map[SpeakerID]string) *SpeakerNames {
+	n := &SpeakerNames{
+		names: map[SpeakerID]string{},
+	}
+	for id, name := range names {
+		n.names[id] = name
+	}
+	return n
+}
+
+func (n *SpeakerNames) SetName(
+	ctx context.Context,
+	id SpeakerID,
+	name string,
+) {
+	n.locker.Do(ctx, func() {
+		n.names[id] = name
+	})
+}
+
+// Name returns the name of the speaker, or its ID if there is no name set.
+func (n *SpeakerNames) Name(
+	ctx context.Context,
+	id SpeakerID,
+) string {
+	return xsync.DoR1(ctx, &n.locker, func() string {
+		if name, ok := n.names[id]; ok {
+			return name
+		}
+		return id.String()
+	})
+}
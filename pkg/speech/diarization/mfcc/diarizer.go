@@ -0,0 +1,112 @@
+package mfcc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/speech/pkg/speech/diarization"
+	"github.com/xaionaro-go/xsync"
+)
+
+type speaker struct {
+	ID       diarization.SpeakerID
+	Centroid []float64
+	Count    uint
+}
+
+// Diarizer is a simple CPU-only diarizer: it summarizes every piece
+// of audio into a vector of MFC coefficient statistics and clusters
+// these vectors online by cosine similarity.
+type Diarizer struct {
+	locker      xsync.Mutex
+	config      config
+	extractor   *extractor
+	speakers    []*speaker
+	lastSpeaker diarization.SpeakerID
+}
+
+var _ diarization.Diarizer = (*Diarizer)(nil)
+
+func New(opts ...Option) *Diarizer {
+	return &Diarizer{
+		config: Options(opts).config(),
+	}
+}
+
+func (d *Diarizer) Close() error {
+	return nil
+}
+
+// IdentifySpeaker implements diarization.Diarizer.
+//
+// If the piece is too short or silent, the previously identified speaker
+// is returned.
+func (d *Diarizer) IdentifySpeaker(
+	ctx context.Context,
+	samples []float32,
+	sampleRate audio.SampleRate,
+) (_ret diarization.SpeakerID, _err error) {
+	logger.Tracef(ctx, "IdentifySpeaker(ctx, [len:%d], %d)", len(samples), sampleRate)
+	defer func() {
+		logger.Tracef(ctx, "/IdentifySpeaker(ctx, [len:%d], %d): %v %v", len(samples), sampleRate, _ret, _err)
+	}()
+	if sampleRate == 0 {
+		return diarization.SpeakerIDUndefined, fmt.Errorf("sample rate is zero")
+	}
+
+	return xsync.DoR2(ctx, &d.locker, func() (diarization.SpeakerID, error) {
+		duration := time.Duration(float64(len(samples)) / float64(sampleRate) * float64(time.Second))
+		if duration < d.config.MinDuration {
+			return d.lastSpeaker, nil
+		}
+
+		if d.extractor == nil || d.extractor.sampleRate != float64(sampleRate) {
+			d.extractor = newExtractor(float64(sampleRate))
+		}
+		embedding := Embedding(d.extractor.Frames(samples))
+		if embedding == nil {
+			return d.lastSpeaker, nil
+		}
+
+		d.lastSpeaker = d.assignNoLock(ctx, embedding)
+		return d.lastSpeaker, nil
+	})
+}
+
+func (d *Diarizer) assignNoLock(
+	ctx context.Context,
+	embedding []float64,
+) diarization.SpeakerID {
+	var (
+		best           *speaker
+		bestSimilarity float64
+	)
+	for _, s := range d.speakers {
+		similarity := cosineSimilarity(s.Centroid, embedding)
+		if best == nil || similarity > bestSimilarity {
+			best, bestSimilarity = s, similarity
+		}
+	}
+	logger.Tracef(ctx, "the most similar speaker: %v (%f)", best, bestSimilarity)
+
+	if best == nil || (bestSimilarity < d.config.SimilarityThreshold && uint(len(d.speakers)) < d.config.MaxSpeakers) {
+		s := &speaker{
+			ID:       diarization.SpeakerID(len(d.speakers) + 1),
+			Centroid: embedding,
+			Count:    1,
+		}
+		d.speakers = append(d.speakers, s)
+		logger.Debugf(ctx, "new speaker: %v", s.ID)
+		return s.ID
+	}
+
+	// running mean, so the centroid adapts to the speaker
+	best.Count++
+	for i, v := range embedding {
+		best.Centroid[i] += (v - best.Centroid[i]) / float64(best.Count)
+	}
+	return best.ID
+}
@@ -0,0 +1,89 @@
+package mfcc
+
+import (
+	"context"
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/speech/pkg/speech/diarization"
+)
+
+const testSampleRate = 16000
+
+// synthVoice generates a crude vowel-like sound: harmonics of the pitch
+// shaped by the formants.
+func synthVoice(rng *rand.Rand, pitch float64, formants []float64, seconds float64) []float32 {
+	samples := make([]float32, int(seconds*testSampleRate))
+	for h := 1; float64(h)*pitch < testSampleRate/2; h++ {
+		freq := float64(h) * pitch
+		var amp float64
+		for _, f := range formants {
+			amp += 1 / (1 + math.Pow((freq-f)/100, 2))
+		}
+		phase := rng.Float64() * 2 * math.Pi
+		for i := range samples {
+			samples[i] += float32(0.1 * amp * math.Sin(2*math.Pi*freq*float64(i)/testSampleRate+phase))
+		}
+	}
+	for i := range samples {
+		samples[i] += float32(0.001 * rng.NormFloat64())
+	}
+	return samples
+}
+
+func TestFFT(t *testing.T) {
+	x := make([]complex128, 8)
+	x[1] = 1
+	fft(x)
+	for k, v := range x {
+		expected := cmplx.Exp(complex(0, -2*math.Pi*float64(k)/8))
+		require.InDelta(t, real(expected), real(v), 1e-9)
+		require.InDelta(t, imag(expected), imag(v), 1e-9)
+	}
+}
+
+func TestDiarizer(t *testing.T) {
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(0))
+	voiceA := func() []float32 { return synthVoice(rng, 110, []float64{700, 1200}, 1) }
+	voiceB := func() []float32 { return synthVoice(rng, 220, []float64{300, 2500}, 1) }
+
+	d := New()
+	defer d.Close()
+
+	var ids []diarization.SpeakerID
+	for _, samples := range [][]float32{voiceA(), voiceA(), voiceB(), voiceA(), voiceB(), voiceB()} {
+		id, err := d.IdentifySpeaker(ctx, samples, testSampleRate)
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	require.Equal(t, []diarization.SpeakerID{1, 1, 2, 1, 2, 2}, ids)
+
+	// too short, so the last speaker is returned
+	id, err := d.IdentifySpeaker(ctx, voiceA()[:1000], testSampleRate)
+	require.NoError(t, err)
+	require.Equal(t, diarization.SpeakerID(2), id)
+
+	// silence, so the last speaker is returned
+	id, err = d.IdentifySpeaker(ctx, make([]float32, testSampleRate), testSampleRate)
+	require.NoError(t, err)
+	require.Equal(t, diarization.SpeakerID(2), id)
+}
+
+func TestDiarizerMaxSpeakers(t *testing.T) {
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(0))
+
+	d := New(OptionMaxSpeakers(1))
+	for _, samples := range [][]float32{
+		synthVoice(rng, 110, []float64{700, 1200}, 1),
+		synthVoice(rng, 220, []float64{300, 2500}, 1),
+	} {
+		id, err := d.IdentifySpeaker(ctx, samples, testSampleRate)
+		require.NoError(t, err)
+		require.Equal(t, diarization.SpeakerID(1), id)
+	}
+}
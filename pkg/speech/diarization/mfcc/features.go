@@ -0,0 +1,204 @@
+package mfcc
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+const (
+	FrameDuration     = 0.025 // seconds
+	HopDuration       = 0.010 // seconds
+	PreEmphasis       = 0.97
+	MelFiltersCount   = 26
+	CoefficientsCount = 13
+
+	// silentFrameEnergy is the mean squared amplitude below which
+	// a frame is ignored.
+	silentFrameEnergy = 1e-6
+)
+
+// extractor calculates MFCCs, it caches the window and the filter bank
+// for a specific sample rate.
+type extractor struct {
+	sampleRate float64
+	frameLen   int
+	hopLen     int
+	fftSize    int
+	window     []float64
+	filterBank [][]float64
+}
+
+func newExtractor(sampleRate float64) *extractor {
+	e := &extractor{
+		sampleRate: sampleRate,
+		frameLen:   int(FrameDuration * sampleRate),
+		hopLen:     int(HopDuration * sampleRate),
+	}
+	e.fftSize = 1
+	for e.fftSize < e.frameLen {
+		e.fftSize <<= 1
+	}
+	e.window = make([]float64, e.frameLen)
+	for i := range e.window {
+		e.window[i] = 0.54 - 0.46*math.Cos(2*math.Pi*float64(i)/float64(e.frameLen-1))
+	}
+	e.filterBank = melFilterBank(MelFiltersCount, e.fftSize, sampleRate)
+	return e
+}
+
+func hzToMel(hz float64) float64 {
+	return 2595 * math.Log10(1+hz/700)
+}
+
+func melToHz(mel float64) float64 {
+	return 700 * (math.Pow(10, mel/2595) - 1)
+}
+
+func melFilterBank(count, fftSize int, sampleRate float64) [][]float64 {
+	binsCount := fftSize/2 + 1
+	lowMel, highMel := hzToMel(0), hzToMel(sampleRate/2)
+	bins := make([]int, count+2)
+	for i := range bins {
+		hz := melToHz(lowMel + (highMel-lowMel)*float64(i)/float64(count+1))
+		bins[i] = int(math.Floor(float64(fftSize+1) * hz / sampleRate))
+	}
+
+	result := make([][]float64, count)
+	for m := 1; m <= count; m++ {
+		filter := make([]float64, binsCount)
+		left, center, right := bins[m-1], bins[m], bins[m+1]
+		for k := left; k < center && k < binsCount; k++ {
+			filter[k] = float64(k-left) / float64(center-left)
+		}
+		for k := center; k < right && k < binsCount; k++ {
+			filter[k] = float64(right-k) / float64(right-center)
+		}
+		result[m-1] = filter
+	}
+	return result
+}
+
+// fft is an in-place iterative radix-2 FFT; len(x) should be a power of 2.
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a, b := x[start+k], x[start+k+size/2]*w
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+}
+
+// Frames returns the MFCCs (without the 0th coefficient) of every
+// non-silent frame.
+func (e *extractor) Frames(samples []float32) [][]float64 {
+	if len(samples) < e.frameLen {
+		return nil
+	}
+
+	emphasized := make([]float64, len(samples))
+	emphasized[0] = float64(samples[0])
+	for i := 1; i < len(samples); i++ {
+		emphasized[i] = float64(samples[i]) - PreEmphasis*float64(samples[i-1])
+	}
+
+	var result [][]float64
+	spectrum := make([]complex128, e.fftSize)
+	melEnergies := make([]float64, len(e.filterBank))
+	for start := 0; start+e.frameLen <= len(emphasized); start += e.hopLen {
+		var energy float64
+		for i := 0; i < e.frameLen; i++ {
+			energy += float64(samples[start+i]) * float64(samples[start+i])
+		}
+		if energy/float64(e.frameLen) < silentFrameEnergy {
+			continue
+		}
+
+		for i := range spectrum {
+			spectrum[i] = 0
+		}
+		for i := 0; i < e.frameLen; i++ {
+			spectrum[i] = complex(emphasized[start+i]*e.window[i], 0)
+		}
+		fft(spectrum)
+
+		for m, filter := range e.filterBank {
+			var sum float64
+			for k, weight := range filter {
+				if weight == 0 {
+					continue
+				}
+				power := cmplx.Abs(spectrum[k])
+				sum += weight * power * power / float64(e.fftSize)
+			}
+			melEnergies[m] = math.Log(sum + 1e-10)
+		}
+
+		coeffs := make([]float64, CoefficientsCount-1)
+		for c := 1; c < CoefficientsCount; c++ {
+			var sum float64
+			for m, v := range melEnergies {
+				sum += v * math.Cos(math.Pi*float64(c)*(float64(m)+0.5)/float64(len(melEnergies)))
+			}
+			coeffs[c-1] = sum
+		}
+		result = append(result, coeffs)
+	}
+	return result
+}
+
+// Embedding summarizes the frames into a single vector: the means
+// and the standard deviations of the coefficients.
+func Embedding(frames [][]float64) []float64 {
+	if len(frames) == 0 {
+		return nil
+	}
+	dim := len(frames[0])
+	result := make([]float64, 2*dim)
+	for _, frame := range frames {
+		for i, v := range frame {
+			result[i] += v
+		}
+	}
+	for i := 0; i < dim; i++ {
+		result[i] /= float64(len(frames))
+	}
+	for _, frame := range frames {
+		for i, v := range frame {
+			d := v - result[i]
+			result[dim+i] += d * d
+		}
+	}
+	for i := 0; i < dim; i++ {
+		result[dim+i] = math.Sqrt(result[dim+i] / float64(len(frames)))
+	}
+	return result
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
@@ -0,0 +1,68 @@
+package mfcc
+
+import (
+	"time"
+)
+
+const (
+	DefaultSimilarityThreshold = 0.9
+	DefaultMaxSpeakers         = 8
+	DefaultMinDuration         = 500 * time.Millisecond
+)
+
+type config struct {
+	SimilarityThreshold float64
+	MaxSpeakers         uint
+	MinDuration         time.Duration
+}
+
+func defaultConfig() config {
+	return config{
+		SimilarityThreshold: DefaultSimilarityThreshold,
+		MaxSpeakers:         DefaultMaxSpeakers,
+		MinDuration:         DefaultMinDuration,
+	}
+}
+
+type Option interface {
+	apply(*config)
+}
+
+type Options []Option
+
+func (opts Options) apply(cfg *config) {
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+}
+
+func (opts Options) config() config {
+	cfg := defaultConfig()
+	opts.apply(&cfg)
+	return cfg
+}
+
+// OptionSimilarityThreshold is the minimal cosine similarity to the
+// centroid of a known speaker to be assigned to that speaker (otherwise
+// a new speaker is created).
+type OptionSimilarityThreshold float64
+
+func (opt OptionSimilarityThreshold) apply(cfg *config) {
+	cfg.SimilarityThreshold = float64(opt)
+}
+
+// OptionMaxSpeakers limits the amount of speakers; when the limit is
+// reached, the most similar known speaker is assigned.
+type OptionMaxSpeakers uint
+
+func (opt OptionMaxSpeakers) apply(cfg *config) {
+	cfg.MaxSpeakers = uint(opt)
+}
+
+// OptionMinDuration is the minimal duration of audio to identify
+// a speaker; shorter pieces are attributed to the previous speaker.
+type OptionMinDuration time.Duration
+
+func (opt OptionMinDuration) apply(cfg *config) {
+	cfg.MinDuration = time.Duration(opt)
+}
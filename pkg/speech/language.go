@@ -15,3 +15,50 @@ func (l Language) Family() LanguageFamily {
 	words := strings.SplitN(string(l), "-", 2)
 	return LanguageFamily(words[0])
 }
+
+// LanguageProbability is a language with the probability of it being spoken.
+type LanguageProbability struct {
+	Language    Language
+	Probability float32
+}
+
+// LanguageProbabilities is a probability distribution over languages,
+// sorted from the most probable language to the least probable one.
+type LanguageProbabilities []LanguageProbability
+
+// Best returns the most probable language
+// (or a zero value if the distribution is empty).
+func (p LanguageProbabilities) Best() LanguageProbability {
+	if len(p) == 0 {
+		return LanguageProbability{}
+	}
+	return p[0]
+}
+
+// Restrict returns the probabilities of the allowed languages only (in the same
+// order); a language is matched to an allowed one by the family, and is replaced
+// by it (e.g. "en" becomes "en-US" if "en-US" is allowed).
+func (p LanguageProbabilities) Restrict(allowed []Language) LanguageProbabilities {
+	var result LanguageProbabilities
+	for _, prob := range p {
+		lang, ok := prob.Language.MatchFamily(allowed)
+		if !ok {
+			continue
+		}
+		result = append(result, LanguageProbability{
+			Language:    lang,
+			Probability: prob.Probability,
+		})
+	}
+	return result
+}
+
+// MatchFamily returns the first language of the same family as l.
+func (l Language) MatchFamily(languages []Language) (Language, bool) {
+	for _, candidate := range languages {
+		if strings.EqualFold(string(candidate.Family()), string(l.Family())) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,28 @@
+package phrasewatcher
+
+import (
+	"fmt"
+)
+
+type ErrInvalidRegex struct {
+	Pattern string
+	Err     error
+}
+
+func (e ErrInvalidRegex) Error() string {
+	return fmt.Sprintf("invalid regular expression '%s': %v", e.Pattern, e.Err)
+}
+
+type ErrEmptyPhrase struct{}
+
+func (ErrEmptyPhrase) Error() string {
+	return "the phrase has no words"
+}
+
+type ErrInvalidHook struct {
+	Hook string
+}
+
+func (e ErrInvalidHook) Error() string {
+	return fmt.Sprintf("invalid hook '%s', expected format 'phrase=command' or 'phrase=http://host/path'", e.Hook)
+}
@@ -0,0 +1,133 @@
+package phrasewatcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Hook is an action to be done on a Match.
+type Hook interface {
+	Fire(ctx context.Context, match Match) error
+}
+
+// CommandHook runs a shell command on a match; the match is passed
+// via the environment variables STT_PHRASE, STT_TEXT, STT_LANGUAGE,
+// STT_START_TIME and STT_END_TIME.
+type CommandHook struct {
+	Command string
+}
+
+var _ Hook = (*CommandHook)(nil)
+
+// Fire implements Hook.
+func (h *CommandHook) Fire(
+	ctx context.Context,
+	match Match,
+) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+	cmd.Env = append(os.Environ(),
+		"STT_PHRASE="+match.Phrase.GetName(),
+		"STT_TEXT="+string(match.Text),
+		"STT_START_TIME="+match.StartTime.String(),
+		"STT_END_TIME="+match.EndTime.String(),
+	)
+	if match.Transcript != nil {
+		cmd.Env = append(cmd.Env, "STT_LANGUAGE="+string(match.Transcript.Language))
+	}
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("the command '%s' failed: %w", h.Command, err)
+	}
+	return nil
+}
+
+// WebhookPayload is the JSON body posted by WebhookHook.
+type WebhookPayload struct {
+	Phrase      string  `json:"phrase"`
+	Text        string  `json:"text"`
+	Similarity  float64 `json:"similarity"`
+	Language    string  `json:"language,omitempty"`
+	IsFinal     bool    `json:"is_final"`
+	StartTimeMS int64   `json:"start_time_ms"`
+	EndTimeMS   int64   `json:"end_time_ms"`
+}
+
+// WebhookHook posts a WebhookPayload to the URL on a match.
+type WebhookHook struct {
+	URL string
+
+	// Client is used to post the payload; if nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+var _ Hook = (*WebhookHook)(nil)
+
+// Fire implements Hook.
+func (h *WebhookHook) Fire(
+	ctx context.Context,
+	match Match,
+) error {
+	payload := WebhookPayload{
+		Phrase:      match.Phrase.GetName(),
+		Text:        string(match.Text),
+		Similarity:  match.Similarity,
+		StartTimeMS: match.StartTime.Milliseconds(),
+		EndTimeMS:   match.EndTime.Milliseconds(),
+	}
+	if match.Transcript != nil {
+		payload.Language = string(match.Transcript.Language)
+		payload.IsFinal = match.Transcript.IsFinal
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("unable to serialize the payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to build a request to '%s': %w", h.URL, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to post to '%s': %w", h.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("'%s' responded with status %d", h.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// ParsePhraseHook parses "phrase=action", where the action is a URL
+// (for WebhookHook) if it starts with "http://" or "https://",
+// or a shell command (for CommandHook) otherwise.
+func ParsePhraseHook(
+	in string,
+	matchType MatchType,
+) (Phrase, Hook, error) {
+	text, action, ok := strings.Cut(in, "=")
+	text, action = strings.TrimSpace(text), strings.TrimSpace(action)
+	if !ok || text == "" || action == "" {
+		return Phrase{}, nil, ErrInvalidHook{Hook: in}
+	}
+	phrase := Phrase{
+		Text:      text,
+		MatchType: matchType,
+	}
+	if strings.HasPrefix(action, "http://") || strings.HasPrefix(action, "https://") {
+		return phrase, &WebhookHook{URL: action}, nil
+	}
+	return phrase, &CommandHook{Command: action}, nil
+}
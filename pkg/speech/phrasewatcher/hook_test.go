@@ -0,0 +1,70 @@
+package phrasewatcher
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePhraseHook(t *testing.T) {
+	phrase, hook, err := ParsePhraseHook("next scene = http://127.0.0.1:8080/scene?next=1", MatchTypeFuzzy)
+	require.NoError(t, err)
+	require.Equal(t, Phrase{Text: "next scene", MatchType: MatchTypeFuzzy}, phrase)
+	require.Equal(t, &WebhookHook{URL: "http://127.0.0.1:8080/scene?next=1"}, hook)
+
+	_, hook, err = ParsePhraseHook("hello=echo hi", MatchTypeExact)
+	require.NoError(t, err)
+	require.Equal(t, &CommandHook{Command: "echo hi"}, hook)
+
+	_, _, err = ParsePhraseHook("hello", MatchTypeExact)
+	require.ErrorAs(t, err, &ErrInvalidHook{})
+}
+
+func TestWebhookHook(t *testing.T) {
+	var payload WebhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+	}))
+	defer srv.Close()
+
+	match := Match{
+		Phrase:     Phrase{Name: "next", Text: "next scene"},
+		Text:       "next scene",
+		Similarity: 1,
+		StartTime:  time.Second,
+		EndTime:    2 * time.Second,
+		Transcript: transcript("next scene", true),
+	}
+	require.NoError(t, (&WebhookHook{URL: srv.URL}).Fire(context.Background(), match))
+	require.Equal(t, WebhookPayload{
+		Phrase:      "next",
+		Text:        "next scene",
+		Similarity:  1,
+		Language:    "en",
+		IsFinal:     true,
+		StartTimeMS: 1000,
+		EndTimeMS:   2000,
+	}, payload)
+}
+
+func TestCommandHook(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	hook := &CommandHook{Command: `printf '%s|%s' "$STT_PHRASE" "$STT_TEXT" > ` + out}
+	require.NoError(t, hook.Fire(context.Background(), Match{
+		Phrase: Phrase{Text: "hello"},
+		Text:   "Hello!",
+	}))
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, "hello|Hello!", string(b))
+
+	require.Error(t, (&CommandHook{Command: "exit 1"}).Fire(context.Background(), Match{}))
+}
@@ -0,0 +1,73 @@
+package phrasewatcher
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MatchType defines how a Phrase is searched in the transcripts.
+type MatchType int
+
+const (
+	// MatchTypeExact matches the words of the phrase (case-insensitive,
+	// ignoring the punctuation).
+	MatchTypeExact = MatchType(iota)
+
+	// MatchTypeFuzzy matches the words similar to the phrase
+	// by the Levenshtein distance (see Phrase.MinSimilarity).
+	MatchTypeFuzzy
+
+	// MatchTypePhonetic matches the words sounding like the phrase
+	// (by Soundex, which is designed for English).
+	MatchTypePhonetic
+
+	// MatchTypeRegex matches the text of the transcript against the phrase
+	// as a regular expression (see package "regexp").
+	MatchTypeRegex
+
+	EndOfMatchType
+)
+
+// String just implements fmt.Stringer, flag.Value and pflag.Value.
+func (t MatchType) String() string {
+	switch t {
+	case MatchTypeExact:
+		return "exact"
+	case MatchTypeFuzzy:
+		return "fuzzy"
+	case MatchTypePhonetic:
+		return "phonetic"
+	case MatchTypeRegex:
+		return "regex"
+	}
+	return fmt.Sprintf("unknown_%d", int(t))
+}
+
+// Set updates the value based on the passed string value.
+// This method just implements flag.Value and pflag.Value.
+func (t *MatchType) Set(value string) error {
+	newValue, err := ParseMatchType(value)
+	if err != nil {
+		return err
+	}
+	*t = newValue
+	return nil
+}
+
+// Type just implements pflag.Value.
+func (t *MatchType) Type() string {
+	return "MatchType"
+}
+
+func ParseMatchType(in string) (MatchType, error) {
+	in = strings.ToLower(in)
+	var allowedValues []string
+	for t := MatchType(0); t < EndOfMatchType; t++ {
+		if t.String() == in {
+			return t, nil
+		}
+		allowedValues = append(allowedValues, t.String())
+	}
+	return MatchTypeExact, fmt.Errorf("unknown match type '%s', known values are: %s",
+		in, strings.Join(allowedValues, ", "))
+}
@@ -0,0 +1,59 @@
+package phrasewatcher
+
+import (
+	"time"
+)
+
+const (
+	DefaultDebounce = 500 * time.Millisecond
+)
+
+type config struct {
+	FireOnPartials bool
+	Debounce       time.Duration
+}
+
+func defaultConfig() config {
+	return config{
+		Debounce: DefaultDebounce,
+	}
+}
+
+type Option interface {
+	apply(*config)
+}
+
+type Options []Option
+
+func (opts Options) apply(cfg *config) {
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+}
+
+func (opts Options) config() config {
+	cfg := defaultConfig()
+	opts.apply(&cfg)
+	return cfg
+}
+
+// OptionFireOnPartials enables matching the non-final transcripts as well
+// (lower latency, but a partial transcript might be a misrecognition);
+// otherwise only the final ones are matched.
+type OptionFireOnPartials bool
+
+func (opt OptionFireOnPartials) apply(cfg *config) {
+	cfg.FireOnPartials = bool(opt)
+}
+
+// OptionDebounce is the minimal gap (in the audio time, see
+// speech.TranscriptToken.StartTime) between the end of a match of a phrase
+// and the start of the next match of it; the matches within the gap are
+// dropped (for example, the same words reported again in another segment
+// after the audio was re-segmented). The matches without the timing are
+// not debounced.
+type OptionDebounce time.Duration
+
+func (opt OptionDebounce) apply(cfg *config) {
+	cfg.Debounce = time.Duration(opt)
+}
@@ -0,0 +1,194 @@
+package phrasewatcher
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+const (
+	DefaultMinSimilarity = 0.8
+)
+
+// Phrase is a phrase to watch for.
+type Phrase struct {
+	// Name identifies the phrase in the matches (for example,
+	// the action to do); if empty, Text is used.
+	Name string
+
+	// Text is the phrase (or the regular expression, see MatchTypeRegex).
+	Text string
+
+	MatchType MatchType
+
+	// MinSimilarity is the minimal similarity (from 0 to 1) of the words
+	// to the phrase for MatchTypeFuzzy; if zero, DefaultMinSimilarity is used.
+	MinSimilarity float64
+}
+
+// GetName returns Name, or Text if Name is empty.
+func (p *Phrase) GetName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.Text
+}
+
+// Match is an occurrence of a Phrase in a transcript.
+type Match struct {
+	Phrase Phrase
+
+	// PhraseIdx is the index of the phrase in the list passed to New.
+	PhraseIdx int
+
+	// Text is the matched part of the transcript.
+	Text speech.Text
+
+	// Similarity is the similarity of the text to the phrase
+	// (see MatchTypeFuzzy); it is 1 for the other match types.
+	Similarity float64
+
+	StartTime  time.Duration
+	EndTime    time.Duration
+	Transcript *speech.Transcript
+}
+
+// matcher is a Phrase prepared for the matching.
+type matcher struct {
+	Phrase Phrase
+	Words  []string
+	Codes  []string
+	Regexp *regexp.Regexp
+}
+
+func newMatcher(phrase Phrase) (*matcher, error) {
+	m := &matcher{Phrase: phrase}
+	if m.Phrase.MinSimilarity == 0 {
+		m.Phrase.MinSimilarity = DefaultMinSimilarity
+	}
+	if phrase.MatchType == MatchTypeRegex {
+		r, err := regexp.Compile(phrase.Text)
+		if err != nil {
+			return nil, ErrInvalidRegex{Pattern: phrase.Text, Err: err}
+		}
+		m.Regexp = r
+		return m, nil
+	}
+	for _, word := range strings.Fields(phrase.Text) {
+		word = normalizeWord(word)
+		if word == "" {
+			continue
+		}
+		m.Words = append(m.Words, word)
+		m.Codes = append(m.Codes, soundex(word))
+	}
+	if len(m.Words) == 0 {
+		return nil, ErrEmptyPhrase{}
+	}
+	return m, nil
+}
+
+// transcriptWord is a non-empty normalized word of a transcript.
+type transcriptWord struct {
+	Token      speech.TranscriptToken
+	Normalized string
+}
+
+func transcriptWords(variant *speech.TranscriptVariant) []transcriptWord {
+	var result []transcriptWord
+	for _, word := range variant.Words() {
+		normalized := normalizeWord(string(word.Text))
+		if normalized == "" {
+			continue
+		}
+		result = append(result, transcriptWord{Token: word, Normalized: normalized})
+	}
+	return result
+}
+
+// normalizeWord returns the word in the lower case
+// without the surrounding punctuation.
+func normalizeWord(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
+
+// Match returns the first occurrence of the phrase in the variant.
+func (m *matcher) Match(
+	variant *speech.TranscriptVariant,
+	words []transcriptWord,
+) (Match, bool) {
+	if m.Regexp != nil {
+		loc := m.Regexp.FindStringIndex(string(variant.Text))
+		if loc == nil {
+			return Match{}, false
+		}
+		return Match{
+			Phrase:     m.Phrase,
+			Text:       variant.Text[loc[0]:loc[1]],
+			Similarity: 1,
+			StartTime:  variant.StartTime(),
+			EndTime:    variant.EndTime(),
+		}, true
+	}
+
+	for start := range words {
+		if similarity, count := m.matchAt(words[start:]); count > 0 {
+			matched := words[start : start+count]
+			tokens := make(speech.TranscriptTokens, 0, len(matched))
+			for _, word := range matched {
+				tokens = append(tokens, word.Token)
+			}
+			return Match{
+				Phrase:     m.Phrase,
+				Text:       tokens.JoinWords(),
+				Similarity: similarity,
+				StartTime:  matched[0].Token.StartTime,
+				EndTime:    matched[len(matched)-1].Token.EndTime,
+			}, true
+		}
+	}
+	return Match{}, false
+}
+
+// matchAt returns the similarity and the amount of the words if the words
+// begin with the phrase; otherwise the amount is zero.
+func (m *matcher) matchAt(words []transcriptWord) (float64, int) {
+	switch m.Phrase.MatchType {
+	case MatchTypeExact, MatchTypePhonetic:
+		if len(words) < len(m.Words) {
+			return 0, 0
+		}
+		for idx, expected := range m.Words {
+			actual := words[idx].Normalized
+			if m.Phrase.MatchType == MatchTypeExact && actual != expected {
+				return 0, 0
+			}
+			if m.Phrase.MatchType == MatchTypePhonetic && (m.Codes[idx] == "" || soundex(actual) != m.Codes[idx]) {
+				return 0, 0
+			}
+		}
+		return 1, len(m.Words)
+	case MatchTypeFuzzy:
+		// the recognized words could be split or merged differently, thus
+		// the window is a word shorter or longer than the phrase as well
+		expected := strings.Join(m.Words, " ")
+		bestSimilarity, bestCount := 0.0, 0
+		for count := max(len(m.Words)-1, 1); count <= len(m.Words)+1 && count <= len(words); count++ {
+			actual := make([]string, 0, count)
+			for _, word := range words[:count] {
+				actual = append(actual, word.Normalized)
+			}
+			similarity := similarity(strings.Join(actual, " "), expected)
+			if similarity >= m.Phrase.MinSimilarity && similarity > bestSimilarity {
+				bestSimilarity, bestCount = similarity, count
+			}
+		}
+		return bestSimilarity, bestCount
+	}
+	return 0, 0
+}
@@ -0,0 +1,163 @@
+// Package phrasewatcher watches the transcripts for the given phrases
+// (keyword spotting), for example to trigger actions by voice commands.
+package phrasewatcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/observability"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/xsync"
+)
+
+// Watcher finds the phrases in the transcripts. It is stateful
+// (see OptionDebounce), thus it should not be shared between streams.
+type Watcher struct {
+	Config   config
+	Matchers []*matcher
+
+	locker    xsync.Mutex
+	lastFired map[firedKey]firedMatch
+}
+
+type firedKey struct {
+	PhraseIdx       int
+	AudioChannelNum audio.Channel
+}
+
+// firedMatch is the last match of a phrase passed through by Process.
+type firedMatch struct {
+	SegmentID speech.SegmentID
+	EndTime   time.Duration
+}
+
+func New(
+	phrases []Phrase,
+	opts ...Option,
+) (*Watcher, error) {
+	w := &Watcher{
+		Config:    Options(opts).config(),
+		lastFired: map[firedKey]firedMatch{},
+	}
+	for idx, phrase := range phrases {
+		m, err := newMatcher(phrase)
+		if err != nil {
+			return nil, fmt.Errorf("unable to prepare the phrase #%d '%s': %w", idx, phrase.GetName(), err)
+		}
+		w.Matchers = append(w.Matchers, m)
+	}
+	return w, nil
+}
+
+// Process returns the matches of the phrases in the transcript.
+//
+// Only the best variant of the speech transcripts is checked; the retracted
+// transcripts, the translations and (unless OptionFireOnPartials) the
+// non-final transcripts are ignored.
+//
+// A phrase fires once per segment (see speech.Transcript.SegmentID), so
+// the partial transcripts and the final one of the same utterance do not
+// fire it again; see also OptionDebounce.
+func (w *Watcher) Process(
+	ctx context.Context,
+	t *speech.Transcript,
+) []Match {
+	switch {
+	case t.IsRetracted:
+		return nil
+	case t.IsTranslation:
+		return nil
+	case t.Kind != speech.TranscriptKindSpeech:
+		return nil
+	case !t.IsFinal && !w.Config.FireOnPartials:
+		return nil
+	case len(t.Variants) == 0:
+		return nil
+	}
+
+	variant := &t.Variants[0]
+	words := transcriptWords(variant)
+	return xsync.DoR1(ctx, &w.locker, func() []Match {
+		var result []Match
+		for idx, m := range w.Matchers {
+			match, ok := m.Match(variant, words)
+			if !ok {
+				continue
+			}
+			key := firedKey{PhraseIdx: idx, AudioChannelNum: t.AudioChannelNum}
+			if last, ok := w.lastFired[key]; ok && w.isDuplicate(t, match, last) {
+				logger.Debugf(ctx, "debounced the match of '%s': '%s'", m.Phrase.GetName(), match.Text)
+				continue
+			}
+			w.lastFired[key] = firedMatch{
+				SegmentID: t.SegmentID,
+				EndTime:   match.EndTime,
+			}
+			match.PhraseIdx = idx
+			match.Transcript = t
+			result = append(result, match)
+		}
+		return result
+	})
+}
+
+// isDuplicate returns true if the match repeats the last fired match
+// of the phrase: it is in the same segment, or (for the re-segmented audio)
+// it starts earlier than Debounce after the end of the last one.
+func (w *Watcher) isDuplicate(
+	t *speech.Transcript,
+	match Match,
+	last firedMatch,
+) bool {
+	if t.SegmentID != 0 && t.SegmentID == last.SegmentID {
+		return true
+	}
+	if match.EndTime == 0 || last.EndTime == 0 {
+		// no timing to compare
+		return false
+	}
+	return match.StartTime < last.EndTime+w.Config.Debounce
+}
+
+// Watch processes the transcripts from the input (see Process) and sends
+// the matches to the returned channel.
+//
+// The returned channel is closed when the input is closed or ctx is done.
+func (w *Watcher) Watch(
+	ctx context.Context,
+	input <-chan *speech.Transcript,
+) <-chan Match {
+	output := make(chan Match, 1024)
+	observability.Go(ctx, func() {
+		defer close(output)
+		logger.Debugf(ctx, "phrase watching loop")
+		defer func() { logger.Debugf(ctx, "/phrase watching loop") }()
+
+		for {
+			var (
+				t  *speech.Transcript
+				ok bool
+			)
+			select {
+			case <-ctx.Done():
+				return
+			case t, ok = <-input:
+				if !ok {
+					return
+				}
+			}
+			for _, match := range w.Process(ctx, t) {
+				select {
+				case <-ctx.Done():
+					return
+				case output <- match:
+				}
+			}
+		}
+	})
+	return output
+}
@@ -0,0 +1,223 @@
+package phrasewatcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+func transcript(text speech.Text, isFinal bool) *speech.Transcript {
+	var tokens speech.TranscriptTokens
+	for idx, word := range (&speech.TranscriptVariant{Text: text}).Words() {
+		word.Text = " " + word.Text
+		word.StartTime = time.Duration(idx) * time.Second
+		word.EndTime = time.Duration(idx+1) * time.Second
+		tokens = append(tokens, word)
+	}
+	return &speech.Transcript{
+		Variants: speech.TranscriptVariants{{
+			Text:             text,
+			TranscriptTokens: tokens,
+		}},
+		IsFinal:  isFinal,
+		Language: "en",
+		Kind:     speech.TranscriptKindSpeech,
+	}
+}
+
+func TestWatcherMatchTypes(t *testing.T) {
+	for _, tc := range []struct {
+		Name       string
+		Phrase     Phrase
+		Text       speech.Text
+		Match      speech.Text
+		Similarity float64
+		StartTime  time.Duration
+	}{
+		{
+			Name:       "exact",
+			Phrase:     Phrase{Text: "Scene Two", MatchType: MatchTypeExact},
+			Text:       "okay, switch to scene two, please",
+			Match:      "scene two,",
+			Similarity: 1,
+			StartTime:  3 * time.Second,
+		},
+		{
+			Name:   "exact_no_match",
+			Phrase: Phrase{Text: "scene two", MatchType: MatchTypeExact},
+			Text:   "switch to scene three",
+		},
+		{
+			Name:       "fuzzy",
+			Phrase:     Phrase{Text: "start recording", MatchType: MatchTypeFuzzy},
+			Text:       "please start recordin now",
+			Match:      "start recordin",
+			Similarity: 1 - 1.0/15,
+			StartTime:  time.Second,
+		},
+		{
+			Name:       "fuzzy_split_word",
+			Phrase:     Phrase{Text: "stream labs", MatchType: MatchTypeFuzzy},
+			Text:       "open streamlabs",
+			Match:      "streamlabs",
+			Similarity: 1 - 1.0/11,
+			StartTime:  time.Second,
+		},
+		{
+			Name:   "fuzzy_too_different",
+			Phrase: Phrase{Text: "start recording", MatchType: MatchTypeFuzzy, MinSimilarity: 0.95},
+			Text:   "please start recordin now",
+		},
+		{
+			Name:       "phonetic",
+			Phrase:     Phrase{Text: "Robert", MatchType: MatchTypePhonetic},
+			Text:       "hi Rupert!",
+			Match:      "Rupert!",
+			Similarity: 1,
+			StartTime:  time.Second,
+		},
+		{
+			Name:       "regex",
+			Phrase:     Phrase{Text: `(?i)scene \d+`, MatchType: MatchTypeRegex},
+			Text:       "go to Scene 42 now",
+			Match:      "Scene 42",
+			Similarity: 1,
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			w, err := New([]Phrase{tc.Phrase})
+			require.NoError(t, err)
+			in := transcript(tc.Text, true)
+			matches := w.Process(context.Background(), in)
+			if tc.Match == "" {
+				require.Empty(t, matches)
+				return
+			}
+			require.Len(t, matches, 1)
+			require.Equal(t, tc.Match, matches[0].Text)
+			require.InDelta(t, tc.Similarity, matches[0].Similarity, 1e-9)
+			require.Equal(t, tc.StartTime, matches[0].StartTime)
+			require.Same(t, in, matches[0].Transcript)
+		})
+	}
+}
+
+// segment returns the transcript of the segment, which starts
+// at the given time of the audio.
+func segment(
+	text speech.Text,
+	segmentID speech.SegmentID,
+	startTime time.Duration,
+	isFinal bool,
+) *speech.Transcript {
+	t := transcript(text, isFinal)
+	for idx := range t.Variants[0].TranscriptTokens {
+		token := &t.Variants[0].TranscriptTokens[idx]
+		token.StartTime += startTime
+		token.EndTime += startTime
+	}
+	t.SegmentID = segmentID
+	return t
+}
+
+func TestWatcherDebounceAndPartials(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("final_only", func(t *testing.T) {
+		w, err := New([]Phrase{{Name: "next", Text: "next scene"}}, OptionDebounce(time.Second))
+		require.NoError(t, err)
+		require.Empty(t, w.Process(ctx, segment("next scene", 1, 0, false)))
+		require.Len(t, w.Process(ctx, segment("next scene", 1, 0, true)), 1)
+	})
+
+	t.Run("partials", func(t *testing.T) {
+		w, err := New([]Phrase{{Text: "next scene"}}, OptionFireOnPartials(true))
+		require.NoError(t, err)
+		require.Len(t, w.Process(ctx, segment("next scene", 1, 0, false)), 1)
+		// the same segment does not fire again, even much later
+		require.Empty(t, w.Process(ctx, segment("the next scene", 1, time.Minute, false)))
+		require.Empty(t, w.Process(ctx, segment("next scene", 1, 0, true)))
+	})
+
+	t.Run("segments", func(t *testing.T) {
+		w, err := New([]Phrase{{Name: "next", Text: "next scene"}}, OptionDebounce(time.Second))
+		require.NoError(t, err)
+		require.Len(t, w.Process(ctx, segment("next scene", 1, 0, true)), 1)
+		// the re-segmented audio reports the same words in another segment
+		require.Empty(t, w.Process(ctx, segment("the next scene", 2, time.Second, true)))
+		// the debounce depends on the time of the audio, not on the time of
+		// processing, so two utterances processed at once both fire
+		matches := w.Process(ctx, segment("next scene", 3, 4*time.Second, true))
+		require.Len(t, matches, 1)
+		require.Equal(t, "next", matches[0].Phrase.GetName())
+		require.Len(t, w.Process(ctx, segment("next scene", 4, 7*time.Second, true)), 1)
+	})
+
+	t.Run("no_timing", func(t *testing.T) {
+		w, err := New([]Phrase{{Text: "next scene"}})
+		require.NoError(t, err)
+		for segmentID := speech.SegmentID(1); segmentID <= 2; segmentID++ {
+			in := segment("next scene", segmentID, 0, true)
+			in.Variants[0].TranscriptTokens = nil
+			require.Len(t, w.Process(ctx, in), 1)
+		}
+	})
+}
+
+func TestWatcherIgnoredTranscripts(t *testing.T) {
+	w, err := New([]Phrase{{Text: "hello"}}, OptionDebounce(0))
+	require.NoError(t, err)
+
+	retracted := transcript("hello", true)
+	retracted.IsRetracted = true
+	translation := transcript("hello", true)
+	translation.IsTranslation = true
+	annotation := transcript("hello", true)
+	annotation.Kind = speech.TranscriptKindSuspectedHallucination
+	for _, in := range []*speech.Transcript{retracted, translation, annotation} {
+		require.Empty(t, w.Process(context.Background(), in))
+	}
+}
+
+func TestNewInvalidPhrases(t *testing.T) {
+	_, err := New([]Phrase{{Text: "(", MatchType: MatchTypeRegex}})
+	require.ErrorAs(t, err, &ErrInvalidRegex{})
+	_, err = New([]Phrase{{Text: " ?! "}})
+	require.ErrorAs(t, err, &ErrEmptyPhrase{})
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := New([]Phrase{{Text: "hello"}, {Text: "world"}})
+	require.NoError(t, err)
+
+	input := make(chan *speech.Transcript, 2)
+	input <- transcript("hello world", true)
+	input <- transcript("nothing", true)
+	close(input)
+
+	var indexes []int
+	for match := range w.Watch(ctx, input) {
+		indexes = append(indexes, match.PhraseIdx)
+	}
+	require.Equal(t, []int{0, 1}, indexes)
+}
+
+func TestSoundex(t *testing.T) {
+	for word, code := range map[string]string{
+		"Robert":     "R163",
+		"Rupert":     "R163",
+		"Ashcraft":   "A261",
+		"Tymczak":    "T522",
+		"Pfister":    "P236",
+		"Washington": "W252",
+		"A":          "A000",
+		"123":        "",
+	} {
+		require.Equal(t, code, soundex(word), word)
+	}
+}
@@ -0,0 +1,77 @@
+package phrasewatcher
+
+import (
+	"strings"
+	"unicode"
+)
+
+// similarity returns 1 minus the Levenshtein distance between the strings
+// (in runes) divided by the length of the longest one.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	maxLen := max(len(ra), len(rb))
+	if maxLen == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(maxLen)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// soundex returns the American Soundex code of the word, like "R163"
+// for "Robert"; it returns an empty string if the word has no Latin letters.
+func soundex(word string) string {
+	const codes = "01230120022455012623010202" // for 'a'...'z'
+	var result strings.Builder
+	var last byte
+	for _, r := range strings.ToLower(word) {
+		if result.Len() == 4 {
+			break
+		}
+		if r < 'a' || r > 'z' {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			// a non-Latin letter separates the consonants like a vowel
+			last = '0'
+			continue
+		}
+		code := codes[r-'a']
+		if result.Len() == 0 {
+			result.WriteByte(byte(unicode.ToUpper(r)))
+			last = code
+			continue
+		}
+		switch {
+		case r == 'h' || r == 'w':
+			// does not separate the consonants of the same code
+		case code == '0':
+			last = code
+		case code != last:
+			result.WriteByte(code)
+			last = code
+		}
+	}
+	if result.Len() == 0 {
+		return ""
+	}
+	return (result.String() + "000")[:4]
+}
@@ -0,0 +1,199 @@
+package postprocessing
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+// NumberVocabulary defines the spelled numbers of a language
+// (see InverseTextNormalization).
+type NumberVocabulary struct {
+	// Units are the numbers below a hundred, like "five", "twelve" or "forty".
+	Units map[string]uint64
+
+	// Hundred is the word of 100.
+	Hundred string
+
+	// Scales are the multipliers, like "thousand" or "million".
+	Scales map[string]uint64
+
+	// Conjunctions are the words allowed after a hundred or a scale,
+	// like "and" in "one hundred and five".
+	Conjunctions []string
+
+	// Articles are the words meaning "one" before a hundred or a scale,
+	// like "a" in "a hundred".
+	Articles []string
+}
+
+var (
+	NumberVocabularyEnglish = NumberVocabulary{
+		Units: map[string]uint64{
+			"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4,
+			"five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9,
+			"ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14,
+			"fifteen": 15, "sixteen": 16, "seventeen": 17, "eighteen": 18, "nineteen": 19,
+			"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50,
+			"sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+		},
+		Hundred: "hundred",
+		Scales: map[string]uint64{
+			"thousand": 1_000,
+			"million":  1_000_000,
+			"billion":  1_000_000_000,
+		},
+		Conjunctions: []string{"and"},
+		Articles:     []string{"a"},
+	}
+)
+
+// InverseTextNormalization converts the spelled numbers to digits,
+// like "twenty five" to "25".
+type InverseTextNormalization struct {
+	Vocabulary NumberVocabulary
+
+	// ConvertSingleDigits enables converting the standalone numbers
+	// below ten (like "one" in "one of them"); by default they are kept
+	// spelled, as it is usual in a text.
+	ConvertSingleDigits bool
+}
+
+var _ Transformer = (*InverseTextNormalization)(nil)
+
+// NewInverseTextNormalization returns an InverseTextNormalization
+// of the numbers of the vocabulary (like NumberVocabularyEnglish).
+func NewInverseTextNormalization(vocabulary NumberVocabulary) *InverseTextNormalization {
+	return &InverseTextNormalization{
+		Vocabulary: vocabulary,
+	}
+}
+
+// Transform implements Transformer.
+func (n *InverseTextNormalization) Transform(
+	ctx context.Context,
+	words speech.TranscriptTokens,
+	language speech.Language,
+) speech.TranscriptTokens {
+	result := make(speech.TranscriptTokens, 0, len(words))
+	for idx := 0; idx < len(words); {
+		value, count := n.parseNumber(words[idx:])
+		if count == 0 || (count == 1 && value < 10 && !n.ConvertSingleDigits) {
+			result = append(result, words[idx])
+			idx++
+			continue
+		}
+		matched := words[idx : idx+count]
+		prefix, _, _ := splitAffixes(string(matched[0].Text))
+		_, _, suffix := splitAffixes(string(matched[len(matched)-1].Text))
+		text := prefix + strconv.FormatUint(value, 10) + suffix
+		result = append(result, mergeWords(matched, speech.Text(text)))
+		idx += count
+	}
+	return result
+}
+
+// parseNumber returns the value of the longest number
+// the words begin with, and the amount of its words.
+func (n *InverseTextNormalization) parseNumber(
+	words speech.TranscriptTokens,
+) (uint64, int) {
+	var (
+		state     numberState
+		lastValue uint64
+		lastCount int
+	)
+	for idx, word := range words {
+		prefix, core, suffix := splitAffixes(string(word.Text))
+		if core == "" || (idx > 0 && prefix != "") {
+			break
+		}
+		core = strings.ToLower(core)
+
+		switch {
+		case idx == 0 && slices.Contains(n.Vocabulary.Articles, core):
+			state.Current = 1
+			state.IsArticle = true
+		case idx > 0 && slices.Contains(n.Vocabulary.Conjunctions, core):
+			if state.Current%100 != 0 || state.IsArticle {
+				return lastValue, lastCount
+			}
+		default:
+			for _, part := range strings.Split(core, "-") {
+				if !state.add(&n.Vocabulary, part) {
+					return lastValue, lastCount
+				}
+			}
+			lastValue, lastCount = state.Total+state.Current, idx+1
+		}
+
+		if suffix != "" {
+			// the punctuation ends the number
+			break
+		}
+	}
+	return lastValue, lastCount
+}
+
+type numberState struct {
+	Total     uint64
+	Current   uint64
+	LastScale uint64
+	HasWords  bool
+	IsZero    bool
+
+	// IsArticle means "a" which has to be followed by a hundred or a scale.
+	IsArticle bool
+}
+
+// add applies the next number word, it returns false
+// if the word does not continue the number.
+func (s *numberState) add(vocabulary *NumberVocabulary, word string) bool {
+	if s.IsZero {
+		return false
+	}
+	if value, ok := vocabulary.Units[word]; ok {
+		if s.IsArticle {
+			return false
+		}
+		rest := s.Current % 100
+		switch {
+		case value == 0:
+			if s.HasWords {
+				return false
+			}
+			s.IsZero = true
+		case rest == 0:
+		case rest >= 20 && rest%10 == 0 && value < 10:
+		default:
+			return false
+		}
+		s.Current += value
+		s.HasWords = true
+		return true
+	}
+	if word == vocabulary.Hundred {
+		if s.Current == 0 || s.Current >= 100 {
+			return false
+		}
+		s.Current *= 100
+		s.HasWords = true
+		s.IsArticle = false
+		return true
+	}
+	if scale, ok := vocabulary.Scales[word]; ok {
+		if s.Current == 0 || (s.LastScale != 0 && scale >= s.LastScale) {
+			return false
+		}
+		s.Total += s.Current * scale
+		s.Current = 0
+		s.LastScale = scale
+		s.HasWords = true
+		s.IsArticle = false
+		return true
+	}
+	return false
+}
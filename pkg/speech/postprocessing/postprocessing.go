@@ -0,0 +1,128 @@
+// Package postprocessing transforms the text of transcripts (masks the
+// profanity, converts the spelled numbers to digits, etc.) by a chain
+// of Transformer-s.
+package postprocessing
+
+import (
+	"context"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/observability"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+// Transformer transforms the words of a transcript (see speech.TranscriptTokens.Words).
+//
+// A Transformer could be used concurrently.
+type Transformer interface {
+	Transform(ctx context.Context, words speech.TranscriptTokens, language speech.Language) speech.TranscriptTokens
+}
+
+// Chain applies the Transformer-s one by one.
+type Chain []Transformer
+
+var _ Transformer = (Chain)(nil)
+
+// Transform implements Transformer.
+func (s Chain) Transform(
+	ctx context.Context,
+	words speech.TranscriptTokens,
+	language speech.Language,
+) speech.TranscriptTokens {
+	for _, transformer := range s {
+		words = transformer.Transform(ctx, words, language)
+	}
+	return words
+}
+
+// PerLanguage applies the rule set of the language family of the transcript.
+type PerLanguage struct {
+	Rules map[speech.LanguageFamily]Transformer
+
+	// Default is applied to the languages without rules;
+	// if nil, such transcripts are not transformed.
+	Default Transformer
+}
+
+var _ Transformer = (*PerLanguage)(nil)
+
+// Transform implements Transformer.
+func (p *PerLanguage) Transform(
+	ctx context.Context,
+	words speech.TranscriptTokens,
+	language speech.Language,
+) speech.TranscriptTokens {
+	transformer, ok := p.Rules[language.Family()]
+	if !ok {
+		transformer = p.Default
+	}
+	if transformer == nil {
+		return words
+	}
+	return transformer.Transform(ctx, words, language)
+}
+
+// Apply forwards the transcripts from the input (for example, the output
+// of a speech.ToText) to the returned channel, transforming the speech
+// transcripts (including the translations) by the transformer.
+//
+// The text of each variant is rebuilt from the transformed words, and the
+// words become the tokens of the variant.
+//
+// The returned channel is closed when the input is closed or ctx is done.
+func Apply(
+	ctx context.Context,
+	transformer Transformer,
+	input <-chan *speech.Transcript,
+) <-chan *speech.Transcript {
+	output := make(chan *speech.Transcript, 1024)
+	observability.Go(ctx, func() {
+		defer close(output)
+		logger.Debugf(ctx, "postprocessing loop")
+		defer func() { logger.Debugf(ctx, "/postprocessing loop") }()
+
+		for {
+			var (
+				t  *speech.Transcript
+				ok bool
+			)
+			select {
+			case <-ctx.Done():
+				return
+			case t, ok = <-input:
+				if !ok {
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case output <- TransformTranscript(ctx, transformer, t):
+			}
+		}
+	})
+	return output
+}
+
+// TransformTranscript returns the transcript with the variants transformed
+// by the transformer (see Apply); the input transcript is not modified.
+func TransformTranscript(
+	ctx context.Context,
+	transformer Transformer,
+	t *speech.Transcript,
+) *speech.Transcript {
+	if t.IsRetracted || t.Kind != speech.TranscriptKindSpeech {
+		return t
+	}
+	result := *t
+	result.Variants = make(speech.TranscriptVariants, 0, len(t.Variants))
+	for _, variant := range t.Variants {
+		words := transformer.Transform(ctx, variant.Words(), t.Language)
+		result.Variants = append(result.Variants, speech.TranscriptVariant{
+			Text:             words.JoinWords(),
+			TranscriptTokens: words,
+			Confidence:       variant.Confidence,
+		})
+	}
+	return &result
+}
@@ -0,0 +1,182 @@
+package postprocessing
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+func transformText(transformer Transformer, text speech.Text) speech.Text {
+	words := speech.TranscriptTokens{{Text: text}}.Words()
+	return transformer.Transform(context.Background(), words, "en").JoinWords()
+}
+
+func TestTransformers(t *testing.T) {
+	for _, tc := range []struct {
+		Name        string
+		Transformer Transformer
+		Input       speech.Text
+		Output      speech.Text
+	}{
+		{
+			Name:        "profanity",
+			Transformer: NewProfanityMask([]string{"damn*", "Heck"}),
+			Input:       "Damned, what the heck? Dam.",
+			Output:      "D*****, what the h***? Dam.",
+		},
+		{
+			Name:        "itn_cardinals",
+			Transformer: NewInverseTextNormalization(NumberVocabularyEnglish),
+			Input:       "twenty five cats, one hundred and five dogs and two thousand three hundred forty-two birds",
+			Output:      "25 cats, 105 dogs and 2342 birds",
+		},
+		{
+			Name:        "itn_small_numbers",
+			Transformer: NewInverseTextNormalization(NumberVocabularyEnglish),
+			Input:       "one of them said zero twelve a hundred times",
+			Output:      "one of them said zero 12 100 times",
+		},
+		{
+			Name:        "itn_sequences",
+			Transformer: NewInverseTextNormalization(NumberVocabularyEnglish),
+			Input:       "twenty twenty, (five million) and a cat",
+			Output:      "20 20, (5000000) and a cat",
+		},
+		{
+			Name:        "itn_single_digits",
+			Transformer: &InverseTextNormalization{Vocabulary: NumberVocabularyEnglish, ConvertSingleDigits: true},
+			Input:       "one and two",
+			Output:      "1 and 2",
+		},
+		{
+			Name: "replacements",
+			Transformer: NewReplacements(map[string]string{
+				"new york":      "New York",
+				"new york city": "NYC",
+				"gonna":         "going to",
+			}),
+			Input:  "I'm gonna visit New York city, then new york.",
+			Output: "I'm going to visit NYC, then New York.",
+		},
+		{
+			Name:        "whitespace",
+			Transformer: WhitespaceCleanup{},
+			Input:       "hello  world",
+			Output:      "hello world",
+		},
+		{
+			Name:        "sentence_casing",
+			Transformer: SentenceCasing{},
+			Input:       "hello. is it me? \"yes\" it is",
+			Output:      "Hello. Is it me? \"Yes\" it is",
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			require.Equal(t, tc.Output, transformText(tc.Transformer, tc.Input))
+		})
+	}
+}
+
+func TestWhitespaceCleanup(t *testing.T) {
+	words := WhitespaceCleanup{}.Transform(context.Background(), speech.TranscriptTokens{
+		{Text: "hello", EndTime: time.Second},
+		{Text: ""},
+		{Text: ",", StartTime: time.Second, EndTime: 2 * time.Second},
+		{Text: "big  cat", StartTime: 2 * time.Second, EndTime: 4 * time.Second},
+	}, "en")
+	require.Equal(t, speech.TranscriptTokens{
+		{Text: "hello,", EndTime: 2 * time.Second},
+		{Text: "big", StartTime: 2 * time.Second, EndTime: 3 * time.Second},
+		{Text: "cat", StartTime: 3 * time.Second, EndTime: 4 * time.Second},
+	}, words)
+}
+
+func TestInverseTextNormalizationTiming(t *testing.T) {
+	words := NewInverseTextNormalization(NumberVocabularyEnglish).Transform(context.Background(), speech.TranscriptTokens{
+		{Text: "twenty", EndTime: time.Second, Confidence: 0.75, Stability: 1},
+		{Text: "five", StartTime: time.Second, EndTime: 2 * time.Second, Confidence: 0.25, Stability: 0.5},
+		{Text: "cats", StartTime: 2 * time.Second, EndTime: 3 * time.Second},
+	}, "en")
+	require.Equal(t, speech.TranscriptTokens{
+		{Text: "25", EndTime: 2 * time.Second, Confidence: 0.5, Stability: 0.5},
+		{Text: "cats", StartTime: 2 * time.Second, EndTime: 3 * time.Second},
+	}, words)
+}
+
+type upperCase struct{}
+
+func (upperCase) Transform(
+	ctx context.Context,
+	words speech.TranscriptTokens,
+	language speech.Language,
+) speech.TranscriptTokens {
+	result := make(speech.TranscriptTokens, 0, len(words))
+	for _, word := range words {
+		word.Text = speech.Text(strings.ToUpper(string(word.Text)))
+		result = append(result, word)
+	}
+	return result
+}
+
+func TestPerLanguage(t *testing.T) {
+	transformer := &PerLanguage{
+		Rules: map[speech.LanguageFamily]Transformer{
+			"en": Chain{SentenceCasing{}, NewInverseTextNormalization(NumberVocabularyEnglish)},
+		},
+	}
+	words := speech.TranscriptTokens{{Text: " twenty one"}}.Words()
+	require.Equal(t, speech.Text("21"), transformer.Transform(context.Background(), words, "en-US").JoinWords())
+	require.Equal(t, speech.Text("twenty one"), transformer.Transform(context.Background(), words, "de").JoinWords())
+
+	transformer.Default = upperCase{}
+	require.Equal(t, speech.Text("TWENTY ONE"), transformer.Transform(context.Background(), words, "de").JoinWords())
+}
+
+func TestApply(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	input := make(chan *speech.Transcript, 3)
+	speechTranscript := &speech.Transcript{
+		Variants: speech.TranscriptVariants{{
+			Text: " hello world",
+			TranscriptTokens: speech.TranscriptTokens{
+				{Text: " hello", EndTime: time.Second},
+				{Text: " world", StartTime: time.Second, EndTime: 2 * time.Second},
+			},
+			Confidence: 0.5,
+		}},
+		Language: "en",
+		Kind:     speech.TranscriptKindSpeech,
+	}
+	retracted := &speech.Transcript{IsRetracted: true, Kind: speech.TranscriptKindSpeech}
+	hallucination := &speech.Transcript{
+		Variants: speech.TranscriptVariants{{Text: " hello"}},
+		Kind:     speech.TranscriptKindSuspectedHallucination,
+	}
+	input <- speechTranscript
+	input <- retracted
+	input <- hallucination
+	close(input)
+
+	var output []*speech.Transcript
+	for t := range Apply(ctx, upperCase{}, input) {
+		output = append(output, t)
+	}
+	require.Len(t, output, 3)
+	require.Equal(t, speech.TranscriptVariants{{
+		Text: "HELLO WORLD",
+		TranscriptTokens: speech.TranscriptTokens{
+			{Text: "HELLO", EndTime: time.Second},
+			{Text: "WORLD", StartTime: time.Second, EndTime: 2 * time.Second},
+		},
+		Confidence: 0.5,
+	}}, output[0].Variants)
+	require.Equal(t, speech.Text(" hello world"), speechTranscript.Variants[0].Text)
+	require.Same(t, retracted, output[1])
+	require.Same(t, hallucination, output[2])
+}
@@ -0,0 +1,104 @@
+package postprocessing
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+// ProfanityMask replaces the letters of the listed words
+// (except the first one) with '*', like "f***".
+type ProfanityMask struct {
+	Words    map[string]struct{}
+	Prefixes []string
+}
+
+var _ Transformer = (*ProfanityMask)(nil)
+
+// NewProfanityMask returns a ProfanityMask of the wordlist (case-insensitive);
+// an entry ending with '*' (like "damn*") matches all the words
+// with such prefix.
+func NewProfanityMask(wordlist []string) *ProfanityMask {
+	m := &ProfanityMask{
+		Words: map[string]struct{}{},
+	}
+	for _, word := range wordlist {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if prefix, ok := strings.CutSuffix(word, "*"); ok {
+			if prefix != "" {
+				m.Prefixes = append(m.Prefixes, prefix)
+			}
+			continue
+		}
+		if word != "" {
+			m.Words[word] = struct{}{}
+		}
+	}
+	return m
+}
+
+// ReadWordlist reads a wordlist: a word per line, the empty lines
+// and the lines starting with '#' are skipped.
+func ReadWordlist(r io.Reader) ([]string, error) {
+	var result []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		result = append(result, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read the wordlist: %w", err)
+	}
+	return result, nil
+}
+
+// Transform implements Transformer.
+func (m *ProfanityMask) Transform(
+	ctx context.Context,
+	words speech.TranscriptTokens,
+	language speech.Language,
+) speech.TranscriptTokens {
+	result := make(speech.TranscriptTokens, 0, len(words))
+	for _, word := range words {
+		if m.isProfane(normalizeWord(word.Text)) {
+			word.Text = maskWord(word.Text)
+		}
+		result = append(result, word)
+	}
+	return result
+}
+
+func (m *ProfanityMask) isProfane(word string) bool {
+	if word == "" {
+		return false
+	}
+	if _, ok := m.Words[word]; ok {
+		return true
+	}
+	for _, prefix := range m.Prefixes {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func maskWord(word speech.Text) speech.Text {
+	prefix, core, suffix := splitAffixes(string(word))
+	var masked strings.Builder
+	for idx, r := range []rune(core) {
+		if idx == 0 || !isWordRune(r) {
+			masked.WriteRune(r)
+			continue
+		}
+		masked.WriteByte('*')
+	}
+	return speech.Text(prefix + masked.String() + suffix)
+}
@@ -0,0 +1,104 @@
+package postprocessing
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+// Replacements replaces phrases (sequences of words) according to a dictionary.
+type Replacements struct {
+	// Phrases are sorted by the amount of words (descending),
+	// so the longest phrase matches first.
+	Phrases []Replacement
+}
+
+// Replacement is an entry of Replacements.
+type Replacement struct {
+	// Words is the normalized phrase (see normalizeWord).
+	Words []string
+	To    speech.Text
+}
+
+var _ Transformer = (*Replacements)(nil)
+
+// NewReplacements returns Replacements of the dictionary (phrase -> replacement).
+// The phrases are matched case-insensitively, ignoring the punctuation;
+// the punctuation around the matched phrase is kept.
+func NewReplacements(dictionary map[string]string) *Replacements {
+	r := &Replacements{}
+	for from, to := range dictionary {
+		var words []string
+		for _, word := range strings.Fields(from) {
+			word = normalizeWord(speech.Text(word))
+			if word != "" {
+				words = append(words, word)
+			}
+		}
+		if len(words) == 0 {
+			continue
+		}
+		r.Phrases = append(r.Phrases, Replacement{Words: words, To: speech.Text(to)})
+	}
+	sort.Slice(r.Phrases, func(i, j int) bool {
+		a, b := r.Phrases[i], r.Phrases[j]
+		if len(a.Words) != len(b.Words) {
+			return len(a.Words) > len(b.Words)
+		}
+		return strings.Join(a.Words, " ") < strings.Join(b.Words, " ")
+	})
+	return r
+}
+
+// Transform implements Transformer.
+func (r *Replacements) Transform(
+	ctx context.Context,
+	words speech.TranscriptTokens,
+	language speech.Language,
+) speech.TranscriptTokens {
+	result := make(speech.TranscriptTokens, 0, len(words))
+	for idx := 0; idx < len(words); {
+		replacement := r.match(words[idx:])
+		if replacement == nil {
+			result = append(result, words[idx])
+			idx++
+			continue
+		}
+		matched := words[idx : idx+len(replacement.Words)]
+		prefix, _, _ := splitAffixes(string(matched[0].Text))
+		_, _, suffix := splitAffixes(string(matched[len(matched)-1].Text))
+		result = append(result, mergeWords(matched, speech.Text(prefix)+replacement.To+speech.Text(suffix)))
+		idx += len(matched)
+	}
+	return result
+}
+
+func (r *Replacements) match(words speech.TranscriptTokens) *Replacement {
+	for idx := range r.Phrases {
+		phrase := &r.Phrases[idx]
+		if len(phrase.Words) > len(words) {
+			continue
+		}
+		if matchPhrase(words, phrase.Words) {
+			return phrase
+		}
+	}
+	return nil
+}
+
+// matchPhrase returns true if the words begin with the phrase; the phrase
+// may have punctuation only around it (not inside).
+func matchPhrase(words speech.TranscriptTokens, phrase []string) bool {
+	for idx, expected := range phrase {
+		prefix, core, suffix := splitAffixes(string(words[idx].Text))
+		if strings.ToLower(core) != expected {
+			return false
+		}
+		if (idx > 0 && prefix != "") || (idx < len(phrase)-1 && suffix != "") {
+			return false
+		}
+	}
+	return true
+}
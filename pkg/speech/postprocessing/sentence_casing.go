@@ -0,0 +1,46 @@
+package postprocessing
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+// SentenceCasing capitalizes the first letter of each sentence.
+type SentenceCasing struct{}
+
+var _ Transformer = SentenceCasing{}
+
+// Transform implements Transformer.
+func (SentenceCasing) Transform(
+	ctx context.Context,
+	words speech.TranscriptTokens,
+	language speech.Language,
+) speech.TranscriptTokens {
+	result := make(speech.TranscriptTokens, 0, len(words))
+	isSentenceStart := true
+	for _, word := range words {
+		if isSentenceStart {
+			word.Text = capitalize(word.Text)
+		}
+		if _, core, suffix := splitAffixes(string(word.Text)); core != "" {
+			isSentenceStart = strings.ContainsAny(suffix, ".!?…。！？")
+		}
+		result = append(result, word)
+	}
+	return result
+}
+
+// capitalize upper-cases the first letter of the word
+// (after the leading punctuation, like in `"hello`).
+func capitalize(word speech.Text) speech.Text {
+	prefix, core, suffix := splitAffixes(string(word))
+	r, size := utf8.DecodeRuneInString(core)
+	if size == 0 {
+		return word
+	}
+	return speech.Text(prefix + string(unicode.ToUpper(r)) + core[size:] + suffix)
+}
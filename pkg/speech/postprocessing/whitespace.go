@@ -0,0 +1,29 @@
+package postprocessing
+
+import (
+	"context"
+
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+// WhitespaceCleanup drops the empty words, splits the words containing
+// spaces (collapsing the repeated spaces), and attaches the standalone
+// punctuation (like " ,") to the preceding word.
+type WhitespaceCleanup struct{}
+
+var _ Transformer = WhitespaceCleanup{}
+
+// Transform implements Transformer.
+func (WhitespaceCleanup) Transform(
+	ctx context.Context,
+	words speech.TranscriptTokens,
+	language speech.Language,
+) speech.TranscriptTokens {
+	tokens := make(speech.TranscriptTokens, 0, len(words))
+	for _, word := range words {
+		// the leading space makes each of the words a separate word again
+		word.Text = " " + word.Text
+		tokens = append(tokens, word)
+	}
+	return tokens.Words()
+}
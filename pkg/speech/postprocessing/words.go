@@ -0,0 +1,46 @@
+package postprocessing
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// splitAffixes splits a word like "(hello)," into the leading punctuation
+// "(", the core "hello" and the trailing punctuation "),".
+func splitAffixes(word string) (prefix, core, suffix string) {
+	start := strings.IndexFunc(word, isWordRune)
+	if start < 0 {
+		return word, "", ""
+	}
+	end := strings.LastIndexFunc(word, isWordRune)
+	_, size := utf8.DecodeRuneInString(word[end:])
+	end += size
+	return word[:start], word[start:end], word[end:]
+}
+
+// normalizeWord returns the core of the word in the lower case.
+func normalizeWord(word speech.Text) string {
+	_, core, _ := splitAffixes(string(word))
+	return strings.ToLower(core)
+}
+
+// mergeWords returns a single word with the given text spanning
+// all the words (the statistics are merged as in speech.TranscriptTokens.Words).
+func mergeWords(words speech.TranscriptTokens, text speech.Text) speech.TranscriptToken {
+	result := words[0]
+	result.Text = text
+	for idx, word := range words[1:] {
+		result.EndTime = max(result.EndTime, word.EndTime)
+		result.Confidence += (word.Confidence - result.Confidence) / float32(idx+2)
+		result.Stability = min(result.Stability, word.Stability)
+		result.IsDTWAligned = result.IsDTWAligned && word.IsDTWAligned
+	}
+	return result
+}
@@ -0,0 +1,41 @@
+package speech
+
+import (
+	"github.com/xaionaro-go/audio/pkg/audio"
+)
+
+// SegmentSequencer assigns SegmentID-s and Revision-s to the transcripts
+// of a backend, which reports each utterance as a sequence of partial
+// (non-final) results followed by a final one.
+//
+// It is not safe for concurrent use.
+type SegmentSequencer struct {
+	lastID  SegmentID
+	current map[audio.Channel]*sequencedSegment
+}
+
+type sequencedSegment struct {
+	ID       SegmentID
+	Revision uint64
+}
+
+// Assign sets SegmentID and Revision of the transcript: all the transcripts
+// of an audio channel up to (and including) a final one describe the same segment.
+func (s *SegmentSequencer) Assign(t *Transcript) {
+	if s.current == nil {
+		s.current = map[audio.Channel]*sequencedSegment{}
+	}
+	seg := s.current[t.AudioChannelNum]
+	if seg == nil {
+		s.lastID++
+		seg = &sequencedSegment{ID: s.lastID}
+		s.current[t.AudioChannelNum] = seg
+	} else {
+		seg.Revision++
+	}
+	t.SegmentID = seg.ID
+	t.Revision = seg.Revision
+	if t.IsFinal {
+		delete(s.current, t.AudioChannelNum)
+	}
+}
@@ -26,6 +26,7 @@ type Client struct {
 }
 
 var _ speech.ToText = (*Client)(nil)
+var _ speech.LanguageDetector = (*Client)(nil)
 
 func New(
 	ctx context.Context,
@@ -122,3 +123,17 @@ func (c *Client) OutputChan(ctx context.Context) (<-chan *speech.Transcript, err
 	})
 	return result, nil
 }
+
+func (c *Client) DetectLanguage(
+	ctx context.Context,
+	audio []byte,
+) (speech.LanguageProbabilities, error) {
+	reply, err := c.SSTClient.DetectLanguage(ctx, &speechtotext_grpc.DetectLanguageRequest{
+		ContextID: c.ContextID,
+		Audio:     audio,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to detect the language: %w", err)
+	}
+	return goconv.LanguageProbabilitiesFromGRPC(reply.GetLanguages()), nil
+}
@@ -0,0 +1,90 @@
+package ensemble
+
+import (
+	"time"
+)
+
+const (
+	DefaultPolicy = PolicyFirstFinalWins
+
+	// DefaultMergeWindow is how long the finals of the other backends are
+	// awaited (since the first final of a segment) before giving up on them.
+	DefaultMergeWindow = 5 * time.Second
+
+	// DefaultAudioQueueSize is the amount of pieces of audio queued
+	// for a backend before it is considered stalled.
+	DefaultAudioQueueSize = 1024
+)
+
+type config struct {
+	Policy         Policy
+	MergeWindow    time.Duration
+	AudioQueueSize int
+
+	// PartialsFrom and FinalsFrom are indexes of backends to take
+	// the partial/final transcripts from; nil means all of them.
+	PartialsFrom []int
+	FinalsFrom   []int
+}
+
+func defaultConfig() config {
+	return config{
+		Policy:         DefaultPolicy,
+		MergeWindow:    DefaultMergeWindow,
+		AudioQueueSize: DefaultAudioQueueSize,
+	}
+}
+
+type Option interface {
+	apply(*config)
+}
+
+type Options []Option
+
+func (opts Options) apply(cfg *config) {
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+}
+
+func (opts Options) config() config {
+	cfg := defaultConfig()
+	opts.apply(&cfg)
+	return cfg
+}
+
+type OptionPolicy Policy
+
+func (opt OptionPolicy) apply(cfg *config) {
+	cfg.Policy = Policy(opt)
+}
+
+type OptionMergeWindow time.Duration
+
+func (opt OptionMergeWindow) apply(cfg *config) {
+	cfg.MergeWindow = time.Duration(opt)
+}
+
+// OptionAudioQueueSize is the amount of pieces of audio queued for
+// a backend; a backend is considered failed if its queue is full.
+type OptionAudioQueueSize int
+
+func (opt OptionAudioQueueSize) apply(cfg *config) {
+	cfg.AudioQueueSize = int(opt)
+}
+
+// OptionPartialsFrom limits the backends (by index) whose non-final
+// transcripts are forwarded; for example, to show partials of a fast model only.
+type OptionPartialsFrom []int
+
+func (opt OptionPartialsFrom) apply(cfg *config) {
+	cfg.PartialsFrom = opt
+}
+
+// OptionFinalsFrom limits the backends (by index) whose final transcripts
+// are used; for example, to take finals from a large model only.
+type OptionFinalsFrom []int
+
+func (opt OptionFinalsFrom) apply(cfg *config) {
+	cfg.FinalsFrom = opt
+}
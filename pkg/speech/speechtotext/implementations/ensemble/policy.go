@@ -0,0 +1,73 @@
+package ensemble
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Policy defines how the outputs of the backends are merged.
+type Policy int
+
+const (
+	PolicyUndefined = Policy(iota)
+
+	// PolicyFirstFinalWins forwards the first final transcript of a segment
+	// and drops the finals of the other backends for the same segment.
+	PolicyFirstFinalWins
+
+	// PolicyHighestConfidence collects the finals of all the backends for
+	// a segment and forwards the one with the highest confidence.
+	PolicyHighestConfidence
+
+	// PolicyFallbackOnError forwards only the outputs of the first backend
+	// which has not failed yet.
+	PolicyFallbackOnError
+
+	// PolicyROVER collects the finals of all the backends for a segment
+	// and builds the result by word voting (Recognizer Output Voting Error Reduction).
+	PolicyROVER
+
+	EndOfPolicy
+)
+
+// String just implements fmt.Stringer, flag.Value and pflag.Value.
+func (p Policy) String() string {
+	switch p {
+	case PolicyUndefined:
+		return "<undefined>"
+	case PolicyFirstFinalWins:
+		return "first-final-wins"
+	case PolicyHighestConfidence:
+		return "highest-confidence"
+	case PolicyFallbackOnError:
+		return "fallback-on-error"
+	case PolicyROVER:
+		return "rover"
+	}
+	return fmt.Sprintf("unknown_%d", int(p))
+}
+
+// Set just implements flag.Value and pflag.Value.
+func (p *Policy) Set(value string) error {
+	newPolicy, err := ParsePolicy(value)
+	if err != nil {
+		return err
+	}
+	*p = newPolicy
+	return nil
+}
+
+// Type just implements pflag.Value.
+func (p *Policy) Type() string {
+	return "Policy"
+}
+
+func ParsePolicy(in string) (Policy, error) {
+	in = strings.ToLower(in)
+	for p := PolicyUndefined + 1; p < EndOfPolicy; p++ {
+		if p.String() == in {
+			return p, nil
+		}
+	}
+	return PolicyUndefined, fmt.Errorf("unknown policy '%s'", in)
+}
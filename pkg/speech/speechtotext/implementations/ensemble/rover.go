@@ -0,0 +1,202 @@
+package ensemble
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+type roverWord struct {
+	Key   string
+	Token speech.TranscriptToken
+}
+
+// variantWords splits a variant into words (see speech.TranscriptVariant.Words),
+// skipping the ones without letters or digits.
+func variantWords(v speech.TranscriptVariant) []roverWord {
+	var result []roverWord
+	for _, word := range v.Words() {
+		if key := normalizeWord(string(word.Text)); key != "" {
+			result = append(result, roverWord{Key: key, Token: word})
+		}
+	}
+	return result
+}
+
+func normalizeWord(w string) string {
+	return strings.ToLower(strings.TrimFunc(w, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
+
+type roverVote struct {
+	Count      int
+	Confidence float32
+	Token      speech.TranscriptToken
+}
+
+// roverSlot is a position in the word transition network; the empty key
+// is a vote for "no word here".
+type roverSlot map[string]*roverVote
+
+func (s roverSlot) addVote(key string, token speech.TranscriptToken) {
+	v := s[key]
+	if v == nil {
+		v = &roverVote{Token: token}
+		s[key] = v
+	}
+	v.Count++
+	v.Confidence += token.Confidence
+	if token.Confidence > v.Token.Confidence {
+		v.Token = token
+	}
+}
+
+func (s roverSlot) best() (string, *roverVote) {
+	var (
+		bestKey  string
+		bestVote *roverVote
+	)
+	for key, v := range s {
+		switch {
+		case bestVote == nil,
+			v.Count > bestVote.Count,
+			v.Count == bestVote.Count && v.Confidence > bestVote.Confidence,
+			v.Count == bestVote.Count && v.Confidence == bestVote.Confidence && key < bestKey:
+			bestKey, bestVote = key, v
+		}
+	}
+	return bestKey, bestVote
+}
+
+// alignToNetwork aligns the words to the slots (by Levenshtein distance)
+// and returns the updated network.
+func alignToNetwork(
+	network []roverSlot,
+	hypothesesCount int,
+	hypothesisConfidence float32,
+	words []roverWord,
+) []roverSlot {
+	n, m := len(network), len(words)
+	cost := make([][]int, n+1)
+	for i := range cost {
+		cost[i] = make([]int, m+1)
+		cost[i][0] = i
+	}
+	for j := 0; j <= m; j++ {
+		cost[0][j] = j
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			substCost := 1
+			if _, ok := network[i-1][words[j-1].Key]; ok {
+				substCost = 0
+			}
+			cost[i][j] = min(
+				cost[i-1][j-1]+substCost,
+				cost[i-1][j]+1,
+				cost[i][j-1]+1,
+			)
+		}
+	}
+
+	nullToken := speech.TranscriptToken{Confidence: hypothesisConfidence}
+	var reversed []roverSlot
+	i, j := n, m
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && cost[i][j] == cost[i-1][j-1]+boolToInt(network[i-1][words[j-1].Key] == nil):
+			network[i-1].addVote(words[j-1].Key, words[j-1].Token)
+			reversed = append(reversed, network[i-1])
+			i--
+			j--
+		case i > 0 && cost[i][j] == cost[i-1][j]+1:
+			network[i-1].addVote("", nullToken)
+			reversed = append(reversed, network[i-1])
+			i--
+		default:
+			slot := roverSlot{}
+			if hypothesesCount > 0 {
+				slot[""] = &roverVote{Count: hypothesesCount}
+			}
+			slot.addVote(words[j-1].Key, words[j-1].Token)
+			reversed = append(reversed, slot)
+			j--
+		}
+	}
+
+	result := make([]roverSlot, 0, len(reversed))
+	for idx := len(reversed) - 1; idx >= 0; idx-- {
+		result = append(result, reversed[idx])
+	}
+	return result
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// rover merges the transcripts of the same segment by word voting;
+// the non-word properties are taken from the most confident transcript.
+func rover(transcripts []*speech.Transcript) *speech.Transcript {
+	transcripts = sortedByConfidence(transcripts)
+	best := transcripts[0]
+
+	var network []roverSlot
+	for idx, t := range transcripts {
+		if len(t.Variants) == 0 {
+			continue
+		}
+		network = alignToNetwork(network, idx, t.Variants[0].Confidence, variantWords(t.Variants[0]))
+	}
+
+	var (
+		texts          []string
+		tokens         speech.TranscriptTokens
+		agreementTotal float32
+	)
+	for _, slot := range network {
+		key, vote := slot.best()
+		if key == "" {
+			continue
+		}
+		token := vote.Token
+		texts = append(texts, string(token.Text))
+		if len(tokens) > 0 {
+			token.Text = " " + token.Text
+		}
+		tokens = append(tokens, token)
+		agreementTotal += float32(vote.Count) / float32(len(transcripts))
+	}
+
+	result := *best
+	result.Variants = []speech.TranscriptVariant{{
+		Text:             speech.Text(strings.Join(texts, " ")),
+		TranscriptTokens: tokens,
+	}}
+	if len(tokens) > 0 {
+		result.Variants[0].Confidence = agreementTotal / float32(len(tokens))
+	}
+	return &result
+}
+
+func transcriptConfidence(t *speech.Transcript) float32 {
+	if len(t.Variants) == 0 {
+		return 0
+	}
+	return t.Variants[0].Confidence
+}
+
+func sortedByConfidence(transcripts []*speech.Transcript) []*speech.Transcript {
+	result := make([]*speech.Transcript, len(transcripts))
+	copy(result, transcripts)
+	sort.SliceStable(result, func(i, j int) bool {
+		return transcriptConfidence(result[i]) > transcriptConfidence(result[j])
+	})
+	return result
+}
@@ -0,0 +1,634 @@
+package ensemble
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/hashicorp/go-multierror"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/observability"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+type backend struct {
+	speech.ToText
+	Index  int
+	Failed atomic.Bool
+	Err    atomic.Pointer[error]
+
+	// AudioQueue is the audio to be written to the backend by its own
+	// goroutine, so that a stalled backend does not block the others.
+	AudioQueue chan []byte
+}
+
+// segmentKey identifies a segment of a backend.
+type segmentKey struct {
+	BackendIdx int
+	SegmentID  speech.SegmentID
+}
+
+// identity is a segment of the ensemble (see speech.Transcript.SegmentID).
+type identity struct {
+	SegmentID       speech.SegmentID
+	Revision        uint64
+	AudioChannelNum audio.Channel
+}
+
+type backendOutput struct {
+	Backend *backend
+
+	// Transcript is nil if the output channel of the backend was closed.
+	Transcript *speech.Transcript
+}
+
+// segment is a set of final transcripts of different backends,
+// which are considered to describe the same piece of audio.
+type segment struct {
+	CreatedAt time.Time
+	StartTime time.Duration
+	EndTime   time.Duration
+	Finals    map[int]*speech.Transcript
+	Order     []int
+	IsEmitted bool
+
+	// Identity is the identity the segment is emitted with.
+	Identity *identity
+
+	// Result is the last emitted transcript of the segment.
+	Result *speech.Transcript
+}
+
+// emittedHistorySize is the amount of the flushed segments remembered
+// to merge the finals, which arrived after the merge window.
+const emittedHistorySize = 16
+
+// SpeechToText fans the audio out to multiple backends and merges
+// their outputs according to the Policy.
+type SpeechToText struct {
+	closeCount    atomic.Uint64
+	wg            sync.WaitGroup
+	config        config
+	backends      []*backend
+	audioEncoding audio.Encoding
+	audioChannels audio.Channel
+	cancelFunc    context.CancelFunc
+	outputs       chan backendOutput
+	resultQueue   chan *speech.Transcript
+
+	// segments, emitted, identities and lastSegmentID are accessed only by the merging loop.
+	segments      []*segment
+	emitted       []*segment
+	identities    map[segmentKey]*identity
+	lastSegmentID speech.SegmentID
+}
+
+var _ speech.ToText = (*SpeechToText)(nil)
+
+// New creates an ensemble of the given backends; all the backends should
+// consume the same audio encoding and channels. The backends are closed
+// on Close.
+func New(
+	ctx context.Context,
+	backends []speech.ToText,
+	opts ...Option,
+) (*SpeechToText, error) {
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("no backends provided")
+	}
+	cfg := Options(opts).config()
+	if cfg.Policy <= PolicyUndefined || cfg.Policy >= EndOfPolicy {
+		return nil, fmt.Errorf("invalid policy: %v", cfg.Policy)
+	}
+
+	audioEncoding, err := backends[0].AudioEncoding(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the audio encoding of backend #0: %w", err)
+	}
+	audioChannels, err := backends[0].AudioChannels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the audio channels of backend #0: %w", err)
+	}
+	for idx, b := range backends[1:] {
+		enc, err := b.AudioEncoding(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the audio encoding of backend #%d: %w", idx+1, err)
+		}
+		if enc != audioEncoding {
+			return nil, fmt.Errorf("backend #%d expects audio encoding %#+v, while backend #0 expects %#+v", idx+1, enc, audioEncoding)
+		}
+		ch, err := b.AudioChannels(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the audio channels of backend #%d: %w", idx+1, err)
+		}
+		if ch != audioChannels {
+			return nil, fmt.Errorf("backend #%d expects %d audio channels, while backend #0 expects %d", idx+1, ch, audioChannels)
+		}
+	}
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	stt := &SpeechToText{
+		config:        cfg,
+		audioEncoding: audioEncoding,
+		audioChannels: audioChannels,
+		cancelFunc:    cancelFunc,
+		outputs:       make(chan backendOutput),
+		resultQueue:   make(chan *speech.Transcript, 1024),
+		identities:    map[segmentKey]*identity{},
+	}
+	for idx, b := range backends {
+		stt.backends = append(stt.backends, &backend{
+			ToText:     b,
+			Index:      idx,
+			AudioQueue: make(chan []byte, cfg.AudioQueueSize),
+		})
+	}
+
+	for _, b := range stt.backends {
+		outCh, err := b.OutputChan(ctx)
+		if err != nil {
+			cancelFunc()
+			stt.wg.Wait()
+			return nil, fmt.Errorf("unable to get the output channel of backend #%d: %w", b.Index, err)
+		}
+		stt.wg.Add(2)
+		observability.Go(ctx, func() {
+			defer stt.wg.Done()
+			stt.readBackend(ctx, b, outCh)
+		})
+		observability.Go(ctx, func() {
+			defer stt.wg.Done()
+			stt.writeBackend(ctx, b)
+		})
+	}
+
+	stt.wg.Add(1)
+	observability.Go(ctx, func() {
+		defer stt.wg.Done()
+		defer close(stt.resultQueue)
+		stt.mergeLoop(ctx)
+	})
+
+	return stt, nil
+}
+
+func (stt *SpeechToText) AudioEncoding(context.Context) (audio.Encoding, error) {
+	return stt.audioEncoding, nil
+}
+
+func (stt *SpeechToText) AudioChannels(context.Context) (audio.Channel, error) {
+	return stt.audioChannels, nil
+}
+
+func (stt *SpeechToText) markFailed(
+	ctx context.Context,
+	b *backend,
+	err error,
+) {
+	if !b.Err.CompareAndSwap(nil, &err) {
+		return
+	}
+	b.Failed.Store(true)
+	logger.Errorf(ctx, "backend #%d failed: %v", b.Index, err)
+}
+
+func (stt *SpeechToText) writeBackend(
+	ctx context.Context,
+	b *backend,
+) {
+	logger.Debugf(ctx, "writeBackend(ctx, #%d)", b.Index)
+	defer func() { logger.Debugf(ctx, "/writeBackend(ctx, #%d)", b.Index) }()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case audio := <-b.AudioQueue:
+			if b.Failed.Load() {
+				continue
+			}
+			if err := b.WriteAudio(ctx, audio); err != nil {
+				stt.markFailed(ctx, b, fmt.Errorf("unable to write the audio: %w", err))
+			}
+		}
+	}
+}
+
+func (stt *SpeechToText) readBackend(
+	ctx context.Context,
+	b *backend,
+	outCh <-chan *speech.Transcript,
+) {
+	logger.Debugf(ctx, "readBackend(ctx, #%d)", b.Index)
+	defer func() { logger.Debugf(ctx, "/readBackend(ctx, #%d)", b.Index) }()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t, ok := <-outCh:
+			if !ok {
+				if ctx.Err() == nil {
+					stt.markFailed(ctx, b, fmt.Errorf("the output channel is closed"))
+				}
+				t = nil
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case stt.outputs <- backendOutput{Backend: b, Transcript: t}:
+			}
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+func (stt *SpeechToText) mergeLoop(ctx context.Context) {
+	logger.Debugf(ctx, "mergeLoop")
+	defer func() { logger.Debugf(ctx, "/mergeLoop") }()
+
+	// since Go 1.23 Reset discards a stale tick, so the timer is reused without draining
+	timer := time.NewTimer(stt.config.MergeWindow)
+	defer timer.Stop()
+	for {
+		var timeoutCh <-chan time.Time
+		if len(stt.segments) > 0 {
+			deadline := stt.segments[0].CreatedAt.Add(stt.config.MergeWindow)
+			timer.Reset(time.Until(deadline))
+			timeoutCh = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case out := <-stt.outputs:
+			stt.handleOutput(ctx, out)
+		case <-timeoutCh:
+		}
+		stt.flushSegments(ctx, time.Now())
+	}
+}
+
+// identityOf returns the identity of the segment of the backend
+// (nil, if the backend does not track segments).
+func (stt *SpeechToText) identityOf(key segmentKey) *identity {
+	if key.SegmentID == 0 {
+		return nil
+	}
+	id := stt.identities[key]
+	if id == nil {
+		id = stt.newIdentity()
+		stt.identities[key] = id
+	}
+	return id
+}
+
+func (stt *SpeechToText) newIdentity() *identity {
+	stt.lastSegmentID++
+	return &identity{SegmentID: stt.lastSegmentID}
+}
+
+func transcriptKey(backendIdx int, t *speech.Transcript) segmentKey {
+	return segmentKey{BackendIdx: backendIdx, SegmentID: t.SegmentID}
+}
+
+// emit sends the transcript as a revision of the segment of the ensemble;
+// the identities of the backends are not related to each other, so
+// they are replaced.
+func (stt *SpeechToText) emit(
+	ctx context.Context,
+	t *speech.Transcript,
+	id *identity,
+) {
+	result := *t
+	result.SegmentID, result.Revision = 0, 0
+	if id != nil {
+		id.Revision++
+		id.AudioChannelNum = result.AudioChannelNum
+		result.SegmentID, result.Revision = id.SegmentID, id.Revision
+	}
+	select {
+	case stt.resultQueue <- &result:
+	default:
+		logger.Error(ctx, "the queue is full, dropping the message")
+	}
+}
+
+// finishKey forgets the segment of the backend, retracting it
+// unless it is emitted as the given identity.
+func (stt *SpeechToText) finishKey(
+	ctx context.Context,
+	key segmentKey,
+	emittedAs *identity,
+) {
+	id, ok := stt.identities[key]
+	if !ok {
+		return
+	}
+	delete(stt.identities, key)
+	if id == emittedAs || id.Revision == 0 {
+		return
+	}
+	stt.emit(ctx, &speech.Transcript{
+		AudioChannelNum: id.AudioChannelNum,
+		IsRetracted:     true,
+	}, id)
+}
+
+func (stt *SpeechToText) activeBackend() *backend {
+	for _, b := range stt.backends {
+		if !b.Failed.Load() {
+			return b
+		}
+	}
+	return nil
+}
+
+func isSelected(selected []int, idx int) bool {
+	return selected == nil || slices.Contains(selected, idx)
+}
+
+func (stt *SpeechToText) handleOutput(
+	ctx context.Context,
+	out backendOutput,
+) {
+	t := out.Transcript
+	if t == nil {
+		return
+	}
+	logger.Tracef(ctx, "backend #%d: %#+v", out.Backend.Index, t)
+	key := transcriptKey(out.Backend.Index, t)
+	if t.IsTranslation {
+		// the translations are not merged across the backends
+		return
+	}
+	if t.IsRetracted {
+		stt.finishKey(ctx, key, nil)
+		return
+	}
+
+	if stt.config.Policy == PolicyFallbackOnError && out.Backend != stt.activeBackend() {
+		return
+	}
+
+	if !t.IsFinal {
+		if isSelected(stt.config.PartialsFrom, out.Backend.Index) {
+			stt.emit(ctx, t, stt.identityOf(key))
+		}
+		return
+	}
+
+	if !isSelected(stt.config.FinalsFrom, out.Backend.Index) {
+		// the final of the backend is not used, so are its partials
+		stt.finishKey(ctx, key, nil)
+		return
+	}
+
+	if stt.config.Policy == PolicyFallbackOnError {
+		id := stt.identityOf(key)
+		stt.emit(ctx, t, id)
+		stt.finishKey(ctx, key, id)
+		return
+	}
+
+	seg := stt.findSegment(out.Backend.Index, t)
+	if seg == nil {
+		if seg = stt.findEmittedSegment(out.Backend.Index, t); seg != nil {
+			stt.handleLateFinal(ctx, key, seg, t)
+			return
+		}
+		seg = stt.newSegment(t)
+	}
+	seg.Finals[out.Backend.Index] = t
+	seg.Order = append(seg.Order, out.Backend.Index)
+
+	if stt.config.Policy != PolicyFirstFinalWins {
+		return
+	}
+	if !seg.IsEmitted {
+		seg.Identity = stt.identityOf(key)
+		if seg.Identity == nil {
+			seg.Identity = stt.newIdentity()
+		}
+		stt.emit(ctx, t, seg.Identity)
+		seg.Result = t
+		seg.IsEmitted = true
+	}
+	stt.finishKey(ctx, key, seg.Identity)
+}
+
+// handleLateFinal merges the final, which arrived after its segment was
+// flushed; it is emitted as a revision of the segment only if it changes
+// the result (e.g. never under PolicyFirstFinalWins).
+func (stt *SpeechToText) handleLateFinal(
+	ctx context.Context,
+	key segmentKey,
+	seg *segment,
+	t *speech.Transcript,
+) {
+	seg.Finals[key.BackendIdx] = t
+	seg.Order = append(seg.Order, key.BackendIdx)
+	if result := stt.merge(seg); result != seg.Result {
+		stt.emit(ctx, result, seg.Identity)
+		seg.Result = result
+	}
+	stt.finishKey(ctx, key, seg.Identity)
+}
+
+func transcriptTimeRange(t *speech.Transcript) (time.Duration, time.Duration, bool) {
+	if len(t.Variants) == 0 {
+		return 0, 0, false
+	}
+	startTime, endTime := t.Variants[0].StartTime(), t.Variants[0].EndTime()
+	return startTime, endTime, endTime > startTime
+}
+
+// findSegment returns the oldest pending segment, which does not have a final
+// from the backend yet, and overlaps with the transcript (if the timing is known).
+func (stt *SpeechToText) findSegment(
+	backendIdx int,
+	t *speech.Transcript,
+) *segment {
+	startTime, endTime, hasTime := transcriptTimeRange(t)
+	for _, seg := range stt.segments {
+		if _, ok := seg.Finals[backendIdx]; ok {
+			continue
+		}
+		segHasTime := seg.EndTime > seg.StartTime
+		if hasTime && segHasTime && (startTime >= seg.EndTime || seg.StartTime >= endTime) {
+			continue
+		}
+		if hasTime && !segHasTime {
+			seg.StartTime, seg.EndTime = startTime, endTime
+		}
+		if hasTime && segHasTime {
+			seg.StartTime, seg.EndTime = min(seg.StartTime, startTime), max(seg.EndTime, endTime)
+		}
+		return seg
+	}
+	return nil
+}
+
+// findEmittedSegment returns the oldest flushed segment, which does not
+// have a final from the backend, and overlaps with the transcript; without
+// the timing a late final is indistinguishable from a new one, so nil is returned.
+func (stt *SpeechToText) findEmittedSegment(
+	backendIdx int,
+	t *speech.Transcript,
+) *segment {
+	startTime, endTime, hasTime := transcriptTimeRange(t)
+	if !hasTime {
+		return nil
+	}
+	for _, seg := range stt.emitted {
+		if _, ok := seg.Finals[backendIdx]; ok {
+			continue
+		}
+		if seg.EndTime <= seg.StartTime || startTime >= seg.EndTime || seg.StartTime >= endTime {
+			continue
+		}
+		return seg
+	}
+	return nil
+}
+
+func (stt *SpeechToText) newSegment(t *speech.Transcript) *segment {
+	startTime, endTime, _ := transcriptTimeRange(t)
+	seg := &segment{
+		CreatedAt: time.Now(),
+		StartTime: startTime,
+		EndTime:   endTime,
+		Finals:    map[int]*speech.Transcript{},
+	}
+	stt.segments = append(stt.segments, seg)
+	return seg
+}
+
+// merge returns the transcript to be emitted for the finals of the segment.
+func (stt *SpeechToText) merge(seg *segment) *speech.Transcript {
+	finals := make([]*speech.Transcript, 0, len(seg.Order))
+	for _, idx := range seg.Order {
+		finals = append(finals, seg.Finals[idx])
+	}
+	switch stt.config.Policy {
+	case PolicyHighestConfidence:
+		return sortedByConfidence(finals)[0]
+	case PolicyROVER:
+		return rover(finals)
+	default:
+		return finals[0]
+	}
+}
+
+func (stt *SpeechToText) isSegmentComplete(seg *segment) bool {
+	for _, b := range stt.backends {
+		if b.Failed.Load() || !isSelected(stt.config.FinalsFrom, b.Index) {
+			continue
+		}
+		if _, ok := seg.Finals[b.Index]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (stt *SpeechToText) flushSegments(
+	ctx context.Context,
+	now time.Time,
+) {
+	var pending []*segment
+	for _, seg := range stt.segments {
+		if !stt.isSegmentComplete(seg) && now.Before(seg.CreatedAt.Add(stt.config.MergeWindow)) {
+			pending = append(pending, seg)
+			continue
+		}
+		stt.emitted = append(stt.emitted, seg)
+		if seg.IsEmitted {
+			continue
+		}
+
+		// the merged final replaces the partials of the first backend
+		// with a tracked segment, the partials of the rest are retracted
+		for _, idx := range seg.Order {
+			if id := stt.identities[transcriptKey(idx, seg.Finals[idx])]; id != nil {
+				seg.Identity = id
+				break
+			}
+		}
+		if seg.Identity == nil {
+			seg.Identity = stt.newIdentity()
+		}
+		seg.Result = stt.merge(seg)
+		stt.emit(ctx, seg.Result, seg.Identity)
+		seg.IsEmitted = true
+		for _, idx := range seg.Order {
+			stt.finishKey(ctx, transcriptKey(idx, seg.Finals[idx]), seg.Identity)
+		}
+	}
+	stt.segments = pending
+	if extra := len(stt.emitted) - emittedHistorySize; extra > 0 {
+		stt.emitted = slices.Delete(stt.emitted, 0, extra)
+	}
+}
+
+// WriteAudio queues the audio to every backend, which has not failed, yet;
+// the backends consume the audio independently, and a backend with
+// the full queue (see OptionAudioQueueSize) is considered failed.
+// An error is returned only if all the backends failed.
+func (stt *SpeechToText) WriteAudio(
+	ctx context.Context,
+	audio []byte,
+) error {
+	// the caller may reuse the buffer, while the backends are consuming it
+	audio = slices.Clone(audio)
+	for _, b := range stt.backends {
+		if b.Failed.Load() {
+			continue
+		}
+		select {
+		case b.AudioQueue <- audio:
+		default:
+			stt.markFailed(ctx, b, fmt.Errorf("the audio queue is full (%d pieces), the backend is stalled", cap(b.AudioQueue)))
+		}
+	}
+	if stt.activeBackend() != nil {
+		return nil
+	}
+	var mErr *multierror.Error
+	for _, b := range stt.backends {
+		if err := b.Err.Load(); err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("backend #%d: %w", b.Index, *err))
+		}
+	}
+	return fmt.Errorf("all the backends failed: %w", mErr.ErrorOrNil())
+}
+
+func (stt *SpeechToText) OutputChan(context.Context) (<-chan *speech.Transcript, error) {
+	return stt.resultQueue, nil
+}
+
+func (stt *SpeechToText) Close() error {
+	if stt.closeCount.Add(1) != 1 {
+		return fmt.Errorf("already closed")
+	}
+
+	stt.cancelFunc()
+	var mErr *multierror.Error
+	for _, b := range stt.backends {
+		if err := b.Close(); err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("unable to close backend #%d: %w", b.Index, err))
+		}
+	}
+	stt.waitForClosure()
+	return mErr.ErrorOrNil()
+}
+
+func (stt *SpeechToText) waitForClosure() {
+	stt.wg.Wait()
+}
@@ -0,0 +1,332 @@
+package ensemble
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+type fakeToText struct {
+	encoding audio.Encoding
+	out      chan *speech.Transcript
+
+	// stall blocks WriteAudio until it is closed (if not nil).
+	stall chan struct{}
+
+	locker   sync.Mutex
+	writeErr error
+	written  int
+}
+
+var _ speech.ToText = (*fakeToText)(nil)
+
+func newFakeToText() *fakeToText {
+	return &fakeToText{
+		encoding: audio.EncodingPCM{PCMFormat: audio.PCMFormatFloat32LE, SampleRate: 16000},
+		out:      make(chan *speech.Transcript, 10),
+	}
+}
+
+func (f *fakeToText) Close() error { return nil }
+func (f *fakeToText) AudioEncoding(context.Context) (audio.Encoding, error) {
+	return f.encoding, nil
+}
+func (f *fakeToText) AudioChannels(context.Context) (audio.Channel, error) { return 1, nil }
+func (f *fakeToText) WriteAudio(_ context.Context, b []byte) error {
+	if f.stall != nil {
+		<-f.stall
+	}
+	f.locker.Lock()
+	defer f.locker.Unlock()
+	if f.writeErr != nil {
+		return f.writeErr
+	}
+	f.written += len(b)
+	return nil
+}
+func (f *fakeToText) setWriteErr(err error) {
+	f.locker.Lock()
+	defer f.locker.Unlock()
+	f.writeErr = err
+}
+func (f *fakeToText) writtenBytes() int {
+	f.locker.Lock()
+	defer f.locker.Unlock()
+	return f.written
+}
+func (f *fakeToText) OutputChan(context.Context) (<-chan *speech.Transcript, error) {
+	return f.out, nil
+}
+
+func final(text string, startTime, endTime time.Duration, confidence float32) *speech.Transcript {
+	return &speech.Transcript{
+		Variants: []speech.TranscriptVariant{{
+			Text: speech.Text(text),
+			TranscriptTokens: []speech.TranscriptToken{{
+				StartTime:  startTime,
+				EndTime:    endTime,
+				Text:       speech.Text(text),
+				Confidence: confidence,
+			}},
+			Confidence: confidence,
+		}},
+		IsFinal: true,
+	}
+}
+
+func partial(segmentID speech.SegmentID, text string) *speech.Transcript {
+	return &speech.Transcript{
+		Variants:  []speech.TranscriptVariant{{Text: speech.Text(text)}},
+		SegmentID: segmentID,
+	}
+}
+
+func newTestEnsemble(t *testing.T, count int, opts ...Option) (*SpeechToText, []*fakeToText, <-chan *speech.Transcript) {
+	var (
+		fakes    []*fakeToText
+		backends []speech.ToText
+	)
+	for range count {
+		f := newFakeToText()
+		fakes = append(fakes, f)
+		backends = append(backends, f)
+	}
+	stt, err := New(context.Background(), backends, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { stt.Close() })
+	outCh, err := stt.OutputChan(context.Background())
+	require.NoError(t, err)
+	return stt, fakes, outCh
+}
+
+func next(t *testing.T, ch <-chan *speech.Transcript) *speech.Transcript {
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+		return nil
+	}
+}
+
+func requireNothing(t *testing.T, ch <-chan *speech.Transcript) {
+	select {
+	case r := <-ch:
+		t.Fatalf("unexpected transcript: %#+v", r)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestFirstFinalWins(t *testing.T) {
+	_, fakes, outCh := newTestEnsemble(t, 2, OptionPolicy(PolicyFirstFinalWins), OptionPartialsFrom{0})
+
+	fakes[1].out <- &speech.Transcript{Variants: []speech.TranscriptVariant{{Text: "slow partial"}}}
+	fakes[0].out <- &speech.Transcript{Variants: []speech.TranscriptVariant{{Text: "fast partial"}}}
+	require.Equal(t, speech.Text("fast partial"), next(t, outCh).Variants[0].Text)
+
+	fakes[1].out <- final("hello", time.Second, 2*time.Second, 0.9)
+	require.Equal(t, speech.Text("hello"), next(t, outCh).Variants[0].Text)
+
+	fakes[0].out <- final("hallo", time.Second+100*time.Millisecond, 2*time.Second, 0.5)
+	requireNothing(t, outCh)
+
+	fakes[0].out <- final("world", 3*time.Second, 4*time.Second, 0.5)
+	require.Equal(t, speech.Text("world"), next(t, outCh).Variants[0].Text)
+}
+
+func TestHighestConfidence(t *testing.T) {
+	_, fakes, outCh := newTestEnsemble(t, 2,
+		OptionPolicy(PolicyHighestConfidence),
+		OptionMergeWindow(200*time.Millisecond),
+	)
+
+	fakes[0].out <- final("hallo", time.Second, 2*time.Second, 0.5)
+	requireNothing(t, outCh)
+	fakes[1].out <- final("hello", time.Second, 2*time.Second, 0.9)
+	require.Equal(t, speech.Text("hello"), next(t, outCh).Variants[0].Text)
+
+	// backend #1 does not respond, so the result is emitted on the merge window timeout
+	fakes[0].out <- final("world", 3*time.Second, 4*time.Second, 0.5)
+	require.Equal(t, speech.Text("world"), next(t, outCh).Variants[0].Text)
+}
+
+func TestLateFinal(t *testing.T) {
+	t.Run("FirstFinalWins", func(t *testing.T) {
+		_, fakes, outCh := newTestEnsemble(t, 2,
+			OptionPolicy(PolicyFirstFinalWins),
+			OptionMergeWindow(100*time.Millisecond),
+		)
+
+		fakes[0].out <- final("hello", time.Second, 2*time.Second, 0.5)
+		require.Equal(t, speech.Text("hello"), next(t, outCh).Variants[0].Text)
+		time.Sleep(200 * time.Millisecond)
+
+		// the segment is flushed, but the late final is still recognized as its part
+		fakes[1].out <- final("hallo", time.Second, 2*time.Second, 0.9)
+		requireNothing(t, outCh)
+	})
+
+	t.Run("HighestConfidence", func(t *testing.T) {
+		_, fakes, outCh := newTestEnsemble(t, 2,
+			OptionPolicy(PolicyHighestConfidence),
+			OptionMergeWindow(100*time.Millisecond),
+		)
+
+		fakes[0].out <- final("hallo", time.Second, 2*time.Second, 0.5)
+		first := next(t, outCh)
+		require.Equal(t, speech.Text("hallo"), first.Variants[0].Text)
+
+		// the more confident late final revises the emitted segment
+		fakes[1].out <- final("hello", time.Second, 2*time.Second, 0.9)
+		revision := next(t, outCh)
+		require.Equal(t, speech.Text("hello"), revision.Variants[0].Text)
+		require.Equal(t, first.SegmentID, revision.SegmentID)
+		require.Equal(t, first.Revision+1, revision.Revision)
+		requireNothing(t, outCh)
+	})
+}
+
+func TestFallbackOnError(t *testing.T) {
+	stt, fakes, outCh := newTestEnsemble(t, 2, OptionPolicy(PolicyFallbackOnError))
+	ctx := context.Background()
+
+	require.NoError(t, stt.WriteAudio(ctx, make([]byte, 10)))
+	require.Eventually(t, func() bool {
+		return fakes[0].writtenBytes() == 10 && fakes[1].writtenBytes() == 10
+	}, 5*time.Second, time.Millisecond)
+
+	fakes[0].out <- final("primary", time.Second, 2*time.Second, 0.5)
+	require.Equal(t, speech.Text("primary"), next(t, outCh).Variants[0].Text)
+	fakes[1].out <- final("standby", time.Second, 2*time.Second, 0.9)
+	requireNothing(t, outCh)
+
+	fakes[0].setWriteErr(fmt.Errorf("connection refused"))
+	require.NoError(t, stt.WriteAudio(ctx, make([]byte, 10)))
+	require.Eventually(t, stt.backends[0].Failed.Load, 5*time.Second, time.Millisecond)
+	fakes[1].out <- final("fallback", 3*time.Second, 4*time.Second, 0.9)
+	require.Equal(t, speech.Text("fallback"), next(t, outCh).Variants[0].Text)
+
+	fakes[1].setWriteErr(fmt.Errorf("connection refused"))
+	require.NoError(t, stt.WriteAudio(ctx, make([]byte, 10)))
+	require.Eventually(t, stt.backends[1].Failed.Load, 5*time.Second, time.Millisecond)
+	require.ErrorContains(t, stt.WriteAudio(ctx, make([]byte, 10)), "connection refused")
+}
+
+func TestStalledBackend(t *testing.T) {
+	f0, f1 := newFakeToText(), newFakeToText()
+	f0.stall = make(chan struct{})
+	stt, err := New(context.Background(), []speech.ToText{f0, f1}, OptionAudioQueueSize(2))
+	require.NoError(t, err)
+	defer stt.Close()
+	defer close(f0.stall)
+	ctx := context.Background()
+
+	// the stalled backend does not block the others, until its queue is full
+	buf := make([]byte, 10)
+	for idx := range 5 {
+		require.NoError(t, stt.WriteAudio(ctx, buf))
+		require.Eventually(t, func() bool {
+			return f1.writtenBytes() == (idx+1)*len(buf)
+		}, 5*time.Second, time.Millisecond)
+	}
+	require.True(t, stt.backends[0].Failed.Load())
+	require.False(t, stt.backends[1].Failed.Load())
+}
+
+func TestSegmentIdentities(t *testing.T) {
+	_, fakes, outCh := newTestEnsemble(t, 2, OptionPolicy(PolicyFirstFinalWins))
+
+	fakes[0].out <- partial(7, "hel")
+	first := next(t, outCh)
+	require.NotZero(t, first.SegmentID)
+	require.Equal(t, uint64(1), first.Revision)
+
+	fakes[1].out <- partial(7, "hallo")
+	second := next(t, outCh)
+	require.NotEqual(t, first.SegmentID, second.SegmentID)
+
+	fakes[0].out <- partial(7, "hello")
+	update := next(t, outCh)
+	require.Equal(t, first.SegmentID, update.SegmentID)
+	require.Equal(t, uint64(2), update.Revision)
+
+	// the final replaces the partials of its backend
+	f := final("hello", time.Second, 2*time.Second, 0.9)
+	f.SegmentID = 7
+	fakes[0].out <- f
+	result := next(t, outCh)
+	require.True(t, result.IsFinal)
+	require.Equal(t, first.SegmentID, result.SegmentID)
+	require.Equal(t, uint64(3), result.Revision)
+
+	// the final of the other backend is dropped, so its partials are retracted
+	f = final("hallo", time.Second, 2*time.Second, 0.5)
+	f.SegmentID = 7
+	fakes[1].out <- f
+	retraction := next(t, outCh)
+	require.True(t, retraction.IsRetracted)
+	require.Equal(t, second.SegmentID, retraction.SegmentID)
+	require.Equal(t, uint64(2), retraction.Revision)
+	requireNothing(t, outCh)
+}
+
+func TestROVER(t *testing.T) {
+	_, fakes, outCh := newTestEnsemble(t, 3, OptionPolicy(PolicyROVER))
+
+	// the words of the most confident hypothesis are preferred on
+	// a tie, so the confidences differ to not depend on the arrival order
+	for idx, text := range []string{"the cat sat", "the bat sat", "The cat sat down."} {
+		fakes[idx].out <- &speech.Transcript{
+			Variants: []speech.TranscriptVariant{{Text: speech.Text(text), Confidence: 0.5 - float32(idx)*0.1}},
+			IsFinal:  true,
+		}
+	}
+	result := next(t, outCh)
+	require.True(t, result.IsFinal)
+	require.Equal(t, speech.Text("the cat sat"), result.Variants[0].Text)
+}
+
+func TestROVERTokens(t *testing.T) {
+	tokens := func(words ...string) speech.TranscriptTokens {
+		var result speech.TranscriptTokens
+		for idx, w := range words {
+			result = append(result, speech.TranscriptToken{
+				StartTime:  time.Duration(idx) * time.Second,
+				EndTime:    time.Duration(idx+1) * time.Second,
+				Text:       speech.Text(w),
+				Confidence: 0.5,
+			})
+		}
+		return result
+	}
+	result := rover([]*speech.Transcript{
+		{Variants: []speech.TranscriptVariant{{TranscriptTokens: tokens("hel", "lo", " big", " world"), Confidence: 0.9}}},
+		{Variants: []speech.TranscriptVariant{{TranscriptTokens: tokens("hello", " world"), Confidence: 0.5}}},
+		{Variants: []speech.TranscriptVariant{{TranscriptTokens: tokens("yellow", " world"), Confidence: 0.4}}},
+	})
+	require.Equal(t, speech.Text("hello world"), result.Variants[0].Text)
+	require.Equal(t, speech.TranscriptTokens{{
+		StartTime:  0,
+		EndTime:    2 * time.Second,
+		Text:       "hello",
+		Confidence: 0.5,
+	}, {
+		StartTime:  3 * time.Second,
+		EndTime:    4 * time.Second,
+		Text:       " world",
+		Confidence: 0.5,
+	}}, result.Variants[0].TranscriptTokens)
+}
+
+func TestEncodingMismatch(t *testing.T) {
+	f0, f1 := newFakeToText(), newFakeToText()
+	f1.encoding = audio.EncodingPCM{PCMFormat: audio.PCMFormatS16LE, SampleRate: 16000}
+	_, err := New(context.Background(), []speech.ToText{f0, f1})
+	require.Error(t, err)
+}
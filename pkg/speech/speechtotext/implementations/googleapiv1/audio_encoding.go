@@ -1,3 +1,5 @@
+//go:build googleapi
+
 package googleapiv1
 
 import (
@@ -11,7 +13,7 @@ func AudioEncodingToThrift(
 	audioEncoding audio.Encoding,
 ) (speechpb.RecognitionConfig_AudioEncoding, int32, error) {
 	switch audioEncoding := audioEncoding.(type) {
-	case audio.AudioEncodingPCM:
+	case audio.EncodingPCM:
 		switch audioEncoding.PCMFormat {
 		case audio.PCMFormatS16LE:
 			return speechpb.RecognitionConfig_LINEAR16, int32(audioEncoding.SampleRate), nil
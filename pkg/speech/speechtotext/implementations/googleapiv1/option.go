@@ -0,0 +1,111 @@
+//go:build googleapi
+
+package googleapiv1
+
+import (
+	"time"
+
+	"google.golang.org/api/option"
+)
+
+const (
+	// DefaultStreamingLimit is a bit less than the ~5 minutes Google allows
+	// a single StreamingRecognize call to last.
+	DefaultStreamingLimit = 290 * time.Second
+
+	// MaxResendDuration limits how much of not-yet-finalized audio
+	// is re-sent to a new stream after a restart.
+	MaxResendDuration = 30 * time.Second
+)
+
+type config struct {
+	ClientOptions                       []option.ClientOption
+	StreamingLimit                      time.Duration
+	InterimResults                      bool
+	Model                               string
+	MaxAlternatives                     int32
+	EnableAutomaticPunctuation          bool
+	EnableSeparateRecognitionPerChannel bool
+	Diarization                         *OptionDiarization
+}
+
+func defaultConfig() config {
+	return config{
+		StreamingLimit: DefaultStreamingLimit,
+		InterimResults: true,
+	}
+}
+
+type Option interface {
+	apply(*config)
+}
+
+type Options []Option
+
+func (opts Options) apply(cfg *config) {
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+}
+
+func (opts Options) config() config {
+	cfg := defaultConfig()
+	opts.apply(&cfg)
+	return cfg
+}
+
+// OptionClientOptions is passed as is to the Google API client (credentials, endpoint, etc).
+type OptionClientOptions []option.ClientOption
+
+func (opt OptionClientOptions) apply(cfg *config) {
+	cfg.ClientOptions = append(cfg.ClientOptions, opt...)
+}
+
+// OptionStreamingLimit defines after how much time the stream is transparently restarted.
+type OptionStreamingLimit time.Duration
+
+func (opt OptionStreamingLimit) apply(cfg *config) {
+	cfg.StreamingLimit = time.Duration(opt)
+}
+
+type OptionInterimResults bool
+
+func (opt OptionInterimResults) apply(cfg *config) {
+	cfg.InterimResults = bool(opt)
+}
+
+type OptionModel string
+
+func (opt OptionModel) apply(cfg *config) {
+	cfg.Model = string(opt)
+}
+
+type OptionMaxAlternatives int32
+
+func (opt OptionMaxAlternatives) apply(cfg *config) {
+	cfg.MaxAlternatives = int32(opt)
+}
+
+type OptionEnableAutomaticPunctuation bool
+
+func (opt OptionEnableAutomaticPunctuation) apply(cfg *config) {
+	cfg.EnableAutomaticPunctuation = bool(opt)
+}
+
+// OptionEnableSeparateRecognitionPerChannel makes Google recognize each
+// audio channel independently; the channel is reported in Transcript.AudioChannelNum.
+type OptionEnableSeparateRecognitionPerChannel bool
+
+func (opt OptionEnableSeparateRecognitionPerChannel) apply(cfg *config) {
+	cfg.EnableSeparateRecognitionPerChannel = bool(opt)
+}
+
+// OptionDiarization enables speaker diarization; the speaker is reported in TranscriptToken.Speaker.
+type OptionDiarization struct {
+	MinSpeakerCount int32
+	MaxSpeakerCount int32
+}
+
+func (opt OptionDiarization) apply(cfg *config) {
+	cfg.Diarization = &opt
+}
@@ -0,0 +1,26 @@
+//go:build googleapi
+
+package googleapiv2
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/speech/apiv2/speechpb"
+	"github.com/xaionaro-go/audio/pkg/audio"
+)
+
+func AudioEncodingToThrift(
+	audioEncoding audio.Encoding,
+) (speechpb.ExplicitDecodingConfig_AudioEncoding, int32, error) {
+	switch audioEncoding := audioEncoding.(type) {
+	case audio.EncodingPCM:
+		switch audioEncoding.PCMFormat {
+		case audio.PCMFormatS16LE:
+			return speechpb.ExplicitDecodingConfig_LINEAR16, int32(audioEncoding.SampleRate), nil
+		default:
+			return 0, 0, fmt.Errorf("google Speech APIv2 does not support PCM format %s", audioEncoding.PCMFormat) // the linter complains if I write "Google" from the capital "G" here...
+		}
+	default:
+		return 0, 0, fmt.Errorf("do not know how to convert %T", audioEncoding)
+	}
+}
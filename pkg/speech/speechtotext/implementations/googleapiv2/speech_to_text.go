@@ -0,0 +1,454 @@
+//go:build googleapi
+
+package googleapiv2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	googleapi "cloud.google.com/go/speech/apiv2"
+	"cloud.google.com/go/speech/apiv2/speechpb"
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/hashicorp/go-multierror"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/observability"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/xsync"
+)
+
+type recognizeStream struct {
+	speechpb.Speech_StreamingRecognizeClient
+	cancelFunc context.CancelFunc
+	startedAt  time.Time
+
+	// offset is the position of the beginning of the stream in the whole audio.
+	offset time.Duration
+}
+
+// MaxAudioChunkSize is the maximal size of audio in a single request,
+// bigger chunks are split into multiple requests.
+const MaxAudioChunkSize = 15 * 1024
+
+type SpeechToText struct {
+	closeCount    atomic.Uint64
+	wg            sync.WaitGroup
+	locker        xsync.Mutex
+	config        config
+	recognizer    string
+	language      speech.Language
+	audioEncoding audio.Encoding
+	audioChannels audio.Channel
+	googleAPI     *googleapi.Client
+	ctx           context.Context
+	cancelFunc    context.CancelFunc
+	resultQueue   chan *speech.Transcript
+
+	stream  *recognizeStream
+	loopErr error
+
+	// segmentSequencer is used only by the receiving loop.
+	segmentSequencer speech.SegmentSequencer
+
+	// unfinalizedAudio is the audio sent to the current stream, which
+	// is not covered by a final result, yet. It starts at
+	// unfinalizedAudioStart (relatively to the stream beginning).
+	unfinalizedAudio      []byte
+	unfinalizedAudioStart time.Duration
+}
+
+var _ speech.ToText = (*SpeechToText)(nil)
+
+// New starts a streaming recognition using the given recognizer, e.g.:
+// "projects/{project}/locations/{location}/recognizers/_".
+func New(
+	ctx context.Context,
+	recognizer string,
+	language speech.Language,
+	audioEncoding audio.Encoding,
+	audioChannels audio.Channel,
+	opts ...Option,
+) (*SpeechToText, error) {
+	if _, _, err := AudioEncodingToThrift(audioEncoding); err != nil {
+		return nil, fmt.Errorf("unable to convert the audio encoding for Google API: %w", err)
+	}
+
+	cfg := Options(opts).config()
+	ctx, cancelFunc := context.WithCancel(ctx)
+
+	googleAPI, err := googleapi.NewClient(ctx, cfg.ClientOptions...)
+	if err != nil {
+		cancelFunc()
+		return nil, fmt.Errorf("unable to initialize a client to Google Speech API: %w", err)
+	}
+
+	stt := &SpeechToText{
+		config:        cfg,
+		recognizer:    recognizer,
+		language:      language,
+		audioEncoding: audioEncoding,
+		audioChannels: audioChannels,
+		googleAPI:     googleAPI,
+		resultQueue:   make(chan *speech.Transcript, 1024),
+		ctx:           ctx,
+		cancelFunc:    cancelFunc,
+	}
+
+	stt.stream, err = stt.newStream(ctx, 0)
+	if err != nil {
+		cancelFunc()
+		googleAPI.Close()
+		return nil, err
+	}
+
+	stt.wg.Add(1)
+	observability.Go(ctx, func() {
+		defer stt.wg.Done()
+		defer close(stt.resultQueue)
+		err := stt.loop(ctx)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+			default:
+				logger.Errorf(ctx, "stt.loop returned error: %v", err)
+			}
+		}
+		stt.locker.Do(ctx, func() {
+			stt.loopErr = err
+		})
+	})
+
+	return stt, nil
+}
+
+func (stt *SpeechToText) newStream(
+	ctx context.Context,
+	offset time.Duration,
+) (*recognizeStream, error) {
+	logger.Debugf(ctx, "newStream(ctx, %v)", offset)
+	thriftAudioEncoding, thriftSampleRate, err := AudioEncodingToThrift(stt.audioEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert the audio encoding for Google API: %w", err)
+	}
+
+	recognitionConfig := &speechpb.RecognitionConfig{
+		DecodingConfig: &speechpb.RecognitionConfig_ExplicitDecodingConfig{
+			ExplicitDecodingConfig: &speechpb.ExplicitDecodingConfig{
+				Encoding:          thriftAudioEncoding,
+				SampleRateHertz:   thriftSampleRate,
+				AudioChannelCount: int32(stt.audioChannels),
+			},
+		},
+		Model:         stt.config.Model,
+		LanguageCodes: []string{string(stt.language)},
+		Features: &speechpb.RecognitionFeatures{
+			EnableWordTimeOffsets:      true,
+			EnableWordConfidence:       true,
+			EnableAutomaticPunctuation: stt.config.EnableAutomaticPunctuation,
+			MaxAlternatives:            stt.config.MaxAlternatives,
+		},
+	}
+	if stt.config.EnableSeparateRecognitionPerChannel {
+		recognitionConfig.Features.MultiChannelMode = speechpb.RecognitionFeatures_SEPARATE_RECOGNITION_PER_CHANNEL
+	}
+	if d := stt.config.Diarization; d != nil {
+		recognitionConfig.Features.DiarizationConfig = &speechpb.SpeakerDiarizationConfig{
+			MinSpeakerCount: d.MinSpeakerCount,
+			MaxSpeakerCount: d.MaxSpeakerCount,
+		}
+	}
+
+	// the stream should live until it is replaced or the SpeechToText is closed,
+	// thus it is not bound to the context of the caller.
+	streamCtx, cancelFunc := context.WithCancel(stt.ctx)
+	stream, err := stt.googleAPI.StreamingRecognize(streamCtx)
+	if err != nil {
+		cancelFunc()
+		return nil, fmt.Errorf("unable to initialize a recognition stream: %w", err)
+	}
+
+	err = stream.Send(&speechpb.StreamingRecognizeRequest{
+		Recognizer: stt.recognizer,
+		StreamingRequest: &speechpb.StreamingRecognizeRequest_StreamingConfig{
+			StreamingConfig: &speechpb.StreamingRecognitionConfig{
+				Config: recognitionConfig,
+				StreamingFeatures: &speechpb.StreamingRecognitionFeatures{
+					InterimResults: stt.config.InterimResults,
+				},
+			},
+		},
+	})
+	if err != nil {
+		cancelFunc()
+		return nil, fmt.Errorf("unable to send the configuration to the stream: %w", err)
+	}
+
+	return &recognizeStream{
+		Speech_StreamingRecognizeClient: stream,
+		cancelFunc:                      cancelFunc,
+		startedAt:                       time.Now(),
+		offset:                          offset,
+	}, nil
+}
+
+func (stt *SpeechToText) AudioEncoding(context.Context) (audio.Encoding, error) {
+	return stt.audioEncoding, nil
+}
+
+func (stt *SpeechToText) AudioChannels(context.Context) (audio.Channel, error) {
+	return stt.audioChannels, nil
+}
+
+func (stt *SpeechToText) currentStream(ctx context.Context) *recognizeStream {
+	return xsync.DoR1(xsync.WithNoLogging(ctx, true), &stt.locker, func() *recognizeStream {
+		return stt.stream
+	})
+}
+
+func (stt *SpeechToText) loop(ctx context.Context) (_err error) {
+	logger.Debugf(ctx, "stt.loop()")
+	defer func() { logger.Debugf(ctx, "/stt.loop(): %v", _err) }()
+
+	for {
+		stream := stt.currentStream(ctx)
+		err := stt.receiveFrom(ctx, stream)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if stream != stt.currentStream(ctx) {
+			logger.Debugf(ctx, "the stream was restarted, switching to the new one (previous stream ended with: %v)", err)
+			continue
+		}
+		return err
+	}
+}
+
+func (stt *SpeechToText) receiveFrom(
+	ctx context.Context,
+	stream *recognizeStream,
+) error {
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return fmt.Errorf("the stream is unexpectedly closed by the server")
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read a result from the stream: %w", err)
+		}
+		if resp == nil {
+			return fmt.Errorf("resp == nil")
+		}
+		if stream != stt.currentStream(ctx) {
+			// the audio which is not finalized yet was re-sent to the new stream,
+			// so the results will come from there.
+			return nil
+		}
+
+		for _, result := range resp.GetResults() {
+			transcript := stt.transcriptFromResult(ctx, stream, result)
+			stt.segmentSequencer.Assign(transcript)
+			if result.GetIsFinal() {
+				stt.locker.Do(xsync.WithNoLogging(ctx, true), func() {
+					stt.markFinalizedNoLock(ctx, stream, result.GetResultEndOffset().AsDuration())
+				})
+			}
+			select {
+			case stt.resultQueue <- transcript:
+			default:
+				logger.Error(ctx, "the queue is full, dropping the message")
+			}
+		}
+	}
+}
+
+func (stt *SpeechToText) transcriptFromResult(
+	ctx context.Context,
+	stream *recognizeStream,
+	result *speechpb.StreamingRecognitionResult,
+) *speech.Transcript {
+	transcript := &speech.Transcript{
+		Stability: result.GetStability(),
+		Language:  stt.language,
+		IsFinal:   result.GetIsFinal(),
+	}
+	if transcript.IsFinal {
+		transcript.Stability = 1
+	}
+	if lang := result.GetLanguageCode(); lang != "" {
+		transcript.Language = speech.Language(lang)
+	}
+	if channelTag := result.GetChannelTag(); channelTag > 0 {
+		// channel tags are 1-based
+		transcript.AudioChannelNum = audio.Channel(channelTag - 1)
+	}
+
+	for _, alt := range result.GetAlternatives() {
+		logger.Debugf(ctx, "confidence: %.3f, transcript: <%s>", alt.GetConfidence(), alt.GetTranscript())
+		variant := speech.TranscriptVariant{
+			Text:       speech.Text(alt.GetTranscript()),
+			Confidence: alt.GetConfidence(),
+		}
+		for idx, word := range alt.GetWords() {
+			text := word.GetWord()
+			if idx > 0 {
+				text = " " + text
+			}
+			variant.TranscriptTokens = append(
+				variant.TranscriptTokens,
+				speech.TranscriptToken{
+					StartTime:  stream.offset + word.GetStartOffset().AsDuration(),
+					EndTime:    stream.offset + word.GetEndOffset().AsDuration(),
+					Text:       speech.Text(text),
+					Confidence: word.GetConfidence(),
+					Speaker:    word.GetSpeakerLabel(),
+				},
+			)
+		}
+		transcript.Variants = append(transcript.Variants, variant)
+	}
+	return transcript
+}
+
+func (stt *SpeechToText) markFinalizedNoLock(
+	ctx context.Context,
+	stream *recognizeStream,
+	resultEnd time.Duration,
+) {
+	if stream != stt.stream {
+		return
+	}
+	if resultEnd <= stt.unfinalizedAudioStart {
+		return
+	}
+	cutBytes := stt.bytesForDuration(resultEnd - stt.unfinalizedAudioStart)
+	if cutBytes > uint64(len(stt.unfinalizedAudio)) {
+		cutBytes = uint64(len(stt.unfinalizedAudio))
+	}
+	stt.unfinalizedAudio = stt.unfinalizedAudio[cutBytes:]
+	stt.unfinalizedAudioStart += stt.durationForBytes(cutBytes)
+	logger.Tracef(ctx, "finalized until %v; unfinalized audio: %d bytes", resultEnd, len(stt.unfinalizedAudio))
+}
+
+func (stt *SpeechToText) frameSize() uint64 {
+	return uint64(stt.audioEncoding.BytesPerSample()) * uint64(stt.audioChannels)
+}
+
+func (stt *SpeechToText) bytesForDuration(d time.Duration) uint64 {
+	return stt.audioEncoding.BytesForDuration(d) * uint64(stt.audioChannels)
+}
+
+func (stt *SpeechToText) durationForBytes(b uint64) time.Duration {
+	return time.Duration(float64(time.Second) * float64(b) / float64(uint64(stt.audioEncoding.BytesForSecond())*uint64(stt.audioChannels)))
+}
+
+// restartStreamNoLock replaces the current stream with a new one and
+// re-sends the audio, which was not finalized by the previous stream.
+func (stt *SpeechToText) restartStreamNoLock(
+	ctx context.Context,
+) error {
+	oldStream := stt.stream
+
+	if maxBytes := stt.bytesForDuration(MaxResendDuration); uint64(len(stt.unfinalizedAudio)) > maxBytes {
+		cutBytes := uint64(len(stt.unfinalizedAudio)) - maxBytes
+		cutBytes -= cutBytes % stt.frameSize()
+		logger.Warnf(ctx, "too much unfinalized audio, dropping %v of it", stt.durationForBytes(cutBytes))
+		stt.unfinalizedAudio = stt.unfinalizedAudio[cutBytes:]
+		stt.unfinalizedAudioStart += stt.durationForBytes(cutBytes)
+	}
+
+	newOffset := oldStream.offset + stt.unfinalizedAudioStart
+	logger.Debugf(ctx, "restarting the stream (the old one is running for %v); new offset: %v", time.Since(oldStream.startedAt), newOffset)
+	newStream, err := stt.newStream(ctx, newOffset)
+	if err != nil {
+		return fmt.Errorf("unable to start a new stream: %w", err)
+	}
+
+	if err := sendAudio(newStream, stt.unfinalizedAudio); err != nil {
+		newStream.cancelFunc()
+		return fmt.Errorf("unable to re-send the unfinalized audio to the new stream: %w", err)
+	}
+	stt.unfinalizedAudioStart = 0
+	stt.stream = newStream
+
+	if err := oldStream.CloseSend(); err != nil {
+		logger.Debugf(ctx, "unable to close the old stream: %v", err)
+	}
+	oldStream.cancelFunc()
+	return nil
+}
+
+func sendAudio(
+	stream *recognizeStream,
+	audio []byte,
+) error {
+	for len(audio) > 0 {
+		chunk := audio
+		if len(chunk) > MaxAudioChunkSize {
+			chunk = chunk[:MaxAudioChunkSize]
+		}
+		err := stream.Send(&speechpb.StreamingRecognizeRequest{
+			StreamingRequest: &speechpb.StreamingRecognizeRequest_Audio{
+				Audio: chunk,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		audio = audio[len(chunk):]
+	}
+	return nil
+}
+
+func (stt *SpeechToText) WriteAudio(
+	ctx context.Context,
+	audio []byte,
+) error {
+	return xsync.DoR1(xsync.WithNoLogging(ctx, true), &stt.locker, func() error {
+		if stt.loopErr != nil {
+			return fmt.Errorf("the receiving loop is closed: %w", stt.loopErr)
+		}
+
+		if time.Since(stt.stream.startedAt) >= stt.config.StreamingLimit {
+			if err := stt.restartStreamNoLock(ctx); err != nil {
+				return err
+			}
+		}
+
+		if err := sendAudio(stt.stream, audio); err != nil {
+			return fmt.Errorf("unable to write audio: %w", err)
+		}
+		stt.unfinalizedAudio = append(stt.unfinalizedAudio, audio...)
+		return nil
+	})
+}
+
+func (stt *SpeechToText) OutputChan(context.Context) (<-chan *speech.Transcript, error) {
+	return stt.resultQueue, nil
+}
+
+func (stt *SpeechToText) Close() error {
+	if stt.closeCount.Add(1) != 1 {
+		return fmt.Errorf("already closed")
+	}
+
+	ctx := context.TODO()
+	var mErr *multierror.Error
+	stt.locker.Do(ctx, func() {
+		if err := stt.stream.CloseSend(); err != nil && !errors.Is(err, io.EOF) {
+			mErr = multierror.Append(mErr, fmt.Errorf("unable to close the stream: %w", err))
+		}
+	})
+
+	stt.cancelFunc()
+	stt.waitForClosure()
+	mErr = multierror.Append(mErr, stt.googleAPI.Close())
+	return mErr.ErrorOrNil()
+}
+
+func (stt *SpeechToText) waitForClosure() {
+	stt.wg.Wait()
+}
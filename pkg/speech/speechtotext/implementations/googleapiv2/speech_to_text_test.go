@@ -0,0 +1,290 @@
+//go:build googleapi
+
+package googleapiv2
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/speech/apiv2/speechpb"
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const testRecognizer = "projects/test/locations/global/recognizers/_"
+
+type fakeStream struct {
+	requests  chan *speechpb.StreamingRecognizeRequest
+	responses chan *speechpb.StreamingRecognizeResponse
+}
+
+type fakeSpeechServer struct {
+	speechpb.UnimplementedSpeechServer
+	streams chan *fakeStream
+}
+
+func (srv *fakeSpeechServer) StreamingRecognize(
+	stream speechpb.Speech_StreamingRecognizeServer,
+) error {
+	s := &fakeStream{
+		requests:  make(chan *speechpb.StreamingRecognizeRequest, 100),
+		responses: make(chan *speechpb.StreamingRecognizeResponse, 100),
+	}
+	srv.streams <- s
+	go func() {
+		defer close(s.requests)
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			s.requests <- req
+		}
+	}()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case resp := <-s.responses:
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func newTestSTT(
+	t *testing.T,
+	opts ...Option,
+) (*SpeechToText, *fakeSpeechServer) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &fakeSpeechServer{streams: make(chan *fakeStream, 10)}
+	grpcServer := grpc.NewServer()
+	speechpb.RegisterSpeechServer(grpcServer, srv)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	opts = append(Options{OptionClientOptions{
+		option.WithEndpoint(listener.Addr().String()),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	}}, opts...)
+
+	stt, err := New(
+		context.Background(),
+		testRecognizer,
+		"en-US",
+		audio.EncodingPCM{PCMFormat: audio.PCMFormatS16LE, SampleRate: 16000},
+		2,
+		opts...,
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { stt.Close() })
+	return stt, srv
+}
+
+func nextStream(t *testing.T, srv *fakeSpeechServer) *fakeStream {
+	select {
+	case s := <-srv.streams:
+		return s
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for a stream")
+		return nil
+	}
+}
+
+func nextRequest(t *testing.T, s *fakeStream) *speechpb.StreamingRecognizeRequest {
+	select {
+	case req, ok := <-s.requests:
+		require.True(t, ok)
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for a request")
+		return nil
+	}
+}
+
+// nextAudio collects audio requests until the given amount of bytes is received.
+func nextAudio(t *testing.T, s *fakeStream, size int) []byte {
+	var result []byte
+	for len(result) < size {
+		chunk := nextRequest(t, s).GetAudio()
+		require.NotEmpty(t, chunk)
+		require.LessOrEqual(t, len(chunk), MaxAudioChunkSize)
+		result = append(result, chunk...)
+	}
+	require.Len(t, result, size)
+	return result
+}
+
+func nextTranscript(t *testing.T, stt *SpeechToText) *speech.Transcript {
+	ch, err := stt.OutputChan(context.Background())
+	require.NoError(t, err)
+	select {
+	case transcript, ok := <-ch:
+		require.True(t, ok)
+		return transcript
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for a transcript")
+		return nil
+	}
+}
+
+func TestResultMapping(t *testing.T) {
+	stt, srv := newTestSTT(t,
+		OptionEnableSeparateRecognitionPerChannel(true),
+		OptionDiarization{MinSpeakerCount: 1, MaxSpeakerCount: 3},
+	)
+	s := nextStream(t, srv)
+
+	req := nextRequest(t, s)
+	require.Equal(t, testRecognizer, req.GetRecognizer())
+	cfg := req.GetStreamingConfig()
+	require.NotNil(t, cfg)
+	require.True(t, cfg.GetStreamingFeatures().GetInterimResults())
+	decodingCfg := cfg.GetConfig().GetExplicitDecodingConfig()
+	require.Equal(t, speechpb.ExplicitDecodingConfig_LINEAR16, decodingCfg.GetEncoding())
+	require.Equal(t, int32(16000), decodingCfg.GetSampleRateHertz())
+	require.Equal(t, int32(2), decodingCfg.GetAudioChannelCount())
+	require.Equal(t, []string{"en-US"}, cfg.GetConfig().GetLanguageCodes())
+	features := cfg.GetConfig().GetFeatures()
+	require.Equal(t, speechpb.RecognitionFeatures_SEPARATE_RECOGNITION_PER_CHANNEL, features.GetMultiChannelMode())
+	require.Equal(t, int32(3), features.GetDiarizationConfig().GetMaxSpeakerCount())
+
+	require.NoError(t, stt.WriteAudio(context.Background(), make([]byte, 1000)))
+	nextAudio(t, s, 1000)
+
+	s.responses <- &speechpb.StreamingRecognizeResponse{
+		Results: []*speechpb.StreamingRecognitionResult{{
+			Stability: 0.5,
+		}, {
+			Alternatives: []*speechpb.SpeechRecognitionAlternative{{
+				Transcript: "hello world",
+				Confidence: 0.9,
+				Words: []*speechpb.WordInfo{{
+					StartOffset:  durationpb.New(100 * time.Millisecond),
+					EndOffset:    durationpb.New(200 * time.Millisecond),
+					Word:         "hello",
+					Confidence:   0.8,
+					SpeakerLabel: "A",
+				}, {
+					StartOffset:  durationpb.New(300 * time.Millisecond),
+					EndOffset:    durationpb.New(400 * time.Millisecond),
+					Word:         "world",
+					Confidence:   0.7,
+					SpeakerLabel: "2",
+				}},
+			}},
+			IsFinal:      true,
+			ChannelTag:   2,
+			LanguageCode: "en-gb",
+		}},
+	}
+
+	interim := nextTranscript(t, stt)
+	require.False(t, interim.IsFinal)
+	require.Equal(t, float32(0.5), interim.Stability)
+	require.Equal(t, speech.Language("en-US"), interim.Language)
+
+	final := nextTranscript(t, stt)
+	require.Equal(t, &speech.Transcript{
+		Variants: []speech.TranscriptVariant{{
+			Text: "hello world",
+			TranscriptTokens: []speech.TranscriptToken{{
+				StartTime:  100 * time.Millisecond,
+				EndTime:    200 * time.Millisecond,
+				Text:       "hello",
+				Confidence: 0.8,
+				Speaker:    "A",
+			}, {
+				StartTime:  300 * time.Millisecond,
+				EndTime:    400 * time.Millisecond,
+				Text:       " world",
+				Confidence: 0.7,
+				Speaker:    "2",
+			}},
+			Confidence: 0.9,
+		}},
+		Stability:       1,
+		AudioChannelNum: 1,
+		Language:        "en-gb",
+		IsFinal:         true,
+		SegmentID:       2,
+	}, final)
+}
+
+func TestStreamRestart(t *testing.T) {
+	const limit = 200 * time.Millisecond
+	stt, srv := newTestSTT(t, OptionStreamingLimit(limit))
+	ctx := context.Background()
+
+	// 1 second of stereo S16LE 16kHz
+	chunk := make([]byte, 64000)
+	for idx := range chunk {
+		chunk[idx] = byte(idx)
+	}
+
+	s0 := nextStream(t, srv)
+	require.NotNil(t, nextRequest(t, s0).GetStreamingConfig())
+	require.NoError(t, stt.WriteAudio(ctx, chunk))
+	nextAudio(t, s0, len(chunk))
+
+	// the first 250ms are finalized, the rest should be re-sent after the restart
+	s0.responses <- &speechpb.StreamingRecognizeResponse{
+		Results: []*speechpb.StreamingRecognitionResult{{
+			Alternatives:    []*speechpb.SpeechRecognitionAlternative{{Transcript: "one"}},
+			IsFinal:         true,
+			ResultEndOffset: durationpb.New(250 * time.Millisecond),
+		}},
+	}
+	require.True(t, nextTranscript(t, stt).IsFinal)
+
+	time.Sleep(limit)
+	chunk2 := make([]byte, 6400)
+	require.NoError(t, stt.WriteAudio(ctx, chunk2))
+
+	s1 := nextStream(t, srv)
+	require.NotNil(t, nextRequest(t, s1).GetStreamingConfig())
+	require.Equal(t, chunk[16000:], nextAudio(t, s1, len(chunk)-16000))
+	require.Equal(t, chunk2, nextAudio(t, s1, len(chunk2)))
+
+	// the old stream is expected to be closed
+	for {
+		select {
+		case _, ok := <-s0.requests:
+			if ok {
+				continue
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("the old stream is not closed")
+		}
+		break
+	}
+
+	s1.responses <- &speechpb.StreamingRecognizeResponse{
+		Results: []*speechpb.StreamingRecognitionResult{{
+			Alternatives: []*speechpb.SpeechRecognitionAlternative{{
+				Transcript: "two",
+				Words: []*speechpb.WordInfo{{
+					StartOffset: durationpb.New(100 * time.Millisecond),
+					EndOffset:   durationpb.New(200 * time.Millisecond),
+					Word:        "two",
+				}},
+			}},
+			IsFinal: true,
+		}},
+	}
+	transcript := nextTranscript(t, stt)
+	require.Equal(t, speech.Text("two"), transcript.Variants[0].Text)
+	require.Equal(t, 350*time.Millisecond, transcript.Variants[0].TranscriptTokens[0].StartTime)
+	require.Equal(t, 450*time.Millisecond, transcript.Variants[0].TranscriptTokens[0].EndTime)
+}
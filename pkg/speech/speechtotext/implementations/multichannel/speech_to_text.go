@@ -0,0 +1,232 @@
+package multichannel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/hashicorp/go-multierror"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/observability"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/xsync"
+)
+
+// BackendFactory creates a mono backend for the given channel.
+type BackendFactory func(ctx context.Context, channel audio.Channel) (speech.ToText, error)
+
+// SpeechToText accepts interleaved multi-channel PCM and transcribes
+// every channel by a separate mono backend, e.g. two-mic interviews
+// recorded as stereo.
+type SpeechToText struct {
+	closeCount    atomic.Uint64
+	wg            sync.WaitGroup
+	locker        xsync.Mutex
+	backends      []speech.ToText
+	audioEncoding audio.Encoding
+	cancelFunc    context.CancelFunc
+	resultQueue   chan *speech.Transcript
+
+	// incompleteFrame is the tail of the previous WriteAudio, which
+	// did not contain samples of all the channels.
+	incompleteFrame []byte
+}
+
+var _ speech.ToText = (*SpeechToText)(nil)
+
+// New creates a backend per channel using the factory. The backends
+// should be mono and consume the same audio encoding; the resulting
+// SpeechToText consumes the same encoding, but interleaved with the given
+// amount of channels.
+func New(
+	ctx context.Context,
+	channels audio.Channel,
+	factory BackendFactory,
+) (_ret *SpeechToText, _err error) {
+	if channels == 0 {
+		return nil, fmt.Errorf("the amount of channels should be positive")
+	}
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	stt := &SpeechToText{
+		cancelFunc:  cancelFunc,
+		resultQueue: make(chan *speech.Transcript, 1024),
+	}
+	defer func() {
+		if _err != nil {
+			stt.closeBackends()
+			cancelFunc()
+		}
+	}()
+
+	for ch := audio.Channel(0); ch < channels; ch++ {
+		backend, err := factory(ctx, ch)
+		if err != nil {
+			return nil, fmt.Errorf("unable to initialize the backend for channel #%d: %w", ch, err)
+		}
+		stt.backends = append(stt.backends, backend)
+
+		backendChannels, err := backend.AudioChannels(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the amount of audio channels of the backend for channel #%d: %w", ch, err)
+		}
+		if backendChannels != 1 {
+			return nil, fmt.Errorf("the backend for channel #%d is not mono: %d channels", ch, backendChannels)
+		}
+
+		enc, err := backend.AudioEncoding(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the audio encoding of the backend for channel #%d: %w", ch, err)
+		}
+		if stt.audioEncoding == nil {
+			stt.audioEncoding = enc
+		}
+		if enc != stt.audioEncoding {
+			return nil, fmt.Errorf("the backend for channel #%d expects audio encoding %#+v, while the backend for channel #0 expects %#+v", ch, enc, stt.audioEncoding)
+		}
+	}
+
+	var outChs []<-chan *speech.Transcript
+	for ch, backend := range stt.backends {
+		outCh, err := backend.OutputChan(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the output channel of the backend for channel #%d: %w", ch, err)
+		}
+		outChs = append(outChs, outCh)
+	}
+
+	var readersWG sync.WaitGroup
+	for ch, outCh := range outChs {
+		readersWG.Add(1)
+		stt.wg.Add(1)
+		observability.Go(ctx, func() {
+			defer stt.wg.Done()
+			defer readersWG.Done()
+			stt.readBackend(ctx, audio.Channel(ch), outCh)
+		})
+	}
+	stt.wg.Add(1)
+	observability.Go(ctx, func() {
+		defer stt.wg.Done()
+		readersWG.Wait()
+		close(stt.resultQueue)
+	})
+
+	return stt, nil
+}
+
+func (stt *SpeechToText) readBackend(
+	ctx context.Context,
+	channel audio.Channel,
+	outCh <-chan *speech.Transcript,
+) {
+	logger.Debugf(ctx, "readBackend(ctx, %d)", channel)
+	defer func() { logger.Debugf(ctx, "/readBackend(ctx, %d)", channel) }()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t, ok := <-outCh:
+			if !ok {
+				return
+			}
+			t.AudioChannelNum = channel
+			select {
+			case stt.resultQueue <- t:
+			default:
+				logger.Error(ctx, "the queue is full, dropping the message")
+			}
+		}
+	}
+}
+
+func (stt *SpeechToText) AudioEncoding(context.Context) (audio.Encoding, error) {
+	return stt.audioEncoding, nil
+}
+
+func (stt *SpeechToText) AudioChannels(context.Context) (audio.Channel, error) {
+	return audio.Channel(len(stt.backends)), nil
+}
+
+// WriteAudio splits the interleaved audio by channels and sends each
+// channel to its backend.
+func (stt *SpeechToText) WriteAudio(
+	ctx context.Context,
+	audio []byte,
+) error {
+	return xsync.DoR1(xsync.WithNoLogging(ctx, true), &stt.locker, func() error {
+		return stt.writeAudioNoLock(ctx, audio)
+	})
+}
+
+func (stt *SpeechToText) writeAudioNoLock(
+	ctx context.Context,
+	audio []byte,
+) error {
+	sampleSize := int(stt.audioEncoding.BytesPerSample())
+	frameSize := sampleSize * len(stt.backends)
+
+	if len(stt.incompleteFrame) > 0 {
+		audio = append(stt.incompleteFrame, audio...)
+		stt.incompleteFrame = nil
+	}
+	if tail := len(audio) % frameSize; tail != 0 {
+		stt.incompleteFrame = append([]byte{}, audio[len(audio)-tail:]...)
+		audio = audio[:len(audio)-tail]
+	}
+	if len(audio) == 0 {
+		return nil
+	}
+
+	frames := len(audio) / frameSize
+	channelBufs := make([][]byte, len(stt.backends))
+	for ch := range channelBufs {
+		channelBufs[ch] = make([]byte, frames*sampleSize)
+	}
+	for frame := 0; frame < frames; frame++ {
+		for ch, buf := range channelBufs {
+			src := frame*frameSize + ch*sampleSize
+			copy(buf[frame*sampleSize:(frame+1)*sampleSize], audio[src:src+sampleSize])
+		}
+	}
+
+	var mErr *multierror.Error
+	for ch, backend := range stt.backends {
+		if err := backend.WriteAudio(ctx, channelBufs[ch]); err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("unable to write audio of channel #%d: %w", ch, err))
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+func (stt *SpeechToText) OutputChan(context.Context) (<-chan *speech.Transcript, error) {
+	return stt.resultQueue, nil
+}
+
+func (stt *SpeechToText) closeBackends() error {
+	var mErr *multierror.Error
+	for ch, backend := range stt.backends {
+		if err := backend.Close(); err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("unable to close the backend of channel #%d: %w", ch, err))
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+func (stt *SpeechToText) Close() error {
+	if stt.closeCount.Add(1) != 1 {
+		return fmt.Errorf("already closed")
+	}
+
+	err := stt.closeBackends()
+	stt.cancelFunc()
+	stt.waitForClosure()
+	return err
+}
+
+func (stt *SpeechToText) waitForClosure() {
+	stt.wg.Wait()
+}
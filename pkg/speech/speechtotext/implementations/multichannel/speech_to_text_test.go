@@ -0,0 +1,77 @@
+package multichannel
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+type fakeToText struct {
+	locker  sync.Mutex
+	written []byte
+	out     chan *speech.Transcript
+}
+
+var _ speech.ToText = (*fakeToText)(nil)
+
+func (f *fakeToText) Close() error {
+	close(f.out)
+	return nil
+}
+func (f *fakeToText) AudioEncoding(context.Context) (audio.Encoding, error) {
+	return audio.EncodingPCM{PCMFormat: audio.PCMFormatS16LE, SampleRate: 16000}, nil
+}
+func (f *fakeToText) AudioChannels(context.Context) (audio.Channel, error) { return 1, nil }
+func (f *fakeToText) WriteAudio(_ context.Context, b []byte) error {
+	f.locker.Lock()
+	defer f.locker.Unlock()
+	f.written = append(f.written, b...)
+	return nil
+}
+func (f *fakeToText) OutputChan(context.Context) (<-chan *speech.Transcript, error) {
+	return f.out, nil
+}
+
+func TestSpeechToText(t *testing.T) {
+	ctx := context.Background()
+	var fakes []*fakeToText
+	stt, err := New(ctx, 2, func(ctx context.Context, channel audio.Channel) (speech.ToText, error) {
+		f := &fakeToText{out: make(chan *speech.Transcript, 1)}
+		fakes = append(fakes, f)
+		return f, nil
+	})
+	require.NoError(t, err)
+	defer stt.Close()
+
+	channels, err := stt.AudioChannels(ctx)
+	require.NoError(t, err)
+	require.Equal(t, audio.Channel(2), channels)
+
+	// S16LE stereo: L0 R0 L1 R1 L2 R2, split at non-frame boundaries
+	interleaved := []byte{
+		0x10, 0x11, 0x20, 0x21,
+		0x12, 0x13, 0x22, 0x23,
+		0x14, 0x15, 0x24, 0x25,
+	}
+	require.NoError(t, stt.WriteAudio(ctx, interleaved[:3]))
+	require.NoError(t, stt.WriteAudio(ctx, interleaved[3:9]))
+	require.NoError(t, stt.WriteAudio(ctx, interleaved[9:]))
+	require.Equal(t, []byte{0x10, 0x11, 0x12, 0x13, 0x14, 0x15}, fakes[0].written)
+	require.Equal(t, []byte{0x20, 0x21, 0x22, 0x23, 0x24, 0x25}, fakes[1].written)
+
+	outCh, err := stt.OutputChan(ctx)
+	require.NoError(t, err)
+	fakes[1].out <- &speech.Transcript{Variants: []speech.TranscriptVariant{{Text: "right"}}}
+	select {
+	case tr := <-outCh:
+		require.Equal(t, audio.Channel(1), tr.AudioChannelNum)
+		require.Equal(t, speech.Text("right"), tr.Variants[0].Text)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+}
@@ -0,0 +1,106 @@
+package preprocessing
+
+import (
+	"math"
+)
+
+// butterworthQ is the quality factor of a second-order Butterworth filter.
+const butterworthQ = 1 / math.Sqrt2
+
+// biquad is a second-order IIR filter of interleaved samples; see
+// "Cookbook formulae for audio EQ biquad filter coefficients" by R. Bristow-Johnson.
+type biquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+	state      []biquadState
+}
+
+type biquadState struct {
+	x1, x2 float64
+	y1, y2 float64
+}
+
+func newHighPassBiquad(
+	sampleRate float64,
+	channels int,
+	cutoff float64,
+	q float64,
+) *biquad {
+	w0 := 2 * math.Pi * cutoff / sampleRate
+	alpha := math.Sin(w0) / (2 * q)
+	cosW0 := math.Cos(w0)
+	a0 := 1 + alpha
+	return &biquad{
+		b0:    (1 + cosW0) / 2 / a0,
+		b1:    -(1 + cosW0) / a0,
+		b2:    (1 + cosW0) / 2 / a0,
+		a1:    -2 * cosW0 / a0,
+		a2:    (1 - alpha) / a0,
+		state: make([]biquadState, channels),
+	}
+}
+
+func newLowPassBiquad(
+	sampleRate float64,
+	channels int,
+	cutoff float64,
+	q float64,
+) *biquad {
+	w0 := 2 * math.Pi * cutoff / sampleRate
+	alpha := math.Sin(w0) / (2 * q)
+	cosW0 := math.Cos(w0)
+	a0 := 1 + alpha
+	return &biquad{
+		b0:    (1 - cosW0) / 2 / a0,
+		b1:    (1 - cosW0) / a0,
+		b2:    (1 - cosW0) / 2 / a0,
+		a1:    -2 * cosW0 / a0,
+		a2:    (1 - alpha) / a0,
+		state: make([]biquadState, channels),
+	}
+}
+
+// process filters the samples in place.
+func (f *biquad) process(samples []float32) {
+	channels := len(f.state)
+	for idx, sample := range samples {
+		st := &f.state[idx%channels]
+		x := float64(sample)
+		y := f.b0*x + f.b1*st.x1 + f.b2*st.x2 - f.a1*st.y1 - f.a2*st.y2
+		st.x2, st.x1 = st.x1, x
+		st.y2, st.y1 = st.y1, y
+		samples[idx] = float32(y)
+	}
+}
+
+// antiAliasingCutoff is the cutoff frequency of the anti-aliasing filter
+// relatively to the target sample rate (leaving a margin below the Nyquist
+// frequency for the roll-off).
+const antiAliasingCutoff = 0.4
+
+// antiAliasingFilter is a fourth-order Butterworth low-pass filter
+// (two cascaded biquads) to be applied before decreasing the sample rate.
+type antiAliasingFilter []*biquad
+
+func newAntiAliasingFilter(
+	sampleRate float64,
+	targetSampleRate float64,
+	channels int,
+) antiAliasingFilter {
+	cutoff := antiAliasingCutoff * targetSampleRate
+	var f antiAliasingFilter
+	for _, q := range []float64{
+		1 / (2 * math.Cos(math.Pi/8)),
+		1 / (2 * math.Cos(3*math.Pi/8)),
+	} {
+		f = append(f, newLowPassBiquad(sampleRate, channels, cutoff, q))
+	}
+	return f
+}
+
+// process filters the samples in place.
+func (f antiAliasingFilter) process(samples []float32) {
+	for _, stage := range f {
+		stage.process(samples)
+	}
+}
@@ -0,0 +1,45 @@
+package preprocessing
+
+import (
+	"context"
+
+	"github.com/xaionaro-go/audio/pkg/audio"
+)
+
+// dcRemovalPole defines how fast the DC offset estimate follows the signal:
+// the closer to 1, the lower the frequencies affected.
+const dcRemovalPole = 0.995
+
+// DCRemoval removes the DC offset (the constant component) of the audio
+// by a DC-blocking filter: y[n] = x[n] - x[n-1] + pole*y[n-1].
+type DCRemoval struct {
+	prevInput  []float32
+	prevOutput []float32
+}
+
+var _ Stage = (*DCRemoval)(nil)
+
+func NewDCRemoval(channels audio.Channel) *DCRemoval {
+	return &DCRemoval{
+		prevInput:  make([]float32, channels),
+		prevOutput: make([]float32, channels),
+	}
+}
+
+func (s *DCRemoval) Process(
+	_ context.Context,
+	samples []float32,
+) ([]float32, error) {
+	channels := len(s.prevInput)
+	for idx, x := range samples {
+		ch := idx % channels
+		y := x - s.prevInput[ch] + dcRemovalPole*s.prevOutput[ch]
+		s.prevInput[ch], s.prevOutput[ch] = x, y
+		samples[idx] = y
+	}
+	return samples, nil
+}
+
+func (s *DCRemoval) Close() error {
+	return nil
+}
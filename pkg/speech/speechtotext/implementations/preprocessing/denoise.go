@@ -0,0 +1,179 @@
+package preprocessing
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/audio/pkg/noisesuppression"
+)
+
+// Denoise suppresses the noise by a noisesuppression.NoiseSuppression
+// (e.g. RNNoise). The audio is resampled to the sample rate of the
+// NoiseSuppression and back (low-pass filtered before decreasing the sample
+// rate), and it is delayed by up to a chunk of the NoiseSuppression (10ms
+// for RNNoise).
+type Denoise struct {
+	noiseSuppression noisesuppression.NoiseSuppression
+	channels         int
+	byteOrder        binary.ByteOrder
+
+	// inChunkLength and nsChunkLength are the amounts of samples (of all
+	// the channels) of a chunk of the NoiseSuppression before and after
+	// the resampling.
+	inChunkLength int
+	nsChunkLength int
+
+	// inFilter is applied before the resampling to the sample rate of
+	// the NoiseSuppression, and outFilter before the resampling back;
+	// only the one decreasing the sample rate is set.
+	inFilter  antiAliasingFilter
+	outFilter antiAliasingFilter
+
+	// inLastFrame and nsLastFrame are the last frames of the previous
+	// chunk before and after the noise suppression (see resampleLinear).
+	inLastFrame []float32
+	nsLastFrame []float32
+
+	pending  []float32
+	nsChunk  []float32
+	nsInput  []byte
+	nsOutput []byte
+}
+
+var _ Stage = (*Denoise)(nil)
+
+// NewDenoise returns a Denoise using the noise suppression,
+// which is closed together with the Denoise.
+func NewDenoise(
+	ctx context.Context,
+	noiseSuppression noisesuppression.NoiseSuppression,
+	sampleRate audio.SampleRate,
+	channels audio.Channel,
+) (*Denoise, error) {
+	nsChannels, err := noiseSuppression.Channels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the amount of channels of the noise suppression: %w", err)
+	}
+	if nsChannels != channels {
+		return nil, fmt.Errorf("the noise suppression expects %d channels, but the audio has %d", nsChannels, channels)
+	}
+	encoding, err := noiseSuppression.Encoding(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the encoding of the noise suppression: %w", err)
+	}
+	encodingPCM, ok := encoding.(audio.EncodingPCM)
+	if !ok {
+		return nil, fmt.Errorf("the noise suppression expects a non-PCM encoding: %T", encoding)
+	}
+	var byteOrder binary.ByteOrder
+	switch encodingPCM.PCMFormat {
+	case audio.PCMFormatFloat32LE:
+		byteOrder = binary.LittleEndian
+	case audio.PCMFormatFloat32BE:
+		byteOrder = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("the noise suppression expects PCM format %s, which is not supported", encodingPCM.PCMFormat)
+	}
+
+	const sampleSize = 4
+	nsChunkLength := int(noiseSuppression.ChunkSize()) / sampleSize
+	nsChunkFrames := nsChunkLength / int(channels)
+	if nsChunkFrames == 0 {
+		return nil, fmt.Errorf("the noise suppression has no chunk size")
+	}
+	if nsChunkFrames*int(sampleRate)%int(encodingPCM.SampleRate) != 0 {
+		return nil, fmt.Errorf("a chunk of %d samples at %dHz is not a whole amount of samples at %dHz", nsChunkFrames, encodingPCM.SampleRate, sampleRate)
+	}
+	inChunkFrames := nsChunkFrames * int(sampleRate) / int(encodingPCM.SampleRate)
+
+	var inFilter, outFilter antiAliasingFilter
+	switch {
+	case encodingPCM.SampleRate < sampleRate:
+		inFilter = newAntiAliasingFilter(float64(sampleRate), float64(encodingPCM.SampleRate), int(channels))
+	case encodingPCM.SampleRate > sampleRate:
+		outFilter = newAntiAliasingFilter(float64(encodingPCM.SampleRate), float64(sampleRate), int(channels))
+	}
+
+	return &Denoise{
+		noiseSuppression: noiseSuppression,
+		channels:         int(channels),
+		byteOrder:        byteOrder,
+		inChunkLength:    inChunkFrames * int(channels),
+		nsChunkLength:    nsChunkLength,
+		inFilter:         inFilter,
+		outFilter:        outFilter,
+		inLastFrame:      make([]float32, channels),
+		nsLastFrame:      make([]float32, channels),
+		nsChunk:          make([]float32, nsChunkLength),
+		nsInput:          make([]byte, nsChunkLength*sampleSize),
+		nsOutput:         make([]byte, nsChunkLength*sampleSize),
+	}, nil
+}
+
+func (s *Denoise) Process(
+	ctx context.Context,
+	samples []float32,
+) ([]float32, error) {
+	s.pending = append(s.pending, samples...)
+	var result []float32
+	for len(s.pending) >= s.inChunkLength {
+		chunk := s.pending[:s.inChunkLength]
+
+		s.inFilter.process(chunk)
+		resampleLinear(s.nsChunk, chunk, s.inLastFrame)
+		for idx, sample := range s.nsChunk {
+			s.byteOrder.PutUint32(s.nsInput[idx*4:], math.Float32bits(sample))
+		}
+		if _, err := s.noiseSuppression.SuppressNoise(ctx, s.nsInput, s.nsOutput); err != nil {
+			return nil, fmt.Errorf("unable to suppress the noise: %w", err)
+		}
+		for idx := range s.nsChunk {
+			s.nsChunk[idx] = math.Float32frombits(s.byteOrder.Uint32(s.nsOutput[idx*4:]))
+		}
+		s.outFilter.process(s.nsChunk)
+		resampleLinear(chunk, s.nsChunk, s.nsLastFrame)
+
+		result = append(result, chunk...)
+		s.pending = s.pending[s.inChunkLength:]
+	}
+	s.pending = append(s.pending[:0:0], s.pending...)
+	return result, nil
+}
+
+// resampleLinear resamples the interleaved samples from src to dst
+// (the sample rate ratio is defined by their lengths) by the linear
+// interpolation; when decreasing the sample rate, src should be low-pass
+// filtered beforehand (see antiAliasingFilter) to avoid aliasing.
+//
+// To keep the signal continuous across the chunks, the interpolation begins
+// from lastFrame (the last frame of the previous src, one sample per channel),
+// thus the result is delayed by a sample of src; lastFrame is updated to
+// the last frame of src.
+func resampleLinear(dst, src, lastFrame []float32) {
+	channels := len(lastFrame)
+	srcFrames := len(src) / channels
+	dstFrames := len(dst) / channels
+	sample := func(frame, ch int) float32 {
+		if frame == 0 {
+			return lastFrame[ch]
+		}
+		return src[(frame-1)*channels+ch]
+	}
+	for dstFrame := 0; dstFrame < dstFrames; dstFrame++ {
+		pos := float64(dstFrame) * float64(srcFrames) / float64(dstFrames)
+		left := int(pos)
+		frac := float32(pos - float64(left))
+		for ch := 0; ch < channels; ch++ {
+			a, b := sample(left, ch), sample(left+1, ch)
+			dst[dstFrame*channels+ch] = a + (b-a)*frac
+		}
+	}
+	copy(lastFrame, src[(srcFrames-1)*channels:])
+}
+
+func (s *Denoise) Close() error {
+	return s.noiseSuppression.Close()
+}
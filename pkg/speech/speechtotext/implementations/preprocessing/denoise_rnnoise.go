@@ -0,0 +1,15 @@
+//go:build cgo && rnnoise && !windows
+
+package preprocessing
+
+import (
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/audio/pkg/noisesuppression"
+	"github.com/xaionaro-go/audio/pkg/noisesuppression/implementations/rnnoise"
+)
+
+func newRNNoise(
+	channels audio.Channel,
+) (noisesuppression.NoiseSuppression, error) {
+	return rnnoise.New(channels)
+}
@@ -0,0 +1,14 @@
+//go:build !cgo || !rnnoise || windows
+
+package preprocessing
+
+import (
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/audio/pkg/noisesuppression"
+)
+
+func newRNNoise(
+	audio.Channel,
+) (noisesuppression.NoiseSuppression, error) {
+	return nil, ErrRNNoiseIsNotSupported{}
+}
@@ -0,0 +1,7 @@
+package preprocessing
+
+type ErrRNNoiseIsNotSupported struct{}
+
+func (ErrRNNoiseIsNotSupported) Error() string {
+	return "RNNoise is not supported by this build (see build tag 'rnnoise')"
+}
@@ -0,0 +1,41 @@
+package preprocessing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xaionaro-go/audio/pkg/audio"
+)
+
+// HighPass is a second-order Butterworth high-pass filter; it removes
+// the rumble (wind, traffic, handling noise) below the speech frequencies.
+type HighPass struct {
+	filter *biquad
+}
+
+var _ Stage = (*HighPass)(nil)
+
+func NewHighPass(
+	sampleRate audio.SampleRate,
+	channels audio.Channel,
+	cutoff float64,
+) (*HighPass, error) {
+	if cutoff <= 0 || cutoff >= float64(sampleRate)/2 {
+		return nil, fmt.Errorf("the cutoff frequency %f is out of range (0, %d)", cutoff, sampleRate/2)
+	}
+	return &HighPass{
+		filter: newHighPassBiquad(float64(sampleRate), int(channels), cutoff, butterworthQ),
+	}, nil
+}
+
+func (s *HighPass) Process(
+	_ context.Context,
+	samples []float32,
+) ([]float32, error) {
+	s.filter.process(samples)
+	return samples, nil
+}
+
+func (s *HighPass) Close() error {
+	return nil
+}
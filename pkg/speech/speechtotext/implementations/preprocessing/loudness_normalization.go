@@ -0,0 +1,109 @@
+package preprocessing
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/xaionaro-go/audio/pkg/audio"
+)
+
+const (
+	// DefaultLoudnessTarget is the default RMS level (in dBFS)
+	// the loudness is normalized to.
+	DefaultLoudnessTarget = -20
+
+	// loudnessMaxGain limits the amplification (in dB), so that
+	// the noise is not amplified too much in pauses.
+	loudnessMaxGain = 30
+
+	// loudnessGate is the RMS level (in dBFS) below which the audio is
+	// considered silence, thus the gain is not changed.
+	loudnessGate = -60
+
+	// loudnessWindow is the time constant of the RMS level measurement.
+	loudnessWindow = 400 * time.Millisecond
+
+	// loudnessAttack and loudnessRelease are the time constants of
+	// the gain decrease and increase respectively: a sudden loud sound
+	// is attenuated fast, while the amplification is restored slowly.
+	loudnessAttack  = 10 * time.Millisecond
+	loudnessRelease = 500 * time.Millisecond
+)
+
+// LoudnessNormalization is an automatic gain control: it amplifies
+// (or attenuates) the audio to keep its RMS level near the target.
+// All the channels get the same gain.
+type LoudnessNormalization struct {
+	channels    int
+	target      float64
+	maxGain     float64
+	gate        float64
+	windowCoef  float64
+	attackCoef  float64
+	releaseCoef float64
+
+	meanSquare float64
+	gain       float64
+}
+
+var _ Stage = (*LoudnessNormalization)(nil)
+
+// NewLoudnessNormalization returns a LoudnessNormalization
+// to the target RMS level (in dBFS, e.g. DefaultLoudnessTarget).
+func NewLoudnessNormalization(
+	sampleRate audio.SampleRate,
+	channels audio.Channel,
+	target float64,
+) *LoudnessNormalization {
+	coef := func(d time.Duration) float64 {
+		return 1 - math.Exp(-1/(d.Seconds()*float64(sampleRate)))
+	}
+	return &LoudnessNormalization{
+		channels:    int(channels),
+		target:      dbToAmplitude(target),
+		maxGain:     dbToAmplitude(loudnessMaxGain),
+		gate:        dbToAmplitude(loudnessGate),
+		windowCoef:  coef(loudnessWindow),
+		attackCoef:  coef(loudnessAttack),
+		releaseCoef: coef(loudnessRelease),
+		gain:        1,
+	}
+}
+
+func dbToAmplitude(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+func (s *LoudnessNormalization) Process(
+	_ context.Context,
+	samples []float32,
+) ([]float32, error) {
+	for frameStart := 0; frameStart+s.channels <= len(samples); frameStart += s.channels {
+		frame := samples[frameStart : frameStart+s.channels]
+		var power float64
+		for _, sample := range frame {
+			power += float64(sample) * float64(sample)
+		}
+		power /= float64(s.channels)
+		s.meanSquare += s.windowCoef * (power - s.meanSquare)
+
+		if level := math.Sqrt(s.meanSquare); level > s.gate {
+			targetGain := min(s.target/level, s.maxGain)
+			coef := s.releaseCoef
+			if targetGain < s.gain {
+				coef = s.attackCoef
+			}
+			s.gain += coef * (targetGain - s.gain)
+		}
+
+		for idx, sample := range frame {
+			frame[idx] = float32(max(-1, min(1, float64(sample)*s.gain)))
+		}
+	}
+	return samples, nil
+}
+
+func (s *LoudnessNormalization) Close() error {
+	return nil
+}
@@ -0,0 +1,86 @@
+package preprocessing
+
+import (
+	"github.com/xaionaro-go/audio/pkg/noisesuppression"
+)
+
+type config struct {
+	RemoveDC         bool
+	HighPassCutoff   float64
+	Denoise          bool
+	NoiseSuppression noisesuppression.NoiseSuppression
+	LoudnessTarget   *float64
+	ExtraStages      []Stage
+}
+
+func defaultConfig() config {
+	return config{}
+}
+
+type Option interface {
+	apply(*config)
+}
+
+type Options []Option
+
+func (opts Options) apply(cfg *config) {
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+}
+
+func (opts Options) config() config {
+	cfg := defaultConfig()
+	opts.apply(&cfg)
+	return cfg
+}
+
+// OptionRemoveDC enables the DC offset removal (see DCRemoval).
+type OptionRemoveDC bool
+
+func (opt OptionRemoveDC) apply(cfg *config) {
+	cfg.RemoveDC = bool(opt)
+}
+
+// OptionHighPass enables the high-pass filter (see HighPass)
+// with the given cutoff frequency in Hz (e.g. 80); zero disables it.
+type OptionHighPass float64
+
+func (opt OptionHighPass) apply(cfg *config) {
+	cfg.HighPassCutoff = float64(opt)
+}
+
+// OptionDenoise enables the noise suppression by RNNoise
+// (requires build tag "rnnoise"); see also OptionNoiseSuppression.
+type OptionDenoise bool
+
+func (opt OptionDenoise) apply(cfg *config) {
+	cfg.Denoise = bool(opt)
+}
+
+// OptionNoiseSuppression enables the noise suppression by the given
+// NoiseSuppression instead of RNNoise. The NoiseSuppression is closed
+// together with the SpeechToText, so it should not be shared.
+type OptionNoiseSuppression struct {
+	noisesuppression.NoiseSuppression
+}
+
+func (opt OptionNoiseSuppression) apply(cfg *config) {
+	cfg.NoiseSuppression = opt.NoiseSuppression
+}
+
+// OptionNormalizeLoudness enables the loudness normalization (see
+// LoudnessNormalization) to the given RMS level in dBFS (e.g. DefaultLoudnessTarget).
+type OptionNormalizeLoudness float64
+
+func (opt OptionNormalizeLoudness) apply(cfg *config) {
+	cfg.LoudnessTarget = (*float64)(&opt)
+}
+
+// OptionExtraStages are custom stages to be run after the built-in ones.
+// The stages are closed together with the SpeechToText.
+type OptionExtraStages []Stage
+
+func (opt OptionExtraStages) apply(cfg *config) {
+	cfg.ExtraStages = opt
+}
@@ -0,0 +1,38 @@
+package preprocessing
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+// ParamsFlags are command line flags for the preprocessing parameters.
+type ParamsFlags struct {
+	flagSet *pflag.FlagSet
+
+	removeDC          bool
+	highPassCutoff    float32
+	denoise           bool
+	normalizeLoudness float32
+}
+
+func AddParamsFlags(flagSet *pflag.FlagSet) *ParamsFlags {
+	f := &ParamsFlags{flagSet: flagSet}
+	flagSet.BoolVar(&f.removeDC, "remove-dc", false, "remove the DC offset of the audio before the recognition")
+	flagSet.Float32Var(&f.highPassCutoff, "high-pass", 0, "the cutoff frequency (in Hz, e.g. 80) of the high-pass filter applied before the recognition; 0 disables it")
+	flagSet.BoolVar(&f.denoise, "denoise", false, "suppress the noise by RNNoise before the recognition (requires build tag 'rnnoise')")
+	flagSet.Float32Var(&f.normalizeLoudness, "normalize-loudness", DefaultLoudnessTarget, "the RMS level (in dBFS) to normalize the loudness to before the recognition; applied only if the flag is set")
+	return f
+}
+
+// GRPC returns the parameters set through the flags.
+func (f *ParamsFlags) GRPC() *speechtotext_grpc.PreprocessingParams {
+	p := &speechtotext_grpc.PreprocessingParams{
+		RemoveDC:         f.removeDC,
+		HighPassCutoffHz: f.highPassCutoff,
+		Denoise:          f.denoise,
+	}
+	if f.flagSet.Changed("normalize-loudness") {
+		p.NormalizeLoudnessDBFS = &f.normalizeLoudness
+	}
+	return p
+}
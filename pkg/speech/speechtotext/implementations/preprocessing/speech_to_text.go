@@ -0,0 +1,234 @@
+// Package preprocessing implements a speech.ToText, which preprocesses
+// the audio (removes the noise, normalizes the loudness, etc) before
+// sending it to another speech.ToText.
+package preprocessing
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync/atomic"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/xsync"
+)
+
+type SpeechToText struct {
+	closeCount atomic.Uint64
+	locker     xsync.Mutex
+	backend    speech.ToText
+	encoding   audio.EncodingPCM
+	channels   audio.Channel
+	stages     []Stage
+
+	// incompleteSample is the tail of the previous WriteAudio,
+	// which did not contain a whole sample.
+	incompleteSample []byte
+}
+
+var (
+	_ speech.ToText           = (*SpeechToText)(nil)
+	_ speech.LanguageDetector = (*SpeechToText)(nil)
+)
+
+// New returns a SpeechToText, which preprocesses the audio by the stages
+// enabled by the options (in the order: DC removal, high-pass filter,
+// noise suppression, loudness normalization, extra stages) and sends it to
+// the backend. The backend should consume PCM Float32LE or S16LE; it is
+// closed together with the SpeechToText.
+func New(
+	ctx context.Context,
+	backend speech.ToText,
+	opts ...Option,
+) (_ret *SpeechToText, _err error) {
+	cfg := Options(opts).config()
+	stt := &SpeechToText{
+		backend: backend,
+	}
+	defer func() {
+		if _err != nil {
+			stt.closeStages()
+		}
+	}()
+
+	encoding, err := backend.AudioEncoding(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the audio encoding of the backend: %w", err)
+	}
+	encodingPCM, ok := encoding.(audio.EncodingPCM)
+	if !ok {
+		return nil, fmt.Errorf("the backend expects a non-PCM encoding: %T", encoding)
+	}
+	switch encodingPCM.PCMFormat {
+	case audio.PCMFormatFloat32LE, audio.PCMFormatS16LE:
+	default:
+		return nil, fmt.Errorf("the backend expects PCM format %s, which is not supported", encodingPCM.PCMFormat)
+	}
+	stt.encoding = encodingPCM
+	stt.channels, err = backend.AudioChannels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the amount of audio channels of the backend: %w", err)
+	}
+
+	if cfg.RemoveDC {
+		stt.stages = append(stt.stages, NewDCRemoval(stt.channels))
+	}
+	if cfg.HighPassCutoff > 0 {
+		highPass, err := NewHighPass(encodingPCM.SampleRate, stt.channels, cfg.HighPassCutoff)
+		if err != nil {
+			return nil, fmt.Errorf("unable to initialize the high-pass filter: %w", err)
+		}
+		stt.stages = append(stt.stages, highPass)
+	}
+	noiseSuppression := cfg.NoiseSuppression
+	if noiseSuppression == nil && cfg.Denoise {
+		noiseSuppression, err = newRNNoise(stt.channels)
+		if err != nil {
+			return nil, fmt.Errorf("unable to initialize RNNoise: %w", err)
+		}
+	}
+	if noiseSuppression != nil {
+		denoise, err := NewDenoise(ctx, noiseSuppression, encodingPCM.SampleRate, stt.channels)
+		if err != nil {
+			noiseSuppression.Close()
+			return nil, fmt.Errorf("unable to initialize the noise suppression: %w", err)
+		}
+		stt.stages = append(stt.stages, denoise)
+	}
+	if cfg.LoudnessTarget != nil {
+		stt.stages = append(stt.stages, NewLoudnessNormalization(encodingPCM.SampleRate, stt.channels, *cfg.LoudnessTarget))
+	}
+	stt.stages = append(stt.stages, cfg.ExtraStages...)
+	return stt, nil
+}
+
+func (stt *SpeechToText) AudioEncoding(context.Context) (audio.Encoding, error) {
+	return stt.encoding, nil
+}
+
+func (stt *SpeechToText) AudioChannels(context.Context) (audio.Channel, error) {
+	return stt.channels, nil
+}
+
+func (stt *SpeechToText) WriteAudio(
+	ctx context.Context,
+	audio []byte,
+) error {
+	return xsync.DoR1(xsync.WithNoLogging(ctx, true), &stt.locker, func() error {
+		return stt.writeAudioNoLock(ctx, audio)
+	})
+}
+
+func (stt *SpeechToText) writeAudioNoLock(
+	ctx context.Context,
+	audio []byte,
+) error {
+	sampleSize := int(stt.encoding.BytesPerSample())
+	if len(stt.incompleteSample) > 0 {
+		audio = append(stt.incompleteSample, audio...)
+		stt.incompleteSample = nil
+	}
+	if tail := len(audio) % sampleSize; tail != 0 {
+		stt.incompleteSample = append([]byte{}, audio[len(audio)-tail:]...)
+		audio = audio[:len(audio)-tail]
+	}
+	if len(audio) == 0 {
+		return nil
+	}
+
+	samples := stt.decode(audio)
+	for idx, stage := range stt.stages {
+		var err error
+		samples, err = stage.Process(ctx, samples)
+		if err != nil {
+			return fmt.Errorf("unable to preprocess the audio by stage #%d (%T): %w", idx, stage, err)
+		}
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+	return stt.backend.WriteAudio(ctx, stt.encode(samples))
+}
+
+func (stt *SpeechToText) decode(buf []byte) []float32 {
+	switch stt.encoding.PCMFormat {
+	case audio.PCMFormatS16LE:
+		samples := make([]float32, len(buf)/2)
+		for idx := range samples {
+			samples[idx] = float32(int16(binary.LittleEndian.Uint16(buf[idx*2:]))) / math.MaxInt16
+		}
+		return samples
+	default:
+		samples := make([]float32, len(buf)/4)
+		for idx := range samples {
+			samples[idx] = math.Float32frombits(binary.LittleEndian.Uint32(buf[idx*4:]))
+		}
+		return samples
+	}
+}
+
+func (stt *SpeechToText) encode(samples []float32) []byte {
+	switch stt.encoding.PCMFormat {
+	case audio.PCMFormatS16LE:
+		buf := make([]byte, len(samples)*2)
+		for idx, sample := range samples {
+			sample = max(-1, min(1, sample))
+			binary.LittleEndian.PutUint16(buf[idx*2:], uint16(int16(sample*math.MaxInt16)))
+		}
+		return buf
+	default:
+		buf := make([]byte, len(samples)*4)
+		for idx, sample := range samples {
+			binary.LittleEndian.PutUint32(buf[idx*4:], math.Float32bits(sample))
+		}
+		return buf
+	}
+}
+
+func (stt *SpeechToText) OutputChan(ctx context.Context) (<-chan *speech.Transcript, error) {
+	return stt.backend.OutputChan(ctx)
+}
+
+// DetectLanguage implements speech.LanguageDetector, if the backend
+// implements it. The audio is passed to the backend as is, since the
+// stages are stateful and expect the continuous stream.
+func (stt *SpeechToText) DetectLanguage(ctx context.Context, audio []byte) (speech.LanguageProbabilities, error) {
+	detector, ok := stt.backend.(speech.LanguageDetector)
+	if !ok {
+		return nil, fmt.Errorf("the backend %T cannot detect the language", stt.backend)
+	}
+	return detector.DetectLanguage(ctx, audio)
+}
+
+// Backend returns the speech.ToText, which receives the preprocessed audio.
+func (stt *SpeechToText) Backend() speech.ToText {
+	return stt.backend
+}
+
+func (stt *SpeechToText) closeStages() error {
+	var mErr *multierror.Error
+	for idx, stage := range stt.stages {
+		if err := stage.Close(); err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("unable to close stage #%d (%T): %w", idx, stage, err))
+		}
+	}
+	return mErr.ErrorOrNil()
+}
+
+func (stt *SpeechToText) Close() error {
+	if stt.closeCount.Add(1) != 1 {
+		return fmt.Errorf("already closed")
+	}
+
+	var mErr *multierror.Error
+	if err := stt.backend.Close(); err != nil {
+		mErr = multierror.Append(mErr, fmt.Errorf("unable to close the backend: %w", err))
+	}
+	if err := stt.closeStages(); err != nil {
+		mErr = multierror.Append(mErr, err)
+	}
+	return mErr.ErrorOrNil()
+}
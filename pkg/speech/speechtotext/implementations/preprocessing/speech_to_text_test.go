@@ -0,0 +1,97 @@
+package preprocessing
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+func decodeFloat32LE(buf []byte) []float32 {
+	samples := make([]float32, len(buf)/4)
+	for idx := range samples {
+		samples[idx] = math.Float32frombits(binary.LittleEndian.Uint32(buf[idx*4:]))
+	}
+	return samples
+}
+
+func encodeFloat32LE(samples []float32) []byte {
+	buf := make([]byte, len(samples)*4)
+	for idx, sample := range samples {
+		binary.LittleEndian.PutUint32(buf[idx*4:], math.Float32bits(sample))
+	}
+	return buf
+}
+
+type fakeBackend struct {
+	format   audio.PCMFormat
+	written  []byte
+	isClosed bool
+}
+
+var _ speech.ToText = (*fakeBackend)(nil)
+
+func (b *fakeBackend) AudioEncoding(context.Context) (audio.Encoding, error) {
+	return audio.EncodingPCM{PCMFormat: b.format, SampleRate: testSampleRate}, nil
+}
+
+func (b *fakeBackend) AudioChannels(context.Context) (audio.Channel, error) {
+	return 1, nil
+}
+
+func (b *fakeBackend) WriteAudio(_ context.Context, audio []byte) error {
+	b.written = append(b.written, audio...)
+	return nil
+}
+
+func (b *fakeBackend) OutputChan(context.Context) (<-chan *speech.Transcript, error) {
+	return nil, nil
+}
+
+func (b *fakeBackend) Close() error {
+	b.isClosed = true
+	return nil
+}
+
+// negate is a Stage, which negates the samples.
+type negate struct{}
+
+func (negate) Process(_ context.Context, samples []float32) ([]float32, error) {
+	for idx := range samples {
+		samples[idx] = -samples[idx]
+	}
+	return samples, nil
+}
+
+func (negate) Close() error { return nil }
+
+func TestSpeechToText(t *testing.T) {
+	ctx := context.Background()
+
+	backend := &fakeBackend{format: audio.PCMFormatFloat32LE}
+	stt, err := New(ctx, backend, OptionExtraStages{negate{}})
+	require.NoError(t, err)
+	input := encodeFloat32LE([]float32{0.5, -0.25, 1})
+	require.NoError(t, stt.WriteAudio(ctx, input[:5]))
+	require.NoError(t, stt.WriteAudio(ctx, input[5:]))
+	require.Equal(t, []float32{-0.5, 0.25, -1}, decodeFloat32LE(backend.written))
+	require.NoError(t, stt.Close())
+	require.True(t, backend.isClosed)
+
+	backend = &fakeBackend{format: audio.PCMFormatS16LE}
+	stt, err = New(ctx, backend, OptionExtraStages{negate{}})
+	require.NoError(t, err)
+	input = make([]byte, 4)
+	binary.LittleEndian.PutUint16(input, uint16(1000))
+	binary.LittleEndian.PutUint16(input[2:], uint16(math.MaxUint16)) // -1
+	require.NoError(t, stt.WriteAudio(ctx, input))
+	require.Equal(t, int16(-1000), int16(binary.LittleEndian.Uint16(backend.written)))
+	require.Equal(t, int16(1), int16(binary.LittleEndian.Uint16(backend.written[2:])))
+
+	_, err = New(ctx, &fakeBackend{format: audio.PCMFormatU8})
+	require.Error(t, err)
+}
@@ -0,0 +1,17 @@
+package preprocessing
+
+import (
+	"context"
+	"io"
+)
+
+// Stage is a step of the audio preprocessing. It is stateful (e.g. a filter
+// depends on the previous samples), so it should not be shared between streams.
+type Stage interface {
+	io.Closer
+
+	// Process returns the processed interleaved samples; it may modify
+	// the input in place, and it may return fewer or more samples than
+	// received (if it needs to buffer the audio).
+	Process(ctx context.Context, samples []float32) ([]float32, error)
+}
@@ -0,0 +1,162 @@
+package preprocessing
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/audio/pkg/noisesuppression"
+)
+
+const testSampleRate = 16000
+
+func sine(frequency, amplitude float64, count int) []float32 {
+	samples := make([]float32, count)
+	for idx := range samples {
+		samples[idx] = float32(amplitude * math.Sin(2*math.Pi*frequency*float64(idx)/testSampleRate))
+	}
+	return samples
+}
+
+func rms(samples []float32) float64 {
+	var sum float64
+	for _, sample := range samples {
+		sum += float64(sample) * float64(sample)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+func TestDCRemoval(t *testing.T) {
+	samples := sine(440, 0.1, testSampleRate)
+	for idx := range samples {
+		samples[idx] += 0.3
+	}
+	result, err := NewDCRemoval(1).Process(context.Background(), samples)
+	require.NoError(t, err)
+
+	var mean float64
+	tail := result[len(result)/2:]
+	for _, sample := range tail {
+		mean += float64(sample)
+	}
+	mean /= float64(len(tail))
+	require.InDelta(t, 0, mean, 0.01)
+	require.InDelta(t, 0.1/math.Sqrt2, rms(tail), 0.01)
+}
+
+func TestHighPass(t *testing.T) {
+	_, err := NewHighPass(testSampleRate, 1, testSampleRate)
+	require.Error(t, err)
+
+	for _, tc := range []struct {
+		frequency   float64
+		expectedRMS float64
+	}{
+		{frequency: 20, expectedRMS: 0.0045},
+		{frequency: 1000, expectedRMS: 0.1 / math.Sqrt2},
+	} {
+		highPass, err := NewHighPass(testSampleRate, 1, 100)
+		require.NoError(t, err)
+		result, err := highPass.Process(context.Background(), sine(tc.frequency, 0.1, testSampleRate))
+		require.NoError(t, err)
+		require.InDelta(t, tc.expectedRMS, rms(result[len(result)/2:]), 0.002, "frequency: %f", tc.frequency)
+	}
+}
+
+func TestLoudnessNormalization(t *testing.T) {
+	for _, amplitude := range []float64{0.01, 0.9} {
+		s := NewLoudnessNormalization(testSampleRate, 1, DefaultLoudnessTarget)
+		result, err := s.Process(context.Background(), sine(440, amplitude, 2*testSampleRate))
+		require.NoError(t, err)
+		require.InDelta(t, dbToAmplitude(DefaultLoudnessTarget), rms(result[len(result)/2:]), 0.01, "amplitude: %f", amplitude)
+	}
+
+	// the silence is not amplified
+	s := NewLoudnessNormalization(testSampleRate, 1, DefaultLoudnessTarget)
+	result, err := s.Process(context.Background(), sine(440, 0.0001, testSampleRate))
+	require.NoError(t, err)
+	require.InDelta(t, 0.0001/math.Sqrt2, rms(result), 0.00001)
+}
+
+// fakeNoiseSuppression halves the samples at 48kHz.
+type fakeNoiseSuppression struct {
+	isClosed bool
+}
+
+var _ noisesuppression.NoiseSuppression = (*fakeNoiseSuppression)(nil)
+
+func (ns *fakeNoiseSuppression) Close() error {
+	ns.isClosed = true
+	return nil
+}
+
+func (*fakeNoiseSuppression) Encoding(context.Context) (audio.Encoding, error) {
+	return audio.EncodingPCM{PCMFormat: audio.PCMFormatFloat32LE, SampleRate: 48000}, nil
+}
+
+func (*fakeNoiseSuppression) Channels(context.Context) (audio.Channel, error) {
+	return 1, nil
+}
+
+func (*fakeNoiseSuppression) ChunkSize() uint {
+	return 480 * 4
+}
+
+func (*fakeNoiseSuppression) SuppressNoise(_ context.Context, input []byte, output []byte) (float64, error) {
+	samples := decodeFloat32LE(input)
+	for idx := range samples {
+		samples[idx] /= 2
+	}
+	copy(output, encodeFloat32LE(samples))
+	return 1, nil
+}
+
+func TestDenoise(t *testing.T) {
+	ns := &fakeNoiseSuppression{}
+	denoise, err := NewDenoise(context.Background(), ns, testSampleRate, 1)
+	require.NoError(t, err)
+
+	samples := sine(100, 0.5, 250)
+	result, err := denoise.Process(context.Background(), append([]float32{}, samples...))
+	require.NoError(t, err)
+	require.Len(t, result, 160)
+	result2, err := denoise.Process(context.Background(), make([]float32, 70))
+	require.NoError(t, err)
+	require.Len(t, result2, 160)
+	result = append(result, result2...)
+	// the resampling to 48kHz and back delays the audio by a sample at 16kHz
+	// and a sample at 48kHz, and the anti-aliasing filter (before resampling
+	// back) by about another sample at 16kHz; it settles in several samples
+	const delay = 1 + 1.0/3 + 1.04
+	for idx := 8; idx < len(samples); idx++ {
+		expected := 0.25 * math.Sin(2*math.Pi*100*(float64(idx)-delay)/testSampleRate)
+		require.InDelta(t, expected, result[idx], 0.001, "idx: %d", idx)
+	}
+
+	require.NoError(t, denoise.Close())
+	require.True(t, ns.isClosed)
+
+	_, err = NewDenoise(context.Background(), &fakeNoiseSuppression{}, testSampleRate, 2)
+	require.Error(t, err)
+	_, err = NewDenoise(context.Background(), &fakeNoiseSuppression{}, 22050, 1)
+	require.Error(t, err)
+}
+
+func TestDenoiseAntiAliasing(t *testing.T) {
+	// the audio at 96kHz is denoised at 48kHz, so a 40kHz tone
+	// would be aliased to 8kHz without the low-pass filtering
+	const sampleRate = 96000
+	denoise, err := NewDenoise(context.Background(), &fakeNoiseSuppression{}, sampleRate, 1)
+	require.NoError(t, err)
+
+	samples := make([]float32, sampleRate/10)
+	for idx := range samples {
+		samples[idx] = float32(0.5 * math.Sin(2*math.Pi*40000*float64(idx)/sampleRate))
+	}
+	result, err := denoise.Process(context.Background(), samples)
+	require.NoError(t, err)
+	require.Len(t, result, len(samples))
+	require.Less(t, rms(result[len(result)/2:]), 0.01)
+}
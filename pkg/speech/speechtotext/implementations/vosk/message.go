@@ -0,0 +1,162 @@
+package vosk
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+type configMessage struct {
+	Config configMessageConfig `json:"config"`
+}
+
+type configMessageConfig struct {
+	SampleRate      float64 `json:"sample_rate"`
+	Words           int     `json:"words"`
+	MaxAlternatives uint    `json:"max_alternatives,omitempty"`
+}
+
+type eofMessage struct {
+	EOF int `json:"eof"`
+}
+
+type word struct {
+	Word       string  `json:"word"`
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Confidence float64 `json:"conf"`
+}
+
+type alternative struct {
+	// Confidence is the raw score of the hypothesis (a log-likelihood,
+	// the greater the better), see alternativeConfidences.
+	Confidence float64 `json:"confidence"`
+	Text       string  `json:"text"`
+	Result     []word  `json:"result"`
+}
+
+// alternativeConfidences normalizes the raw scores of the N-best
+// alternatives (by the softmax) to the probabilities of each of them
+// to be the right one.
+func alternativeConfidences(alts []alternative) []float64 {
+	if len(alts) == 0 {
+		return nil
+	}
+	maxScore := alts[0].Confidence
+	for _, alt := range alts[1:] {
+		maxScore = max(maxScore, alt.Confidence)
+	}
+	result := make([]float64, len(alts))
+	var sum float64
+	for idx, alt := range alts {
+		result[idx] = math.Exp(alt.Confidence - maxScore)
+		sum += result[idx]
+	}
+	for idx := range result {
+		result[idx] /= sum
+	}
+	return result
+}
+
+// message is a response of a Vosk server, it is one of:
+// * {"partial": "..."} -- a not finalized hypothesis;
+// * {"text": "...", "result": [...]} -- a final result;
+// * {"alternatives": [{"confidence": ..., "text": "...", "result": [...]}, ...]} -- a final result with N-best variants.
+type message struct {
+	Partial       *string       `json:"partial"`
+	PartialResult []word        `json:"partial_result"`
+	Text          *string       `json:"text"`
+	Result        []word        `json:"result"`
+	Alternatives  []alternative `json:"alternatives"`
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+func variantFromWords(
+	text string,
+	words []word,
+) speech.TranscriptVariant {
+	variant := speech.TranscriptVariant{
+		Text: speech.Text(text),
+	}
+	var confidenceSum float64
+	for idx, w := range words {
+		wordText := w.Word
+		if idx > 0 {
+			wordText = " " + wordText
+		}
+		variant.TranscriptTokens = append(variant.TranscriptTokens, speech.TranscriptToken{
+			StartTime:  seconds(w.Start),
+			EndTime:    seconds(w.End),
+			Text:       speech.Text(wordText),
+			Confidence: float32(w.Confidence),
+		})
+		confidenceSum += w.Confidence
+	}
+	if len(words) > 0 {
+		variant.Confidence = float32(confidenceSum / float64(len(words)))
+	}
+	return variant
+}
+
+// parseMessage converts a Vosk response to a Transcript; returns nil
+// if the response contains no text.
+func parseMessage(
+	msg []byte,
+	language speech.Language,
+) (*speech.Transcript, error) {
+	var m message
+	if err := json.Unmarshal(msg, &m); err != nil {
+		return nil, fmt.Errorf("unable to parse '%s': %w", msg, err)
+	}
+
+	switch {
+	case m.Partial != nil:
+		if strings.TrimSpace(*m.Partial) == "" {
+			return nil, nil
+		}
+		return &speech.Transcript{
+			Variants: []speech.TranscriptVariant{variantFromWords(*m.Partial, m.PartialResult)},
+			Language: language,
+			IsFinal:  false,
+		}, nil
+	case m.Text != nil:
+		if strings.TrimSpace(*m.Text) == "" {
+			return nil, nil
+		}
+		return &speech.Transcript{
+			Variants:  []speech.TranscriptVariant{variantFromWords(*m.Text, m.Result)},
+			Stability: 1,
+			Language:  language,
+			IsFinal:   true,
+		}, nil
+	case m.Alternatives != nil:
+		transcript := &speech.Transcript{
+			Stability: 1,
+			Language:  language,
+			IsFinal:   true,
+		}
+		// the N-best alternatives have no per-word confidences
+		confidences := alternativeConfidences(m.Alternatives)
+		for idx, alt := range m.Alternatives {
+			if strings.TrimSpace(alt.Text) == "" {
+				continue
+			}
+			variant := variantFromWords(alt.Text, alt.Result)
+			variant.Confidence = float32(confidences[idx])
+			transcript.Variants = append(transcript.Variants, variant)
+		}
+		if len(transcript.Variants) == 0 {
+			return nil, nil
+		}
+		return transcript, nil
+	default:
+		return nil, fmt.Errorf("unexpected message: '%s'", msg)
+	}
+}
@@ -0,0 +1,77 @@
+package vosk
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/xaionaro-go/audio/pkg/audio"
+)
+
+const (
+	DefaultSampleRate = audio.SampleRate(16000)
+
+	// DefaultCloseTimeout is how long Close waits for the final result
+	// after sending EOF to the server.
+	DefaultCloseTimeout = 5 * time.Second
+)
+
+type config struct {
+	SampleRate      audio.SampleRate
+	MaxAlternatives uint
+	Dialer          *websocket.Dialer
+	CloseTimeout    time.Duration
+}
+
+func defaultConfig() config {
+	return config{
+		SampleRate:   DefaultSampleRate,
+		Dialer:       websocket.DefaultDialer,
+		CloseTimeout: DefaultCloseTimeout,
+	}
+}
+
+type Option interface {
+	apply(*config)
+}
+
+type Options []Option
+
+func (opts Options) apply(cfg *config) {
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+}
+
+func (opts Options) config() config {
+	cfg := defaultConfig()
+	opts.apply(&cfg)
+	return cfg
+}
+
+// OptionSampleRate defines the sample rate of the S16LE audio sent to the server.
+type OptionSampleRate audio.SampleRate
+
+func (opt OptionSampleRate) apply(cfg *config) {
+	cfg.SampleRate = audio.SampleRate(opt)
+}
+
+// OptionMaxAlternatives asks the server to return N-best variants of final results.
+type OptionMaxAlternatives uint
+
+func (opt OptionMaxAlternatives) apply(cfg *config) {
+	cfg.MaxAlternatives = uint(opt)
+}
+
+type OptionDialer struct {
+	*websocket.Dialer
+}
+
+func (opt OptionDialer) apply(cfg *config) {
+	cfg.Dialer = opt.Dialer
+}
+
+type OptionCloseTimeout time.Duration
+
+func (opt OptionCloseTimeout) apply(cfg *config) {
+	cfg.CloseTimeout = time.Duration(opt)
+}
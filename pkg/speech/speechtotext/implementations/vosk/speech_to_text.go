@@ -0,0 +1,186 @@
+package vosk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/gorilla/websocket"
+	"github.com/hashicorp/go-multierror"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/observability"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/xsync"
+)
+
+// SpeechToText is a client to a Vosk server (https://github.com/alphacep/vosk-server)
+// via its WebSocket protocol.
+type SpeechToText struct {
+	closeCount  atomic.Uint64
+	wg          sync.WaitGroup
+	writeLocker xsync.Mutex
+	config      config
+	language    speech.Language
+	conn        *websocket.Conn
+	cancelFunc  context.CancelFunc
+	resultQueue chan *speech.Transcript
+	loopDone    chan struct{}
+
+	// segmentSequencer is used only by the receiving loop.
+	segmentSequencer speech.SegmentSequencer
+}
+
+var _ speech.ToText = (*SpeechToText)(nil)
+
+// New connects to a Vosk server, e.g. "ws://localhost:2700".
+//
+// The language is chosen by the model loaded into the server, the argument
+// is only used to fill Transcript.Language.
+func New(
+	ctx context.Context,
+	url string,
+	language speech.Language,
+	opts ...Option,
+) (*SpeechToText, error) {
+	cfg := Options(opts).config()
+
+	conn, _, err := cfg.Dialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to '%s': %w", url, err)
+	}
+
+	err = conn.WriteJSON(configMessage{
+		Config: configMessageConfig{
+			SampleRate:      float64(cfg.SampleRate),
+			Words:           1,
+			MaxAlternatives: cfg.MaxAlternatives,
+		},
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("unable to send the configuration: %w", err)
+	}
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	stt := &SpeechToText{
+		config:      cfg,
+		language:    language,
+		conn:        conn,
+		resultQueue: make(chan *speech.Transcript, 1024),
+		cancelFunc:  cancelFunc,
+		loopDone:    make(chan struct{}),
+	}
+
+	stt.wg.Add(1)
+	observability.Go(ctx, func() {
+		defer stt.wg.Done()
+		defer close(stt.loopDone)
+		defer close(stt.resultQueue)
+		err := stt.loop(ctx)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+			default:
+				logger.Errorf(ctx, "stt.loop returned error: %v", err)
+			}
+		}
+	})
+
+	return stt, nil
+}
+
+func (stt *SpeechToText) AudioEncoding(context.Context) (audio.Encoding, error) {
+	return audio.EncodingPCM{
+		PCMFormat:  audio.PCMFormatS16LE,
+		SampleRate: stt.config.SampleRate,
+	}, nil
+}
+
+func (stt *SpeechToText) AudioChannels(context.Context) (audio.Channel, error) {
+	return 1, nil
+}
+
+func (stt *SpeechToText) loop(ctx context.Context) (_err error) {
+	logger.Debugf(ctx, "stt.loop()")
+	defer func() { logger.Debugf(ctx, "/stt.loop(): %v", _err) }()
+
+	for {
+		msgType, msg, err := stt.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return nil
+			}
+			return fmt.Errorf("unable to read from the Vosk server: %w", err)
+		}
+		if msgType != websocket.TextMessage {
+			return fmt.Errorf("unexpected message type: %d", msgType)
+		}
+		logger.Tracef(ctx, "received: %s", msg)
+
+		transcript, err := parseMessage(msg, stt.language)
+		if err != nil {
+			return fmt.Errorf("unable to parse Vosk output: %w", err)
+		}
+		if transcript == nil {
+			continue
+		}
+		stt.segmentSequencer.Assign(transcript)
+
+		select {
+		case stt.resultQueue <- transcript:
+		default:
+			logger.Error(ctx, "the queue is full, dropping the message")
+		}
+	}
+}
+
+func (stt *SpeechToText) WriteAudio(
+	ctx context.Context,
+	audio []byte,
+) error {
+	return xsync.DoR1(xsync.WithNoLogging(ctx, true), &stt.writeLocker, func() error {
+		if err := stt.conn.WriteMessage(websocket.BinaryMessage, audio); err != nil {
+			return fmt.Errorf("unable to write audio: %w", err)
+		}
+		return nil
+	})
+}
+
+func (stt *SpeechToText) OutputChan(context.Context) (<-chan *speech.Transcript, error) {
+	return stt.resultQueue, nil
+}
+
+// Close asks the server to finalize the recognition, waits for the final
+// result (up to OptionCloseTimeout) and closes the connection.
+func (stt *SpeechToText) Close() error {
+	if stt.closeCount.Add(1) != 1 {
+		return fmt.Errorf("already closed")
+	}
+
+	ctx := context.TODO()
+	var mErr *multierror.Error
+	err := xsync.DoR1(ctx, &stt.writeLocker, func() error {
+		return stt.conn.WriteJSON(eofMessage{EOF: 1})
+	})
+	if err != nil {
+		mErr = multierror.Append(mErr, fmt.Errorf("unable to send EOF: %w", err))
+	} else {
+		select {
+		case <-stt.loopDone:
+		case <-time.After(stt.config.CloseTimeout):
+			logger.Warnf(ctx, "timed out waiting for the final result")
+		}
+	}
+
+	stt.cancelFunc()
+	mErr = multierror.Append(mErr, stt.conn.Close())
+	stt.waitForClosure()
+	return mErr.ErrorOrNil()
+}
+
+func (stt *SpeechToText) waitForClosure() {
+	stt.wg.Wait()
+}
@@ -0,0 +1,162 @@
+package vosk
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+// newFakeVoskServer starts a server replying like Vosk does: a partial result
+// for every audio chunk and a final result on EOF.
+func newFakeVoskServer(
+	t *testing.T,
+	configs chan<- configMessage,
+) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var cfg configMessage
+		if err := conn.ReadJSON(&cfg); err != nil {
+			return
+		}
+		configs <- cfg
+
+		var received int
+		for {
+			msgType, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if msgType == websocket.BinaryMessage {
+				received += len(msg)
+				var reply string
+				switch received {
+				case 10:
+					reply = `{"partial": ""}`
+				default:
+					reply = `{"partial": "hello"}`
+				}
+				if err := conn.WriteMessage(websocket.TextMessage, []byte(reply)); err != nil {
+					return
+				}
+				continue
+			}
+			if !strings.Contains(string(msg), `"eof"`) {
+				continue
+			}
+			conn.WriteMessage(websocket.TextMessage, []byte(`{
+  "result" : [{
+      "conf" : 1.000000,
+      "end" : 1.110000,
+      "start" : 0.870000,
+      "word" : "hello"
+    }, {
+      "conf" : 0.5,
+      "end" : 1.530000,
+      "start" : 1.110000,
+      "word" : "world"
+    }],
+  "text" : "hello world"
+}`))
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSpeechToText(t *testing.T) {
+	ctx := context.Background()
+	configs := make(chan configMessage, 1)
+	srv := newFakeVoskServer(t, configs)
+
+	stt, err := New(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), "en-US", OptionSampleRate(8000))
+	require.NoError(t, err)
+
+	enc, err := stt.AudioEncoding(ctx)
+	require.NoError(t, err)
+	require.Equal(t, audio.EncodingPCM{PCMFormat: audio.PCMFormatS16LE, SampleRate: 8000}, enc)
+
+	select {
+	case cfg := <-configs:
+		require.Equal(t, float64(8000), cfg.Config.SampleRate)
+		require.Equal(t, 1, cfg.Config.Words)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the config")
+	}
+
+	outCh, err := stt.OutputChan(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, stt.WriteAudio(ctx, make([]byte, 10)))
+	require.NoError(t, stt.WriteAudio(ctx, make([]byte, 10)))
+	partial := <-outCh
+	require.Equal(t, &speech.Transcript{
+		Variants:  []speech.TranscriptVariant{{Text: "hello"}},
+		Language:  "en-US",
+		SegmentID: 1,
+	}, partial)
+
+	require.NoError(t, stt.Close())
+
+	final := <-outCh
+	require.Equal(t, &speech.Transcript{
+		Variants: []speech.TranscriptVariant{{
+			Text: "hello world",
+			TranscriptTokens: []speech.TranscriptToken{{
+				StartTime:  870 * time.Millisecond,
+				EndTime:    1110 * time.Millisecond,
+				Text:       "hello",
+				Confidence: 1,
+			}, {
+				StartTime:  1110 * time.Millisecond,
+				EndTime:    1530 * time.Millisecond,
+				Text:       " world",
+				Confidence: 0.5,
+			}},
+			Confidence: 0.75,
+		}},
+		Stability: 1,
+		Language:  "en-US",
+		IsFinal:   true,
+		SegmentID: 1,
+		Revision:  1,
+	}, final)
+
+	_, ok := <-outCh
+	require.False(t, ok)
+}
+
+func TestParseMessageAlternatives(t *testing.T) {
+	transcript, err := parseMessage([]byte(`{"alternatives": [{"confidence": 210.5, "text": "one two"}, {"confidence": 200.1, "text": "one too"}, {"confidence": 1, "text": ""}]}`), "")
+	require.NoError(t, err)
+	require.True(t, transcript.IsFinal)
+	require.Len(t, transcript.Variants, 2)
+	require.Equal(t, speech.Text("one two"), transcript.Variants[0].Text)
+	require.Equal(t, speech.Text("one too"), transcript.Variants[1].Text)
+	// the raw scores are normalized to the probabilities (e^-10.4 is the odds of the second one)
+	require.InDelta(t, 1, transcript.Variants[0].Confidence, 0.0001)
+	require.InDelta(t, math.Exp(-10.4), transcript.Variants[1].Confidence, 1e-6)
+
+	transcript, err = parseMessage([]byte(`{"text": ""}`), "")
+	require.NoError(t, err)
+	require.Nil(t, transcript)
+
+	_, err = parseMessage([]byte(`{}`), "")
+	require.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package whisper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
+)
+
+// CommitPolicy defines when the streamed audio is sent to whisper and
+// when the recognized segments are considered final.
+type CommitPolicy struct {
+	// BufferLimit is the maximal duration of uncommitted audio; if it is
+	// exceeded, the older half of the buffer is discarded.
+	BufferLimit time.Duration
+
+	// GapToCommit is the minimal silence after the last segment
+	// to consider it final.
+	GapToCommit time.Duration
+
+	// DiscardIfNoUsefulSegmentsIterations is the amount of consecutive
+	// iterations without useful segments, after which the buffer is discarded.
+	DiscardIfNoUsefulSegmentsIterations uint
+
+	// IterationInterval is how often the buffer is sent to whisper.
+	IterationInterval time.Duration
+
+	// PreserveHeadingDuration is how much of the already processed audio
+	// is kept before the uncommitted audio, as a context.
+	PreserveHeadingDuration time.Duration
+
+	// MinSendingDuration is the minimal duration of the buffer
+	// to be sent to whisper.
+	MinSendingDuration time.Duration
+}
+
+var (
+	// CommitPolicyBalanced is the default.
+	CommitPolicyBalanced = CommitPolicy{
+		BufferLimit:                         120 * time.Second,
+		GapToCommit:                         2 * time.Second,
+		DiscardIfNoUsefulSegmentsIterations: 4,
+		IterationInterval:                   time.Second,
+		PreserveHeadingDuration:             time.Second,
+		MinSendingDuration:                  2 * time.Second,
+	}
+
+	// CommitPolicyLowLatency is for live captions: it finalizes
+	// the segments faster at the cost of accuracy and CPU/GPU usage.
+	CommitPolicyLowLatency = CommitPolicy{
+		BufferLimit:                         30 * time.Second,
+		GapToCommit:                         time.Second,
+		DiscardIfNoUsefulSegmentsIterations: 4,
+		IterationInterval:                   500 * time.Millisecond,
+		PreserveHeadingDuration:             500 * time.Millisecond,
+		MinSendingDuration:                  time.Second,
+	}
+
+	// CommitPolicyAccuracy gives whisper more context at the cost of latency.
+	CommitPolicyAccuracy = CommitPolicy{
+		BufferLimit:                         120 * time.Second,
+		GapToCommit:                         3 * time.Second,
+		DiscardIfNoUsefulSegmentsIterations: 6,
+		IterationInterval:                   2 * time.Second,
+		PreserveHeadingDuration:             2 * time.Second,
+		MinSendingDuration:                  5 * time.Second,
+	}
+)
+
+func CommitPolicyFromPreset(preset types.CommitPolicyPreset) (CommitPolicy, error) {
+	switch preset {
+	case types.CommitPolicyPresetBalanced:
+		return CommitPolicyBalanced, nil
+	case types.CommitPolicyPresetLowLatency:
+		return CommitPolicyLowLatency, nil
+	case types.CommitPolicyPresetAccuracy:
+		return CommitPolicyAccuracy, nil
+	}
+	return CommitPolicy{}, fmt.Errorf("unknown commit policy preset: %v", preset)
+}
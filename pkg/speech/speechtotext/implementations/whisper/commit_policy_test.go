@@ -0,0 +1,115 @@
+package whisper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+func TestCommitPolicyMinSendingDuration(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		policy     CommitPolicy
+		expectCall bool
+	}{
+		{name: "low-latency", policy: CommitPolicyLowLatency, expectCall: true},
+		{name: "balanced", policy: CommitPolicyBalanced, expectCall: false},
+		{name: "accuracy", policy: CommitPolicyAccuracy, expectCall: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := &fakeEngine{}
+			stt := newTestSTT(t, engine, OptionCommitPolicy(tc.policy))
+			writeSilence(t, stt, 1500*time.Millisecond)
+			commit(t, stt)
+			require.Equal(t, tc.expectCall, len(engine.Calls) > 0)
+		})
+	}
+}
+
+func TestCommitPolicyGapToCommit(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		policy      CommitPolicy
+		expectFinal bool
+	}{
+		{name: "low-latency", policy: CommitPolicyLowLatency, expectFinal: true},
+		{name: "balanced", policy: CommitPolicyBalanced, expectFinal: false},
+		{name: "accuracy", policy: CommitPolicyAccuracy, expectFinal: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// the speech ends 1.5 seconds before the end of the buffer
+			engine := &fakeEngine{Script: func(_ int, _ []float32, duration time.Duration) []*Segment {
+				return []*Segment{fakeSegment(" hello world", 0, duration-1500*time.Millisecond)}
+			}}
+			stt := newTestSTT(t, engine, OptionCommitPolicy(tc.policy))
+			writeSilence(t, stt, 6*time.Second)
+			commit(t, stt)
+			require.Len(t, engine.Calls, 1)
+
+			transcripts := readTranscripts(stt)
+			require.Len(t, transcripts, 1)
+			require.Equal(t, speech.Text(" hello world"), transcripts[0].Variants[0].Text)
+			require.Equal(t, tc.expectFinal, transcripts[0].IsFinal)
+		})
+	}
+}
+
+func TestCommitPolicyCommitsAfterWarmup(t *testing.T) {
+	policy := CommitPolicyLowLatency
+	engine := &fakeEngine{Script: func(_ int, _ []float32, duration time.Duration) []*Segment {
+		return []*Segment{
+			fakeSegment(" first", 0, time.Second),
+			fakeSegment(" second", time.Second, duration-100*time.Millisecond),
+		}
+	}}
+	stt := newTestSTT(t, engine, OptionCommitPolicy(policy))
+
+	// warmup: the buffer is discarded except the heading to preserve
+	for i := 0; i < 2; i++ {
+		writeSilence(t, stt, 2*time.Second)
+		posBefore, bufLen := stt.CommittingPosBytes, uint64(len(stt.NextBuffer))
+		commit(t, stt)
+		require.Equal(t, posBefore+bufLen-getBytesPos(policy.PreserveHeadingDuration), stt.CommittingPosBytes)
+		require.Equal(t, policy.PreserveHeadingDuration, getDurationFromBytes(uint64(len(stt.NextBuffer))))
+	}
+	readTranscripts(stt)
+
+	writeSilence(t, stt, 2*time.Second)
+	posBefore := stt.CommittingPosBytes
+	commit(t, stt)
+	require.Len(t, engine.Calls, 3)
+
+	// only the first segment is final, so only it is committed
+	require.Equal(t, posBefore+getBytesPos(time.Second), stt.CommittingPosBytes)
+	transcripts := readTranscripts(stt)
+	require.Len(t, transcripts, 2)
+	require.True(t, transcripts[0].IsFinal)
+	require.False(t, transcripts[1].IsFinal)
+	require.Equal(t, getDurationFromBytes(posBefore), transcripts[0].Variants[0].TranscriptTokens[0].StartTime)
+	require.Equal(t, policy.PreserveHeadingDuration+time.Second, getDurationFromBytes(uint64(len(stt.NextBuffer))))
+}
+
+func TestCommitPolicyBufferLimit(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		policy     CommitPolicy
+		expectHalf bool
+	}{
+		{name: "low-latency", policy: CommitPolicyLowLatency, expectHalf: true},
+		{name: "balanced", policy: CommitPolicyBalanced, expectHalf: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stt := newTestSTT(t, &fakeEngine{}, OptionCommitPolicy(tc.policy))
+			writeSilence(t, stt, 40*time.Second)
+			if tc.expectHalf {
+				require.Equal(t, getBytesPos(tc.policy.BufferLimit/2), uint64(len(stt.NextBuffer)))
+				require.Equal(t, getBytesPos(tc.policy.BufferLimit/2), stt.CommittingPosBytes)
+			} else {
+				require.Equal(t, getBytesPos(40*time.Second), uint64(len(stt.NextBuffer)))
+				require.Zero(t, stt.CommittingPosBytes)
+			}
+		})
+	}
+}
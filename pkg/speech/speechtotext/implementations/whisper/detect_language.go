@@ -0,0 +1,118 @@
+package whisper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/xsync"
+)
+
+var _ speech.LanguageDetector = (*SpeechToText)(nil)
+
+// DetectLanguage returns the probabilities of the languages spoken in the audio
+// (PCM F32LE 16kHz mono); it does not affect the transcription of the stream.
+func (stt *SpeechToText) DetectLanguage(
+	ctx context.Context,
+	audio []byte,
+) (_ret speech.LanguageProbabilities, _err error) {
+	logger.Tracef(ctx, "DetectLanguage(ctx, %d bytes)", len(audio))
+	defer func() { logger.Tracef(ctx, "/DetectLanguage(ctx, %d bytes): %v %v", len(audio), _ret, _err) }()
+
+	if len(audio) < 4 {
+		return nil, fmt.Errorf("the audio is too short: %d bytes", len(audio))
+	}
+	samples := convertBytesToFloat32Slice(audio)
+
+	// the engine state is shared with the transcription, but only
+	// the encoder is run here (the audio is not decoded)
+	return xsync.DoR2(xsync.WithNoLogging(ctx, true), &stt.EngineMutex, func() (speech.LanguageProbabilities, error) {
+		return stt.Engine.DetectSamplesLanguage(ctx, samples)
+	})
+}
+
+// detectIterationLanguages returns the probabilities of the languages
+// spoken in the samples passed to the last Engine.Full call; nil
+// if they could not be detected.
+func (stt *SpeechToText) detectIterationLanguages(
+	ctx context.Context,
+) speech.LanguageProbabilities {
+	langs, err := stt.Engine.DetectLanguage(ctx, 0)
+	if err != nil {
+		logger.Errorf(ctx, "unable to detect the language: %v", err)
+		return nil
+	}
+	return langs
+}
+
+// segmentLanguage returns the most probable language (among the allowed ones,
+// see OptionAllowedLanguages) of the segment recognized by the last Engine.Full call.
+//
+// Each detection runs the encoder, so it is done separately only for
+// the committed segments (which are sent once) starting after the beginning
+// of the samples; the rest get the language of the whole iteration.
+func (stt *SpeechToText) segmentLanguage(
+	ctx context.Context,
+	s *Segment,
+	isCommitted bool,
+	iterationLangs speech.LanguageProbabilities,
+) speech.LanguageProbability {
+	langs := iterationLangs
+	if isCommitted && s.T0 > 0 {
+		var err error
+		langs, err = stt.Engine.DetectLanguage(ctx, s.T0)
+		if err != nil {
+			logger.Errorf(ctx, "unable to detect the language of segment '%s': %v", s.Text, err)
+			langs = nil
+		}
+	}
+	if langs == nil {
+		return speech.LanguageProbability{Language: stt.LastLanguageDetected}
+	}
+	lang := stt.bestAllowedLanguage(langs)
+	logger.Debugf(ctx, "the language of segment '%s' is %s (%.3f)", s.Text, lang.Language, lang.Probability)
+	stt.LastLanguageDetected = lang.Language
+	return lang
+}
+
+// bestAllowedLanguage returns the most probable language among the allowed ones
+// (or the first allowed language, if none of them is probable at all).
+func (stt *SpeechToText) bestAllowedLanguage(
+	langs speech.LanguageProbabilities,
+) speech.LanguageProbability {
+	if len(stt.AllowedLanguages) == 0 {
+		return langs.Best()
+	}
+	allowed := langs.Restrict(stt.AllowedLanguages)
+	if len(allowed) == 0 {
+		return speech.LanguageProbability{Language: stt.AllowedLanguages[0]}
+	}
+	return allowed.Best()
+}
+
+// enforceAllowedLanguages re-runs the inference on the samples with the most
+// probable allowed language, if the language detected in them (langs) is
+// not allowed (see OptionAllowedLanguages). It returns the language set to
+// the Engine (to be unset by the caller), if any.
+//
+// The re-run doubles the cost of the iteration, but it happens only if
+// the speech is recognized as a language, which is not allowed.
+func (stt *SpeechToText) enforceAllowedLanguages(
+	ctx context.Context,
+	samples []float32,
+	langs speech.LanguageProbabilities,
+) (speech.Language, error) {
+	if len(stt.AllowedLanguages) == 0 || langs == nil {
+		return "", nil
+	}
+	detected := langs.Best()
+	if _, ok := detected.Language.MatchFamily(stt.AllowedLanguages); ok {
+		return "", nil
+	}
+
+	lang := stt.bestAllowedLanguage(langs)
+	logger.Debugf(ctx, "detected language %s (%.3f) is not allowed, decoding as %s (%.3f)", detected.Language, detected.Probability, lang.Language, lang.Probability)
+	stt.Engine.SetLanguage(lang.Language)
+	return lang.Language, stt.Engine.Full(ctx, samples)
+}
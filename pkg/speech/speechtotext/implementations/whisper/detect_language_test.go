@@ -0,0 +1,136 @@
+package whisper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+func TestLanguageFromWhisper(t *testing.T) {
+	require.Equal(t, speech.Language("en-US"), LanguageFromWhisper("en", "en-US"))
+	require.Equal(t, speech.Language("de"), LanguageFromWhisper("de", "en-US"))
+	require.Equal(t, speech.Language("de"), LanguageFromWhisper("de", ""))
+	require.Equal(t, speech.Language(""), LanguageFromWhisper("", "en-US"))
+}
+
+func TestCommitAudioSegmentLanguage(t *testing.T) {
+	newEngine := func() *fakeEngine {
+		return &fakeEngine{
+			Script: func(_ int, _ []float32, _ time.Duration) []*Segment {
+				return []*Segment{
+					fakeSegment(" hello", 0, time.Second),
+					fakeSegment(" hallo", time.Second, 2*time.Second),
+				}
+			},
+			Languages: func(offset time.Duration) speech.LanguageProbabilities {
+				if offset < time.Second {
+					return speech.LanguageProbabilities{{Language: "en-US", Probability: 0.9}, {Language: "de", Probability: 0.1}}
+				}
+				return speech.LanguageProbabilities{{Language: "de", Probability: 0.7}, {Language: "en-US", Probability: 0.3}}
+			},
+		}
+	}
+
+	t.Run("committed", func(t *testing.T) {
+		engine := newEngine()
+		stt := newTestSTT(t, engine)
+		skipWarmup(stt)
+		writeSilence(t, stt, 4*time.Second)
+		commit(t, stt)
+
+		require.Equal(t, []time.Duration{0, time.Second}, engine.DetectLanguageOffsets)
+		transcripts := readTranscripts(stt)
+		require.Len(t, transcripts, 2)
+		require.Equal(t, speech.Language("en-US"), transcripts[0].Language)
+		require.Equal(t, float32(0.9), transcripts[0].LanguageConfidence)
+		require.Equal(t, speech.Language("de"), transcripts[1].Language)
+		require.Equal(t, float32(0.7), transcripts[1].LanguageConfidence)
+	})
+
+	t.Run("uncommitted", func(t *testing.T) {
+		// the language is detected once per iteration for the uncommitted segments
+		engine := newEngine()
+		stt := newTestSTT(t, engine)
+		skipWarmup(stt)
+		writeSilence(t, stt, 3*time.Second)
+		commit(t, stt)
+
+		require.Equal(t, []time.Duration{0}, engine.DetectLanguageOffsets)
+		transcripts := readTranscripts(stt)
+		require.Len(t, transcripts, 2)
+		require.True(t, transcripts[0].IsFinal)
+		require.False(t, transcripts[1].IsFinal)
+		require.Equal(t, speech.Language("en-US"), transcripts[1].Language)
+		require.Equal(t, float32(0.9), transcripts[1].LanguageConfidence)
+	})
+}
+
+func TestDetectLanguage(t *testing.T) {
+	engine := &fakeEngine{}
+	stt := newTestSTT(t, engine)
+
+	_, err := stt.DetectLanguage(context.Background(), nil)
+	require.Error(t, err)
+
+	langs, err := stt.DetectLanguage(context.Background(), make([]byte, getBytesPos(time.Second)))
+	require.NoError(t, err)
+	require.Equal(t, speech.LanguageProbability{Language: "en", Probability: 1}, langs.Best())
+	require.Equal(t, 1, engine.DetectSamplesLanguageCalls)
+	require.Empty(t, engine.Calls)
+}
+
+func TestCommitAudioAllowedLanguages(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		detected         speech.LanguageProbabilities
+		expectedCalls    []speech.Language
+		expectedLanguage speech.Language
+	}{
+		{
+			name:             "allowed",
+			detected:         speech.LanguageProbabilities{{Language: "en", Probability: 0.8}, {Language: "ru", Probability: 0.2}},
+			expectedCalls:    []speech.Language{""},
+			expectedLanguage: "en-US",
+		},
+		{
+			name:             "not-allowed",
+			detected:         speech.LanguageProbabilities{{Language: "de", Probability: 0.6}, {Language: "ru", Probability: 0.3}, {Language: "en", Probability: 0.1}},
+			expectedCalls:    []speech.Language{"", "ru-RU"},
+			expectedLanguage: "ru-RU",
+		},
+		{
+			name:             "none-probable",
+			detected:         speech.LanguageProbabilities{{Language: "de", Probability: 1}},
+			expectedCalls:    []speech.Language{"", "en-US"},
+			expectedLanguage: "en-US",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := &fakeEngine{
+				Script: func(_ int, _ []float32, _ time.Duration) []*Segment {
+					return []*Segment{fakeSegment(" hello", 0, time.Second)}
+				},
+				Languages: func(time.Duration) speech.LanguageProbabilities {
+					return tc.detected
+				},
+			}
+			stt := newTestSTT(t, engine, OptionAllowedLanguages{"en-US", "ru-RU"})
+			skipWarmup(stt)
+			writeSilence(t, stt, 3*time.Second)
+			commit(t, stt)
+
+			// the re-run of the inference in an allowed language doubles
+			// the cost of the iteration, so it is done only when needed,
+			// and the language is detected once per iteration
+			require.Equal(t, tc.expectedCalls, engine.CallLanguages)
+			require.Equal(t, []time.Duration{0}, engine.DetectLanguageOffsets)
+			require.Empty(t, engine.Language)
+			transcripts := readTranscripts(stt)
+			require.Len(t, transcripts, 1)
+			require.Equal(t, tc.expectedLanguage, transcripts[0].Language)
+		})
+	}
+}
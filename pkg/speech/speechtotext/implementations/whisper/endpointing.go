@@ -0,0 +1,204 @@
+package whisper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/facebookincubator/go-belt"
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/xsync"
+)
+
+const (
+	// EndpointingFrameDuration is the granularity of the pauses detection.
+	EndpointingFrameDuration = 30 * time.Millisecond
+
+	// EndpointingIterationInterval is how often the new audio
+	// is checked for pauses.
+	EndpointingIterationInterval = 100 * time.Millisecond
+)
+
+// Endpointing defines how the audio is cut into utterances
+// at the pauses detected by the VAD (see OptionEndpointing).
+type Endpointing struct {
+	// MinSilence is the minimal pause after the speech
+	// to consider the utterance finished; it should be positive.
+	MinSilence time.Duration
+
+	// MaxUtteranceLength is the maximal duration of an utterance;
+	// a longer utterance is cut forcefully. It should not be shorter
+	// than the minimal voice duration (see OptionVADMinVoiceDuration).
+	MaxUtteranceLength time.Duration
+}
+
+var (
+	// DefaultEndpointing is suitable for dictation.
+	DefaultEndpointing = Endpointing{
+		MinSilence:         600 * time.Millisecond,
+		MaxUtteranceLength: 20 * time.Second,
+	}
+)
+
+// endpointAudio is used instead of commitAudio if Endpointing is set:
+// it cuts the audio into utterances at the pauses detected by the VAD,
+// and transcribes each utterance once, sending its segments as final.
+func (stt *SpeechToText) endpointAudio(
+	ctx context.Context,
+) (_err error) {
+	logger.Tracef(ctx, "endpointAudio")
+	defer func() { logger.Tracef(ctx, "/endpointAudio: %v", _err) }()
+
+	buf := xsync.DoR1(xsync.WithNoLogging(ctx, true), &stt.Mutex, func() []byte {
+		if len(stt.NextBuffer) == 0 {
+			return nil
+		}
+		stt.NextBuffer, stt.CommittingBuffer = stt.CommittingBuffer, stt.NextBuffer
+		stt.NextBuffer = stt.NextBuffer[:0]
+		return stt.CommittingBuffer
+	})
+	if buf == nil {
+		return nil
+	}
+
+	oldCommittingPosBytes := stt.CommittingPosBytes
+	defer stt.Mutex.Do(xsync.WithNoLogging(ctx, true), func() {
+		stt.releaseCommittingBufferNoLock(ctx, buf, stt.CommittingPosBytes-oldCommittingPosBytes)
+	})
+
+	frameSize := getBytesPos(EndpointingFrameDuration)
+	startPos := stt.CommittingPosBytes
+	endPos := startPos + uint64(len(buf))
+	if stt.EndpointingCheckedUntilBytes < startPos {
+		stt.EndpointingCheckedUntilBytes = startPos
+	}
+
+	isFinished := false
+	for !isFinished && stt.EndpointingCheckedUntilBytes+frameSize <= endPos {
+		framePos := stt.EndpointingCheckedUntilBytes
+		frame := buf[framePos-startPos : framePos-startPos+frameSize]
+		isVoice, _, err := stt.checkIfVoiceActive(belt.WithField(ctx, "subsystem", "VAD"), frame, time.Nanosecond)
+		if err != nil {
+			logger.Errorf(ctx, "VAD: unable to detect if voice is active: %v", err)
+		}
+		stt.EndpointingCheckedUntilBytes += frameSize
+		isFinished = stt.endpointingNextFrame(framePos, isVoice)
+	}
+
+	if !stt.EndpointingIsInUtterance {
+		// no speech, keeping only the context for the next utterance
+		keepBytes := getBytesPos(stt.VADKeepContext)
+		if stt.EndpointingCheckedUntilBytes > startPos+keepBytes {
+			stt.CommittingPosBytes = stt.EndpointingCheckedUntilBytes - keepBytes
+		}
+		return nil
+	}
+	stt.CommittingPosBytes = stt.EndpointingUtteranceStartBytes
+	if !isFinished {
+		return nil
+	}
+
+	utteranceEndPos := stt.EndpointingCheckedUntilBytes
+	stt.EndpointingIsInUtterance = false
+	if stt.EndpointingVoiceDuration < stt.VADMinVoiceDuration {
+		logger.Debugf(ctx, "the voice is too short (%v < %v), skipping the utterance", stt.EndpointingVoiceDuration, stt.VADMinVoiceDuration)
+		stt.CommittingPosBytes = utteranceEndPos
+		return nil
+	}
+
+	utterance := buf[stt.CommittingPosBytes-startPos : utteranceEndPos-startPos]
+	if err := stt.transcribeUtterance(ctx, convertBytesToFloat32Slice(utterance)); err != nil {
+		return err
+	}
+	stt.CommittingPosBytes = utteranceEndPos
+	stt.LocalAgreement.Commit(stt.CommittingPosBytes)
+	stt.SegmentTracker.Commit(stt.CommittingPosBytes)
+	return nil
+}
+
+// endpointingNextFrame processes the VAD result of the next frame of
+// the audio, it returns true if the current utterance is finished.
+func (stt *SpeechToText) endpointingNextFrame(
+	framePos uint64,
+	isVoice bool,
+) bool {
+	if !stt.EndpointingIsInUtterance {
+		if !isVoice {
+			return false
+		}
+		stt.EndpointingIsInUtterance = true
+		stt.EndpointingUtteranceStartBytes = stt.CommittingPosBytes
+		if keepBytes := getBytesPos(stt.VADKeepContext); framePos > stt.CommittingPosBytes+keepBytes {
+			stt.EndpointingUtteranceStartBytes = framePos - keepBytes
+		}
+		stt.EndpointingVoiceDuration = 0
+		stt.EndpointingSilenceDuration = 0
+	}
+
+	if isVoice {
+		stt.EndpointingVoiceDuration += EndpointingFrameDuration
+		stt.EndpointingSilenceDuration = 0
+	} else {
+		stt.EndpointingSilenceDuration += EndpointingFrameDuration
+	}
+
+	utteranceLength := getDurationFromBytes(framePos + getBytesPos(EndpointingFrameDuration) - stt.EndpointingUtteranceStartBytes)
+	return stt.EndpointingSilenceDuration >= stt.Endpointing.MinSilence ||
+		utteranceLength >= stt.Endpointing.MaxUtteranceLength
+}
+
+// transcribeUtterance sends the segments of the utterance starting at
+// CommittingPosBytes; all the segments are final.
+func (stt *SpeechToText) transcribeUtterance(
+	ctx context.Context,
+	samples []float32,
+) error {
+	logger.Debugf(ctx, "transcribing an utterance of %v", getDurationFromBytes(uint64(len(samples))*4))
+	stt.EngineMutex.ManualLock(xsync.WithNoLogging(ctx, true))
+	defer stt.EngineMutex.ManualUnlock(xsync.WithNoLogging(ctx, true))
+
+	stt.Iterations++
+	if err := stt.Engine.Full(ctx, samples); err != nil {
+		return fmt.Errorf("unable to build a transcription: %w", err)
+	}
+	iterationLangs := stt.detectIterationLanguages(ctx)
+	forcedLanguage, err := stt.enforceAllowedLanguages(ctx, samples, iterationLangs)
+	if err != nil {
+		return fmt.Errorf("unable to build a transcription in an allowed language: %w", err)
+	}
+	if forcedLanguage != "" {
+		defer stt.Engine.SetLanguage("")
+	}
+
+	segments := engineSegments(stt.Engine)
+	var translations [][]*Segment
+	if stt.EmitTranslation && len(segments) > 0 {
+		translations, err = stt.translateSegments(ctx, samples, segments)
+		if err != nil {
+			return fmt.Errorf("unable to build a translation: %w", err)
+		}
+	}
+
+	stt.LocalAgreement.Begin(stt.CommittingPosBytes)
+	stt.retractSegments(ctx, stt.SegmentTracker.Begin(stt.CommittingPosBytes))
+	for idx, segment := range segments {
+		if isHangingSegment(segment) {
+			logger.Debugf(ctx, "this is a hang-causing segment")
+			continue
+		}
+		language := stt.segmentLanguage(ctx, segment, true, iterationLangs)
+		if stt.isLikelyHallucination(ctx, segment, language.Language) {
+			logger.Debugf(ctx, "likely a hallucination: '%s', skipping", segment.Text)
+			stt.writeSegment(ctx, segment, true, samples, speech.TranscriptKindSuspectedHallucination, language, nil)
+			continue
+		}
+		var translation []*Segment
+		if translations != nil {
+			translation = translations[idx]
+		}
+		stt.writeSegment(ctx, segment, true, samples, speech.TranscriptKindSpeech, language, translation)
+	}
+	stt.retractSegments(ctx, stt.SegmentTracker.End())
+	return nil
+}
@@ -0,0 +1,115 @@
+package whisper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/hallucination"
+)
+
+func newTestEndpointingSTT(t *testing.T, engine InferenceEngine, opts ...Option) *SpeechToText {
+	opts = append([]Option{OptionVAD{VAD: fakeVAD{}}}, opts...)
+	stt, err := newTestSTTWithVAD(engine, opts...)
+	require.NoError(t, err)
+	stt.Out = make(chan *speech.Transcript, 1024)
+	return stt
+}
+
+func endpoint(t *testing.T, stt *SpeechToText) {
+	require.NoError(t, stt.endpointAudio(context.Background()))
+}
+
+func TestEndpointAudio(t *testing.T) {
+	engine := &fakeEngine{Script: func(_ int, _ []float32, duration time.Duration) []*Segment {
+		return []*Segment{fakeSegment(" hello world", 200*time.Millisecond, duration-300*time.Millisecond)}
+	}}
+	stt := newTestEndpointingSTT(t, engine,
+		OptionVADKeepContext(200*time.Millisecond),
+		OptionVADMinVoiceDuration(100*time.Millisecond),
+		OptionEndpointing{MinSilence: 300 * time.Millisecond, MaxUtteranceLength: 5 * time.Second},
+	)
+
+	// the silence is discarded, except the context
+	writeSilence(t, stt, 900*time.Millisecond)
+	endpoint(t, stt)
+	require.Empty(t, engine.Calls)
+	require.Equal(t, getBytesPos(700*time.Millisecond), stt.CommittingPosBytes)
+	require.Equal(t, getBytesPos(200*time.Millisecond), uint64(len(stt.NextBuffer)))
+
+	// the utterance is not finished, yet
+	writeVoice(t, stt, 900*time.Millisecond)
+	endpoint(t, stt)
+	require.Empty(t, engine.Calls)
+	require.Equal(t, getBytesPos(700*time.Millisecond), stt.CommittingPosBytes)
+
+	writeSilence(t, stt, 300*time.Millisecond)
+	endpoint(t, stt)
+	require.Equal(t, []time.Duration{1400 * time.Millisecond}, engine.Calls)
+	require.Equal(t, getBytesPos(2100*time.Millisecond), stt.CommittingPosBytes)
+	require.Empty(t, stt.NextBuffer)
+
+	transcripts := readAllTranscripts(stt)
+	require.Len(t, transcripts, 1)
+	require.True(t, transcripts[0].IsFinal)
+	require.Equal(t, speech.SegmentID(1), transcripts[0].SegmentID)
+	require.Equal(t, speech.Text(" hello world"), transcripts[0].Variants[0].Text)
+	require.Equal(t, 900*time.Millisecond, transcripts[0].Variants[0].TranscriptTokens[0].StartTime)
+
+	// the transcribed utterance is not transcribed again
+	writeSilence(t, stt, 300*time.Millisecond)
+	endpoint(t, stt)
+	require.Len(t, engine.Calls, 1)
+	require.Empty(t, readAllTranscripts(stt))
+}
+
+func TestEndpointAudioMaxUtteranceLength(t *testing.T) {
+	engine := &fakeEngine{}
+	stt := newTestEndpointingSTT(t, engine,
+		OptionVADKeepContext(0),
+		OptionEndpointing{MinSilence: 300 * time.Millisecond, MaxUtteranceLength: 600 * time.Millisecond},
+	)
+	writeVoice(t, stt, 1500*time.Millisecond)
+	endpoint(t, stt)
+	endpoint(t, stt)
+	require.Equal(t, []time.Duration{600 * time.Millisecond, 600 * time.Millisecond}, engine.Calls)
+	require.Equal(t, getBytesPos(1200*time.Millisecond), stt.CommittingPosBytes)
+	require.Equal(t, getBytesPos(300*time.Millisecond), uint64(len(stt.NextBuffer)))
+}
+
+func TestEndpointAudioTooShortVoice(t *testing.T) {
+	engine := &fakeEngine{}
+	stt := newTestEndpointingSTT(t, engine,
+		OptionVADKeepContext(0),
+		OptionVADMinVoiceDuration(100*time.Millisecond),
+		OptionEndpointing{MinSilence: 300 * time.Millisecond, MaxUtteranceLength: 5 * time.Second},
+	)
+	writeVoice(t, stt, 60*time.Millisecond)
+	writeSilence(t, stt, 300*time.Millisecond)
+	endpoint(t, stt)
+	require.Empty(t, engine.Calls)
+	require.Equal(t, getBytesPos(360*time.Millisecond), stt.CommittingPosBytes)
+	require.False(t, stt.EndpointingIsInUtterance)
+}
+
+func TestEndpointingRequiresVAD(t *testing.T) {
+	_, err := newSpeechToText(context.Background(), &fakeEngine{}, hallucination.Model{}, 0, Options{OptionEndpointing(DefaultEndpointing)}.config())
+	require.ErrorAs(t, err, &ErrEndpointingRequiresVAD{})
+}
+
+func TestInvalidEndpointing(t *testing.T) {
+	for _, endpointing := range []Endpointing{
+		{MinSilence: 0, MaxUtteranceLength: 5 * time.Second},
+		{MinSilence: 300 * time.Millisecond, MaxUtteranceLength: 0},
+		{MinSilence: 300 * time.Millisecond, MaxUtteranceLength: 50 * time.Millisecond},
+	} {
+		_, err := newTestSTTWithVAD(&fakeEngine{},
+			OptionVAD{VAD: fakeVAD{}},
+			OptionVADMinVoiceDuration(100*time.Millisecond),
+			OptionEndpointing(endpointing),
+		)
+		require.ErrorAs(t, err, &ErrInvalidEndpointing{}, "endpointing: %+v", endpointing)
+	}
+}
@@ -0,0 +1,74 @@
+package whisper
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+// InferenceEngine is the speech recognition model used by SpeechToText.
+//
+// The default implementation is whisper.cpp (see New), but the streaming
+// logic (buffering, VAD, committing of segments) does not depend on it,
+// so it could be run with a different engine (see NewWithEngine).
+type InferenceEngine interface {
+	io.Closer
+
+	// Full recognizes the speech in the given samples (F32LE 16kHz mono);
+	// the result is available through NumSegments and Segment.
+	Full(ctx context.Context, samples []float32) error
+
+	// DetectLanguage returns the probabilities of the languages spoken
+	// in the samples passed to the last Full (or DetectSamplesLanguage)
+	// call, starting at the offset.
+	DetectLanguage(ctx context.Context, offset time.Duration) (speech.LanguageProbabilities, error)
+
+	// DetectSamplesLanguage returns the probabilities of the languages
+	// spoken in the given samples (F32LE 16kHz mono) without decoding
+	// them; the result of the last Full call is kept.
+	DetectSamplesLanguage(ctx context.Context, samples []float32) (speech.LanguageProbabilities, error)
+
+	// NumSegments returns the amount of segments recognized
+	// by the last Full call.
+	NumSegments() int
+
+	// Segment returns the segment recognized by the last Full call.
+	Segment(idx int) *Segment
+
+	// SetPrompt sets the text to prime the decoder with;
+	// an empty prompt unsets it.
+	SetPrompt(prompt string)
+
+	// SetLanguage sets the language to decode the speech as;
+	// an empty language means the configured one.
+	SetLanguage(language speech.Language)
+
+	// SetTranslate enables or disables translating the speech to English.
+	SetTranslate(shouldTranslate bool)
+}
+
+// Segment is a piece of a recognized text; the timestamps are relative
+// to the beginning of the samples passed to InferenceEngine.Full.
+type Segment struct {
+	Text         string
+	T0           time.Duration
+	T1           time.Duration
+	SpeakerTurn  bool
+	Tokens       []Token
+	NoSpeechProb float32
+}
+
+// Token is a token of a Segment.
+type Token struct {
+	ID   int32
+	Text string
+	P    float32
+	T0   time.Duration
+	T1   time.Duration
+
+	// IsDTWAligned tells that T0 and T1 are aligned by DTW
+	// on the attention heads (see types.AlignmentAheadsPreset).
+	IsDTWAligned bool
+}
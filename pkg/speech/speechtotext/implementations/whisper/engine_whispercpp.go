@@ -0,0 +1,269 @@
+//go:build cgo
+
+package whisper
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+	"unsafe"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/mutablelogic/go-whisper/sys/whisper"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+// #cgo pkg-config: libwhisper
+// #cgo linux pkg-config: libwhisper-linux
+// #cgo darwin pkg-config: libwhisper-darwin
+// #include <stdlib.h>
+// #include <whisper.h>
+import "C"
+
+// whisperCPPEngine is the InferenceEngine backed by whisper.cpp.
+type whisperCPPEngine struct {
+	Context *whisper.Context
+	Params  whisper.FullParams
+
+	// AbortCtx is the context of the current Full call; whisper.cpp
+	// aborts the inference if it is cancelled.
+	AbortCtx context.Context
+
+	// Language is the configured language; the detected
+	// languages are normalized to it (see LanguageFromWhisper).
+	Language speech.Language
+
+	// IsDTWEnabled tells that the tokens are aligned by DTW
+	// (see applyDTWTimestamps).
+	IsDTWEnabled bool
+
+	// AlignmentHeads is the C memory with the custom alignment heads
+	// (if any); it is freed on Close.
+	AlignmentHeads unsafe.Pointer
+}
+
+var _ InferenceEngine = (*whisperCPPEngine)(nil)
+
+func newWhisperCPPEngine(
+	ctx context.Context,
+	modelBytes []byte,
+	language speech.Language,
+	samplingStrategy types.SamplingStrategy,
+	shouldTranslate bool,
+	alignmentAheadPreset types.AlignmentAheadsPreset,
+	cfg config,
+) (*whisperCPPEngine, error) {
+	params := whisper.DefaultContextParams()
+	if cfg.UseGPU != nil {
+		params.SetUseGpu(*cfg.UseGPU)
+	}
+	if cfg.GPUDeviceID != nil {
+		params.SetGpuDevice(*cfg.GPUDeviceID)
+	}
+	if cfg.FlashAttn != nil {
+		params.SetFlashAttn(*cfg.FlashAttn)
+	}
+	isDTWEnabled := speechtotext_grpc.WhisperAlignmentAheadsPreset(alignmentAheadPreset) != speechtotext_grpc.WhisperAlignmentAheadsPreset_WhisperAlignmentAheadsPresetNone
+	params.SetTokenTimestamps(isDTWEnabled)
+	params.SetDTWAheadsPreset(AlignmentAheadsPreset(alignmentAheadPreset).ToWhisper())
+	var alignmentHeads unsafe.Pointer
+	switch speechtotext_grpc.WhisperAlignmentAheadsPreset(alignmentAheadPreset) {
+	case speechtotext_grpc.WhisperAlignmentAheadsPreset_WhisperAlignmentAheadsPresetCustom:
+		if len(cfg.AlignmentHeads) == 0 {
+			return nil, ErrAlignmentHeadsAreNotSet{Preset: alignmentAheadPreset}
+		}
+		alignmentHeads = setAlignmentHeads(&params, cfg.AlignmentHeads)
+	case speechtotext_grpc.WhisperAlignmentAheadsPreset_WhisperAlignmentAheadsPresetNTopMost:
+		if cfg.AlignmentHeadsNTop <= 0 {
+			return nil, ErrAlignmentHeadsAreNotSet{Preset: alignmentAheadPreset}
+		}
+		params.SetDTWNTop(cfg.AlignmentHeadsNTop)
+	}
+	whisper.Whisper_log_set(func(level whisper.LogLevel, text string) {
+		logger.FromCtx(ctx).Log(logLevelFromWhisper(level), text)
+	})
+
+	whisperCtx := whisper.Whisper_init_from_buffer_with_params(modelBytes, params)
+	if whisperCtx == nil {
+		C.free(alignmentHeads)
+		return nil, ErrInitContext{Err: fmt.Errorf("whisper.cpp was unable to load the model")}
+	}
+	e := &whisperCPPEngine{
+		Context:        whisperCtx,
+		Params:         whisper.DefaultFullParams(SamplingStrategy(samplingStrategy).ToWhisper()),
+		Language:       language,
+		IsDTWEnabled:   isDTWEnabled,
+		AlignmentHeads: alignmentHeads,
+	}
+
+	if shouldTranslate || cfg.EmitTranslation {
+		if !whisper.Whisper_is_multilingual(e.Context) {
+			e.Close()
+			return nil, ErrModelCannotTranslate{}
+		}
+	}
+
+	lang := LanguageToWhisper(language)
+	logger.Infof(ctx, "language: '%v'; shouldTranslate: %v", lang, shouldTranslate)
+
+	e.Params.SetTranslate(shouldTranslate)
+	e.Params.SetDiarize(true)
+	e.Params.SetTokenTimestamps(true)
+	e.Params.SetLanguage(lang)
+	e.applyDecodingParams(cfg)
+
+	e.Params.SetAbortCallback(e.Context, func() bool {
+		return e.AbortCtx != nil && e.AbortCtx.Err() != nil
+	})
+	return e, nil
+}
+
+func (e *whisperCPPEngine) Full(ctx context.Context, samples []float32) error {
+	e.AbortCtx = ctx
+	e.Params.SetOffsetMS(0)
+	return whisper.Whisper_full(e.Context, e.Params, samples)
+}
+
+func (e *whisperCPPEngine) DetectLanguage(
+	ctx context.Context,
+	offset time.Duration,
+) (speech.LanguageProbabilities, error) {
+	langProbs := make([]float32, whisper.Whisper_lang_max_id()+1)
+	err := whisper.Whisper_lang_auto_detect(e.Context, int(offset.Milliseconds()), e.Params.NumThreads(), langProbs)
+	if err != nil {
+		return nil, fmt.Errorf("unable to detect the language at %v: %w", offset, err)
+	}
+	result := make(speech.LanguageProbabilities, 0, len(langProbs))
+	for langID, langProb := range langProbs {
+		if langProb <= 0 {
+			continue
+		}
+		result = append(result, speech.LanguageProbability{
+			Language:    LanguageFromWhisper(whisper.Whisper_lang_str(langID), e.Language),
+			Probability: langProb,
+		})
+	}
+	slices.SortStableFunc(result, func(a, b speech.LanguageProbability) int {
+		return cmp.Compare(b.Probability, a.Probability)
+	})
+	return result, nil
+}
+
+func (e *whisperCPPEngine) DetectSamplesLanguage(
+	ctx context.Context,
+	samples []float32,
+) (speech.LanguageProbabilities, error) {
+	cCtx := (*C.struct_whisper_context)(unsafe.Pointer(e.Context))
+	if C.whisper_pcm_to_mel(cCtx, (*C.float)(&samples[0]), C.int(len(samples)), C.int(e.Params.NumThreads())) != 0 {
+		return nil, fmt.Errorf("unable to compute the log mel spectrogram of %d samples", len(samples))
+	}
+	return e.DetectLanguage(ctx, 0)
+}
+
+func (e *whisperCPPEngine) SetLanguage(language speech.Language) {
+	if language == "" {
+		language = e.Language
+	}
+	e.Params.SetLanguage(LanguageToWhisper(language))
+}
+
+func (e *whisperCPPEngine) SetTranslate(shouldTranslate bool) {
+	e.Params.SetTranslate(shouldTranslate)
+}
+
+func (e *whisperCPPEngine) NumSegments() int {
+	return e.Context.NumSegments()
+}
+
+func (e *whisperCPPEngine) Segment(idx int) *Segment {
+	s := e.Context.Segment(idx)
+	if s == nil {
+		return nil
+	}
+	tokens := make([]Token, 0, len(s.Tokens))
+	for _, token := range s.Tokens {
+		tokens = append(tokens, Token{
+			ID:   token.Id,
+			Text: token.Text,
+			P:    token.P,
+			T0:   token.T0,
+			T1:   token.T1,
+		})
+	}
+	if e.IsDTWEnabled {
+		e.applyDTWTimestamps(idx, tokens, s.T1)
+	}
+	return &Segment{
+		Text:         s.Text,
+		T0:           s.T0,
+		T1:           s.T1,
+		SpeakerTurn:  s.SpeakerTurn,
+		Tokens:       tokens,
+		NoSpeechProb: s.NoSpeechProb,
+	}
+}
+
+func (e *whisperCPPEngine) SetPrompt(prompt string) {
+	e.setInitialPrompt(prompt)
+}
+
+func (e *whisperCPPEngine) Close() error {
+	e.Params.SetAbortCallback(e.Context, nil)
+	whisper.Whisper_free(e.Context)
+	e.Context = nil
+	C.free(e.AlignmentHeads)
+	e.AlignmentHeads = nil
+	e.setInitialPrompt("")
+	return nil
+}
+
+// setAlignmentHeads sets the custom alignment heads, which are not
+// supported by the Go bindings of whisper.cpp; it returns the C memory
+// allocated for the heads.
+func setAlignmentHeads(
+	params *whisper.ContextParams,
+	heads types.AlignmentHeads,
+) unsafe.Pointer {
+	cHeadsPtr := C.malloc(C.size_t(len(heads)) * C.size_t(unsafe.Sizeof(C.whisper_ahead{})))
+	cHeads := unsafe.Slice((*C.whisper_ahead)(cHeadsPtr), len(heads))
+	for idx, head := range heads {
+		cHeads[idx] = C.whisper_ahead{
+			n_text_layer: C.int(head.TextLayer),
+			n_head:       C.int(head.Head),
+		}
+	}
+	cParams := (*C.struct_whisper_context_params)(unsafe.Pointer(params))
+	cParams.dtw_aheads = C.struct_whisper_aheads{
+		n_heads: C.size_t(len(heads)),
+		heads:   (*C.whisper_ahead)(cHeadsPtr),
+	}
+	return cHeadsPtr
+}
+
+// applyDTWTimestamps replaces the timestamps of the tokens with the ones
+// aligned by DTW (which are not exposed by the Go bindings of whisper.cpp):
+// a token lasts until the next aligned token, or until the end of the segment.
+// The special tokens are not aligned, so they keep the original timestamps.
+func (e *whisperCPPEngine) applyDTWTimestamps(
+	segmentIdx int,
+	tokens []Token,
+	segmentEnd time.Duration,
+) {
+	cCtx := (*C.struct_whisper_context)(unsafe.Pointer(e.Context))
+	end := segmentEnd
+	for tokenIdx := len(tokens) - 1; tokenIdx >= 0; tokenIdx-- {
+		data := C.whisper_full_get_token_data(cCtx, C.int(segmentIdx), C.int(tokenIdx))
+		if data.t_dtw < 0 {
+			continue
+		}
+		start := time.Duration(data.t_dtw) * 10 * time.Millisecond
+		tokens[tokenIdx].T0 = start
+		tokens[tokenIdx].T1 = max(end, start)
+		tokens[tokenIdx].IsDTWAligned = true
+		end = start
+	}
+}
@@ -2,6 +2,10 @@ package whisper
 
 import (
 	"fmt"
+	"time"
+
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
 )
 
 type ErrInitModel struct {
@@ -34,3 +38,57 @@ type ErrInitVAD struct {
 func (e ErrInitVAD) Error() string {
 	return fmt.Sprintf("unable to initialize VAD: %v", e.Err)
 }
+
+type ErrAllowedLanguagesWithFixedLanguage struct {
+	Language speech.Language
+}
+
+func (e ErrAllowedLanguagesWithFixedLanguage) Error() string {
+	return fmt.Sprintf("the allowed languages are applicable only to the auto-detected language, but the language is set to '%s'", e.Language)
+}
+
+type ErrTranslationIsAlreadyEnabled struct{}
+
+func (ErrTranslationIsAlreadyEnabled) Error() string {
+	return "the translation cannot be emitted in addition to the transcript, because the transcript is already translated"
+}
+
+type ErrVADBackendIsNotSupported struct {
+	Backend types.VADBackend
+}
+
+func (e ErrVADBackendIsNotSupported) Error() string {
+	return fmt.Sprintf("VAD backend '%s' is not supported by this build", e.Backend)
+}
+
+type ErrInvalidVADKeepContext struct {
+	KeepContext        time.Duration
+	MinSendingDuration time.Duration
+}
+
+func (e ErrInvalidVADKeepContext) Error() string {
+	return fmt.Sprintf("the VAD keep-context %v is out of range [0, %v) (the minimal sending duration of the commit policy)", e.KeepContext, e.MinSendingDuration)
+}
+
+type ErrEndpointingRequiresVAD struct{}
+
+func (ErrEndpointingRequiresVAD) Error() string {
+	return "the endpointing requires the VAD to be enabled"
+}
+
+type ErrInvalidEndpointing struct {
+	Endpointing         Endpointing
+	VADMinVoiceDuration time.Duration
+}
+
+func (e ErrInvalidEndpointing) Error() string {
+	return fmt.Sprintf("invalid endpointing %+v: the durations should be positive, and MaxUtteranceLength should not be shorter than the VAD minimal voice duration %v", e.Endpointing, e.VADMinVoiceDuration)
+}
+
+type ErrAlignmentHeadsAreNotSet struct {
+	Preset types.AlignmentAheadsPreset
+}
+
+func (e ErrAlignmentHeadsAreNotSet) Error() string {
+	return fmt.Sprintf("the alignment heads preset '%s' requires the heads to be set (see OptionAlignmentHeads and OptionAlignmentHeadsNTop)", e.Preset)
+}
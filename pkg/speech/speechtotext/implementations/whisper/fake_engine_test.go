@@ -0,0 +1,232 @@
+package whisper
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/audio/pkg/vad"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/hallucination"
+)
+
+// fakeEngine is an InferenceEngine returning scripted segments.
+type fakeEngine struct {
+	// Script returns the segments for the call number `call`
+	// of Full with `samples` of the given duration.
+	Script func(call int, samples []float32, duration time.Duration) []*Segment
+
+	// TranslationScript returns the segments for a call of Full
+	// with translation enabled (see SetTranslate).
+	TranslationScript func(samples []float32, duration time.Duration) []*Segment
+
+	// Languages returns the result of DetectLanguage at the offset.
+	Languages func(offset time.Duration) speech.LanguageProbabilities
+
+	Calls []time.Duration
+
+	// DetectLanguageOffsets are the offsets of the calls of DetectLanguage.
+	DetectLanguageOffsets []time.Duration
+
+	// DetectSamplesLanguageCalls is the amount of calls of DetectSamplesLanguage.
+	DetectSamplesLanguageCalls int
+
+	// CallLanguages are the languages set (see SetLanguage) on each call of Full.
+	CallLanguages []speech.Language
+	Language      speech.Language
+	IsTranslating bool
+
+	// TranslationCalls is the amount of calls of Full with translation
+	// enabled (they are not counted in Calls).
+	TranslationCalls int
+
+	Prompts  []string
+	IsClosed bool
+
+	segments []*Segment
+}
+
+var _ InferenceEngine = (*fakeEngine)(nil)
+
+func (e *fakeEngine) Full(_ context.Context, samples []float32) error {
+	duration := getDurationFromBytes(uint64(len(samples)) * 4)
+	e.segments = nil
+	if e.IsTranslating {
+		if e.TranslationScript != nil {
+			e.segments = e.TranslationScript(samples, duration)
+		}
+		e.TranslationCalls++
+		return nil
+	}
+	if e.Script != nil {
+		e.segments = e.Script(len(e.Calls), samples, duration)
+	}
+	e.Calls = append(e.Calls, duration)
+	e.CallLanguages = append(e.CallLanguages, e.Language)
+	return nil
+}
+
+func (e *fakeEngine) DetectLanguage(
+	_ context.Context,
+	offset time.Duration,
+) (speech.LanguageProbabilities, error) {
+	e.DetectLanguageOffsets = append(e.DetectLanguageOffsets, offset)
+	return e.languages(offset), nil
+}
+
+func (e *fakeEngine) DetectSamplesLanguage(
+	_ context.Context,
+	_ []float32,
+) (speech.LanguageProbabilities, error) {
+	e.DetectSamplesLanguageCalls++
+	return e.languages(0), nil
+}
+
+func (e *fakeEngine) languages(offset time.Duration) speech.LanguageProbabilities {
+	if e.Languages != nil {
+		return e.Languages(offset)
+	}
+	return speech.LanguageProbabilities{{Language: "en", Probability: 1}}
+}
+
+func (e *fakeEngine) NumSegments() int {
+	return len(e.segments)
+}
+
+func (e *fakeEngine) Segment(idx int) *Segment {
+	return e.segments[idx]
+}
+
+func (e *fakeEngine) SetPrompt(prompt string) {
+	e.Prompts = append(e.Prompts, prompt)
+}
+
+func (e *fakeEngine) SetLanguage(language speech.Language) {
+	e.Language = language
+}
+
+func (e *fakeEngine) SetTranslate(shouldTranslate bool) {
+	e.IsTranslating = shouldTranslate
+}
+
+func (e *fakeEngine) Close() error {
+	e.IsClosed = true
+	return nil
+}
+
+// fakeVAD considers a voice any sample with the amplitude above 0.1.
+type fakeVAD struct{}
+
+var _ vad.VAD = fakeVAD{}
+
+func (fakeVAD) Close() error { return nil }
+
+func (fakeVAD) Encoding(context.Context) (audio.Encoding, error) {
+	return (*SpeechToText)(nil).AudioEncodingNoErr(), nil
+}
+
+func (fakeVAD) Channels(context.Context) (audio.Channel, error) {
+	return 1, nil
+}
+
+func (fakeVAD) FindNextVoice(
+	_ context.Context,
+	samples []byte,
+	_ float64,
+	_ time.Duration,
+) (float64, time.Duration, error) {
+	for idx, sample := range convertBytesToFloat32Slice(samples) {
+		if math.Abs(float64(sample)) > 0.1 {
+			return 1, getDurationFromBytes(uint64(idx) * 4), nil
+		}
+	}
+	return 0, 0, nil
+}
+
+// fakeSegment returns a segment with a token per word,
+// evenly spread between t0 and t1.
+func fakeSegment(text string, t0, t1 time.Duration) *Segment {
+	var words []string
+	for _, word := range strings.SplitAfter(text, " ") {
+		if strings.Trim(word, " ") == "" {
+			continue
+		}
+		words = append(words, " "+strings.Trim(word, " "))
+	}
+	s := &Segment{Text: text, T0: t0, T1: t1}
+	for idx, word := range words {
+		s.Tokens = append(s.Tokens, Token{
+			ID:   int32(idx),
+			Text: word,
+			P:    0.9,
+			T0:   t0 + (t1-t0)*time.Duration(idx)/time.Duration(len(words)),
+			T1:   t0 + (t1-t0)*time.Duration(idx+1)/time.Duration(len(words)),
+		})
+	}
+	return s
+}
+
+// newTestSTT returns a SpeechToText without the processing loop,
+// so that commitAudio could be called directly.
+func newTestSTT(t *testing.T, engine InferenceEngine, opts ...Option) *SpeechToText {
+	stt, err := newSpeechToText(context.Background(), engine, hallucination.Model{}, 0, Options(opts).config())
+	require.NoError(t, err)
+	stt.Out = make(chan *speech.Transcript, 1024)
+	return stt
+}
+
+func writeSilence(t *testing.T, stt *SpeechToText, duration time.Duration) {
+	err := stt.WriteAudio(context.Background(), make([]byte, getBytesPos(duration)))
+	require.NoError(t, err)
+}
+
+func writeVoice(t *testing.T, stt *SpeechToText, duration time.Duration) {
+	samples := make([]float32, getBytesPos(duration)/4)
+	for idx := range samples {
+		samples[idx] = 0.5
+	}
+	err := stt.WriteAudio(context.Background(), float32SliceToBytes(samples))
+	require.NoError(t, err)
+}
+
+func float32SliceToBytes(samples []float32) []byte {
+	b := make([]byte, 0, len(samples)*4)
+	for _, sample := range samples {
+		bits := math.Float32bits(sample)
+		b = append(b, byte(bits), byte(bits>>8), byte(bits>>16), byte(bits>>24))
+	}
+	return b
+}
+
+func commit(t *testing.T, stt *SpeechToText) {
+	require.NoError(t, stt.commitAudio(context.Background()))
+}
+
+// readAllTranscripts returns the transcripts sent so far (including retractions).
+func readAllTranscripts(stt *SpeechToText) []*speech.Transcript {
+	var result []*speech.Transcript
+	for {
+		select {
+		case t := <-stt.Out:
+			result = append(result, t)
+		default:
+			return result
+		}
+	}
+}
+
+// readTranscripts returns the transcripts sent so far, except retractions.
+func readTranscripts(stt *SpeechToText) []*speech.Transcript {
+	var result []*speech.Transcript
+	for _, t := range readAllTranscripts(stt) {
+		if t.IsRetracted {
+			continue
+		}
+		result = append(result, t)
+	}
+	return result
+}
@@ -0,0 +1,77 @@
+//go:build cgo
+
+package whisper
+
+// #include <stdlib.h>
+// #include <whisper.h>
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/mutablelogic/go-whisper/sys/whisper"
+)
+
+// cFullParams gives access to the fields of whisper_full_params,
+// which have no setters in the Go bindings.
+func cFullParams(p *whisper.FullParams) *C.struct_whisper_full_params {
+	return (*C.struct_whisper_full_params)(unsafe.Pointer(p))
+}
+
+// applyDecodingParams sets the decoding parameters explicitly
+// requested by the options (the rest keep whisper.cpp defaults).
+func (e *whisperCPPEngine) applyDecodingParams(cfg config) {
+	p := cFullParams(&e.Params)
+	if cfg.BeamSize != nil {
+		p.beam_search.beam_size = C.int(*cfg.BeamSize)
+	}
+	if cfg.BestOf != nil {
+		p.greedy.best_of = C.int(*cfg.BestOf)
+	}
+	if cfg.Temperature != nil {
+		p.temperature = C.float(*cfg.Temperature)
+	}
+	if cfg.TemperatureInc != nil {
+		p.temperature_inc = C.float(*cfg.TemperatureInc)
+	}
+	if cfg.EntropyThreshold != nil {
+		p.entropy_thold = C.float(*cfg.EntropyThreshold)
+	}
+	if cfg.LogprobThreshold != nil {
+		p.logprob_thold = C.float(*cfg.LogprobThreshold)
+	}
+	if cfg.NoSpeechThreshold != nil {
+		p.no_speech_thold = C.float(*cfg.NoSpeechThreshold)
+	}
+	if cfg.SuppressBlank != nil {
+		p.suppress_blank = C.bool(*cfg.SuppressBlank)
+	}
+	if cfg.SuppressNonSpeechTokens != nil {
+		p.suppress_nst = C.bool(*cfg.SuppressNonSpeechTokens)
+	}
+	if cfg.MaxSegmentLength != nil {
+		p.max_len = C.int(*cfg.MaxSegmentLength)
+	}
+	if cfg.SplitOnWord != nil {
+		p.split_on_word = C.bool(*cfg.SplitOnWord)
+	}
+	if cfg.Threads != nil {
+		e.Params.SetNumThreads(*cfg.Threads)
+	}
+	if cfg.NoContext != nil {
+		e.Params.SetNoContext(*cfg.NoContext)
+	}
+}
+
+// setInitialPrompt replaces the prompt of the decoder; an empty prompt unsets it.
+func (e *whisperCPPEngine) setInitialPrompt(prompt string) {
+	p := cFullParams(&e.Params)
+	old := p.initial_prompt
+	p.initial_prompt = nil
+	if prompt != "" {
+		p.initial_prompt = C.CString(prompt)
+	}
+	if old != nil {
+		C.free(unsafe.Pointer(old))
+	}
+}
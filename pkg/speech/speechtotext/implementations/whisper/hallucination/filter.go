@@ -0,0 +1,97 @@
+package hallucination
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/xsync"
+)
+
+// Stats describes what was suppressed by a Filter.
+type Stats struct {
+	Checked    uint64
+	Suppressed uint64
+	ByRule     map[string]uint64
+}
+
+// Filter detects hallucinations of a specific model.
+type Filter struct {
+	locker xsync.Mutex
+	model  Model
+	rules  []Rule
+	stats  Stats
+}
+
+// NewFilter returns a Filter using only the rules that apply
+// to the given model.
+func NewFilter(model Model, rules ...Rule) (*Filter, error) {
+	f := &Filter{
+		model: model,
+		stats: Stats{
+			ByRule: map[string]uint64{},
+		},
+	}
+	for idx, rule := range rules {
+		if !rule.AppliesToModel(model) {
+			continue
+		}
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("invalid rule #%d (%s): %w", idx, rule.String(), err)
+		}
+		f.rules = append(f.rules, rule)
+	}
+	return f, nil
+}
+
+// Check returns the rule that matched the text, or nil if the text
+// is not considered a hallucination.
+func (f *Filter) Check(
+	ctx context.Context,
+	text string,
+	lang speech.Language,
+) *Rule {
+	if f == nil {
+		return nil
+	}
+	text = strings.Trim(text, " ")
+	return xsync.DoR1(xsync.WithNoLogging(ctx, true), &f.locker, func() *Rule {
+		f.stats.Checked++
+		for idx := range f.rules {
+			rule := &f.rules[idx]
+			if !rule.AppliesToLanguage(lang) {
+				continue
+			}
+			match, err := rule.Match(text)
+			if err != nil {
+				logger.Errorf(ctx, "unable to apply rule %s: %v", rule, err)
+				continue
+			}
+			if !match {
+				continue
+			}
+			f.stats.Suppressed++
+			f.stats.ByRule[rule.String()]++
+			logger.Tracef(ctx, "'%s' matched rule %s", text, rule)
+			return rule
+		}
+		return nil
+	})
+}
+
+// Stats returns a copy of the statistics collected so far.
+func (f *Filter) Stats(ctx context.Context) Stats {
+	if f == nil {
+		return Stats{}
+	}
+	return xsync.DoR1(xsync.WithNoLogging(ctx, true), &f.locker, func() Stats {
+		result := f.stats
+		result.ByRule = make(map[string]uint64, len(f.stats.ByRule))
+		for k, v := range f.stats.ByRule {
+			result.ByRule[k] = v
+		}
+		return result
+	})
+}
@@ -0,0 +1,81 @@
+package hallucination
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustHexDecode(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestDefaultRules(t *testing.T) {
+	ctx := context.Background()
+
+	largeV3, err := NewFilter(Model{
+		Hash:   mustHexDecode("ad82bf6a9043ceed055076d0fd39f5f186ff8062"),
+		Family: "large",
+	}, DefaultRules()...)
+	require.NoError(t, err)
+	other, err := NewFilter(Model{Family: "small"}, DefaultRules()...)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		Text    string
+		LargeV3 bool
+		Other   bool
+	}{
+		{Text: " Thanks for watching!", LargeV3: true, Other: true},
+		{Text: "THANK YOU FOR WATCHING.", LargeV3: true, Other: true},
+		{Text: "I'll be right back.", LargeV3: true},
+		{Text: "I'll be right back", LargeV3: false},
+		{Text: "The End.", LargeV3: true},
+		{Text: `"Quoted."`, LargeV3: true},
+		{Text: "End of the story", LargeV3: true},
+		{Text: "So, I'm going to write the value of the value of the value of the value of the value of", LargeV3: true},
+		{Text: "Hello, this is a normal sentence, which is quite long and is not supposed to be filtered."},
+	} {
+		t.Run(tc.Text, func(t *testing.T) {
+			require.Equal(t, tc.LargeV3, largeV3.Check(ctx, tc.Text, "en") != nil)
+			require.Equal(t, tc.Other, other.Check(ctx, tc.Text, "en") != nil)
+		})
+	}
+
+	stats := other.Stats(ctx)
+	require.Equal(t, uint64(9), stats.Checked)
+	require.Equal(t, uint64(2), stats.Suppressed)
+	require.Equal(t, uint64(1), stats.ByRule["normalized:Thanks for watching"])
+}
+
+func TestParseRules(t *testing.T) {
+	ctx := context.Background()
+
+	rules, err := ParseRules([]byte(`{"rules": [
+		{"type": "regex", "pattern": "^Субтитры", "languages": ["ru"]},
+		{"name": "small-bye", "type": "normalized", "pattern": "bye", "model_families": ["small"]}
+	]}`))
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	f, err := NewFilter(Model{Family: "small"}, rules...)
+	require.NoError(t, err)
+	require.NotNil(t, f.Check(ctx, "Субтитры сделал DimaTorzok", "ru-RU"))
+	require.Nil(t, f.Check(ctx, "Субтитры сделал DimaTorzok", "en"))
+	require.Equal(t, "small-bye", f.Check(ctx, "Bye!", "en").String())
+
+	f, err = NewFilter(Model{Family: "medium"}, rules...)
+	require.NoError(t, err)
+	require.Nil(t, f.Check(ctx, "Bye!", "en"))
+
+	_, err = ParseRules([]byte(`{"rules": [{"type": "regex", "pattern": "("}]}`))
+	require.Error(t, err)
+	_, err = ParseRules([]byte(`{"rules": [{"type": "unknown"}]}`))
+	require.Error(t, err)
+}
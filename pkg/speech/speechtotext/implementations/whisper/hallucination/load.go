@@ -0,0 +1,66 @@
+package hallucination
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+//go:embed default_rules.json
+var defaultRulesJSON []byte
+
+// RuleFile is the format of a rule file, for example:
+//
+//	{"rules": [
+//	  {"type": "normalized", "pattern": "Thanks for watching", "languages": ["en"]},
+//	  {"type": "regex", "pattern": "^End of", "model_families": ["large"]},
+//	  {"type": "entropy", "min_length": 80, "skip_prefix": 30, "max_entropy": 3.63}
+//	]}
+type RuleFile struct {
+	Rules []Rule `json:"rules"`
+}
+
+// DefaultRules returns the rules used if no other rules are provided.
+func DefaultRules() []Rule {
+	rules, err := ParseRules(defaultRulesJSON)
+	if err != nil {
+		panic(err)
+	}
+	return rules
+}
+
+func ParseRules(b []byte) ([]Rule, error) {
+	var f RuleFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("unable to parse the rules: %w", err)
+	}
+	for idx := range f.Rules {
+		rule := &f.Rules[idx]
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("invalid rule #%d (%s): %w", idx, rule.String(), err)
+		}
+	}
+	return f.Rules, nil
+}
+
+func ReadRules(r io.Reader) ([]Rule, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the rules: %w", err)
+	}
+	return ParseRules(b)
+}
+
+func LoadRulesFile(path string) ([]Rule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read file '%s': %w", path, err)
+	}
+	rules, err := ParseRules(b)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load file '%s': %w", path, err)
+	}
+	return rules, nil
+}
@@ -0,0 +1,170 @@
+package hallucination
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lazybeaver/entropy"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+// Model identifies the model the text was produced by.
+type Model struct {
+	// Hash is the SHA1 of the model file.
+	Hash []byte
+
+	// Family is the model size reported by whisper.cpp,
+	// e.g.: "tiny", "base", "small", "medium", "large".
+	Family string
+}
+
+// Scope limits where a Rule applies. An empty list means "any".
+type Scope struct {
+	// ModelHashes are hex-encoded SHA1 hashes of model files.
+	ModelHashes []string `json:"model_hashes,omitempty"`
+
+	// ModelFamilies are the model sizes, see Model.Family.
+	ModelFamilies []string `json:"model_families,omitempty"`
+
+	// Languages are language codes; "en" also matches "en-US".
+	Languages []string `json:"languages,omitempty"`
+}
+
+// AppliesToModel returns true if the Scope includes the model.
+func (s Scope) AppliesToModel(model Model) bool {
+	if len(s.ModelHashes) > 0 {
+		found := false
+		for _, h := range s.ModelHashes {
+			b, err := hex.DecodeString(h)
+			if err == nil && bytes.Equal(b, model.Hash) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(s.ModelFamilies) > 0 {
+		found := false
+		for _, family := range s.ModelFamilies {
+			if strings.EqualFold(family, model.Family) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// AppliesToLanguage returns true if the Scope includes the language.
+func (s Scope) AppliesToLanguage(lang speech.Language) bool {
+	if len(s.Languages) == 0 {
+		return true
+	}
+	primary, _, _ := strings.Cut(string(lang), "-")
+	for _, l := range s.Languages {
+		if strings.EqualFold(l, string(lang)) || strings.EqualFold(l, primary) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule describes a single kind of text to be considered a hallucination.
+type Rule struct {
+	// Name is used in statistics; if empty, it is derived from Type and Pattern.
+	Name string `json:"name,omitempty"`
+
+	Type RuleType `json:"type"`
+
+	// Pattern is the text (for RuleTypeExact and RuleTypeNormalized) or
+	// the regular expression (for RuleTypeRegex).
+	Pattern string `json:"pattern,omitempty"`
+
+	// MinLength is the minimal length of the text (in bytes) to apply
+	// a RuleTypeEntropy rule.
+	MinLength int `json:"min_length,omitempty"`
+
+	// SkipPrefix is the amount of heading bytes to exclude from the entropy
+	// calculation (a loop usually starts after a meaningful beginning).
+	SkipPrefix int `json:"skip_prefix,omitempty"`
+
+	// MaxEntropy is the Shannon entropy below which a RuleTypeEntropy
+	// rule matches.
+	MaxEntropy float64 `json:"max_entropy,omitempty"`
+
+	Scope
+
+	regex *regexp.Regexp
+}
+
+func (r *Rule) String() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	switch r.Type {
+	case RuleTypeEntropy:
+		return fmt.Sprintf("%s:<%g", r.Type, r.MaxEntropy)
+	default:
+		return fmt.Sprintf("%s:%s", r.Type, r.Pattern)
+	}
+}
+
+func (r *Rule) compile() error {
+	switch r.Type {
+	case RuleTypeExact, RuleTypeNormalized:
+	case RuleTypeRegex:
+		var err error
+		r.regex, err = regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("unable to compile the regular expression '%s': %w", r.Pattern, err)
+		}
+	case RuleTypeEntropy:
+		if r.MaxEntropy <= 0 {
+			return fmt.Errorf("max_entropy is not set")
+		}
+	default:
+		return fmt.Errorf("unknown rule type: %v", r.Type)
+	}
+	return nil
+}
+
+// Normalize trims the text and drops the punctuation whisper tends to
+// vary in hallucinated phrases.
+func Normalize(text string) string {
+	text = strings.Trim(text, " ")
+	text = strings.ReplaceAll(text, "!", "")
+	text = strings.ReplaceAll(text, ".", "")
+	text = strings.ReplaceAll(text, "-", "")
+	return strings.Trim(text, " ")
+}
+
+// Match returns true if the (trimmed) text matches the rule.
+// The rule is expected to be already compiled.
+func (r *Rule) Match(text string) (bool, error) {
+	switch r.Type {
+	case RuleTypeExact:
+		return text == r.Pattern, nil
+	case RuleTypeNormalized:
+		return strings.EqualFold(Normalize(text), Normalize(r.Pattern)), nil
+	case RuleTypeRegex:
+		return r.regex.MatchString(text), nil
+	case RuleTypeEntropy:
+		if len(text) <= r.MinLength || len(text) <= r.SkipPrefix {
+			return false, nil
+		}
+		e, err := entropy.Shannon(text[r.SkipPrefix:])
+		if err != nil {
+			return false, fmt.Errorf("unable to calculate shannon entropy: %w", err)
+		}
+		return e < r.MaxEntropy, nil
+	}
+	return false, fmt.Errorf("unknown rule type: %v", r.Type)
+}
@@ -0,0 +1,81 @@
+package hallucination
+
+import (
+	"fmt"
+	"strings"
+)
+
+type RuleType uint
+
+const (
+	// RuleTypeExact matches if the text (with surrounding spaces trimmed)
+	// is equal to the pattern.
+	RuleTypeExact = RuleType(iota)
+
+	// RuleTypeNormalized matches if the normalized text is equal to
+	// the normalized pattern, case-insensitively (see Normalize).
+	RuleTypeNormalized
+
+	// RuleTypeRegex matches if the text matches the regular expression.
+	RuleTypeRegex
+
+	// RuleTypeEntropy matches if the text is long enough and its Shannon
+	// entropy is too low (which is typical for looping output, e.g.:
+	// "the value of the value of the value of ...").
+	RuleTypeEntropy
+)
+
+// String just implements fmt.Stringer, flag.Value and pflag.Value.
+func (t RuleType) String() string {
+	switch t {
+	case RuleTypeExact:
+		return "exact"
+	case RuleTypeNormalized:
+		return "normalized"
+	case RuleTypeRegex:
+		return "regex"
+	case RuleTypeEntropy:
+		return "entropy"
+	}
+	return fmt.Sprintf("unknown_%d", uint(t))
+}
+
+// Set updates the value based on the passed string value.
+// This method just implements flag.Value and pflag.Value.
+func (t *RuleType) Set(value string) error {
+	newValue, err := ParseRuleType(value)
+	if err != nil {
+		return err
+	}
+	*t = newValue
+	return nil
+}
+
+// Type just implements pflag.Value.
+func (t *RuleType) Type() string {
+	return "RuleType"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t RuleType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *RuleType) UnmarshalText(b []byte) error {
+	return t.Set(string(b))
+}
+
+func ParseRuleType(in string) (RuleType, error) {
+	switch strings.ToLower(in) {
+	case "exact":
+		return RuleTypeExact, nil
+	case "normalized":
+		return RuleTypeNormalized, nil
+	case "regex":
+		return RuleTypeRegex, nil
+	case "entropy":
+		return RuleTypeEntropy, nil
+	}
+	return RuleTypeExact, fmt.Errorf("unknown rule type '%s'", in)
+}
@@ -1,6 +1,8 @@
 package whisper
 
 import (
+	"strings"
+
 	"github.com/xaionaro-go/speech/pkg/speech"
 )
 
@@ -10,3 +12,18 @@ func LanguageToWhisper(language speech.Language) string {
 	}
 	return string(language.Family())
 }
+
+// LanguageFromWhisper converts a whisper language code (like "en")
+// to speech.Language: if the preferred language (e.g. the one
+// the recognizer is configured with) is of the same family, then it
+// is returned (like "en-US"); otherwise the code itself is returned
+// (it is a valid BCP-47 language tag without a region).
+func LanguageFromWhisper(code string, preferred speech.Language) speech.Language {
+	if code == "" {
+		return ""
+	}
+	if preferred != "" && strings.EqualFold(string(preferred.Family()), code) {
+		return preferred
+	}
+	return speech.Language(strings.ToLower(code))
+}
@@ -0,0 +1,96 @@
+package whisper
+
+import (
+	"strings"
+)
+
+// LocalAgreement stabilizes the partial (non-final) results: the uncommitted
+// audio is re-decoded on every iteration, and the prefix of the hypothesis
+// on which consecutive iterations agree is considered stable (see
+// "Turning Whisper into Real-Time Transcription System", Macháček et al., 2023).
+type LocalAgreement struct {
+	// Previous is the uncommitted hypothesis of the previous iteration.
+	Previous []AgreedToken
+
+	// Current is the uncommitted hypothesis of the current iteration.
+	Current []AgreedToken
+
+	// StartPosBytes is where the audio of Current starts.
+	StartPosBytes uint64
+
+	pos        int
+	isMatching bool
+	stability  float32
+}
+
+// AgreedToken is a token of a hypothesis.
+type AgreedToken struct {
+	Text string
+
+	// Agreements is the amount of consecutive iterations, which agreed
+	// on this token (and all the tokens before it).
+	Agreements uint
+}
+
+// Begin starts a new iteration; startPosBytes is where the decoded audio starts.
+func (la *LocalAgreement) Begin(startPosBytes uint64) {
+	la.Previous = la.Current
+	if startPosBytes != la.StartPosBytes {
+		// the audio does not continue the previous hypothesis
+		la.Previous = nil
+	}
+	la.Current = nil
+	la.StartPosBytes = startPosBytes
+	la.pos = 0
+	la.isMatching = true
+	la.stability = 0
+}
+
+// Commit tells that the audio before posBytes will not be decoded again,
+// thus the next iteration starts from posBytes.
+func (la *LocalAgreement) Commit(posBytes uint64) {
+	la.StartPosBytes = posBytes
+}
+
+// Reset forgets the hypothesis (e.g. because the audio was discarded).
+func (la *LocalAgreement) Reset() {
+	la.Previous = nil
+	la.Current = nil
+}
+
+// Next processes the next token of the current iteration,
+// and returns its stability: 1 for final tokens; for the others
+// it rises from 0 with each consecutive agreement on it.
+func (la *LocalAgreement) Next(text string, isFinal bool) float32 {
+	if isSpecialToken(text) {
+		if isFinal {
+			return 1
+		}
+		return la.stability
+	}
+
+	var agreements uint
+	if la.isMatching && la.pos < len(la.Previous) && la.Previous[la.pos].Text == text {
+		agreements = la.Previous[la.pos].Agreements + 1
+	} else {
+		la.isMatching = false
+	}
+	la.pos++
+
+	if isFinal {
+		la.stability = 1
+		return la.stability
+	}
+	la.Current = append(la.Current, AgreedToken{
+		Text:       text,
+		Agreements: agreements,
+	})
+	la.stability = 1 - 1/float32(agreements+1)
+	return la.stability
+}
+
+// isSpecialToken returns true for tokens like "[_BEG_]" or "[_TT_150]",
+// which are not a part of the text.
+func isSpecialToken(text string) bool {
+	return strings.HasPrefix(text, "[_") && strings.HasSuffix(text, "]")
+}
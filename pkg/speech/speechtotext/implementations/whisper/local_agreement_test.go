@@ -0,0 +1,77 @@
+package whisper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+func tokenStabilities(transcript *speech.Transcript) []float32 {
+	var result []float32
+	for _, token := range transcript.Variants[0].TranscriptTokens {
+		result = append(result, token.Stability)
+	}
+	return result
+}
+
+func TestLocalAgreement(t *testing.T) {
+	var la LocalAgreement
+
+	la.Begin(0)
+	require.Equal(t, float32(0), la.Next(" hello", false))
+	require.Equal(t, float32(0), la.Next(" world", false))
+
+	la.Begin(0)
+	require.Equal(t, float32(0.5), la.Next(" hello", false))
+	require.Equal(t, float32(0.5), la.Next("[_TT_100]", false))
+	require.Equal(t, float32(0), la.Next(" word", false))
+	require.Equal(t, float32(0), la.Next(" again", false))
+
+	// " hello" is committed, so the next hypothesis starts after it
+	la.Begin(0)
+	require.Equal(t, float32(1), la.Next(" hello", true))
+	require.Equal(t, float32(0), la.Next(" world", false))
+	la.Commit(100)
+
+	la.Begin(100)
+	require.Equal(t, float32(0.5), la.Next(" world", false))
+
+	// the audio was discarded
+	la.Begin(200)
+	require.Equal(t, float32(0), la.Next(" world", false))
+}
+
+func TestCommitAudioStability(t *testing.T) {
+	hypotheses := []string{
+		" hello world",
+		" hello world again",
+		" hello there",
+	}
+	engine := &fakeEngine{Script: func(call int, _ []float32, duration time.Duration) []*Segment {
+		return []*Segment{fakeSegment(hypotheses[call], 0, duration-100*time.Millisecond)}
+	}}
+	stt := newTestSTT(t, engine)
+	skipWarmup(stt)
+
+	writeSilence(t, stt, time.Second)
+	var transcripts []*speech.Transcript
+	for range hypotheses {
+		writeSilence(t, stt, time.Second)
+		commit(t, stt)
+		transcripts = append(transcripts, readTranscripts(stt)...)
+	}
+	require.Len(t, transcripts, 3)
+	for _, transcript := range transcripts {
+		require.False(t, transcript.IsFinal)
+	}
+	require.Equal(t, []float32{0, 0}, tokenStabilities(transcripts[0]))
+	require.Equal(t, []float32{0.5, 0.5, 0}, tokenStabilities(transcripts[1]))
+	require.Equal(t, []float32{1 - 1/float32(3), 0}, tokenStabilities(transcripts[2]))
+
+	require.Zero(t, transcripts[0].Stability)
+	require.Equal(t, float32(1)/3, transcripts[1].Stability)
+	require.Equal(t, " hello world", string(transcripts[1].Variants[0].Text[:transcripts[1].Variants[0].StableTextLength(0.5)]))
+	require.Equal(t, " hello", string(transcripts[2].Variants[0].Text[:transcripts[2].Variants[0].StableTextLength(0.5)]))
+}
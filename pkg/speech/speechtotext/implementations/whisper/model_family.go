@@ -0,0 +1,20 @@
+//go:build cgo
+
+package whisper
+
+// #include <whisper.h>
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/mutablelogic/go-whisper/sys/whisper"
+)
+
+// modelFamily returns the model size, e.g.: "tiny", "base", "small", "medium", "large".
+func modelFamily(ctx *whisper.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	return C.GoString(C.whisper_model_type_readable((*C.struct_whisper_context)(unsafe.Pointer(ctx))))
+}
@@ -0,0 +1,82 @@
+//go:build cgo
+
+package whisper
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/hallucination"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
+)
+
+func New(
+	ctx context.Context,
+	modelBytes []byte,
+	language speech.Language,
+	samplingStrategy types.SamplingStrategy,
+	shouldTranslate bool,
+	alignmentAheadPreset types.AlignmentAheadsPreset,
+	vadThreshold float64,
+	opts ...Option,
+) (*SpeechToText, error) {
+	cfg := Options(opts).config()
+	isOwnershipTransferred := false
+	defer func() {
+		if !isOwnershipTransferred {
+			closeOwnedComponents(cfg)
+		}
+	}()
+
+	if len(modelBytes) == 0 {
+		return nil, fmt.Errorf("the model is empty")
+	}
+	if language != "" && len(cfg.AllowedLanguages) > 0 {
+		return nil, ErrAllowedLanguagesWithFixedLanguage{Language: language}
+	}
+	if shouldTranslate && cfg.EmitTranslation {
+		return nil, ErrTranslationIsAlreadyEnabled{}
+	}
+
+	h := sha1.Sum(modelBytes)
+	logger.Debugf(ctx, "model SHA1: %X", h)
+
+	engine, err := newWhisperCPPEngine(
+		ctx,
+		modelBytes,
+		language,
+		samplingStrategy,
+		shouldTranslate,
+		alignmentAheadPreset,
+		cfg,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// since here the components are closed by NewWithEngine on failure
+	isOwnershipTransferred = true
+	stt, err := NewWithEngine(ctx, engine, hallucination.Model{
+		Hash:   h[:],
+		Family: modelFamily(engine.Context),
+	}, vadThreshold, opts...)
+	if err != nil {
+		engine.Close()
+		return nil, err
+	}
+	return stt, nil
+}
+
+// closeOwnedComponents closes the components passed by the options,
+// which are owned by the SpeechToText (see OptionDiarizer and OptionVAD).
+func closeOwnedComponents(cfg config) {
+	if cfg.Diarizer != nil {
+		cfg.Diarizer.Close()
+	}
+	if cfg.VAD != nil {
+		cfg.VAD.Close()
+	}
+}
@@ -1,13 +1,69 @@
 package whisper
 
+import (
+	"time"
+
+	"github.com/xaionaro-go/audio/pkg/vad"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/speech/pkg/speech/diarization"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/hallucination"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
+)
+
 type config struct {
-	UseGPU      *bool
-	GPUDeviceID *int
-	FlashAttn   *bool
+	UseGPU       *bool
+	GPUDeviceID  *int
+	FlashAttn    *bool
+	Diarizer     diarization.Diarizer
+	SpeakerNames map[diarization.SpeakerID]string
+
+	HallucinationRules []hallucination.Rule
+	EmitAnnotations    bool
+
+	BeamSize                *int
+	BestOf                  *int
+	Temperature             *float32
+	TemperatureInc          *float32
+	EntropyThreshold        *float32
+	LogprobThreshold        *float32
+	NoSpeechThreshold       *float32
+	InitialPrompt           *string
+	SuppressBlank           *bool
+	SuppressNonSpeechTokens *bool
+	MaxSegmentLength        *int
+	SplitOnWord             *bool
+	Threads                 *int
+	NoContext               *bool
+
+	BoostPhrases       []string
+	CarryForwardPrompt bool
+
+	CommitPolicy CommitPolicy
+
+	AllowedLanguages []speech.Language
+
+	EmitTranslation bool
+
+	VAD                 vad.VAD
+	VADBackend          types.VADBackend
+	VADLibFVADMode      int
+	VADMinVoiceDuration *time.Duration
+	VADKeepContext      *time.Duration
+
+	Endpointing *Endpointing
+
+	AlignmentHeads     types.AlignmentHeads
+	AlignmentHeadsNTop int
+	WordTimestamps     bool
 }
 
 func defaultConfig() config {
-	return config{}
+	return config{
+		HallucinationRules: hallucination.DefaultRules(),
+		CommitPolicy:       CommitPolicyBalanced,
+		VADBackend:         types.VADBackendAuto,
+		VADLibFVADMode:     DefaultVADLibFVADMode,
+	}
 }
 
 type Option interface {
@@ -45,3 +101,283 @@ type OptionFlashAttn bool
 func (opt OptionFlashAttn) apply(cfg *config) {
 	cfg.FlashAttn = (*bool)(&opt)
 }
+
+// OptionDiarizer enables speaker diarization using the given Diarizer
+// instead of whisper's speaker-turn detection (which can distinguish
+// only two speakers: ">" and "<"). The Diarizer is closed together with
+// the SpeechToText, so it should not be shared.
+type OptionDiarizer struct {
+	diarization.Diarizer
+}
+
+func (opt OptionDiarizer) apply(cfg *config) {
+	cfg.Diarizer = opt.Diarizer
+}
+
+// OptionSpeakerNames sets the initial human-readable names of the speakers
+// identified by the Diarizer (see also SpeechToText.SetSpeakerName).
+type OptionSpeakerNames map[diarization.SpeakerID]string
+
+func (opt OptionSpeakerNames) apply(cfg *config) {
+	cfg.SpeakerNames = opt
+}
+
+// OptionHallucinationRules replaces the rules used to detect hallucinated
+// segments (by default hallucination.DefaultRules() are used);
+// nil disables the filtering.
+type OptionHallucinationRules []hallucination.Rule
+
+func (opt OptionHallucinationRules) apply(cfg *config) {
+	cfg.HallucinationRules = opt
+}
+
+// OptionExtraHallucinationRules adds rules to detect hallucinated segments
+// (in addition to the default ones, or the ones set by OptionHallucinationRules).
+type OptionExtraHallucinationRules []hallucination.Rule
+
+func (opt OptionExtraHallucinationRules) apply(cfg *config) {
+	cfg.HallucinationRules = append(cfg.HallucinationRules[:len(cfg.HallucinationRules):len(cfg.HallucinationRules)], opt...)
+}
+
+// OptionEmitAnnotations makes sound annotations (e.g. "[music]",
+// "(door opens)") and suspected hallucinations to be sent as transcripts
+// of the respective speech.TranscriptKind, instead of being dropped.
+type OptionEmitAnnotations bool
+
+func (opt OptionEmitAnnotations) apply(cfg *config) {
+	cfg.EmitAnnotations = bool(opt)
+}
+
+// OptionBeamSize is the amount of beams (used only by the beam search
+// sampling strategy).
+type OptionBeamSize int
+
+func (opt OptionBeamSize) apply(cfg *config) {
+	cfg.BeamSize = (*int)(&opt)
+}
+
+// OptionBestOf is the amount of candidates to choose from (used only
+// by the greedy sampling strategy).
+type OptionBestOf int
+
+func (opt OptionBestOf) apply(cfg *config) {
+	cfg.BestOf = (*int)(&opt)
+}
+
+// OptionTemperature is the initial sampling temperature.
+type OptionTemperature float32
+
+func (opt OptionTemperature) apply(cfg *config) {
+	cfg.Temperature = (*float32)(&opt)
+}
+
+// OptionTemperatureInc is the increment of the temperature to use
+// on a decoding failure (see the thresholds below); 0 disables the fallback.
+type OptionTemperatureInc float32
+
+func (opt OptionTemperatureInc) apply(cfg *config) {
+	cfg.TemperatureInc = (*float32)(&opt)
+}
+
+// OptionEntropyThreshold is the entropy of tokens above which
+// the decoding is considered failed.
+type OptionEntropyThreshold float32
+
+func (opt OptionEntropyThreshold) apply(cfg *config) {
+	cfg.EntropyThreshold = (*float32)(&opt)
+}
+
+// OptionLogprobThreshold is the average log probability of tokens below
+// which the decoding is considered failed.
+type OptionLogprobThreshold float32
+
+func (opt OptionLogprobThreshold) apply(cfg *config) {
+	cfg.LogprobThreshold = (*float32)(&opt)
+}
+
+// OptionNoSpeechThreshold is the probability of "no speech" above which
+// a segment is considered silent.
+type OptionNoSpeechThreshold float32
+
+func (opt OptionNoSpeechThreshold) apply(cfg *config) {
+	cfg.NoSpeechThreshold = (*float32)(&opt)
+}
+
+// OptionInitialPrompt is the text to prime the decoder with
+// (e.g. to hint names, terminology or the punctuation style).
+type OptionInitialPrompt string
+
+func (opt OptionInitialPrompt) apply(cfg *config) {
+	cfg.InitialPrompt = (*string)(&opt)
+}
+
+type OptionSuppressBlank bool
+
+func (opt OptionSuppressBlank) apply(cfg *config) {
+	cfg.SuppressBlank = (*bool)(&opt)
+}
+
+// OptionSuppressNonSpeechTokens suppresses tokens like "[music]" or "♪".
+type OptionSuppressNonSpeechTokens bool
+
+func (opt OptionSuppressNonSpeechTokens) apply(cfg *config) {
+	cfg.SuppressNonSpeechTokens = (*bool)(&opt)
+}
+
+// OptionMaxSegmentLength is the maximal length of a segment
+// in characters; 0 means no limit.
+type OptionMaxSegmentLength int
+
+func (opt OptionMaxSegmentLength) apply(cfg *config) {
+	cfg.MaxSegmentLength = (*int)(&opt)
+}
+
+// OptionSplitOnWord makes OptionMaxSegmentLength to split on words
+// instead of tokens.
+type OptionSplitOnWord bool
+
+func (opt OptionSplitOnWord) apply(cfg *config) {
+	cfg.SplitOnWord = (*bool)(&opt)
+}
+
+type OptionThreads int
+
+func (opt OptionThreads) apply(cfg *config) {
+	cfg.Threads = (*int)(&opt)
+}
+
+// OptionNoContext disables using the previously decoded text
+// as the prompt for the next window.
+type OptionNoContext bool
+
+func (opt OptionNoContext) apply(cfg *config) {
+	cfg.NoContext = (*bool)(&opt)
+}
+
+// OptionBoostPhrases are phrases (product names, jargon, etc) to be added
+// to the prompt, so that whisper is more likely to recognize them.
+type OptionBoostPhrases []string
+
+func (opt OptionBoostPhrases) apply(cfg *config) {
+	cfg.BoostPhrases = opt
+}
+
+// OptionCarryForwardPrompt makes the tail of the committed text to be
+// used as the prompt (after the boost phrases and the initial prompt)
+// for the next iterations, so that the context persists across commits.
+type OptionCarryForwardPrompt bool
+
+func (opt OptionCarryForwardPrompt) apply(cfg *config) {
+	cfg.CarryForwardPrompt = bool(opt)
+}
+
+// OptionCommitPolicy defines when the audio is sent to whisper and when
+// the segments are considered final; see CommitPolicyLowLatency,
+// CommitPolicyBalanced (the default) and CommitPolicyAccuracy.
+type OptionCommitPolicy CommitPolicy
+
+func (opt OptionCommitPolicy) apply(cfg *config) {
+	cfg.CommitPolicy = CommitPolicy(opt)
+}
+
+// OptionAllowedLanguages restricts the language auto-detection to the given
+// languages: if another language is detected, the audio is decoded again
+// with the most probable allowed language. It is applicable only if
+// the language is not set (is detected automatically).
+type OptionAllowedLanguages []speech.Language
+
+func (opt OptionAllowedLanguages) apply(cfg *config) {
+	cfg.AllowedLanguages = opt
+}
+
+// OptionEmitTranslation makes every transcript to be followed by its
+// translation to English (see speech.Transcript.IsTranslation); it
+// doubles the inference time, since the audio is decoded twice.
+type OptionEmitTranslation bool
+
+func (opt OptionEmitTranslation) apply(cfg *config) {
+	cfg.EmitTranslation = bool(opt)
+}
+
+// OptionVAD makes the given VAD to be used instead of the one selected by
+// OptionVADBackend. The VAD is closed together with the SpeechToText,
+// so it should not be shared.
+type OptionVAD struct {
+	vad.VAD
+}
+
+func (opt OptionVAD) apply(cfg *config) {
+	cfg.VAD = opt.VAD
+}
+
+// OptionVADBackend selects the implementation of the VAD
+// (types.VADBackendAuto by default).
+type OptionVADBackend types.VADBackend
+
+func (opt OptionVADBackend) apply(cfg *config) {
+	cfg.VADBackend = types.VADBackend(opt)
+}
+
+// OptionVADLibFVADMode is the aggressiveness mode of libfvad: from 0
+// (the least aggressive about filtering out non-speech) to 3 (the default).
+type OptionVADLibFVADMode int
+
+func (opt OptionVADLibFVADMode) apply(cfg *config) {
+	cfg.VADLibFVADMode = int(opt)
+}
+
+// OptionVADMinVoiceDuration is the minimal duration of the voice detected
+// by the VAD to consider the audio to contain speech.
+type OptionVADMinVoiceDuration time.Duration
+
+func (opt OptionVADMinVoiceDuration) apply(cfg *config) {
+	cfg.VADMinVoiceDuration = (*time.Duration)(&opt)
+}
+
+// OptionVADKeepContext is how much of the audio before the detected voice
+// is kept (not discarded as silence); it should be shorter than
+// CommitPolicy.MinSendingDuration (see ErrInvalidVADKeepContext).
+type OptionVADKeepContext time.Duration
+
+func (opt OptionVADKeepContext) apply(cfg *config) {
+	cfg.VADKeepContext = (*time.Duration)(&opt)
+}
+
+// OptionEndpointing makes the audio to be cut into utterances at the pauses
+// detected by the VAD, and each utterance to be transcribed once and sent
+// as final (instead of re-decoding the buffer according to the CommitPolicy).
+// It gives lower CPU usage and more deterministic results for dictation-like
+// usage; it requires the VAD to be enabled. See also DefaultEndpointing.
+type OptionEndpointing Endpointing
+
+func (opt OptionEndpointing) apply(cfg *config) {
+	cfg.Endpointing = (*Endpointing)(&opt)
+}
+
+// OptionAlignmentHeads sets the attention heads used to align the tokens
+// with DTW; it is required for the "custom" types.AlignmentAheadsPreset.
+type OptionAlignmentHeads types.AlignmentHeads
+
+func (opt OptionAlignmentHeads) apply(cfg *config) {
+	cfg.AlignmentHeads = types.AlignmentHeads(opt)
+}
+
+// OptionAlignmentHeadsNTop is the amount of the top text layers, all heads
+// of which are used to align the tokens with DTW; it is required for
+// the "n_top_most" types.AlignmentAheadsPreset.
+type OptionAlignmentHeadsNTop int
+
+func (opt OptionAlignmentHeadsNTop) apply(cfg *config) {
+	cfg.AlignmentHeadsNTop = int(opt)
+}
+
+// OptionWordTimestamps makes the sub-word tokens to be merged into words
+// (see speech.TranscriptTokens.Words), so that speech.TranscriptTokens are
+// words with their own timestamps and confidences. The timestamps are
+// accurate only if the tokens are aligned by DTW
+// (see types.AlignmentAheadsPreset).
+type OptionWordTimestamps bool
+
+func (opt OptionWordTimestamps) apply(cfg *config) {
+	cfg.WordTimestamps = bool(opt)
+}
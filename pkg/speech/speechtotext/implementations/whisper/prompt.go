@@ -0,0 +1,48 @@
+package whisper
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// updatePrompt sets the decoder prompt to the boost phrases, the initial
+// prompt and the carried forward committed text (in this order, so that
+// the most recent text is the closest to the audio being decoded).
+func (stt *SpeechToText) updatePrompt() {
+	var parts []string
+	if len(stt.BoostPhrases) > 0 {
+		parts = append(parts, strings.Join(stt.BoostPhrases, ", ")+".")
+	}
+	if stt.InitialPrompt != "" {
+		parts = append(parts, stt.InitialPrompt)
+	}
+	if stt.CarriedPrompt != "" {
+		parts = append(parts, stt.CarriedPrompt)
+	}
+	stt.Engine.SetPrompt(strings.Join(parts, " "))
+}
+
+// carryForwardNoLock appends the committed text to the prompt.
+func (stt *SpeechToText) carryForwardNoLock(text string) {
+	if !stt.CarryForwardPrompt {
+		return
+	}
+	stt.CarriedPrompt = promptTail(strings.Trim(stt.CarriedPrompt+" "+strings.Trim(text, " "), " "), CarryForwardPromptMaxLength)
+	stt.updatePrompt()
+}
+
+// promptTail returns at most maxLength trailing bytes of the text,
+// trying to not cut a word.
+func promptTail(text string, maxLength int) string {
+	if len(text) <= maxLength {
+		return text
+	}
+	text = text[len(text)-maxLength:]
+	if idx := strings.IndexByte(text, ' '); idx >= 0 {
+		return text[idx+1:]
+	}
+	for len(text) > 0 && !utf8.RuneStart(text[0]) {
+		text = text[1:]
+	}
+	return text
+}
@@ -0,0 +1,36 @@
+package whisper
+
+import (
+	"strings"
+
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+// segmentKind tells if the segment is speech or a sound annotation;
+// ok is false if the segment says nothing about the audio at all
+// (e.g.: "[BLANK_AUDIO]").
+func segmentKind(text string) (_ speech.TranscriptKind, ok bool) {
+	trimmedText := strings.ToLower(strings.Trim(text, " "))
+	switch {
+	case trimmedText == "":
+		return speech.TranscriptKindSpeech, false
+	case strings.HasPrefix(trimmedText, "♪") && strings.HasSuffix(trimmedText, "♪"):
+		// e.g.: ♪ ♪
+		return speech.TranscriptKindMusic, true
+	case strings.HasPrefix(trimmedText, "[") && strings.HasSuffix(trimmedText, "]"),
+		strings.HasPrefix(trimmedText, "(") && strings.HasSuffix(trimmedText, ")"),
+		strings.HasPrefix(trimmedText, "*") && strings.HasSuffix(trimmedText, "*"):
+		// e.g.: [silence], [typing], [click], [music], [blank_audio], [ pause ],
+		// (clicking), (faint clicking), (door opens), *thump*
+		annotation := strings.Trim(trimmedText, "[]()* ")
+		switch annotation {
+		case "", "silence", "blank_audio", "pause", "no speech":
+			return speech.TranscriptKindSoundEvent, false
+		}
+		if strings.Contains(annotation, "music") {
+			return speech.TranscriptKindMusic, true
+		}
+		return speech.TranscriptKindSoundEvent, true
+	}
+	return speech.TranscriptKindSpeech, true
+}
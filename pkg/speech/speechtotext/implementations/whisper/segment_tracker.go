@@ -0,0 +1,90 @@
+package whisper
+
+import (
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+// SegmentTracker assigns speech.SegmentID-s to the segments: the uncommitted
+// audio is re-decoded on every iteration, and the n-th segment of an iteration
+// is considered an update of the n-th uncommitted segment of the previous one.
+type SegmentTracker struct {
+	LastID speech.SegmentID
+
+	// Previous are the uncommitted segments of the previous iteration.
+	Previous []TrackedSegment
+
+	// Current are the uncommitted segments of the current iteration.
+	Current []TrackedSegment
+
+	// StartPosBytes is where the audio of Current starts.
+	StartPosBytes uint64
+
+	pos int
+}
+
+// TrackedSegment is the identity of a segment sent in a speech.Transcript.
+type TrackedSegment struct {
+	ID       speech.SegmentID
+	Revision uint64
+}
+
+// Begin starts a new iteration; startPosBytes is where the decoded audio starts.
+// It returns the segments to be retracted.
+func (st *SegmentTracker) Begin(startPosBytes uint64) []TrackedSegment {
+	var retracted []TrackedSegment
+	if startPosBytes == st.StartPosBytes {
+		st.Previous = st.Current
+	} else {
+		// the audio does not continue the previous iteration
+		retracted = st.Current
+		st.Previous = nil
+	}
+	st.Current = nil
+	st.StartPosBytes = startPosBytes
+	st.pos = 0
+	return retracted
+}
+
+// Next returns the identity of the next segment sent in the current iteration.
+func (st *SegmentTracker) Next(isFinal bool) TrackedSegment {
+	var seg TrackedSegment
+	if st.pos < len(st.Previous) {
+		seg = st.Previous[st.pos]
+		seg.Revision++
+	} else {
+		st.LastID++
+		seg = TrackedSegment{ID: st.LastID}
+	}
+	st.pos++
+	if !isFinal {
+		st.Current = append(st.Current, seg)
+	}
+	return seg
+}
+
+// End finishes the current iteration, it returns the segments
+// of the previous iteration which have no update in the current one.
+func (st *SegmentTracker) End() []TrackedSegment {
+	var retracted []TrackedSegment
+	if st.pos < len(st.Previous) {
+		retracted = st.Previous[st.pos:]
+	}
+	st.Previous = nil
+	st.pos = 0
+	return retracted
+}
+
+// Commit tells that the audio before posBytes will not be decoded again,
+// thus the next iteration starts from posBytes.
+func (st *SegmentTracker) Commit(posBytes uint64) {
+	st.StartPosBytes = posBytes
+}
+
+// Reset forgets the uncommitted segments (e.g. because the audio was
+// discarded); it returns the segments to be retracted.
+func (st *SegmentTracker) Reset() []TrackedSegment {
+	retracted := st.End()
+	retracted = append(retracted, st.Current...)
+	st.Current = nil
+	return retracted
+}
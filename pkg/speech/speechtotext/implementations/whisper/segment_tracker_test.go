@@ -0,0 +1,120 @@
+package whisper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+type transcriptSummary struct {
+	Text        string
+	SegmentID   speech.SegmentID
+	Revision    uint64
+	IsFinal     bool
+	IsRetracted bool
+}
+
+func summarize(transcripts []*speech.Transcript) []transcriptSummary {
+	var result []transcriptSummary
+	for _, t := range transcripts {
+		s := transcriptSummary{
+			SegmentID:   t.SegmentID,
+			Revision:    t.Revision,
+			IsFinal:     t.IsFinal,
+			IsRetracted: t.IsRetracted,
+		}
+		if len(t.Variants) > 0 {
+			s.Text = string(t.Variants[0].Text)
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func TestSegmentTracker(t *testing.T) {
+	var st SegmentTracker
+
+	require.Empty(t, st.Begin(0))
+	require.Equal(t, TrackedSegment{ID: 1}, st.Next(false))
+	require.Equal(t, TrackedSegment{ID: 2}, st.Next(false))
+	require.Empty(t, st.End())
+
+	// re-segmented: the two segments are merged into one
+	require.Empty(t, st.Begin(0))
+	require.Equal(t, TrackedSegment{ID: 1, Revision: 1}, st.Next(false))
+	require.Equal(t, []TrackedSegment{{ID: 2}}, st.End())
+
+	// the segment is finalized and committed
+	require.Empty(t, st.Begin(0))
+	require.Equal(t, TrackedSegment{ID: 1, Revision: 2}, st.Next(true))
+	require.Equal(t, TrackedSegment{ID: 3}, st.Next(false))
+	require.Empty(t, st.End())
+	st.Commit(100)
+
+	require.Empty(t, st.Begin(100))
+	require.Equal(t, TrackedSegment{ID: 3, Revision: 1}, st.Next(false))
+	require.Empty(t, st.End())
+
+	// the audio does not continue the previous iteration
+	require.Equal(t, []TrackedSegment{{ID: 3, Revision: 1}}, st.Begin(200))
+	require.Equal(t, TrackedSegment{ID: 4}, st.Next(false))
+	require.Empty(t, st.End())
+
+	// the audio was discarded
+	require.Equal(t, []TrackedSegment{{ID: 4}}, st.Reset())
+	require.Empty(t, st.Begin(200))
+	require.Equal(t, TrackedSegment{ID: 5}, st.Next(false))
+}
+
+func TestCommitAudioSegmentIDs(t *testing.T) {
+	script := [][]*Segment{
+		{
+			fakeSegment(" one", 0, time.Second),
+			fakeSegment(" two", time.Second, 2900*time.Millisecond),
+		},
+		{
+			fakeSegment(" two three", 0, 1500*time.Millisecond),
+			fakeSegment(" four", 1500*time.Millisecond, 2300*time.Millisecond),
+			fakeSegment(" five", 2300*time.Millisecond, 2900*time.Millisecond),
+		},
+		{
+			fakeSegment(" five six", 0, 2100*time.Millisecond),
+		},
+		nil,
+	}
+	engine := &fakeEngine{Script: func(call int, _ []float32, _ time.Duration) []*Segment {
+		return script[call]
+	}}
+	stt := newTestSTT(t, engine)
+	skipWarmup(stt)
+
+	writeSilence(t, stt, 3*time.Second)
+	commit(t, stt)
+	require.Equal(t, []transcriptSummary{
+		{Text: " one", SegmentID: 1, IsFinal: true},
+		{Text: " two", SegmentID: 2},
+	}, summarize(readAllTranscripts(stt)))
+
+	writeSilence(t, stt, time.Second)
+	commit(t, stt)
+	require.Equal(t, []transcriptSummary{
+		{Text: " two three", SegmentID: 2, Revision: 1, IsFinal: true},
+		{Text: " four", SegmentID: 3, IsFinal: true},
+		{Text: " five", SegmentID: 4},
+	}, summarize(readAllTranscripts(stt)))
+
+	writeSilence(t, stt, 1500*time.Millisecond)
+	commit(t, stt)
+	require.Equal(t, []transcriptSummary{
+		{Text: " five six", SegmentID: 4, Revision: 1},
+	}, summarize(readAllTranscripts(stt)))
+
+	// nothing is recognized, so the audio is discarded
+	writeSilence(t, stt, time.Second)
+	commit(t, stt)
+	require.Equal(t, []transcriptSummary{
+		{SegmentID: 4, Revision: 2, IsRetracted: true},
+	}, summarize(readAllTranscripts(stt)))
+}
@@ -11,42 +11,30 @@ import (
 
 	"github.com/facebookincubator/go-belt"
 	"github.com/facebookincubator/go-belt/tool/logger"
-	"github.com/lazybeaver/entropy"
 	"github.com/mutablelogic/go-whisper/pkg/schema"
-	"github.com/mutablelogic/go-whisper/sys/whisper"
 	"github.com/xaionaro-go/audio/pkg/audio"
 	"github.com/xaionaro-go/audio/pkg/audio/resampler"
 	"github.com/xaionaro-go/audio/pkg/vad"
 	"github.com/xaionaro-go/observability"
 	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/speech/pkg/speech/diarization"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/consts"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/hallucination"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
 	"github.com/xaionaro-go/xsync"
 )
 
-// #cgo pkg-config: libwhisper
-// #cgo linux pkg-config: libwhisper-linux
-// #cgo darwin pkg-config: libwhisper-darwin
-import "C"
-
 const (
-	BufferLimit                              = 120 * time.Second
-	GapToCommit                              = 2 * time.Second
-	DiscardIfNoUsefulSegmentsIterations      = 4
-	DiscardFromSingleIterationIfBufferBigger = 10 * time.Second
-	IterationInterval                        = time.Second
-	PreserveHeadingDuration                  = time.Second
-
-	EntropyMin            = 3.63
-	EntropyDetectorLenMin = 80
+	// CarryForwardPromptMaxLength is the maximal length (in bytes) of
+	// the committed text carried forward as the prompt.
+	CarryForwardPromptMaxLength = 500
 )
 
 type SpeechToText struct {
 	xsync.Mutex
-	Context  *whisper.Context
+	Engine   InferenceEngine
 	Out      chan *speech.Transcript
 	Received *schema.Transcription
-	Params   whisper.FullParams
 
 	NextBuffer       []byte
 	CommittingBuffer []byte
@@ -55,6 +43,15 @@ type SpeechToText struct {
 	CommittingPosBytes uint64
 
 	IsFirstSpeakerSpeaking bool
+	EmitAnnotations        bool
+	CommitPolicy           CommitPolicy
+	InitialPrompt          string
+	BoostPhrases           []string
+	CarryForwardPrompt     bool
+	CarriedPrompt          string
+	Diarizer               diarization.Diarizer
+	SpeakerNames           *diarization.SpeakerNames
+	LastSpeaker            diarization.SpeakerID
 	CommitAudioError       error
 
 	CancelFunc context.CancelFunc
@@ -62,14 +59,34 @@ type SpeechToText struct {
 	Iterations                 uint
 	NoUsefulSegmentsIterations uint
 	ModelHash                  [sha1.Size]byte
+	HallucinationFilter        *hallucination.Filter
+	LocalAgreement             LocalAgreement
+	SegmentTracker             SegmentTracker
 
 	VAD                  vad.VAD
 	VADThreshold         float64
+	VADMinVoiceDuration  time.Duration
+	VADKeepContext       time.Duration
 	VADBuffer            []byte
 	VADCheckedUntilBytes uint64
 	VADVoiceIsFound      bool
 
+	// Endpointing is set if the audio is cut into utterances at the pauses
+	// (instead of following CommitPolicy), see OptionEndpointing.
+	Endpointing                    *Endpointing
+	EndpointingCheckedUntilBytes   uint64
+	EndpointingUtteranceStartBytes uint64
+	EndpointingIsInUtterance       bool
+	EndpointingVoiceDuration       time.Duration
+	EndpointingSilenceDuration     time.Duration
+
+	// EngineMutex serializes the usage of Engine.
+	EngineMutex xsync.Mutex
+
 	LastLanguageDetected speech.Language
+	AllowedLanguages     []speech.Language
+	EmitTranslation      bool
+	WordTimestamps       bool
 
 	LastSegmentString  string
 	LastSegmentStartTS time.Duration
@@ -78,228 +95,144 @@ type SpeechToText struct {
 
 var _ speech.ToText = (*SpeechToText)(nil)
 
-func New(
+// NewWithEngine returns a SpeechToText which uses the given InferenceEngine
+// instead of whisper.cpp; the engine is closed when SpeechToText is closed.
+//
+// The options configuring whisper.cpp (like OptionUseGPU or OptionBeamSize)
+// are the responsibility of the engine, thus they are ignored here.
+func NewWithEngine(
 	ctx context.Context,
-	modelBytes []byte,
-	language speech.Language,
-	samplingStrategy types.SamplingStrategy,
-	shouldTranslate bool,
-	alignmentAheadPreset types.AlignmentAheadsPreset,
+	engine InferenceEngine,
+	model hallucination.Model,
 	vadThreshold float64,
 	opts ...Option,
 ) (*SpeechToText, error) {
-	if len(modelBytes) == 0 {
-		return nil, fmt.Errorf("the model is empty")
+	stt, err := newSpeechToText(ctx, engine, model, vadThreshold, Options(opts).config())
+	if err != nil {
+		return nil, err
 	}
 
-	cfg := Options(opts).config()
-	params := whisper.DefaultContextParams()
-	if cfg.UseGPU != nil {
-		params.SetUseGpu(*cfg.UseGPU)
-	}
-	if cfg.GPUDeviceID != nil {
-		params.SetGpuDevice(*cfg.GPUDeviceID)
-	}
-	if cfg.FlashAttn != nil {
-		params.SetFlashAttn(*cfg.FlashAttn)
-	}
-	params.SetTokenTimestamps(false)
-	params.SetDTWAheadsPreset(AlignmentAheadsPreset(alignmentAheadPreset).ToWhisper())
-	whisper.Whisper_log_set(func(level whisper.LogLevel, text string) {
-		logger.FromCtx(ctx).Log(logLevelFromWhisper(level), text)
-	})
-
-	h := sha1.Sum(modelBytes)
-	logger.Debugf(ctx, "model SHA1: %X", h)
+	ctx, cancelFn := context.WithCancel(ctx)
+	stt.launchProcessingLoop(ctx)
+	stt.CancelFunc = cancelFn
+	return stt, nil
+}
 
+func newSpeechToText(
+	ctx context.Context,
+	engine InferenceEngine,
+	model hallucination.Model,
+	vadThreshold float64,
+	cfg config,
+) (_ret *SpeechToText, _err error) {
 	stt := &SpeechToText{
-		Context:   whisper.Whisper_init_from_buffer_with_params(modelBytes, params),
-		Params:    whisper.DefaultFullParams(SamplingStrategy(samplingStrategy).ToWhisper()),
-		Received:  &schema.Transcription{},
-		ModelHash: h,
+		Engine:   engine,
+		Received: &schema.Transcription{},
 
 		VADThreshold: vadThreshold,
 
 		IsFirstSpeakerSpeaking: true,
-	}
+		Diarizer:               cfg.Diarizer,
+		SpeakerNames:           diarization.NewSpeakerNames(cfg.SpeakerNames),
+		EmitAnnotations:        cfg.EmitAnnotations,
+		CommitPolicy:           cfg.CommitPolicy,
+		BoostPhrases:           cfg.BoostPhrases,
+		CarryForwardPrompt:     cfg.CarryForwardPrompt,
+		AllowedLanguages:       cfg.AllowedLanguages,
+		EmitTranslation:        cfg.EmitTranslation,
+		Endpointing:            cfg.Endpointing,
+		WordTimestamps:         cfg.WordTimestamps,
+	}
+	copy(stt.ModelHash[:], model.Hash)
+	defer func() {
+		if _err != nil {
+			stt.closeDiarizerAndVAD(ctx)
+		}
+	}()
 
-	if vadThreshold > 0 {
+	if len(cfg.HallucinationRules) > 0 {
+		logger.Debugf(ctx, "model family: '%s'", model.Family)
 		var err error
-		stt.VAD, err = stt.newVAD(ctx)
+		stt.HallucinationFilter, err = hallucination.NewFilter(model, cfg.HallucinationRules...)
 		if err != nil {
-			return nil, ErrInitVAD{Err: err}
+			return nil, fmt.Errorf("unable to initialize the hallucination filter: %w", err)
 		}
 	}
 
-	if shouldTranslate {
-		if !whisper.Whisper_is_multilingual(stt.Context) {
-			return nil, ErrModelCannotTranslate{}
+	if vadThreshold > 0 {
+		stt.VAD = cfg.VAD
+		if stt.VAD == nil {
+			backend := resolveVADBackend(cfg.VADBackend)
+			var err error
+			stt.VAD, err = stt.newVAD(ctx, backend, cfg.VADLibFVADMode)
+			if err != nil {
+				return nil, ErrInitVAD{Err: err}
+			}
+			stt.VADMinVoiceDuration, stt.VADKeepContext = vadDefaults(backend)
+		} else {
+			// the defaults of the backends are not related to a custom VAD
+			stt.VADMinVoiceDuration, stt.VADKeepContext = vadDefaults(types.VADBackendNone)
+		}
+		if cfg.VADMinVoiceDuration != nil {
+			stt.VADMinVoiceDuration = *cfg.VADMinVoiceDuration
+		}
+		if cfg.VADKeepContext != nil {
+			keepContext := *cfg.VADKeepContext
+			if keepContext < 0 || keepContext >= stt.CommitPolicy.MinSendingDuration {
+				return nil, ErrInvalidVADKeepContext{
+					KeepContext:        keepContext,
+					MinSendingDuration: stt.CommitPolicy.MinSendingDuration,
+				}
+			}
+			stt.VADKeepContext = keepContext
+		}
+	} else if cfg.VAD != nil {
+		// the VAD is owned by the SpeechToText (see OptionVAD), but is not used
+		if err := cfg.VAD.Close(); err != nil {
+			logger.Errorf(ctx, "unable to close the VAD: %v", err)
 		}
 	}
 
-	lang := LanguageToWhisper(language)
-	logger.Infof(ctx, "language: '%v'; shouldTranslate: %v", lang, shouldTranslate)
-
-	stt.Params.SetTranslate(shouldTranslate)
-	stt.Params.SetDiarize(true)
-	stt.Params.SetTokenTimestamps(true)
-	stt.Params.SetLanguage(lang)
-
-	stt.Params.SetAbortCallback(stt.Context, func() bool {
-		select {
-		case <-ctx.Done():
-			return true
-		default:
-			return false
+	if stt.Endpointing != nil {
+		if stt.VAD == nil {
+			return nil, ErrEndpointingRequiresVAD{}
 		}
-	})
+		// otherwise an utterance might never be long enough to be transcribed
+		if stt.Endpointing.MinSilence <= 0 || stt.Endpointing.MaxUtteranceLength <= 0 || stt.Endpointing.MaxUtteranceLength < stt.VADMinVoiceDuration {
+			return nil, ErrInvalidEndpointing{
+				Endpointing:         *stt.Endpointing,
+				VADMinVoiceDuration: stt.VADMinVoiceDuration,
+			}
+		}
+	}
 
-	ctx, cancelFn := context.WithCancel(ctx)
-	stt.launchProcessingLoop(ctx)
-	stt.CancelFunc = cancelFn
+	if cfg.InitialPrompt != nil {
+		stt.InitialPrompt = *cfg.InitialPrompt
+	}
+	stt.updatePrompt()
 	return stt, nil
 }
 
 func (stt *SpeechToText) isLikelyHallucination(
 	ctx context.Context,
-	s *whisper.Segment,
+	s *Segment,
+	language speech.Language,
 ) bool {
-	t0 := strings.Trim(s.Text, " ")
-	t1 := strings.ReplaceAll(t0, "!", "")
-	t1 = strings.ReplaceAll(t1, ".", "")
-	t1 = strings.ReplaceAll(t1, "-", "")
-	t1 = strings.Trim(t1, " ")
-	switch {
-	case bytes.Equal(stt.ModelHash[:], ModelHashMedium[:]):
-		logger.Tracef(ctx, "hallucination check for medium")
-		switch t1 {
-		case "Thank you for watching", "Thanks for watching",
-			"Thank you for watching Please subscribe to my channel",
-			"Thank you",
-			"Bye":
-			return true
-		}
-
-	case bytes.Equal(stt.ModelHash[:], ModelHashLargeV3[:]):
-		logger.Tracef(ctx, "hallucination check for large-v3")
-		switch t0 {
-		case "0.", "0.5.", "0.001.",
-			"you",
-			"Oh!",
-			"Pew.",
-			"So,",
-			"Thanks.",
-			"- What?",
-			//"- Mm-hmm.",
-			"Hello everyone, welcome to my channel.",
-			//"Hi, everyone.",
-			"The next day",
-			"So, that's it.",
-			"So, let's do that.",
-			"So, let's do this.",
-			"So, let's do it.",
-			"Well, I'm going to do it.",
-			"So, let's go ahead and do that.",
-			"I'm going to go ahead and do that.",
-			"So, I'm going to go ahead and do that.",
-			"So, we're going to do the same thing.",
-			"So, we're going to do that.",
-			"I'm going to...",
-			"So, we have the following.",
-			"I don't know what to do.",
-			"We don't know about the fill of our 20 pairs, but it's a big one.",
-			"We have 15 minutes left.",
-			"I'll be back.",
-			"I'll be back in a minute.",
-			"I'll be right back.",
-			"I'll go and get the baby.",
-			"Sleep.",
-			"I'm going to bed.",
-			"I'm going to sleep.",
-			"I'm going to go to sleep",
-			"I'm going to go and get some water.",
-			"I'll be waiting for you at the station.",
-			"So, we have a function called,",
-			"So, we have a function called the,",
-			"So, we're going to do a little bit of a loop.",
-			"All right.",
-			"I'll go and get the money.",
-			"I love you.",
-			"So, what is the relationship between the two?",
-			"You're welcome.",
-			"Shit.",
-			"Amen.",
-			"what the hell is that sound?",
-			"I'm not a doctor.",
-			"You're going to be a good boy.",
-			"let's go to the bathroom",
-			"I'm sorry. I'll go to the bathroom.",
-			"I'm going to the bathroom. I'll be right back.",
-			"I'm going to the hospital.",
-			"I'm going to the hospital. I'll be there in a minute.",
-			"I'm going to make a new one.",
-			"I'm going to write a new one.",
-			"I'm going to write this.",
-			`"I'm sorry.`,
-			"I'm sorry, I didn't mean to.",
-			"I'm sorry, I didn't mean to hurt you.",
-			"I'm sorry. I'm sorry.",
-			"I'm sorry, I'm sorry.",
-			"I'm sorry, I'm sorry. I'm sorry.",
-			"I'm sorry. I'm sorry. I'm sorry.":
-			return true
-		}
-		switch t1 {
-		case "Thank you for watching", "Thanks for watching",
-			"Thank you for watching Please subscribe to my channel",
-			"Thank you",
-			"I'm sorry",
-			"Bye",
-			"Subtitles by the Amaraorg community",
-			"Okay",
-			"The end",
-			"The End",
-			"THE END",
-			"I'll go to the bathroom",
-			"I'm going to the bathroom",
-			"":
-			return true
-		}
-
-		switch {
-		case strings.Contains(s.Text, "So, this is the first step"):
-			return true
-		case strings.Contains(s.Text, "So, we have a function of 0.001"):
-			return true
-		case strings.HasPrefix(t0, `"`) && strings.HasSuffix(t0, `"`):
-			return true
-		case strings.HasPrefix(t0, "End of"):
-			return true
-		}
-
-		if len(t0) > EntropyDetectorLenMin {
-			// Examples of what we are suppressing here:
-			// * "So, I'm going to write the value of the value of the value of the value of the value of"
-			entropy, err := entropy.Shannon(string(s.Text)[30:])
-			if err != nil {
-				logger.Errorf(ctx, "unable to calculate shannon entropy: %v", err)
-				return false
-			}
-
-			// Entropy:
-			// * "ue of the value of the value of the value of the value of" -> 3.176
-
-			if entropy < EntropyMin {
-				logger.Tracef(ctx, "entropy is too low, assuming '%s' is a hallucination: %f < %f", s.Text, entropy, EntropyMin)
-				return true
-			}
-		}
+	rule := stt.HallucinationFilter.Check(ctx, s.Text, language)
+	if rule == nil {
+		return false
 	}
+	logger.Debugf(ctx, "'%s' matched the hallucination rule %s; stats: %+v", s.Text, rule, stt.HallucinationFilter.Stats(ctx))
+	return true
+}
 
-	return false
+// HallucinationStats returns the statistics of the segments suppressed
+// as hallucinations.
+func (stt *SpeechToText) HallucinationStats(ctx context.Context) hallucination.Stats {
+	return stt.HallucinationFilter.Stats(ctx)
 }
 
-func isHangingSegment(s *whisper.Segment) bool {
+func isHangingSegment(s *Segment) bool {
 	// Sometimes Whisper goes crazy and hangs while processing a specific audio,
 	// in this case it returns a lot of exclamation marks and nothing else
 
@@ -315,25 +248,47 @@ func (stt *SpeechToText) launchProcessingLoop(ctx context.Context) {
 	stt.Out = make(chan *speech.Transcript, 1024)
 	observability.Go(ctx, func() {
 		defer func() {
-			close(stt.Out)
-			whisper.Whisper_free(stt.Context)
-			stt.Context = nil
+			defer close(stt.Out)
+			if err := stt.Engine.Close(); err != nil {
+				logger.Errorf(ctx, "unable to close the inference engine: %v", err)
+			}
+			stt.closeDiarizerAndVAD(ctx)
 		}()
 		stt.processingLoop(ctx)
 	})
 }
 
+// closeDiarizerAndVAD closes the components owned by the SpeechToText
+// (except the engine, which is closed by the caller if the initialization fails).
+func (stt *SpeechToText) closeDiarizerAndVAD(ctx context.Context) {
+	if stt.Diarizer != nil {
+		if err := stt.Diarizer.Close(); err != nil {
+			logger.Errorf(ctx, "unable to close the diarizer: %v", err)
+		}
+	}
+	if stt.VAD != nil {
+		if err := stt.VAD.Close(); err != nil {
+			logger.Errorf(ctx, "unable to close the VAD: %v", err)
+		}
+	}
+}
+
 func (stt *SpeechToText) processingLoop(ctx context.Context) {
 	logger.Tracef(ctx, "processingLoop")
 	defer func() { logger.Tracef(ctx, "/processingLoop") }()
 
-	t := time.NewTicker(IterationInterval)
+	interval, commitAudio := stt.CommitPolicy.IterationInterval, stt.commitAudio
+	if stt.Endpointing != nil {
+		interval, commitAudio = EndpointingIterationInterval, stt.endpointAudio
+	}
+
+	t := time.NewTicker(interval)
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-t.C:
-			err := stt.commitAudio(ctx)
+			err := commitAudio(ctx)
 			if err != nil {
 				logger.Debugf(ctx, "unable to commit audio: %v", err)
 				stt.Mutex.Do(xsync.WithNoLogging(ctx, true), func() {
@@ -350,6 +305,16 @@ func (stt *SpeechToText) Close() error {
 	return nil
 }
 
+// SetSpeakerName sets the name to be reported instead of the speaker ID
+// (is used only if a Diarizer is set, see OptionDiarizer).
+func (stt *SpeechToText) SetSpeakerName(
+	ctx context.Context,
+	id diarization.SpeakerID,
+	name string,
+) {
+	stt.SpeakerNames.SetName(ctx, id, name)
+}
+
 func (*SpeechToText) AudioEncoding(context.Context) (audio.Encoding, error) {
 	return (*SpeechToText)(nil).AudioEncodingNoErr(), nil
 }
@@ -368,10 +333,16 @@ func (*SpeechToText) AudioChannelsNoErr() audio.Channel {
 
 func (stt *SpeechToText) writeSegment(
 	ctx context.Context,
-	s *whisper.Segment,
+	s *Segment,
 	isFinal bool,
+	samples []float32,
+	kind speech.TranscriptKind,
+	language speech.LanguageProbability,
+	translation []*Segment,
 ) bool {
-	return xsync.DoA3R1(xsync.WithNoLogging(ctx, true), &stt.Mutex, stt.writeSegmentNoLock, ctx, s, isFinal)
+	return xsync.DoR1(xsync.WithNoLogging(ctx, true), &stt.Mutex, func() bool {
+		return stt.writeSegmentNoLock(ctx, s, isFinal, samples, kind, language, translation)
+	})
 }
 
 func containsAlphaNum(s string) bool {
@@ -385,69 +356,90 @@ func containsAlphaNum(s string) bool {
 
 func (stt *SpeechToText) writeSegmentNoLock(
 	ctx context.Context,
-	s *whisper.Segment,
+	s *Segment,
 	isFinal bool,
+	samples []float32,
+	kind speech.TranscriptKind,
+	language speech.LanguageProbability,
+	translation []*Segment,
 ) bool {
-	logger.Debugf(ctx, "segment: %#+v; isFinal: %v", s, isFinal)
+	logger.Debugf(ctx, "segment: %#+v; isFinal: %v; kind: %v; language: %v", s, isFinal, kind, language)
 
-	trimmedText := strings.ToLower(strings.Trim(s.Text, " "))
-	switch {
-	case strings.HasPrefix(trimmedText, "[") && strings.HasSuffix(trimmedText, "]"):
-		// e.g.: [silence], [typing], [click], [music], [blank_audio], [ pause ]
-		return false
-	case strings.HasPrefix(trimmedText, "(") && strings.HasSuffix(trimmedText, ")"):
-		// e.g.: (clicking), (faint clicking), (door opens)
-		return false
-	case strings.HasPrefix(trimmedText, "*") && strings.HasSuffix(trimmedText, "*"):
-		// e.g.: *thump*
-		return false
-	case strings.HasPrefix(trimmedText, "♪") && strings.HasSuffix(trimmedText, "♪"):
-		// e.g.: ♪ ♪
-		return false
+	// is calculated for every segment (even if it is not sent)
+	// to keep the hypotheses of different iterations comparable
+	stabilities := make([]float32, 0, len(s.Tokens))
+	for _, token := range s.Tokens {
+		stabilities = append(stabilities, stt.LocalAgreement.Next(token.Text, isFinal))
 	}
 
-	if s.SpeakerTurn {
-		stt.IsFirstSpeakerSpeaking = !stt.IsFirstSpeakerSpeaking
+	if kind == speech.TranscriptKindSpeech {
+		var ok bool
+		kind, ok = segmentKind(s.Text)
+		if !ok {
+			return false
+		}
+	}
+	if kind != speech.TranscriptKindSpeech && !stt.EmitAnnotations {
+		return false
 	}
 
-	speaker := ">"
-	if !stt.IsFirstSpeakerSpeaking {
-		speaker = "<"
+	var speaker string
+	if kind == speech.TranscriptKindSpeech {
+		speaker = stt.segmentSpeakerNoLock(ctx, s, isFinal, samples)
 	}
 
 	nonEmptyTokenCount := 0
+	stability := float32(0)
+	stabilityCount := 0
 
 	committingPos := getDurationFromBytes(stt.CommittingPosBytes)
 	words := make([]speech.TranscriptToken, 0, len(s.Tokens))
 	for idx, token := range s.Tokens {
 		logger.Debugf(ctx, "token %d: %#+v", idx, token)
 		words = append(words, speech.TranscriptToken{
-			StartTime:  token.T0 + committingPos,
-			EndTime:    token.T1 + committingPos,
-			Text:       speech.Text(token.Text),
-			Confidence: token.P,
-			Speaker:    speaker,
+			StartTime:    token.T0 + committingPos,
+			EndTime:      token.T1 + committingPos,
+			Text:         speech.Text(token.Text),
+			Confidence:   token.P,
+			Speaker:      speaker,
+			Stability:    stabilities[idx],
+			IsDTWAligned: token.IsDTWAligned,
 		})
 		if containsAlphaNum(token.Text) {
 			nonEmptyTokenCount++
 		}
+		if !isSpecialToken(token.Text) {
+			stability += stabilities[idx]
+			stabilityCount++
+		}
+	}
+	if stabilityCount > 0 {
+		stability /= float32(stabilityCount)
+	}
+	if stt.WordTimestamps {
+		words = speech.TranscriptTokens(words).Words()
 	}
 
-	if nonEmptyTokenCount == 0 {
+	if nonEmptyTokenCount == 0 && kind == speech.TranscriptKindSpeech {
 		return false
 	}
 
+	trackedSegment := stt.SegmentTracker.Next(isFinal)
 	t := &speech.Transcript{
 		Variants: []speech.TranscriptVariant{{
 			Text:             speech.Text(s.Text),
 			TranscriptTokens: words,
 			Confidence:       0.5,
 		}},
-		Stability:           0,
+		Stability:           stability,
 		NoSpeechProbability: s.NoSpeechProb,
-		AudioChannelNum:     stt.AudioChannelsNoErr(),
-		Language:            stt.LastLanguageDetected,
+		AudioChannelNum:     0, // the index of the channel (we support mono only)
+		Language:            language.Language,
 		IsFinal:             isFinal,
+		Kind:                kind,
+		LanguageConfidence:  language.Probability,
+		SegmentID:           trackedSegment.ID,
+		Revision:            trackedSegment.Revision,
 	}
 
 	logger.Debugf(ctx, "sending Transcript: %#+v", *t)
@@ -456,7 +448,84 @@ func (stt *SpeechToText) writeSegmentNoLock(
 	default:
 		logger.Error(ctx, "the queue is full, dropping the message")
 	}
-	return true
+	if tr := translationTranscript(t, translation, committingPos, speaker); tr != nil {
+		logger.Debugf(ctx, "sending the translation: %#+v", *tr)
+		select {
+		case stt.Out <- tr:
+		default:
+			logger.Error(ctx, "the queue is full, dropping the message")
+		}
+	}
+	if isFinal && kind == speech.TranscriptKindSpeech {
+		stt.carryForwardNoLock(s.Text)
+	}
+	return kind == speech.TranscriptKindSpeech
+}
+
+// retractSegments tells the consumers that the segments
+// sent earlier are not valid anymore.
+func (stt *SpeechToText) retractSegments(
+	ctx context.Context,
+	segments []TrackedSegment,
+) {
+	if len(segments) == 0 {
+		return
+	}
+	stt.Mutex.Do(xsync.WithNoLogging(ctx, true), func() {
+		for _, seg := range segments {
+			logger.Debugf(ctx, "retracting segment %d", seg.ID)
+			t := &speech.Transcript{
+				Language:    stt.LastLanguageDetected,
+				SegmentID:   seg.ID,
+				Revision:    seg.Revision + 1,
+				IsRetracted: true,
+			}
+			select {
+			case stt.Out <- t:
+			default:
+				logger.Error(ctx, "the queue is full, dropping the message")
+			}
+		}
+	})
+}
+
+// segmentSpeakerNoLock returns the speaker of the segment; `samples`
+// is the audio the segment timestamps are relative to.
+func (stt *SpeechToText) segmentSpeakerNoLock(
+	ctx context.Context,
+	s *Segment,
+	isFinal bool,
+	samples []float32,
+) string {
+	if stt.Diarizer == nil {
+		if s.SpeakerTurn {
+			stt.IsFirstSpeakerSpeaking = !stt.IsFirstSpeakerSpeaking
+		}
+		if !stt.IsFirstSpeakerSpeaking {
+			return "<"
+		}
+		return ">"
+	}
+
+	// Non-final segments are re-decoded on every iteration, so
+	// identifying the speaker of them would feed the same audio
+	// to the Diarizer again and again; thus we just reuse the last speaker.
+	if !isFinal {
+		return stt.SpeakerNames.Name(ctx, stt.LastSpeaker)
+	}
+
+	sampleRate := stt.AudioEncodingNoErr().SampleRate
+	from := min(int(s.T0.Seconds()*float64(sampleRate)), len(samples))
+	to := min(int(s.T1.Seconds()*float64(sampleRate)), len(samples))
+	if from < to {
+		speakerID, err := stt.Diarizer.IdentifySpeaker(ctx, samples[from:to], sampleRate)
+		if err != nil {
+			logger.Errorf(ctx, "unable to identify the speaker: %v", err)
+		} else {
+			stt.LastSpeaker = speakerID
+		}
+	}
+	return stt.SpeakerNames.Name(ctx, stt.LastSpeaker)
 }
 
 func (stt *SpeechToText) WriteAudio(
@@ -475,7 +544,7 @@ func (stt *SpeechToText) WriteAudio(
 
 		// the buffer is already too big, assuming it is not committing, because it contains
 		// essentially silence, so just cutting the buffer in half
-		limit := getBytesPos(BufferLimit)
+		limit := getBytesPos(stt.CommitPolicy.BufferLimit)
 		if uint64(len(stt.NextBuffer)) > limit {
 			copy(stt.NextBuffer, stt.NextBuffer[limit/2:])
 			stt.NextBuffer = stt.NextBuffer[:limit/2]
@@ -491,6 +560,7 @@ func (stt *SpeechToText) WriteAudio(
 func (stt *SpeechToText) checkIfVoiceActive(
 	ctx context.Context,
 	buf []byte,
+	minVoiceDuration time.Duration,
 ) (_ret0 bool, _ret1 time.Duration, _err error) {
 	logger.Tracef(ctx, "checkIfVoiceActive: len(buf):%d", len(buf))
 	defer func() { logger.Tracef(ctx, "/checkIfVoiceActive: %v %v %v", _ret0, _ret1, _err) }()
@@ -550,7 +620,7 @@ func (stt *SpeechToText) checkIfVoiceActive(
 
 	msg := stt.VADBuffer[:n]
 
-	maxConfidence, foundAt, err := stt.VAD.FindNextVoice(ctx, msg, stt.VADThreshold, VADMinVoiceDuration)
+	maxConfidence, foundAt, err := stt.VAD.FindNextVoice(ctx, msg, stt.VADThreshold, minVoiceDuration)
 	if err != nil {
 		return true, 0, fmt.Errorf("unable to detect voice probability: %w", err)
 	}
@@ -559,6 +629,33 @@ func (stt *SpeechToText) checkIfVoiceActive(
 	return maxConfidence > stt.VADThreshold, foundAt, nil
 }
 
+// releaseCommittingBufferNoLock returns the part of the committing buffer
+// after the first bytesDiff bytes back to the beginning of NextBuffer.
+func (stt *SpeechToText) releaseCommittingBufferNoLock(
+	ctx context.Context,
+	committingBuf []byte,
+	bytesDiff uint64,
+) {
+	stt.TempBuffer = stt.TempBuffer[:0]
+	stt.TempBuffer = append(stt.TempBuffer, committingBuf[bytesDiff:]...)
+	stt.TempBuffer = append(stt.TempBuffer, stt.NextBuffer...)
+	stt.NextBuffer = stt.NextBuffer[:0]
+	stt.CommittingBuffer = stt.CommittingBuffer[:0]
+	stt.NextBuffer, stt.TempBuffer = stt.TempBuffer, stt.NextBuffer
+
+	assert(ctx, len(stt.NextBuffer)%4 == 0)
+
+	tsDiff := getDurationFromBytes(bytesDiff)
+	logger.Debugf(
+		ctx,
+		"considering final everything until %v (%v); leftover buffer: %d bytes (%v)",
+		tsDiff,
+		getDurationFromBytes(stt.CommittingPosBytes)+tsDiff,
+		len(stt.NextBuffer),
+		getDurationFromBytes(uint64(len(stt.NextBuffer))),
+	)
+}
+
 func (stt *SpeechToText) commitAudio(
 	ctx context.Context,
 ) (_err error) {
@@ -566,8 +663,8 @@ func (stt *SpeechToText) commitAudio(
 	defer func() { logger.Tracef(ctx, "/commitAudio: %v", _err) }()
 
 	buf := xsync.DoR1(xsync.WithNoLogging(ctx, true), &stt.Mutex, func() []byte {
-		if uint64(len(stt.NextBuffer)) < requiredSendingFrameSize() {
-			logger.Tracef(ctx, "buffer is not big enough: %d < %d", len(stt.NextBuffer), requiredSendingFrameSize())
+		if uint64(len(stt.NextBuffer)) < stt.requiredSendingFrameSize() {
+			logger.Tracef(ctx, "buffer is not big enough: %d < %d", len(stt.NextBuffer), stt.requiredSendingFrameSize())
 			return nil
 		}
 
@@ -582,33 +679,17 @@ func (stt *SpeechToText) commitAudio(
 	committingBuf := buf
 	oldCommittedPosBytes := stt.CommittingPosBytes
 	defer stt.Mutex.Do(xsync.WithNoLogging(ctx, true), func() {
-		bytesDiff := stt.CommittingPosBytes - oldCommittedPosBytes
-		stt.TempBuffer = stt.TempBuffer[:0]
-		stt.TempBuffer = append(stt.TempBuffer, committingBuf[bytesDiff:]...)
-		stt.TempBuffer = append(stt.TempBuffer, stt.NextBuffer...)
-		stt.NextBuffer = stt.NextBuffer[:0]
-		stt.CommittingBuffer = stt.CommittingBuffer[:0]
-		stt.NextBuffer, stt.TempBuffer = stt.TempBuffer, stt.NextBuffer
-
-		assert(ctx, len(stt.NextBuffer)%4 == 0)
-
-		tsDiff := getDurationFromBytes(stt.CommittingPosBytes - oldCommittedPosBytes)
-		logger.Debugf(
-			ctx,
-			"considering final everything until %v (%v); leftover buffer: %d bytes (%v)",
-			tsDiff,
-			getDurationFromBytes(stt.CommittingPosBytes)+tsDiff,
-			len(stt.NextBuffer),
-			getDurationFromBytes(uint64(len(stt.NextBuffer))),
-		)
+		stt.releaseCommittingBufferNoLock(ctx, committingBuf, stt.CommittingPosBytes-oldCommittedPosBytes)
 	})
 
 	bufferEndTSDiff := getDurationFromBytes(uint64(len(buf)))
 
-	preserveBytes := getBytesPos(PreserveHeadingDuration)
+	preserveBytes := getBytesPos(stt.CommitPolicy.PreserveHeadingDuration)
 
 	discardBuffer := func() {
 		stt.NoUsefulSegmentsIterations = 0
+		stt.LocalAgreement.Reset()
+		stt.retractSegments(ctx, stt.SegmentTracker.Reset())
 		stt.CommittingPosBytes += uint64(len(buf)) - preserveBytes
 	}
 
@@ -628,19 +709,26 @@ func (stt *SpeechToText) commitAudio(
 		voiceIsActive, foundAt, err := stt.checkIfVoiceActive(
 			belt.WithField(ctx, "subsystem", "VAD"),
 			buf[stt.VADCheckedUntilBytes-stt.CommittingPosBytes:],
+			stt.VADMinVoiceDuration,
 		)
 		if err != nil {
 			logger.Errorf(ctx, "VAD: unable to detect if voice is active: %v", err)
 		}
 		stt.VADVoiceIsFound = voiceIsActive
 		if !voiceIsActive {
-			stt.VADCheckedUntilBytes = stt.CommittingPosBytes + uint64(len(buf)) - getBytesPos(VADKeepContext)
+			// VADKeepContext might exceed the buffer (e.g. the warm-up), thus not going below CommittingPosBytes
+			stt.VADCheckedUntilBytes = stt.CommittingPosBytes
+			if keepBytes := getBytesPos(stt.VADKeepContext); uint64(len(buf)) > keepBytes {
+				stt.VADCheckedUntilBytes += uint64(len(buf)) - keepBytes
+			}
 			discardBuffer()
 			return nil
 		}
-		stt.VADCheckedUntilBytes = stt.CommittingPosBytes + getBytesPos(foundAt)
+		// foundAt is relative to the checked part of the buffer,
+		// which starts at VADCheckedUntilBytes (not at CommittingPosBytes)
+		stt.VADCheckedUntilBytes += getBytesPos(foundAt)
 
-		shouldCutAway := int64(stt.VADCheckedUntilBytes) - int64(getBytesPos(PreserveHeadingDuration)) - int64(getBytesPos(committingPos))
+		shouldCutAway := int64(stt.VADCheckedUntilBytes) - int64(getBytesPos(stt.CommitPolicy.PreserveHeadingDuration)) - int64(getBytesPos(committingPos))
 		logger.Debugf(ctx, "VAD: shouldCutAway == %v", shouldCutAway)
 		if shouldCutAway > 0 {
 			logger.Debugf(ctx, "VAD: cutting away %s from the beginning (%s -> %s)", getDurationFromBytes(uint64(shouldCutAway)), bufferEndTSDiff, bufferEndTSDiff-getDurationFromBytes(uint64(shouldCutAway)))
@@ -668,14 +756,12 @@ func (stt *SpeechToText) commitAudio(
 		duration,
 		len(samples),
 	)
+	stt.EngineMutex.ManualLock(xsync.WithNoLogging(ctx, true))
+	defer stt.EngineMutex.ManualUnlock(xsync.WithNoLogging(ctx, true))
+
 	stt.Iterations++
 	startCommittingTS := time.Now()
-	stt.Params.SetOffsetMS(0)
-	err := whisper.Whisper_full(
-		stt.Context,
-		stt.Params,
-		samples,
-	)
+	err := stt.Engine.Full(ctx, samples)
 	commitTime := time.Since(startCommittingTS)
 	logger.Debugf(
 		ctx,
@@ -689,34 +775,32 @@ func (stt *SpeechToText) commitAudio(
 	if err != nil {
 		return fmt.Errorf("unable to build a transcription: %w", err)
 	}
-
-	langProbs := make([]float32, whisper.Whisper_lang_max_id()+1)
-	whisper.Whisper_lang_auto_detect(stt.Context, 0, stt.Params.NumThreads(), langProbs)
-	langDetectTime := time.Since(startCommittingTS)
-	likelyLangID := -1
-	maxProb := float32(0)
-	for langID, langProb := range langProbs {
-		if langProb > maxProb {
-			likelyLangID = langID
-			maxProb = langProb
-		}
+	iterationLangs := stt.detectIterationLanguages(ctx)
+	forcedLanguage, err := stt.enforceAllowedLanguages(ctx, samples, iterationLangs)
+	if err != nil {
+		return fmt.Errorf("unable to build a transcription in an allowed language: %w", err)
+	}
+	if forcedLanguage != "" {
+		defer stt.Engine.SetLanguage("")
 	}
-	lang := speech.Language(whisper.Whisper_lang_str(likelyLangID))
-	logger.Debugf(
-		ctx,
-		"finished detecting the language, total time (with STT) is %v; resulting language: %v",
-		langDetectTime, lang,
-	)
-	stt.LastLanguageDetected = lang
 
-	numSegments := stt.Context.NumSegments()
+	segments := engineSegments(stt.Engine)
+	numSegments := len(segments)
 	logger.Debugf(ctx, "numSegments == %d", numSegments)
 	if numSegments == 0 {
 		discardBuffer()
 		return nil
 	}
 
-	lastSegment := stt.Context.Segment(numSegments - 1)
+	var translations [][]*Segment
+	if stt.EmitTranslation {
+		translations, err = stt.translateSegments(ctx, samples, segments)
+		if err != nil {
+			return fmt.Errorf("unable to build a translation: %w", err)
+		}
+	}
+
+	lastSegment := segments[numSegments-1]
 	lastSegmentStartTS := getFirstTimestamp(lastSegment)
 
 	var lastSegmentEndTS time.Duration
@@ -732,10 +816,13 @@ func (stt *SpeechToText) commitAudio(
 		stt.LastSegmentEndTS = lastSegmentEndTS
 	}
 
+	stt.LocalAgreement.Begin(stt.CommittingPosBytes)
+	stt.retractSegments(ctx, stt.SegmentTracker.Begin(stt.CommittingPosBytes))
+
 	lastCommittingSegmentIdx := numSegments - 2
 	tailGapLength := bufferEndTSDiff - lastSegmentEndTS
 	logger.Debugf(ctx, "tailGapLength == %v == %v - %v", tailGapLength, bufferEndTSDiff, lastSegmentEndTS)
-	if tailGapLength >= GapToCommit {
+	if tailGapLength >= stt.CommitPolicy.GapToCommit {
 		logger.Debugf(ctx, "considering the last segment committed")
 		lastCommittingSegmentIdx = numSegments - 1
 	} else {
@@ -747,8 +834,8 @@ func (stt *SpeechToText) commitAudio(
 	hasHangingSegment := false
 	numUsefulSegments := 0
 	for i := 0; i < numSegments; i++ {
-		logger.Debugf(ctx, "writeSegment(ctx, stt.Context.Segment(%d), %v)", i, i <= lastCommittingSegmentIdx)
-		segment := stt.Context.Segment(i)
+		logger.Debugf(ctx, "writeSegment(ctx, segments[%d], %v)", i, i <= lastCommittingSegmentIdx)
+		segment := segments[i]
 		if isHangingSegment(segment) {
 			logger.Debugf(ctx, "this is a hang-causing segment")
 			if i > lastCommittingSegmentIdx {
@@ -758,15 +845,23 @@ func (stt *SpeechToText) commitAudio(
 			hasHangingSegment = true
 			continue
 		}
-		if stt.isLikelyHallucination(ctx, segment) {
+		language := stt.segmentLanguage(ctx, segment, i <= lastCommittingSegmentIdx, iterationLangs)
+		if stt.isLikelyHallucination(ctx, segment, language.Language) {
 			logger.Debugf(ctx, "likely a hallucination: '%s', skipping", segment.Text)
+			stt.writeSegment(ctx, segment, i <= lastCommittingSegmentIdx, samples, speech.TranscriptKindSuspectedHallucination, language, nil)
 			continue
 		}
-		if stt.writeSegment(ctx, segment, i <= lastCommittingSegmentIdx) {
+		var translation []*Segment
+		if translations != nil {
+			translation = translations[i]
+		}
+		if stt.writeSegment(ctx, segment, i <= lastCommittingSegmentIdx, samples, speech.TranscriptKindSpeech, language, translation) {
 			numUsefulSegments++
 		}
 	}
 
+	stt.retractSegments(ctx, stt.SegmentTracker.End())
+
 	logger.Debugf(ctx, "numUsefulSegments == %d", numUsefulSegments)
 	if numUsefulSegments == 0 {
 		stt.NoUsefulSegmentsIterations++
@@ -774,10 +869,10 @@ func (stt *SpeechToText) commitAudio(
 			ctx,
 			"%d: NoUsefulSegmentsIterations: %d >= %d; hasHangingSegment: %v",
 			stt.Iterations,
-			stt.NoUsefulSegmentsIterations, DiscardIfNoUsefulSegmentsIterations,
+			stt.NoUsefulSegmentsIterations, stt.CommitPolicy.DiscardIfNoUsefulSegmentsIterations,
 			hasHangingSegment,
 		)
-		if stt.NoUsefulSegmentsIterations >= DiscardIfNoUsefulSegmentsIterations || hasHangingSegment {
+		if stt.NoUsefulSegmentsIterations >= stt.CommitPolicy.DiscardIfNoUsefulSegmentsIterations || hasHangingSegment {
 			discardBuffer()
 			return nil
 		}
@@ -796,7 +891,7 @@ func (stt *SpeechToText) commitAudio(
 
 	logger.Debugf(ctx, "resulting lastCommittingSegmentIdx == %d", lastCommittingSegmentIdx)
 	if lastCommittingSegmentIdx >= 0 {
-		lastCommittingSegment := stt.Context.Segment(lastCommittingSegmentIdx)
+		lastCommittingSegment := segments[lastCommittingSegmentIdx]
 		tsDiff = getLastTimestamp(lastCommittingSegment)
 		bytesDiff = getBytesPos(tsDiff)
 		logger.Debugf(ctx, "lastCommittingSegment == %#+v; tsDiff == %s", lastCommittingSegment, tsDiff)
@@ -806,6 +901,8 @@ func (stt *SpeechToText) commitAudio(
 	assert(ctx, int(bytesDiff) < len(buf), int(bytesDiff), len(buf))
 	assert(ctx, bytesDiff%4 == 0, bytesDiff)
 	stt.CommittingPosBytes += bytesDiff
+	stt.LocalAgreement.Commit(stt.CommittingPosBytes)
+	stt.SegmentTracker.Commit(stt.CommittingPosBytes)
 
 	return nil
 }
@@ -820,8 +917,8 @@ func getBytesPos(d time.Duration) uint64 {
 	return stt.AudioEncodingNoErr().BytesForDuration(d) * uint64(stt.AudioChannelsNoErr())
 }
 
-func requiredSendingFrameSize() uint64 {
-	return getBytesPos(2 * time.Second)
+func (stt *SpeechToText) requiredSendingFrameSize() uint64 {
+	return getBytesPos(stt.CommitPolicy.MinSendingDuration)
 }
 
 func (stt *SpeechToText) OutputChan(context.Context) (<-chan *speech.Transcript, error) {
@@ -832,7 +929,7 @@ func (stt *SpeechToText) OutputChanNoErr() <-chan *speech.Transcript {
 	return stt.Out
 }
 
-func getLastTimestamp(s *whisper.Segment) time.Duration {
+func getLastTimestamp(s *Segment) time.Duration {
 	for idx := len(s.Tokens) - 1; idx >= 0; idx-- {
 		token := s.Tokens[idx]
 		if token.T0 == token.T1 {
@@ -843,7 +940,7 @@ func getLastTimestamp(s *whisper.Segment) time.Duration {
 	return 0
 }
 
-func getFirstTimestamp(s *whisper.Segment) time.Duration {
+func getFirstTimestamp(s *Segment) time.Duration {
 	for _, token := range s.Tokens {
 		if token.T0 == token.T1 {
 			continue
@@ -0,0 +1,392 @@
+package whisper
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/speech/pkg/speech/diarization"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/hallucination"
+)
+
+// skipWarmup makes the next commitAudio call behave as if
+// the first iterations (which always discard the buffer) are already passed.
+func skipWarmup(stt *SpeechToText) {
+	stt.Iterations = 2
+}
+
+func TestCommitAudioNotEnoughAudio(t *testing.T) {
+	engine := &fakeEngine{}
+	stt := newTestSTT(t, engine)
+	writeSilence(t, stt, time.Second)
+	commit(t, stt)
+	require.Empty(t, engine.Calls)
+	require.Equal(t, getBytesPos(time.Second), uint64(len(stt.NextBuffer)))
+	require.Zero(t, stt.CommittingPosBytes)
+}
+
+func TestCommitAudioNoSegments(t *testing.T) {
+	engine := &fakeEngine{}
+	stt := newTestSTT(t, engine)
+	skipWarmup(stt)
+	writeSilence(t, stt, 3*time.Second)
+	commit(t, stt)
+	require.Len(t, engine.Calls, 1)
+	require.Equal(t, 3*time.Second, engine.Calls[0])
+
+	// everything is discarded, except the heading preserved as a context
+	require.Equal(t, getBytesPos(2*time.Second), stt.CommittingPosBytes)
+	require.Equal(t, getBytesPos(time.Second), uint64(len(stt.NextBuffer)))
+	require.Empty(t, readTranscripts(stt))
+}
+
+func TestCommitAudioWarmup(t *testing.T) {
+	engine := &fakeEngine{Script: func(_ int, _ []float32, duration time.Duration) []*Segment {
+		return []*Segment{fakeSegment(" hello", 0, time.Second)}
+	}}
+	stt := newTestSTT(t, engine)
+	for i := 0; i < 2; i++ {
+		writeSilence(t, stt, 3*time.Second)
+		posBefore, bufLen := stt.CommittingPosBytes, uint64(len(stt.NextBuffer))
+		commit(t, stt)
+		require.Equal(t, posBefore+bufLen-getBytesPos(CommitPolicyBalanced.PreserveHeadingDuration), stt.CommittingPosBytes)
+	}
+	// the segments are still reported during the warmup
+	require.Len(t, readTranscripts(stt), 2)
+}
+
+func hangingSegment(t0, t1 time.Duration) *Segment {
+	s := &Segment{Text: "!!!", T0: t0, T1: t1}
+	for idx := 0; idx < 3; idx++ {
+		s.Tokens = append(s.Tokens, Token{
+			Text: "!",
+			T0:   t0 + (t1-t0)*time.Duration(idx)/3,
+			T1:   t0 + (t1-t0)*time.Duration(idx+1)/3,
+		})
+	}
+	return s
+}
+
+func TestCommitAudioHangingSegment(t *testing.T) {
+	t.Run("only", func(t *testing.T) {
+		engine := &fakeEngine{Script: func(_ int, _ []float32, duration time.Duration) []*Segment {
+			return []*Segment{hangingSegment(0, duration-100*time.Millisecond)}
+		}}
+		stt := newTestSTT(t, engine)
+		skipWarmup(stt)
+		writeSilence(t, stt, 3*time.Second)
+		commit(t, stt)
+		require.Empty(t, readTranscripts(stt))
+		require.Equal(t, getBytesPos(2*time.Second), stt.CommittingPosBytes)
+	})
+	t.Run("after-speech", func(t *testing.T) {
+		engine := &fakeEngine{Script: func(_ int, _ []float32, duration time.Duration) []*Segment {
+			return []*Segment{
+				fakeSegment(" hello", 0, time.Second),
+				hangingSegment(time.Second, duration-100*time.Millisecond),
+			}
+		}}
+		stt := newTestSTT(t, engine)
+		skipWarmup(stt)
+		writeSilence(t, stt, 3*time.Second)
+		commit(t, stt)
+		transcripts := readTranscripts(stt)
+		require.Len(t, transcripts, 1)
+		require.Equal(t, speech.Text(" hello"), transcripts[0].Variants[0].Text)
+		require.True(t, transcripts[0].IsFinal)
+
+		// the hanging segment is committed (to not be re-decoded)
+		require.Equal(t, getBytesPos(2900*time.Millisecond), stt.CommittingPosBytes)
+	})
+}
+
+func TestCommitAudioNoUsefulSegments(t *testing.T) {
+	engine := &fakeEngine{Script: func(call int, _ []float32, duration time.Duration) []*Segment {
+		// growing, to not trigger the silent tail workaround
+		return []*Segment{fakeSegment(" ..."+strings.Repeat(".", call), 0, duration-100*time.Millisecond)}
+	}}
+	stt := newTestSTT(t, engine)
+	skipWarmup(stt)
+	writeSilence(t, stt, time.Second)
+	for i := uint(1); i < CommitPolicyBalanced.DiscardIfNoUsefulSegmentsIterations; i++ {
+		writeSilence(t, stt, time.Second)
+		commit(t, stt)
+		require.Zero(t, stt.CommittingPosBytes, i)
+		require.Equal(t, i, stt.NoUsefulSegmentsIterations)
+	}
+
+	writeSilence(t, stt, time.Second)
+	bufLen := uint64(len(stt.NextBuffer))
+	commit(t, stt)
+	require.Equal(t, bufLen-getBytesPos(CommitPolicyBalanced.PreserveHeadingDuration), stt.CommittingPosBytes)
+	require.Zero(t, stt.NoUsefulSegmentsIterations)
+	require.Empty(t, readTranscripts(stt))
+}
+
+func TestCommitAudioSilentTailWorkaround(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		secondText  string
+		expectFinal bool
+	}{
+		// the same segment is just stretched over the silence after it
+		{name: "stretched", secondText: " hello", expectFinal: true},
+		// the segment actually continues
+		{name: "continued", secondText: " hello world", expectFinal: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := &fakeEngine{Script: func(call int, _ []float32, duration time.Duration) []*Segment {
+				if call == 0 {
+					return []*Segment{fakeSegment(" hello", 500*time.Millisecond, 1200*time.Millisecond)}
+				}
+				return []*Segment{fakeSegment(tc.secondText, 500*time.Millisecond, duration-100*time.Millisecond)}
+			}}
+			stt := newTestSTT(t, engine)
+			skipWarmup(stt)
+
+			writeSilence(t, stt, 2*time.Second)
+			commit(t, stt)
+			transcripts := readTranscripts(stt)
+			require.Len(t, transcripts, 1)
+			require.False(t, transcripts[0].IsFinal)
+			require.Zero(t, stt.CommittingPosBytes)
+
+			writeSilence(t, stt, 1500*time.Millisecond)
+			commit(t, stt)
+			require.Equal(t, []time.Duration{2 * time.Second, 3500 * time.Millisecond}, engine.Calls)
+			transcripts = readTranscripts(stt)
+			require.Len(t, transcripts, 1)
+			require.Equal(t, speech.Text(tc.secondText), transcripts[0].Variants[0].Text)
+			require.Equal(t, tc.expectFinal, transcripts[0].IsFinal)
+		})
+	}
+}
+
+func leadingSilence(samples []float32) time.Duration {
+	for idx, sample := range samples {
+		if math.Abs(float64(sample)) > 0.1 {
+			return getDurationFromBytes(uint64(idx) * 4)
+		}
+	}
+	return getDurationFromBytes(uint64(len(samples)) * 4)
+}
+
+func TestCommitAudioVAD(t *testing.T) {
+	var sent []time.Duration
+	engine := &fakeEngine{Script: func(_ int, samples []float32, duration time.Duration) []*Segment {
+		sent = append(sent, leadingSilence(samples))
+		return nil
+	}}
+	stt := newTestSTT(t, engine)
+	stt.VAD = fakeVAD{}
+	stt.VADThreshold = 0.5
+	skipWarmup(stt)
+
+	// silence is discarded without running the inference
+	writeSilence(t, stt, 3*time.Second)
+	commit(t, stt)
+	require.Empty(t, engine.Calls)
+	require.Equal(t, getBytesPos(2*time.Second), stt.CommittingPosBytes)
+
+	// the silence before the voice is cut away (except the heading to preserve)
+	writeSilence(t, stt, 500*time.Millisecond)
+	writeVoice(t, stt, 2*time.Second)
+	commit(t, stt)
+	require.Len(t, engine.Calls, 1)
+	require.Equal(t, 2*time.Second+CommitPolicyBalanced.PreserveHeadingDuration, engine.Calls[0])
+	require.Equal(t, []time.Duration{CommitPolicyBalanced.PreserveHeadingDuration}, sent)
+}
+
+func TestCommitAudioVADOffset(t *testing.T) {
+	engine := &fakeEngine{}
+	stt := newTestSTT(t, engine)
+	stt.VAD = fakeVAD{}
+	stt.VADThreshold = 0.5
+	skipWarmup(stt)
+
+	writeSilence(t, stt, 3*time.Second)
+	commit(t, stt)
+	// the VAD skips the checked silence next time, except VADKeepContext
+	require.Equal(t, getBytesPos(2*time.Second), stt.CommittingPosBytes)
+	require.Equal(t, getBytesPos(3*time.Second-stt.VADKeepContext), stt.VADCheckedUntilBytes)
+
+	// the voice starts at 3.5s, while the VAD checks the audio since 2.5s:
+	// counting the offset from CommittingPosBytes (2s) would point to 3s
+	writeSilence(t, stt, 500*time.Millisecond)
+	writeVoice(t, stt, 2*time.Second)
+	commit(t, stt)
+	require.Equal(t, getBytesPos(3500*time.Millisecond), stt.VADCheckedUntilBytes)
+	require.Equal(t, []time.Duration{CommitPolicyBalanced.PreserveHeadingDuration + 2*time.Second}, engine.Calls)
+}
+
+func TestCommitAudioVADKeepContextExceedsBuffer(t *testing.T) {
+	engine := &fakeEngine{}
+	stt := newTestSTT(t, engine)
+	stt.VAD = fakeVAD{}
+	stt.VADThreshold = 0.5
+	stt.VADKeepContext = 3 * time.Second
+	skipWarmup(stt)
+
+	for i := 0; i < 2; i++ {
+		writeSilence(t, stt, 2*time.Second)
+		commit(t, stt)
+		require.LessOrEqual(t, stt.VADCheckedUntilBytes, stt.CommittingPosBytes)
+	}
+	require.Empty(t, engine.Calls)
+}
+
+func TestCommitAudioSuspectedHallucination(t *testing.T) {
+	rules := OptionHallucinationRules([]hallucination.Rule{{
+		Name:    "test",
+		Type:    hallucination.RuleTypeNormalized,
+		Pattern: "thanks for watching",
+	}})
+	for _, emitAnnotations := range []bool{false, true} {
+		engine := &fakeEngine{Script: func(_ int, _ []float32, duration time.Duration) []*Segment {
+			return []*Segment{fakeSegment(" Thanks for watching!", 0, time.Second)}
+		}}
+		stt := newTestSTT(t, engine, rules, OptionEmitAnnotations(emitAnnotations))
+		writeSilence(t, stt, 3*time.Second)
+		commit(t, stt)
+		transcripts := readTranscripts(stt)
+		if !emitAnnotations {
+			require.Empty(t, transcripts)
+			continue
+		}
+		require.Len(t, transcripts, 1)
+		require.Equal(t, speech.TranscriptKindSuspectedHallucination, transcripts[0].Kind)
+	}
+}
+
+func TestCommitAudioAnnotations(t *testing.T) {
+	for _, emitAnnotations := range []bool{false, true} {
+		engine := &fakeEngine{Script: func(_ int, _ []float32, duration time.Duration) []*Segment {
+			return []*Segment{
+				fakeSegment(" [music]", 0, time.Second),
+				fakeSegment(" hello", time.Second, 2*time.Second),
+			}
+		}}
+		stt := newTestSTT(t, engine, OptionEmitAnnotations(emitAnnotations))
+		writeSilence(t, stt, 3*time.Second)
+		commit(t, stt)
+		transcripts := readTranscripts(stt)
+		var kinds []speech.TranscriptKind
+		for _, transcript := range transcripts {
+			kinds = append(kinds, transcript.Kind)
+		}
+		if emitAnnotations {
+			require.Equal(t, []speech.TranscriptKind{speech.TranscriptKindMusic, speech.TranscriptKindSpeech}, kinds)
+		} else {
+			require.Equal(t, []speech.TranscriptKind{speech.TranscriptKindSpeech}, kinds)
+		}
+	}
+}
+
+func TestCommitAudioCarryForwardPrompt(t *testing.T) {
+	engine := &fakeEngine{Script: func(call int, _ []float32, duration time.Duration) []*Segment {
+		return []*Segment{fakeSegment(" first", 0, time.Second)}
+	}}
+	stt := newTestSTT(t, engine, OptionInitialPrompt("Glossary:"), OptionCarryForwardPrompt(true))
+	require.Equal(t, []string{"Glossary:"}, engine.Prompts)
+	writeSilence(t, stt, 3*time.Second)
+	commit(t, stt)
+	require.Equal(t, []string{"Glossary:", "Glossary: first"}, engine.Prompts)
+}
+
+func TestNewWithEngine(t *testing.T) {
+	ctx := context.Background()
+	engine := &fakeEngine{Script: func(_ int, _ []float32, duration time.Duration) []*Segment {
+		return []*Segment{fakeSegment(" hello", 0, 500*time.Millisecond)}
+	}}
+	policy := CommitPolicyBalanced
+	policy.IterationInterval = 10 * time.Millisecond
+	policy.MinSendingDuration = time.Second
+	stt, err := NewWithEngine(ctx, engine, hallucination.Model{}, 0, OptionCommitPolicy(policy))
+	require.NoError(t, err)
+
+	writeSilence(t, stt, time.Second)
+	ch, err := stt.OutputChan(ctx)
+	require.NoError(t, err)
+	select {
+	case transcript := <-ch:
+		require.Equal(t, speech.Text(" hello"), transcript.Variants[0].Text)
+		require.Equal(t, speech.Language("en"), transcript.Language)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout")
+	}
+
+	require.NoError(t, stt.Close())
+	for range ch {
+	}
+	require.True(t, engine.IsClosed)
+}
+
+func TestCommitAudioWordTimestamps(t *testing.T) {
+	engine := &fakeEngine{Script: func(_ int, _ []float32, duration time.Duration) []*Segment {
+		return []*Segment{{
+			Text: " hello",
+			T0:   0,
+			T1:   500 * time.Millisecond,
+			Tokens: []Token{
+				{Text: "[_BEG_]"},
+				{Text: " hel", P: 0.8, T0: 0, T1: 200 * time.Millisecond, IsDTWAligned: true},
+				{Text: "lo", P: 0.6, T0: 200 * time.Millisecond, T1: 500 * time.Millisecond, IsDTWAligned: true},
+			},
+		}}
+	}}
+	stt := newTestSTT(t, engine, OptionWordTimestamps(true))
+	skipWarmup(stt)
+	writeSilence(t, stt, 3*time.Second)
+	commit(t, stt)
+
+	transcripts := readTranscripts(stt)
+	require.Len(t, transcripts, 1)
+	tokens := transcripts[0].Variants[0].TranscriptTokens
+	require.Len(t, tokens, 1)
+	require.Equal(t, speech.Text("hello"), tokens[0].Text)
+	require.Equal(t, time.Duration(0), tokens[0].StartTime)
+	require.Equal(t, 500*time.Millisecond, tokens[0].EndTime)
+	require.InDelta(t, 0.7, tokens[0].Confidence, 0.0001)
+	require.True(t, tokens[0].IsDTWAligned)
+}
+
+type closeTrackingDiarizer struct {
+	isClosed bool
+}
+
+func (d *closeTrackingDiarizer) IdentifySpeaker(context.Context, []float32, audio.SampleRate) (diarization.SpeakerID, error) {
+	return 1, nil
+}
+
+func (d *closeTrackingDiarizer) Close() error {
+	d.isClosed = true
+	return nil
+}
+
+type closeTrackingVAD struct {
+	fakeVAD
+	isClosed bool
+}
+
+func (v *closeTrackingVAD) Close() error {
+	v.isClosed = true
+	return nil
+}
+
+func TestNewClosesDiarizerOnFailure(t *testing.T) {
+	diarizer := &closeTrackingDiarizer{}
+	vad := &closeTrackingVAD{}
+	_, err := newSpeechToText(context.Background(), &fakeEngine{}, hallucination.Model{}, 0, Options{
+		OptionDiarizer{Diarizer: diarizer},
+		OptionVAD{VAD: vad},
+		OptionEndpointing(DefaultEndpointing),
+	}.config())
+	require.ErrorAs(t, err, &ErrEndpointingRequiresVAD{})
+	require.True(t, diarizer.isClosed)
+	require.True(t, vad.isClosed)
+}
@@ -0,0 +1,118 @@
+package whisper
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+// TranslationLanguage is the language whisper translates to.
+const TranslationLanguage = speech.Language("en")
+
+// engineSegments returns the segments recognized by the last Full call.
+func engineSegments(engine InferenceEngine) []*Segment {
+	numSegments := engine.NumSegments()
+	segments := make([]*Segment, 0, numSegments)
+	for idx := 0; idx < numSegments; idx++ {
+		segments = append(segments, engine.Segment(idx))
+	}
+	return segments
+}
+
+// translateSegments runs the inference on the samples once again, but
+// translating them, and returns the translated segments for each of the given
+// segments (the segments of the transcription and of the translation
+// do not match one-to-one, so they are matched by the time overlap).
+func (stt *SpeechToText) translateSegments(
+	ctx context.Context,
+	samples []float32,
+	segments []*Segment,
+) ([][]*Segment, error) {
+	stt.Engine.SetTranslate(true)
+	defer stt.Engine.SetTranslate(false)
+	if err := stt.Engine.Full(ctx, samples); err != nil {
+		return nil, err
+	}
+	return matchTranslations(segments, engineSegments(stt.Engine)), nil
+}
+
+// matchTranslations assigns each translated segment to the segment
+// it overlaps with the most.
+func matchTranslations(
+	segments []*Segment,
+	translated []*Segment,
+) [][]*Segment {
+	result := make([][]*Segment, len(segments))
+	if len(segments) == 0 {
+		return result
+	}
+	for _, t := range translated {
+		bestIdx, bestOverlap := 0, time.Duration(math.MinInt64)
+		for idx, s := range segments {
+			overlap := min(s.T1, t.T1) - max(s.T0, t.T0)
+			if overlap > bestOverlap {
+				bestIdx, bestOverlap = idx, overlap
+			}
+		}
+		result[bestIdx] = append(result[bestIdx], t)
+	}
+	return result
+}
+
+// translationTranscript returns the transcript of the translation
+// of the transcript t (or nil if there is no translation).
+func translationTranscript(
+	t *speech.Transcript,
+	translation []*Segment,
+	committingPos time.Duration,
+	speaker string,
+) *speech.Transcript {
+	if t.Kind != speech.TranscriptKindSpeech {
+		return nil
+	}
+
+	var (
+		text   strings.Builder
+		tokens []speech.TranscriptToken
+	)
+	for _, s := range translation {
+		text.WriteString(s.Text)
+		for _, token := range s.Tokens {
+			tokens = append(tokens, speech.TranscriptToken{
+				StartTime:  token.T0 + committingPos,
+				EndTime:    token.T1 + committingPos,
+				Text:       speech.Text(token.Text),
+				Confidence: token.P,
+				Speaker:    speaker,
+			})
+		}
+	}
+	if !containsAlphaNum(text.String()) {
+		return nil
+	}
+
+	// the translation is not stabilized by LocalAgreement
+	var stability float32
+	if t.IsFinal {
+		stability = 1
+	}
+	return &speech.Transcript{
+		Variants: []speech.TranscriptVariant{{
+			Text:             speech.Text(text.String()),
+			TranscriptTokens: tokens,
+			Confidence:       0.5,
+		}},
+		Stability:           stability,
+		NoSpeechProbability: t.NoSpeechProbability,
+		AudioChannelNum:     t.AudioChannelNum,
+		Language:            TranslationLanguage,
+		IsFinal:             t.IsFinal,
+		Kind:                t.Kind,
+		SegmentID:           t.SegmentID,
+		Revision:            t.Revision,
+		IsTranslation:       true,
+	}
+}
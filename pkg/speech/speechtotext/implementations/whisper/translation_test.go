@@ -0,0 +1,75 @@
+package whisper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+func TestMatchTranslations(t *testing.T) {
+	segments := []*Segment{
+		fakeSegment(" Hallo Welt.", 0, time.Second),
+		fakeSegment(" Wie geht's?", time.Second, 2*time.Second),
+	}
+	translated := []*Segment{
+		fakeSegment(" Hello", 0, 400*time.Millisecond),
+		fakeSegment(" world.", 400*time.Millisecond, 1100*time.Millisecond),
+		fakeSegment(" How are you?", 1100*time.Millisecond, 2500*time.Millisecond),
+	}
+	require.Equal(t, [][]*Segment{
+		translated[:2],
+		translated[2:],
+	}, matchTranslations(segments, translated))
+	require.Equal(t, [][]*Segment{nil, nil}, matchTranslations(segments, nil))
+	require.Empty(t, matchTranslations(nil, translated))
+}
+
+func TestCommitAudioEmitTranslation(t *testing.T) {
+	engine := &fakeEngine{
+		Script: func(_ int, _ []float32, _ time.Duration) []*Segment {
+			return []*Segment{
+				fakeSegment(" Hallo Welt.", 0, time.Second),
+				fakeSegment(" [Musik]", time.Second, 1500*time.Millisecond),
+				fakeSegment(" Wie geht's?", 1500*time.Millisecond, 2900*time.Millisecond),
+			}
+		},
+		TranslationScript: func(_ []float32, _ time.Duration) []*Segment {
+			return []*Segment{
+				fakeSegment(" Hello world.", 0, time.Second),
+				fakeSegment(" How are you?", 1500*time.Millisecond, 2900*time.Millisecond),
+			}
+		},
+	}
+	stt := newTestSTT(t, engine, OptionEmitTranslation(true))
+	skipWarmup(stt)
+	writeSilence(t, stt, 3*time.Second)
+	commit(t, stt)
+	require.Equal(t, 1, engine.TranslationCalls)
+	require.False(t, engine.IsTranslating)
+
+	type summary struct {
+		Text          string
+		Language      speech.Language
+		SegmentID     speech.SegmentID
+		IsFinal       bool
+		IsTranslation bool
+	}
+	var summaries []summary
+	for _, transcript := range readTranscripts(stt) {
+		summaries = append(summaries, summary{
+			Text:          string(transcript.Variants[0].Text),
+			Language:      transcript.Language,
+			SegmentID:     transcript.SegmentID,
+			IsFinal:       transcript.IsFinal,
+			IsTranslation: transcript.IsTranslation,
+		})
+	}
+	require.Equal(t, []summary{
+		{Text: " Hallo Welt.", Language: "en", SegmentID: 1, IsFinal: true},
+		{Text: " Hello world.", Language: TranslationLanguage, SegmentID: 1, IsFinal: true, IsTranslation: true},
+		{Text: " Wie geht's?", Language: "en", SegmentID: 2},
+		{Text: " How are you?", Language: TranslationLanguage, SegmentID: 2, IsTranslation: true},
+	}, summaries)
+}
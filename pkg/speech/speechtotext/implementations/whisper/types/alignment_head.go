@@ -0,0 +1,70 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AlignmentHead is an attention head of a text layer of the model, which
+// is used to align the tokens to the audio with DTW (see the "custom"
+// AlignmentAheadsPreset).
+type AlignmentHead struct {
+	TextLayer int
+	Head      int
+}
+
+func (h AlignmentHead) String() string {
+	return fmt.Sprintf("%d:%d", h.TextLayer, h.Head)
+}
+
+// AlignmentHeads is a list of the heads in format "layer:head,layer:head,...".
+type AlignmentHeads []AlignmentHead
+
+// String just implements fmt.Stringer, flag.Value and pflag.Value.
+func (s AlignmentHeads) String() string {
+	result := make([]string, 0, len(s))
+	for _, head := range s {
+		result = append(result, head.String())
+	}
+	return strings.Join(result, ",")
+}
+
+// Set just implements flag.Value and pflag.Value.
+func (s *AlignmentHeads) Set(value string) error {
+	heads, err := ParseAlignmentHeads(value)
+	if err != nil {
+		return err
+	}
+	*s = heads
+	return nil
+}
+
+// Type just implements pflag.Value.
+func (s *AlignmentHeads) Type() string {
+	return "AlignmentHeads"
+}
+
+func ParseAlignmentHeads(in string) (AlignmentHeads, error) {
+	var result AlignmentHeads
+	for _, item := range strings.Split(in, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		layerString, headString, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid alignment head '%s', expected format 'layer:head'", item)
+		}
+		layer, err := strconv.ParseUint(layerString, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid text layer in alignment head '%s': %w", item, err)
+		}
+		head, err := strconv.ParseUint(headString, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid head in alignment head '%s': %w", item, err)
+		}
+		result = append(result, AlignmentHead{TextLayer: int(layer), Head: int(head)})
+	}
+	return result, nil
+}
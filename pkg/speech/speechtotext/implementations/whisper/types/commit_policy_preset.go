@@ -0,0 +1,62 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+type CommitPolicyPreset speechtotext_grpc.WhisperCommitPolicyPreset
+
+const (
+	CommitPolicyPresetBalanced   = CommitPolicyPreset(speechtotext_grpc.WhisperCommitPolicyPreset_WhisperCommitPolicyPresetBalanced)
+	CommitPolicyPresetLowLatency = CommitPolicyPreset(speechtotext_grpc.WhisperCommitPolicyPreset_WhisperCommitPolicyPresetLowLatency)
+	CommitPolicyPresetAccuracy   = CommitPolicyPreset(speechtotext_grpc.WhisperCommitPolicyPreset_WhisperCommitPolicyPresetAccuracy)
+)
+
+// String just implements fmt.Stringer, flag.Value and pflag.Value.
+func (p CommitPolicyPreset) String() string {
+	switch p {
+	case CommitPolicyPresetBalanced:
+		return "balanced"
+	case CommitPolicyPresetLowLatency:
+		return "low-latency"
+	case CommitPolicyPresetAccuracy:
+		return "accuracy"
+	}
+	return fmt.Sprintf("unknown_%d", p)
+}
+
+// Set updates the value based on the passed string value.
+// This method just implements flag.Value and pflag.Value.
+func (p *CommitPolicyPreset) Set(value string) error {
+	newValue, err := ParseCommitPolicyPreset(value)
+	if err != nil {
+		return err
+	}
+	*p = newValue
+	return nil
+}
+
+// Type just implements pflag.Value.
+func (p *CommitPolicyPreset) Type() string {
+	return "CommitPolicyPreset"
+}
+
+func ParseCommitPolicyPreset(in string) (CommitPolicyPreset, error) {
+	switch strings.ToLower(in) {
+	case "balanced":
+		return CommitPolicyPresetBalanced, nil
+	case "low-latency":
+		return CommitPolicyPresetLowLatency, nil
+	case "accuracy":
+		return CommitPolicyPresetAccuracy, nil
+	}
+	var allowedValues []string
+	for p := CommitPolicyPresetBalanced; p <= CommitPolicyPresetAccuracy; p++ {
+		allowedValues = append(allowedValues, p.String())
+	}
+	return CommitPolicyPresetBalanced, fmt.Errorf("unknown commit policy preset '%s', known values are: %s",
+		in, strings.Join(allowedValues, ", "))
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+// DecodingParamsFlags are command line flags for the whisper decoding
+// parameters; only the flags explicitly set are passed to whisper.
+type DecodingParamsFlags struct {
+	flagSet *pflag.FlagSet
+
+	beamSize                uint32
+	bestOf                  uint32
+	temperature             float32
+	temperatureInc          float32
+	entropyThreshold        float32
+	logprobThreshold        float32
+	noSpeechThreshold       float32
+	suppressBlank           bool
+	suppressNonSpeechTokens bool
+	maxSegmentLength        uint32
+	splitOnWord             bool
+	threads                 uint32
+	noContext               bool
+}
+
+func AddDecodingParamsFlags(flagSet *pflag.FlagSet) *DecodingParamsFlags {
+	f := &DecodingParamsFlags{flagSet: flagSet}
+	flagSet.Uint32Var(&f.beamSize, "beam-size", 0, "the amount of beams for the beam search sampling strategy")
+	flagSet.Uint32Var(&f.bestOf, "best-of", 0, "the amount of candidates for the greedy sampling strategy")
+	flagSet.Float32Var(&f.temperature, "temperature", 0, "the initial sampling temperature")
+	flagSet.Float32Var(&f.temperatureInc, "temperature-inc", 0, "the temperature increment on a decoding failure; 0 disables the fallback")
+	flagSet.Float32Var(&f.entropyThreshold, "entropy-threshold", 0, "the entropy of tokens above which the decoding is considered failed")
+	flagSet.Float32Var(&f.logprobThreshold, "logprob-threshold", 0, "the average log probability of tokens below which the decoding is considered failed")
+	flagSet.Float32Var(&f.noSpeechThreshold, "no-speech-threshold", 0, "the probability of no speech above which a segment is considered silent")
+	flagSet.BoolVar(&f.suppressBlank, "suppress-blank", false, "suppress blank outputs")
+	flagSet.BoolVar(&f.suppressNonSpeechTokens, "suppress-non-speech-tokens", false, "suppress tokens like \"[music]\"")
+	flagSet.Uint32Var(&f.maxSegmentLength, "max-segment-length", 0, "the maximal length of a segment in characters; 0 means no limit")
+	flagSet.BoolVar(&f.splitOnWord, "split-on-word", false, "split segments on words instead of tokens")
+	flagSet.Uint32Var(&f.threads, "threads", 0, "the amount of CPU threads to use")
+	flagSet.BoolVar(&f.noContext, "no-context", false, "do not use the previously decoded text as the prompt")
+	return f
+}
+
+// GRPC returns the parameters explicitly set through the flags.
+func (f *DecodingParamsFlags) GRPC() *speechtotext_grpc.WhisperDecodingParams {
+	p := &speechtotext_grpc.WhisperDecodingParams{}
+	if f.flagSet.Changed("beam-size") {
+		p.BeamSize = &f.beamSize
+	}
+	if f.flagSet.Changed("best-of") {
+		p.BestOf = &f.bestOf
+	}
+	if f.flagSet.Changed("temperature") {
+		p.Temperature = &f.temperature
+	}
+	if f.flagSet.Changed("temperature-inc") {
+		p.TemperatureInc = &f.temperatureInc
+	}
+	if f.flagSet.Changed("entropy-threshold") {
+		p.EntropyThreshold = &f.entropyThreshold
+	}
+	if f.flagSet.Changed("logprob-threshold") {
+		p.LogprobThreshold = &f.logprobThreshold
+	}
+	if f.flagSet.Changed("no-speech-threshold") {
+		p.NoSpeechThreshold = &f.noSpeechThreshold
+	}
+	if f.flagSet.Changed("suppress-blank") {
+		p.SuppressBlank = &f.suppressBlank
+	}
+	if f.flagSet.Changed("suppress-non-speech-tokens") {
+		p.SuppressNonSpeechTokens = &f.suppressNonSpeechTokens
+	}
+	if f.flagSet.Changed("max-segment-length") {
+		p.MaxSegmentLength = &f.maxSegmentLength
+	}
+	if f.flagSet.Changed("split-on-word") {
+		p.SplitOnWord = &f.splitOnWord
+	}
+	if f.flagSet.Changed("threads") {
+		p.Threads = &f.threads
+	}
+	if f.flagSet.Changed("no-context") {
+		p.NoContext = &f.noContext
+	}
+	return p
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+// EndpointingParamsFlags are command line flags for the endpointing
+// parameters; only the flags explicitly set are passed to whisper.
+type EndpointingParamsFlags struct {
+	flagSet *pflag.FlagSet
+
+	enabled            bool
+	minSilence         time.Duration
+	maxUtteranceLength time.Duration
+}
+
+func AddEndpointingParamsFlags(flagSet *pflag.FlagSet) *EndpointingParamsFlags {
+	f := &EndpointingParamsFlags{flagSet: flagSet}
+	flagSet.BoolVar(&f.enabled, "endpointing", false, "cut the audio into utterances at the pauses detected by the VAD, and transcribe each utterance once (instead of following --commit-policy)")
+	flagSet.DurationVar(&f.minSilence, "endpointing-min-silence", 0, "the minimal pause to consider an utterance finished")
+	flagSet.DurationVar(&f.maxUtteranceLength, "endpointing-max-utterance-length", 0, "the maximal duration of an utterance")
+	return f
+}
+
+// GRPC returns the parameters explicitly set through the flags;
+// it returns nil if the endpointing is not enabled.
+func (f *EndpointingParamsFlags) GRPC() *speechtotext_grpc.WhisperEndpointingParams {
+	if !f.enabled {
+		return nil
+	}
+	p := &speechtotext_grpc.WhisperEndpointingParams{}
+	if f.flagSet.Changed("endpointing-min-silence") {
+		ms := uint32(f.minSilence.Milliseconds())
+		p.MinSilenceMS = &ms
+	}
+	if f.flagSet.Changed("endpointing-max-utterance-length") {
+		ms := uint32(f.maxUtteranceLength.Milliseconds())
+		p.MaxUtteranceLengthMS = &ms
+	}
+	return p
+}
@@ -0,0 +1,69 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+// VADBackend selects the implementation of the voice activity detection.
+type VADBackend speechtotext_grpc.WhisperVADBackend
+
+const (
+	// VADBackendAuto is RNNoise if it is compiled in (see build tag "rnnoise"),
+	// otherwise libfvad if it is compiled in (requires cgo),
+	// otherwise Energy.
+	VADBackendAuto    = VADBackend(speechtotext_grpc.WhisperVADBackend_WhisperVADBackendAuto)
+	VADBackendNone    = VADBackend(speechtotext_grpc.WhisperVADBackend_WhisperVADBackendNone)
+	VADBackendEnergy  = VADBackend(speechtotext_grpc.WhisperVADBackend_WhisperVADBackendEnergy)
+	VADBackendLibFVAD = VADBackend(speechtotext_grpc.WhisperVADBackend_WhisperVADBackendLibFVAD)
+	VADBackendRNNoise = VADBackend(speechtotext_grpc.WhisperVADBackend_WhisperVADBackendRNNoise)
+)
+
+// String just implements fmt.Stringer, flag.Value and pflag.Value.
+func (b VADBackend) String() string {
+	switch b {
+	case VADBackendAuto:
+		return "auto"
+	case VADBackendNone:
+		return "none"
+	case VADBackendEnergy:
+		return "energy"
+	case VADBackendLibFVAD:
+		return "libfvad"
+	case VADBackendRNNoise:
+		return "rnnoise"
+	}
+	return fmt.Sprintf("unknown_%d", b)
+}
+
+// Set updates the value based on the passed string value.
+// This method just implements flag.Value and pflag.Value.
+func (b *VADBackend) Set(value string) error {
+	newValue, err := ParseVADBackend(value)
+	if err != nil {
+		return err
+	}
+	*b = newValue
+	return nil
+}
+
+// Type just implements pflag.Value.
+func (b *VADBackend) Type() string {
+	return "VADBackend"
+}
+
+func ParseVADBackend(in string) (VADBackend, error) {
+	for b := VADBackendAuto; b <= VADBackendRNNoise; b++ {
+		if strings.EqualFold(in, b.String()) {
+			return b, nil
+		}
+	}
+	var allowedValues []string
+	for b := VADBackendAuto; b <= VADBackendRNNoise; b++ {
+		allowedValues = append(allowedValues, b.String())
+	}
+	return VADBackendAuto, fmt.Errorf("unknown VAD backend '%s', known values are: %s",
+		in, strings.Join(allowedValues, ", "))
+}
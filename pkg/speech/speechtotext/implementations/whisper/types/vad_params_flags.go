@@ -0,0 +1,47 @@
+package types
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+// VADParamsFlags are command line flags for the VAD parameters;
+// only the flags explicitly set are passed to whisper.
+type VADParamsFlags struct {
+	flagSet *pflag.FlagSet
+
+	backend          VADBackend
+	libFVADMode      int32
+	minVoiceDuration time.Duration
+	keepContext      time.Duration
+}
+
+func AddVADParamsFlags(flagSet *pflag.FlagSet) *VADParamsFlags {
+	f := &VADParamsFlags{flagSet: flagSet}
+	flagSet.Var(&f.backend, "vad", "the VAD implementation: auto, none, energy, libfvad or rnnoise")
+	flagSet.Int32Var(&f.libFVADMode, "vad-libfvad-mode", 0, "the aggressiveness of libfvad about filtering out non-speech: from 0 to 3")
+	flagSet.DurationVar(&f.minVoiceDuration, "vad-min-voice-duration", 0, "the minimal duration of the voice to consider the audio to contain speech")
+	flagSet.DurationVar(&f.keepContext, "vad-keep-context", 0, "how much of the audio before the detected voice to keep")
+	return f
+}
+
+// GRPC returns the parameters explicitly set through the flags.
+func (f *VADParamsFlags) GRPC() *speechtotext_grpc.WhisperVADParams {
+	p := &speechtotext_grpc.WhisperVADParams{
+		Backend: speechtotext_grpc.WhisperVADBackend(f.backend),
+	}
+	if f.flagSet.Changed("vad-libfvad-mode") {
+		p.LibFVADMode = &f.libFVADMode
+	}
+	if f.flagSet.Changed("vad-min-voice-duration") {
+		ms := uint32(f.minVoiceDuration.Milliseconds())
+		p.MinVoiceDurationMS = &ms
+	}
+	if f.flagSet.Changed("vad-keep-context") {
+		ms := uint32(f.keepContext.Milliseconds())
+		p.KeepContextMS = &ms
+	}
+	return p
+}
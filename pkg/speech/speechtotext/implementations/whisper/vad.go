@@ -0,0 +1,66 @@
+package whisper
+
+import (
+	"context"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/audio/pkg/vad"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
+	"github.com/xaionaro-go/speech/pkg/speech/vad/energy"
+)
+
+const (
+	// DefaultVADLibFVADMode is the most aggressive mode
+	// about filtering out non-speech.
+	DefaultVADLibFVADMode = 3
+)
+
+// resolveVADBackend returns the backend to be used for types.VADBackendAuto.
+func resolveVADBackend(backend types.VADBackend) types.VADBackend {
+	if backend != types.VADBackendAuto {
+		return backend
+	}
+	switch {
+	case isRNNoiseSupported:
+		return types.VADBackendRNNoise
+	case isLibFVADSupported:
+		return types.VADBackendLibFVAD
+	default:
+		return types.VADBackendEnergy
+	}
+}
+
+// vadDefaults returns the default minimal voice duration and the default
+// duration of the context kept before the voice for the VAD backend.
+func vadDefaults(backend types.VADBackend) (minVoiceDuration, keepContext time.Duration) {
+	switch backend {
+	case types.VADBackendLibFVAD:
+		return 150 * time.Millisecond, time.Second
+	case types.VADBackendEnergy:
+		// the energy is a crude signal, so it is better to be more careful
+		return 100 * time.Millisecond, 500 * time.Millisecond
+	default:
+		return time.Nanosecond, 0
+	}
+}
+
+func (stt *SpeechToText) newVAD(
+	ctx context.Context,
+	backend types.VADBackend,
+	libfvadMode int,
+) (vad.VAD, error) {
+	logger.Debugf(ctx, "newVAD:%s", backend)
+	switch backend {
+	case types.VADBackendNone:
+		return vad.NewDummy(stt.AudioEncodingNoErr(), stt.AudioChannelsNoErr()), nil
+	case types.VADBackendEnergy:
+		return energy.New(stt.AudioEncodingNoErr().SampleRate), nil
+	case types.VADBackendLibFVAD:
+		return newLibFVAD(libfvadMode)
+	case types.VADBackendRNNoise:
+		return newRNNoiseVAD(ctx)
+	default:
+		return nil, ErrVADBackendIsNotSupported{Backend: backend}
+	}
+}
@@ -1,24 +1,16 @@
-//go:build !no_libfvad && !rnnoise && !windows
+//go:build cgo && !no_libfvad && !windows
 
 package whisper
 
 import (
-	"context"
-	"time"
-
-	"github.com/facebookincubator/go-belt/tool/logger"
 	"github.com/xaionaro-go/audio/pkg/vad"
 	"github.com/xaionaro-go/audio/pkg/vad/implementations/libfvad"
 )
 
-const (
-	VADMinVoiceDuration = 150 * time.Millisecond
-	VADKeepContext      = time.Second
-)
+const isLibFVADSupported = true
 
-func (stt *SpeechToText) newVAD(
-	ctx context.Context,
+func newLibFVAD(
+	mode int,
 ) (vad.VAD, error) {
-	logger.Debugf(ctx, "newVAD:libfvad")
-	return libfvad.NewVAD(16000, 3)
+	return libfvad.NewVAD(16000, mode)
 }
@@ -0,0 +1,16 @@
+//go:build !cgo || no_libfvad || windows
+
+package whisper
+
+import (
+	"github.com/xaionaro-go/audio/pkg/vad"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
+)
+
+const isLibFVADSupported = false
+
+func newLibFVAD(
+	int,
+) (vad.VAD, error) {
+	return nil, ErrVADBackendIsNotSupported{Backend: types.VADBackendLibFVAD}
+}
@@ -1,4 +1,4 @@
-//go:build rnnoise && !windows
+//go:build cgo && rnnoise && !windows
 
 package whisper
 
@@ -7,21 +7,16 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/facebookincubator/go-belt/tool/logger"
 	"github.com/xaionaro-go/audio/pkg/noisesuppression/implementations/rnnoise"
 	"github.com/xaionaro-go/audio/pkg/vad"
 	"github.com/xaionaro-go/audio/pkg/vad/implementations/noisesuppression"
 )
 
-const (
-	VADMinVoiceDuration = time.Nanosecond
-	VADKeepContext      = 0
-)
+const isRNNoiseSupported = true
 
-func (stt *SpeechToText) newVAD(
+func newRNNoiseVAD(
 	ctx context.Context,
 ) (vad.VAD, error) {
-	logger.Debugf(ctx, "newVAD:rnnoise")
 	ns, err := rnnoise.New(1)
 	if err != nil {
 		return nil, fmt.Errorf("unable to initialize a RNNoise: %w", err)
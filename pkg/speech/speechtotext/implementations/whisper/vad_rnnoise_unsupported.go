@@ -0,0 +1,18 @@
+//go:build !cgo || !rnnoise || windows
+
+package whisper
+
+import (
+	"context"
+
+	"github.com/xaionaro-go/audio/pkg/vad"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
+)
+
+const isRNNoiseSupported = false
+
+func newRNNoiseVAD(
+	context.Context,
+) (vad.VAD, error) {
+	return nil, ErrVADBackendIsNotSupported{Backend: types.VADBackendRNNoise}
+}
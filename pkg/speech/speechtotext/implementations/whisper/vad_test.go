@@ -0,0 +1,61 @@
+package whisper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/hallucination"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
+	"github.com/xaionaro-go/speech/pkg/speech/vad/energy"
+)
+
+func newTestSTTWithVAD(engine InferenceEngine, opts ...Option) (*SpeechToText, error) {
+	return newSpeechToText(context.Background(), engine, hallucination.Model{}, 0.5, Options(opts).config())
+}
+
+func TestNewVAD(t *testing.T) {
+	stt, err := newTestSTTWithVAD(&fakeEngine{}, OptionVADBackend(types.VADBackendEnergy))
+	require.NoError(t, err)
+	require.IsType(t, &energy.VAD{}, stt.VAD)
+	require.Equal(t, 100*time.Millisecond, stt.VADMinVoiceDuration)
+	require.Equal(t, 500*time.Millisecond, stt.VADKeepContext)
+
+	stt, err = newTestSTTWithVAD(
+		&fakeEngine{},
+		OptionVAD{VAD: fakeVAD{}},
+		OptionVADBackend(types.VADBackendLibFVAD),
+		OptionVADMinVoiceDuration(time.Second),
+		OptionVADKeepContext(0),
+	)
+	require.NoError(t, err)
+	require.Equal(t, fakeVAD{}, stt.VAD)
+	require.Equal(t, time.Second, stt.VADMinVoiceDuration)
+	require.Equal(t, time.Duration(0), stt.VADKeepContext)
+
+	// the defaults of the backend are not applied to a custom VAD
+	stt, err = newTestSTTWithVAD(
+		&fakeEngine{},
+		OptionVAD{VAD: fakeVAD{}},
+		OptionVADBackend(types.VADBackendLibFVAD),
+	)
+	require.NoError(t, err)
+	require.Equal(t, time.Nanosecond, stt.VADMinVoiceDuration)
+	require.Equal(t, time.Duration(0), stt.VADKeepContext)
+
+	for _, keepContext := range []time.Duration{-time.Second, CommitPolicyBalanced.MinSendingDuration} {
+		_, err = newTestSTTWithVAD(&fakeEngine{}, OptionVAD{VAD: fakeVAD{}}, OptionVADKeepContext(keepContext))
+		require.ErrorAs(t, err, &ErrInvalidVADKeepContext{}, "keepContext: %v", keepContext)
+	}
+
+	if !isLibFVADSupported {
+		_, err = newTestSTTWithVAD(&fakeEngine{}, OptionVADBackend(types.VADBackendLibFVAD))
+		require.ErrorAs(t, err, &ErrInitVAD{})
+		require.ErrorContains(t, err, ErrVADBackendIsNotSupported{Backend: types.VADBackendLibFVAD}.Error())
+	}
+	if !isLibFVADSupported && !isRNNoiseSupported {
+		require.Equal(t, types.VADBackendEnergy, resolveVADBackend(types.VADBackendAuto))
+	}
+	require.Equal(t, types.VADBackendNone, resolveVADBackend(types.VADBackendNone))
+}
@@ -24,6 +24,9 @@ type SpeechToText struct {
 	whisperClient io.ReadWriteCloser
 	cancelFunc    context.CancelFunc
 	resultQueue   chan *speech.Transcript
+
+	// lastSegmentID is used only by the receiving loop.
+	lastSegmentID speech.SegmentID
 }
 
 var _ speech.ToText = (*SpeechToText)(nil)
@@ -95,6 +98,7 @@ func (stt *SpeechToText) loop(ctx context.Context) (_err error) {
 		if err != nil {
 			return fmt.Errorf("unable to parse whisper output '%s' (%X): %w", msg, msg, err)
 		}
+		stt.lastSegmentID++
 		stt.resultQueue <- &speech.Transcript{
 			Variants: []speech.TranscriptVariant{{
 				Text:             speech.Text(text),
@@ -105,6 +109,7 @@ func (stt *SpeechToText) loop(ctx context.Context) (_err error) {
 			AudioChannelNum: 0,
 			Language:        "",
 			IsFinal:         true,
+			SegmentID:       stt.lastSegmentID,
 		}
 	}
 }
@@ -0,0 +1,80 @@
+package goconv
+
+import (
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/hallucination"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+func HallucinationRuleTypeFromGRPC(
+	t speechtotext_grpc.HallucinationRuleType,
+) hallucination.RuleType {
+	switch t {
+	case speechtotext_grpc.HallucinationRuleType_HallucinationRuleTypeExact:
+		return hallucination.RuleTypeExact
+	case speechtotext_grpc.HallucinationRuleType_HallucinationRuleTypeNormalized:
+		return hallucination.RuleTypeNormalized
+	case speechtotext_grpc.HallucinationRuleType_HallucinationRuleTypeRegex:
+		return hallucination.RuleTypeRegex
+	case speechtotext_grpc.HallucinationRuleType_HallucinationRuleTypeEntropy:
+		return hallucination.RuleTypeEntropy
+	}
+	return hallucination.RuleType(t)
+}
+
+func HallucinationRuleTypeToGRPC(
+	t hallucination.RuleType,
+) speechtotext_grpc.HallucinationRuleType {
+	switch t {
+	case hallucination.RuleTypeExact:
+		return speechtotext_grpc.HallucinationRuleType_HallucinationRuleTypeExact
+	case hallucination.RuleTypeNormalized:
+		return speechtotext_grpc.HallucinationRuleType_HallucinationRuleTypeNormalized
+	case hallucination.RuleTypeRegex:
+		return speechtotext_grpc.HallucinationRuleType_HallucinationRuleTypeRegex
+	case hallucination.RuleTypeEntropy:
+		return speechtotext_grpc.HallucinationRuleType_HallucinationRuleTypeEntropy
+	}
+	return speechtotext_grpc.HallucinationRuleType(t)
+}
+
+func HallucinationRulesFromGRPC(
+	rules []*speechtotext_grpc.HallucinationRule,
+) []hallucination.Rule {
+	result := make([]hallucination.Rule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, hallucination.Rule{
+			Name:       rule.GetName(),
+			Type:       HallucinationRuleTypeFromGRPC(rule.GetType()),
+			Pattern:    rule.GetPattern(),
+			MinLength:  int(rule.GetMinLength()),
+			SkipPrefix: int(rule.GetSkipPrefix()),
+			MaxEntropy: rule.GetMaxEntropy(),
+			Scope: hallucination.Scope{
+				ModelHashes:   rule.GetModelHashes(),
+				ModelFamilies: rule.GetModelFamilies(),
+				Languages:     rule.GetLanguages(),
+			},
+		})
+	}
+	return result
+}
+
+func HallucinationRulesToGRPC(
+	rules []hallucination.Rule,
+) []*speechtotext_grpc.HallucinationRule {
+	result := make([]*speechtotext_grpc.HallucinationRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, &speechtotext_grpc.HallucinationRule{
+			Name:          rule.Name,
+			Type:          HallucinationRuleTypeToGRPC(rule.Type),
+			Pattern:       rule.Pattern,
+			MinLength:     int32(rule.MinLength),
+			SkipPrefix:    int32(rule.SkipPrefix),
+			MaxEntropy:    rule.MaxEntropy,
+			ModelHashes:   rule.ModelHashes,
+			ModelFamilies: rule.ModelFamilies,
+			Languages:     rule.Languages,
+		})
+	}
+	return result
+}
@@ -0,0 +1,32 @@
+package goconv
+
+import (
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+func LanguageProbabilitiesToGRPC(
+	probs speech.LanguageProbabilities,
+) []*speechtotext_grpc.LanguageProbability {
+	result := make([]*speechtotext_grpc.LanguageProbability, 0, len(probs))
+	for _, prob := range probs {
+		result = append(result, &speechtotext_grpc.LanguageProbability{
+			Language:    string(prob.Language),
+			Probability: prob.Probability,
+		})
+	}
+	return result
+}
+
+func LanguageProbabilitiesFromGRPC(
+	probs []*speechtotext_grpc.LanguageProbability,
+) speech.LanguageProbabilities {
+	result := make(speech.LanguageProbabilities, 0, len(probs))
+	for _, prob := range probs {
+		result = append(result, speech.LanguageProbability{
+			Language:    speech.Language(prob.GetLanguage()),
+			Probability: prob.GetProbability(),
+		})
+	}
+	return result
+}
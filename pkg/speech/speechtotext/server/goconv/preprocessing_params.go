@@ -0,0 +1,29 @@
+package goconv
+
+import (
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/preprocessing"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+// PreprocessingParamsFromGRPC converts the preprocessing parameters received
+// through gRPC (or command line flags) to preprocessing.Options; it returns
+// no options if the preprocessing is not needed.
+func PreprocessingParamsFromGRPC(p *speechtotext_grpc.PreprocessingParams) preprocessing.Options {
+	var opts preprocessing.Options
+	if p == nil {
+		return opts
+	}
+	if p.GetRemoveDC() {
+		opts = append(opts, preprocessing.OptionRemoveDC(true))
+	}
+	if p.GetHighPassCutoffHz() > 0 {
+		opts = append(opts, preprocessing.OptionHighPass(p.GetHighPassCutoffHz()))
+	}
+	if p.GetDenoise() {
+		opts = append(opts, preprocessing.OptionDenoise(true))
+	}
+	if p.NormalizeLoudnessDBFS != nil {
+		opts = append(opts, preprocessing.OptionNormalizeLoudness(p.GetNormalizeLoudnessDBFS()))
+	}
+	return opts
+}
@@ -0,0 +1,23 @@
+package goconv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/preprocessing"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+func TestPreprocessingParamsFromGRPC(t *testing.T) {
+	require.Empty(t, PreprocessingParamsFromGRPC(nil))
+	require.Empty(t, PreprocessingParamsFromGRPC(&speechtotext_grpc.PreprocessingParams{}))
+
+	loudness := float32(-23)
+	require.Equal(t, preprocessing.Options{
+		preprocessing.OptionRemoveDC(true),
+		preprocessing.OptionNormalizeLoudness(-23),
+	}, PreprocessingParamsFromGRPC(&speechtotext_grpc.PreprocessingParams{
+		RemoveDC:              true,
+		NormalizeLoudnessDBFS: &loudness,
+	}))
+}
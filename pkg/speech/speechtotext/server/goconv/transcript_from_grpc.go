@@ -10,14 +10,34 @@ import (
 
 func TranscriptFromGRPC(t *speechtotext_grpc.Transcript) *speech.Transcript {
 	return &speech.Transcript{
-		Variants:        VariantsFromGRPC(t.GetVariants()),
-		Stability:       t.GetStability(),
-		AudioChannelNum: audio.Channel(t.GetAudioChannelNum()),
-		Language:        speech.Language(t.GetLanguage()),
-		IsFinal:         t.GetIsFinal(),
+		Variants:           VariantsFromGRPC(t.GetVariants()),
+		Stability:          t.GetStability(),
+		AudioChannelNum:    audio.Channel(t.GetAudioChannelNum()),
+		Language:           speech.Language(t.GetLanguage()),
+		IsFinal:            t.GetIsFinal(),
+		Kind:               TranscriptKindFromGRPC(t.GetKind()),
+		SegmentID:          speech.SegmentID(t.GetSegmentID()),
+		Revision:           t.GetRevision(),
+		IsRetracted:        t.GetIsRetracted(),
+		LanguageConfidence: t.GetLanguageConfidence(),
+		IsTranslation:      t.GetIsTranslation(),
 	}
 }
 
+func TranscriptKindFromGRPC(k speechtotext_grpc.TranscriptKind) speech.TranscriptKind {
+	switch k {
+	case speechtotext_grpc.TranscriptKind_TranscriptKindSpeech:
+		return speech.TranscriptKindSpeech
+	case speechtotext_grpc.TranscriptKind_TranscriptKindSoundEvent:
+		return speech.TranscriptKindSoundEvent
+	case speechtotext_grpc.TranscriptKind_TranscriptKindMusic:
+		return speech.TranscriptKindMusic
+	case speechtotext_grpc.TranscriptKind_TranscriptKindSuspectedHallucination:
+		return speech.TranscriptKindSuspectedHallucination
+	}
+	return speech.TranscriptKind(k)
+}
+
 func VariantsFromGRPC(variants []*speechtotext_grpc.TranscriptVariant) speech.TranscriptVariants {
 	result := make(speech.TranscriptVariants, 0, len(variants))
 	for _, variant := range variants {
@@ -44,10 +64,12 @@ func TranscriptTokensFromGRPC(tokens []*speechtotext_grpc.TranscriptToken) speec
 
 func TranscriptTokenFromGRPC(variant *speechtotext_grpc.TranscriptToken) speech.TranscriptToken {
 	return speech.TranscriptToken{
-		StartTime:  time.Duration(variant.GetStartTimeNano()) * time.Nanosecond,
-		EndTime:    time.Duration(variant.GetEndTimeNano()) * time.Nanosecond,
-		Text:       speech.Text(variant.GetText()),
-		Confidence: variant.GetConfidence(),
-		Speaker:    variant.GetSpeaker(),
+		StartTime:    time.Duration(variant.GetStartTimeNano()) * time.Nanosecond,
+		EndTime:      time.Duration(variant.GetEndTimeNano()) * time.Nanosecond,
+		Text:         speech.Text(variant.GetText()),
+		Confidence:   variant.GetConfidence(),
+		Speaker:      variant.GetSpeaker(),
+		Stability:    variant.GetStability(),
+		IsDTWAligned: variant.GetIsDTWAligned(),
 	}
 }
@@ -7,14 +7,34 @@ import (
 
 func TranscriptToGRPC(t *speech.Transcript) *speechtotext_grpc.Transcript {
 	return &speechtotext_grpc.Transcript{
-		Variants:        VariantsToGRPC(t.Variants),
-		Stability:       t.Stability,
-		AudioChannelNum: uint32(t.AudioChannelNum),
-		Language:        string(t.Language),
-		IsFinal:         t.IsFinal,
+		Variants:           VariantsToGRPC(t.Variants),
+		Stability:          t.Stability,
+		AudioChannelNum:    uint32(t.AudioChannelNum),
+		Language:           string(t.Language),
+		IsFinal:            t.IsFinal,
+		Kind:               TranscriptKindToGRPC(t.Kind),
+		SegmentID:          uint64(t.SegmentID),
+		Revision:           t.Revision,
+		IsRetracted:        t.IsRetracted,
+		LanguageConfidence: t.LanguageConfidence,
+		IsTranslation:      t.IsTranslation,
 	}
 }
 
+func TranscriptKindToGRPC(k speech.TranscriptKind) speechtotext_grpc.TranscriptKind {
+	switch k {
+	case speech.TranscriptKindSpeech:
+		return speechtotext_grpc.TranscriptKind_TranscriptKindSpeech
+	case speech.TranscriptKindSoundEvent:
+		return speechtotext_grpc.TranscriptKind_TranscriptKindSoundEvent
+	case speech.TranscriptKindMusic:
+		return speechtotext_grpc.TranscriptKind_TranscriptKindMusic
+	case speech.TranscriptKindSuspectedHallucination:
+		return speechtotext_grpc.TranscriptKind_TranscriptKindSuspectedHallucination
+	}
+	return speechtotext_grpc.TranscriptKind(k)
+}
+
 func VariantsToGRPC(variants speech.TranscriptVariants) []*speechtotext_grpc.TranscriptVariant {
 	result := make([]*speechtotext_grpc.TranscriptVariant, 0, len(variants))
 	for _, variant := range variants {
@@ -46,5 +66,7 @@ func TranscriptTokenToGRPC(variant speech.TranscriptToken) *speechtotext_grpc.Tr
 		Text:          string(variant.Text),
 		Confidence:    variant.Confidence,
 		Speaker:       variant.Speaker,
+		Stability:     variant.Stability,
+		IsDTWAligned:  variant.IsDTWAligned,
 	}
 }
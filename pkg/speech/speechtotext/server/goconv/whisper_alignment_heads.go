@@ -0,0 +1,32 @@
+package goconv
+
+import (
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+func AlignmentHeadsFromGRPC(
+	heads []*speechtotext_grpc.WhisperAlignmentHead,
+) types.AlignmentHeads {
+	result := make(types.AlignmentHeads, 0, len(heads))
+	for _, head := range heads {
+		result = append(result, types.AlignmentHead{
+			TextLayer: int(head.GetTextLayer()),
+			Head:      int(head.GetHead()),
+		})
+	}
+	return result
+}
+
+func AlignmentHeadsToGRPC(
+	heads types.AlignmentHeads,
+) []*speechtotext_grpc.WhisperAlignmentHead {
+	result := make([]*speechtotext_grpc.WhisperAlignmentHead, 0, len(heads))
+	for _, head := range heads {
+		result = append(result, &speechtotext_grpc.WhisperAlignmentHead{
+			TextLayer: uint32(head.TextLayer),
+			Head:      uint32(head.Head),
+		})
+	}
+	return result
+}
@@ -0,0 +1,18 @@
+package goconv
+
+import (
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+func CommitPolicyPresetFromGRPC(
+	p speechtotext_grpc.WhisperCommitPolicyPreset,
+) types.CommitPolicyPreset {
+	return types.CommitPolicyPreset(p)
+}
+
+func CommitPolicyPresetToGRPC(
+	p types.CommitPolicyPreset,
+) speechtotext_grpc.WhisperCommitPolicyPreset {
+	return speechtotext_grpc.WhisperCommitPolicyPreset(p)
+}
@@ -0,0 +1,55 @@
+package goconv
+
+import (
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+// DecodingParamsFromGRPC converts the decoding parameters received
+// through gRPC (or command line flags) to whisper.Options; unset parameters are skipped.
+func DecodingParamsFromGRPC(p *speechtotext_grpc.WhisperDecodingParams) whisper.Options {
+	var opts whisper.Options
+	if p == nil {
+		return opts
+	}
+	if p.BeamSize != nil {
+		opts = append(opts, whisper.OptionBeamSize(p.GetBeamSize()))
+	}
+	if p.BestOf != nil {
+		opts = append(opts, whisper.OptionBestOf(p.GetBestOf()))
+	}
+	if p.Temperature != nil {
+		opts = append(opts, whisper.OptionTemperature(p.GetTemperature()))
+	}
+	if p.TemperatureInc != nil {
+		opts = append(opts, whisper.OptionTemperatureInc(p.GetTemperatureInc()))
+	}
+	if p.EntropyThreshold != nil {
+		opts = append(opts, whisper.OptionEntropyThreshold(p.GetEntropyThreshold()))
+	}
+	if p.LogprobThreshold != nil {
+		opts = append(opts, whisper.OptionLogprobThreshold(p.GetLogprobThreshold()))
+	}
+	if p.NoSpeechThreshold != nil {
+		opts = append(opts, whisper.OptionNoSpeechThreshold(p.GetNoSpeechThreshold()))
+	}
+	if p.SuppressBlank != nil {
+		opts = append(opts, whisper.OptionSuppressBlank(p.GetSuppressBlank()))
+	}
+	if p.SuppressNonSpeechTokens != nil {
+		opts = append(opts, whisper.OptionSuppressNonSpeechTokens(p.GetSuppressNonSpeechTokens()))
+	}
+	if p.MaxSegmentLength != nil {
+		opts = append(opts, whisper.OptionMaxSegmentLength(p.GetMaxSegmentLength()))
+	}
+	if p.SplitOnWord != nil {
+		opts = append(opts, whisper.OptionSplitOnWord(p.GetSplitOnWord()))
+	}
+	if p.Threads != nil {
+		opts = append(opts, whisper.OptionThreads(p.GetThreads()))
+	}
+	if p.NoContext != nil {
+		opts = append(opts, whisper.OptionNoContext(p.GetNoContext()))
+	}
+	return opts
+}
@@ -0,0 +1,25 @@
+package goconv
+
+import (
+	"time"
+
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+// EndpointingParamsFromGRPC converts the endpointing parameters received
+// through gRPC (or command line flags) to whisper.Options; nil disables
+// the endpointing, and unset parameters keep whisper.DefaultEndpointing.
+func EndpointingParamsFromGRPC(p *speechtotext_grpc.WhisperEndpointingParams) whisper.Options {
+	if p == nil {
+		return nil
+	}
+	endpointing := whisper.DefaultEndpointing
+	if p.MinSilenceMS != nil {
+		endpointing.MinSilence = time.Duration(p.GetMinSilenceMS()) * time.Millisecond
+	}
+	if p.MaxUtteranceLengthMS != nil {
+		endpointing.MaxUtteranceLength = time.Duration(p.GetMaxUtteranceLengthMS()) * time.Millisecond
+	}
+	return whisper.Options{whisper.OptionEndpointing(endpointing)}
+}
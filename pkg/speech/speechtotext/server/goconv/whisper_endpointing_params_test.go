@@ -0,0 +1,22 @@
+package goconv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+func TestEndpointingParamsFromGRPC(t *testing.T) {
+	require.Nil(t, EndpointingParamsFromGRPC(nil))
+
+	minSilenceMS := uint32(250)
+	require.Equal(t, whisper.Options{whisper.OptionEndpointing(whisper.Endpointing{
+		MinSilence:         250 * time.Millisecond,
+		MaxUtteranceLength: whisper.DefaultEndpointing.MaxUtteranceLength,
+	})}, EndpointingParamsFromGRPC(&speechtotext_grpc.WhisperEndpointingParams{
+		MinSilenceMS: &minSilenceMS,
+	}))
+}
@@ -0,0 +1,18 @@
+package goconv
+
+import (
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+func VADBackendFromGRPC(
+	b speechtotext_grpc.WhisperVADBackend,
+) types.VADBackend {
+	return types.VADBackend(b)
+}
+
+func VADBackendToGRPC(
+	b types.VADBackend,
+) speechtotext_grpc.WhisperVADBackend {
+	return speechtotext_grpc.WhisperVADBackend(b)
+}
@@ -0,0 +1,40 @@
+package goconv
+
+import (
+	"time"
+
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+// VADParamsFromGRPC converts the VAD parameters received through gRPC
+// (or command line flags) to whisper.Options; unset parameters are skipped.
+// The keep-context is validated against the commit policy
+// (see whisper.ErrInvalidVADKeepContext).
+func VADParamsFromGRPC(
+	p *speechtotext_grpc.WhisperVADParams,
+	commitPolicy whisper.CommitPolicy,
+) (whisper.Options, error) {
+	var opts whisper.Options
+	if p == nil {
+		return opts, nil
+	}
+	opts = append(opts, whisper.OptionVADBackend(VADBackendFromGRPC(p.GetBackend())))
+	if p.LibFVADMode != nil {
+		opts = append(opts, whisper.OptionVADLibFVADMode(p.GetLibFVADMode()))
+	}
+	if p.MinVoiceDurationMS != nil {
+		opts = append(opts, whisper.OptionVADMinVoiceDuration(time.Duration(p.GetMinVoiceDurationMS())*time.Millisecond))
+	}
+	if p.KeepContextMS != nil {
+		keepContext := time.Duration(p.GetKeepContextMS()) * time.Millisecond
+		if keepContext >= commitPolicy.MinSendingDuration {
+			return nil, whisper.ErrInvalidVADKeepContext{
+				KeepContext:        keepContext,
+				MinSendingDuration: commitPolicy.MinSendingDuration,
+			}
+		}
+		opts = append(opts, whisper.OptionVADKeepContext(keepContext))
+	}
+	return opts, nil
+}
@@ -0,0 +1,37 @@
+package goconv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+func TestVADParamsFromGRPC(t *testing.T) {
+	mode := int32(1)
+	minVoiceDurationMS := uint32(250)
+	opts, err := VADParamsFromGRPC(&speechtotext_grpc.WhisperVADParams{
+		Backend:            speechtotext_grpc.WhisperVADBackend_WhisperVADBackendLibFVAD,
+		LibFVADMode:        &mode,
+		MinVoiceDurationMS: &minVoiceDurationMS,
+	}, whisper.CommitPolicyBalanced)
+	require.NoError(t, err)
+	require.Equal(t, whisper.Options{
+		whisper.OptionVADBackend(types.VADBackendLibFVAD),
+		whisper.OptionVADLibFVADMode(1),
+		whisper.OptionVADMinVoiceDuration(250 * time.Millisecond),
+	}, opts)
+
+	opts, err = VADParamsFromGRPC(nil, whisper.CommitPolicyBalanced)
+	require.NoError(t, err)
+	require.Empty(t, opts)
+
+	keepContextMS := uint32(whisper.CommitPolicyLowLatency.MinSendingDuration / time.Millisecond)
+	_, err = VADParamsFromGRPC(&speechtotext_grpc.WhisperVADParams{
+		KeepContextMS: &keepContextMS,
+	}, whisper.CommitPolicyLowLatency)
+	require.ErrorAs(t, err, &whisper.ErrInvalidVADKeepContext{})
+}
@@ -6,6 +6,7 @@ import (
 
 type config struct {
 	WhisperOptions whisper.Options
+	RecordDir      string
 }
 
 type Option interface {
@@ -31,3 +32,11 @@ type OptionWhisperOptions whisper.Options
 func (opt OptionWhisperOptions) apply(cfg *config) {
 	cfg.WhisperOptions = whisper.Options(opt)
 }
+
+// OptionRecordDir enables recording each context into a session file
+// in the directory (see package session).
+type OptionRecordDir string
+
+func (opt OptionRecordDir) apply(cfg *config) {
+	cfg.RecordDir = string(opt)
+}
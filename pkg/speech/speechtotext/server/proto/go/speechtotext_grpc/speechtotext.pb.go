@@ -151,33 +151,741 @@ func (WhisperAlignmentAheadsPreset) EnumDescriptor() ([]byte, []int) {
 	return file_speechtotext_proto_rawDescGZIP(), []int{1}
 }
 
+type HallucinationRuleType int32
+
+const (
+	HallucinationRuleType_HallucinationRuleTypeExact      HallucinationRuleType = 0
+	HallucinationRuleType_HallucinationRuleTypeNormalized HallucinationRuleType = 1
+	HallucinationRuleType_HallucinationRuleTypeRegex      HallucinationRuleType = 2
+	HallucinationRuleType_HallucinationRuleTypeEntropy    HallucinationRuleType = 3
+)
+
+// Enum value maps for HallucinationRuleType.
+var (
+	HallucinationRuleType_name = map[int32]string{
+		0: "HallucinationRuleTypeExact",
+		1: "HallucinationRuleTypeNormalized",
+		2: "HallucinationRuleTypeRegex",
+		3: "HallucinationRuleTypeEntropy",
+	}
+	HallucinationRuleType_value = map[string]int32{
+		"HallucinationRuleTypeExact":      0,
+		"HallucinationRuleTypeNormalized": 1,
+		"HallucinationRuleTypeRegex":      2,
+		"HallucinationRuleTypeEntropy":    3,
+	}
+)
+
+func (x HallucinationRuleType) Enum() *HallucinationRuleType {
+	p := new(HallucinationRuleType)
+	*p = x
+	return p
+}
+
+func (x HallucinationRuleType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HallucinationRuleType) Descriptor() protoreflect.EnumDescriptor {
+	return file_speechtotext_proto_enumTypes[2].Descriptor()
+}
+
+func (HallucinationRuleType) Type() protoreflect.EnumType {
+	return &file_speechtotext_proto_enumTypes[2]
+}
+
+func (x HallucinationRuleType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HallucinationRuleType.Descriptor instead.
+func (HallucinationRuleType) EnumDescriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{2}
+}
+
+type WhisperCommitPolicyPreset int32
+
+const (
+	WhisperCommitPolicyPreset_WhisperCommitPolicyPresetBalanced   WhisperCommitPolicyPreset = 0
+	WhisperCommitPolicyPreset_WhisperCommitPolicyPresetLowLatency WhisperCommitPolicyPreset = 1
+	WhisperCommitPolicyPreset_WhisperCommitPolicyPresetAccuracy   WhisperCommitPolicyPreset = 2
+)
+
+// Enum value maps for WhisperCommitPolicyPreset.
+var (
+	WhisperCommitPolicyPreset_name = map[int32]string{
+		0: "WhisperCommitPolicyPresetBalanced",
+		1: "WhisperCommitPolicyPresetLowLatency",
+		2: "WhisperCommitPolicyPresetAccuracy",
+	}
+	WhisperCommitPolicyPreset_value = map[string]int32{
+		"WhisperCommitPolicyPresetBalanced":   0,
+		"WhisperCommitPolicyPresetLowLatency": 1,
+		"WhisperCommitPolicyPresetAccuracy":   2,
+	}
+)
+
+func (x WhisperCommitPolicyPreset) Enum() *WhisperCommitPolicyPreset {
+	p := new(WhisperCommitPolicyPreset)
+	*p = x
+	return p
+}
+
+func (x WhisperCommitPolicyPreset) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WhisperCommitPolicyPreset) Descriptor() protoreflect.EnumDescriptor {
+	return file_speechtotext_proto_enumTypes[3].Descriptor()
+}
+
+func (WhisperCommitPolicyPreset) Type() protoreflect.EnumType {
+	return &file_speechtotext_proto_enumTypes[3]
+}
+
+func (x WhisperCommitPolicyPreset) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WhisperCommitPolicyPreset.Descriptor instead.
+func (WhisperCommitPolicyPreset) EnumDescriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{3}
+}
+
+type WhisperVADBackend int32
+
+const (
+	WhisperVADBackend_WhisperVADBackendAuto    WhisperVADBackend = 0
+	WhisperVADBackend_WhisperVADBackendNone    WhisperVADBackend = 1
+	WhisperVADBackend_WhisperVADBackendEnergy  WhisperVADBackend = 2
+	WhisperVADBackend_WhisperVADBackendLibFVAD WhisperVADBackend = 3
+	WhisperVADBackend_WhisperVADBackendRNNoise WhisperVADBackend = 4
+)
+
+// Enum value maps for WhisperVADBackend.
+var (
+	WhisperVADBackend_name = map[int32]string{
+		0: "WhisperVADBackendAuto",
+		1: "WhisperVADBackendNone",
+		2: "WhisperVADBackendEnergy",
+		3: "WhisperVADBackendLibFVAD",
+		4: "WhisperVADBackendRNNoise",
+	}
+	WhisperVADBackend_value = map[string]int32{
+		"WhisperVADBackendAuto":    0,
+		"WhisperVADBackendNone":    1,
+		"WhisperVADBackendEnergy":  2,
+		"WhisperVADBackendLibFVAD": 3,
+		"WhisperVADBackendRNNoise": 4,
+	}
+)
+
+func (x WhisperVADBackend) Enum() *WhisperVADBackend {
+	p := new(WhisperVADBackend)
+	*p = x
+	return p
+}
+
+func (x WhisperVADBackend) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WhisperVADBackend) Descriptor() protoreflect.EnumDescriptor {
+	return file_speechtotext_proto_enumTypes[4].Descriptor()
+}
+
+func (WhisperVADBackend) Type() protoreflect.EnumType {
+	return &file_speechtotext_proto_enumTypes[4]
+}
+
+func (x WhisperVADBackend) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WhisperVADBackend.Descriptor instead.
+func (WhisperVADBackend) EnumDescriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{4}
+}
+
+type TranscriptKind int32
+
+const (
+	TranscriptKind_TranscriptKindSpeech                 TranscriptKind = 0
+	TranscriptKind_TranscriptKindSoundEvent             TranscriptKind = 1
+	TranscriptKind_TranscriptKindMusic                  TranscriptKind = 2
+	TranscriptKind_TranscriptKindSuspectedHallucination TranscriptKind = 3
+)
+
+// Enum value maps for TranscriptKind.
+var (
+	TranscriptKind_name = map[int32]string{
+		0: "TranscriptKindSpeech",
+		1: "TranscriptKindSoundEvent",
+		2: "TranscriptKindMusic",
+		3: "TranscriptKindSuspectedHallucination",
+	}
+	TranscriptKind_value = map[string]int32{
+		"TranscriptKindSpeech":                 0,
+		"TranscriptKindSoundEvent":             1,
+		"TranscriptKindMusic":                  2,
+		"TranscriptKindSuspectedHallucination": 3,
+	}
+)
+
+func (x TranscriptKind) Enum() *TranscriptKind {
+	p := new(TranscriptKind)
+	*p = x
+	return p
+}
+
+func (x TranscriptKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TranscriptKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_speechtotext_proto_enumTypes[5].Descriptor()
+}
+
+func (TranscriptKind) Type() protoreflect.EnumType {
+	return &file_speechtotext_proto_enumTypes[5]
+}
+
+func (x TranscriptKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TranscriptKind.Descriptor instead.
+func (TranscriptKind) EnumDescriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{5}
+}
+
 type PingRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	PayloadToReturn         string `protobuf:"bytes,1,opt,name=payloadToReturn,proto3" json:"payloadToReturn,omitempty"`
-	PayloadToIgnore         string `protobuf:"bytes,2,opt,name=payloadToIgnore,proto3" json:"payloadToIgnore,omitempty"`
-	RequestExtraPayloadSize int32  `protobuf:"varint,3,opt,name=requestExtraPayloadSize,proto3" json:"requestExtraPayloadSize,omitempty"`
+	PayloadToReturn         string `protobuf:"bytes,1,opt,name=payloadToReturn,proto3" json:"payloadToReturn,omitempty"`
+	PayloadToIgnore         string `protobuf:"bytes,2,opt,name=payloadToIgnore,proto3" json:"payloadToIgnore,omitempty"`
+	RequestExtraPayloadSize int32  `protobuf:"varint,3,opt,name=requestExtraPayloadSize,proto3" json:"requestExtraPayloadSize,omitempty"`
+}
+
+func (x *PingRequest) Reset() {
+	*x = PingRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_speechtotext_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingRequest) ProtoMessage() {}
+
+func (x *PingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_speechtotext_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
+func (*PingRequest) Descriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PingRequest) GetPayloadToReturn() string {
+	if x != nil {
+		return x.PayloadToReturn
+	}
+	return ""
+}
+
+func (x *PingRequest) GetPayloadToIgnore() string {
+	if x != nil {
+		return x.PayloadToIgnore
+	}
+	return ""
+}
+
+func (x *PingRequest) GetRequestExtraPayloadSize() int32 {
+	if x != nil {
+		return x.RequestExtraPayloadSize
+	}
+	return 0
+}
+
+type PingReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Payload string `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (x *PingReply) Reset() {
+	*x = PingReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_speechtotext_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PingReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingReply) ProtoMessage() {}
+
+func (x *PingReply) ProtoReflect() protoreflect.Message {
+	mi := &file_speechtotext_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingReply.ProtoReflect.Descriptor instead.
+func (*PingReply) Descriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PingReply) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+type HallucinationRule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name          string                `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          HallucinationRuleType `protobuf:"varint,2,opt,name=type,proto3,enum=speechtotext.HallucinationRuleType" json:"type,omitempty"`
+	Pattern       string                `protobuf:"bytes,3,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	MinLength     int32                 `protobuf:"varint,4,opt,name=minLength,proto3" json:"minLength,omitempty"`
+	SkipPrefix    int32                 `protobuf:"varint,5,opt,name=skipPrefix,proto3" json:"skipPrefix,omitempty"`
+	MaxEntropy    float64               `protobuf:"fixed64,6,opt,name=maxEntropy,proto3" json:"maxEntropy,omitempty"`
+	ModelHashes   []string              `protobuf:"bytes,7,rep,name=modelHashes,proto3" json:"modelHashes,omitempty"`
+	ModelFamilies []string              `protobuf:"bytes,8,rep,name=modelFamilies,proto3" json:"modelFamilies,omitempty"`
+	Languages     []string              `protobuf:"bytes,9,rep,name=languages,proto3" json:"languages,omitempty"`
+}
+
+func (x *HallucinationRule) Reset() {
+	*x = HallucinationRule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_speechtotext_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HallucinationRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HallucinationRule) ProtoMessage() {}
+
+func (x *HallucinationRule) ProtoReflect() protoreflect.Message {
+	mi := &file_speechtotext_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HallucinationRule.ProtoReflect.Descriptor instead.
+func (*HallucinationRule) Descriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *HallucinationRule) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HallucinationRule) GetType() HallucinationRuleType {
+	if x != nil {
+		return x.Type
+	}
+	return HallucinationRuleType_HallucinationRuleTypeExact
+}
+
+func (x *HallucinationRule) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *HallucinationRule) GetMinLength() int32 {
+	if x != nil {
+		return x.MinLength
+	}
+	return 0
+}
+
+func (x *HallucinationRule) GetSkipPrefix() int32 {
+	if x != nil {
+		return x.SkipPrefix
+	}
+	return 0
+}
+
+func (x *HallucinationRule) GetMaxEntropy() float64 {
+	if x != nil {
+		return x.MaxEntropy
+	}
+	return 0
+}
+
+func (x *HallucinationRule) GetModelHashes() []string {
+	if x != nil {
+		return x.ModelHashes
+	}
+	return nil
+}
+
+func (x *HallucinationRule) GetModelFamilies() []string {
+	if x != nil {
+		return x.ModelFamilies
+	}
+	return nil
+}
+
+func (x *HallucinationRule) GetLanguages() []string {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+type WhisperVADParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Backend            WhisperVADBackend `protobuf:"varint,1,opt,name=backend,proto3,enum=speechtotext.WhisperVADBackend" json:"backend,omitempty"`
+	LibFVADMode        *int32            `protobuf:"varint,2,opt,name=libFVADMode,proto3,oneof" json:"libFVADMode,omitempty"`
+	MinVoiceDurationMS *uint32           `protobuf:"varint,3,opt,name=minVoiceDurationMS,proto3,oneof" json:"minVoiceDurationMS,omitempty"`
+	KeepContextMS      *uint32           `protobuf:"varint,4,opt,name=keepContextMS,proto3,oneof" json:"keepContextMS,omitempty"`
+}
+
+func (x *WhisperVADParams) Reset() {
+	*x = WhisperVADParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_speechtotext_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WhisperVADParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WhisperVADParams) ProtoMessage() {}
+
+func (x *WhisperVADParams) ProtoReflect() protoreflect.Message {
+	mi := &file_speechtotext_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WhisperVADParams.ProtoReflect.Descriptor instead.
+func (*WhisperVADParams) Descriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *WhisperVADParams) GetBackend() WhisperVADBackend {
+	if x != nil {
+		return x.Backend
+	}
+	return WhisperVADBackend_WhisperVADBackendAuto
+}
+
+func (x *WhisperVADParams) GetLibFVADMode() int32 {
+	if x != nil && x.LibFVADMode != nil {
+		return *x.LibFVADMode
+	}
+	return 0
+}
+
+func (x *WhisperVADParams) GetMinVoiceDurationMS() uint32 {
+	if x != nil && x.MinVoiceDurationMS != nil {
+		return *x.MinVoiceDurationMS
+	}
+	return 0
+}
+
+func (x *WhisperVADParams) GetKeepContextMS() uint32 {
+	if x != nil && x.KeepContextMS != nil {
+		return *x.KeepContextMS
+	}
+	return 0
+}
+
+type WhisperEndpointingParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MinSilenceMS         *uint32 `protobuf:"varint,1,opt,name=minSilenceMS,proto3,oneof" json:"minSilenceMS,omitempty"`
+	MaxUtteranceLengthMS *uint32 `protobuf:"varint,2,opt,name=maxUtteranceLengthMS,proto3,oneof" json:"maxUtteranceLengthMS,omitempty"`
+}
+
+func (x *WhisperEndpointingParams) Reset() {
+	*x = WhisperEndpointingParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_speechtotext_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WhisperEndpointingParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WhisperEndpointingParams) ProtoMessage() {}
+
+func (x *WhisperEndpointingParams) ProtoReflect() protoreflect.Message {
+	mi := &file_speechtotext_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WhisperEndpointingParams.ProtoReflect.Descriptor instead.
+func (*WhisperEndpointingParams) Descriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WhisperEndpointingParams) GetMinSilenceMS() uint32 {
+	if x != nil && x.MinSilenceMS != nil {
+		return *x.MinSilenceMS
+	}
+	return 0
+}
+
+func (x *WhisperEndpointingParams) GetMaxUtteranceLengthMS() uint32 {
+	if x != nil && x.MaxUtteranceLengthMS != nil {
+		return *x.MaxUtteranceLengthMS
+	}
+	return 0
+}
+
+type WhisperDecodingParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BeamSize                *uint32  `protobuf:"varint,1,opt,name=beamSize,proto3,oneof" json:"beamSize,omitempty"`
+	BestOf                  *uint32  `protobuf:"varint,2,opt,name=bestOf,proto3,oneof" json:"bestOf,omitempty"`
+	Temperature             *float32 `protobuf:"fixed32,3,opt,name=temperature,proto3,oneof" json:"temperature,omitempty"`
+	TemperatureInc          *float32 `protobuf:"fixed32,4,opt,name=temperatureInc,proto3,oneof" json:"temperatureInc,omitempty"`
+	EntropyThreshold        *float32 `protobuf:"fixed32,5,opt,name=entropyThreshold,proto3,oneof" json:"entropyThreshold,omitempty"`
+	LogprobThreshold        *float32 `protobuf:"fixed32,6,opt,name=logprobThreshold,proto3,oneof" json:"logprobThreshold,omitempty"`
+	NoSpeechThreshold       *float32 `protobuf:"fixed32,7,opt,name=noSpeechThreshold,proto3,oneof" json:"noSpeechThreshold,omitempty"`
+	SuppressBlank           *bool    `protobuf:"varint,9,opt,name=suppressBlank,proto3,oneof" json:"suppressBlank,omitempty"`
+	SuppressNonSpeechTokens *bool    `protobuf:"varint,10,opt,name=suppressNonSpeechTokens,proto3,oneof" json:"suppressNonSpeechTokens,omitempty"`
+	MaxSegmentLength        *uint32  `protobuf:"varint,11,opt,name=maxSegmentLength,proto3,oneof" json:"maxSegmentLength,omitempty"`
+	SplitOnWord             *bool    `protobuf:"varint,12,opt,name=splitOnWord,proto3,oneof" json:"splitOnWord,omitempty"`
+	Threads                 *uint32  `protobuf:"varint,13,opt,name=threads,proto3,oneof" json:"threads,omitempty"`
+	NoContext               *bool    `protobuf:"varint,14,opt,name=noContext,proto3,oneof" json:"noContext,omitempty"`
+}
+
+func (x *WhisperDecodingParams) Reset() {
+	*x = WhisperDecodingParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_speechtotext_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WhisperDecodingParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WhisperDecodingParams) ProtoMessage() {}
+
+func (x *WhisperDecodingParams) ProtoReflect() protoreflect.Message {
+	mi := &file_speechtotext_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WhisperDecodingParams.ProtoReflect.Descriptor instead.
+func (*WhisperDecodingParams) Descriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WhisperDecodingParams) GetBeamSize() uint32 {
+	if x != nil && x.BeamSize != nil {
+		return *x.BeamSize
+	}
+	return 0
+}
+
+func (x *WhisperDecodingParams) GetBestOf() uint32 {
+	if x != nil && x.BestOf != nil {
+		return *x.BestOf
+	}
+	return 0
+}
+
+func (x *WhisperDecodingParams) GetTemperature() float32 {
+	if x != nil && x.Temperature != nil {
+		return *x.Temperature
+	}
+	return 0
+}
+
+func (x *WhisperDecodingParams) GetTemperatureInc() float32 {
+	if x != nil && x.TemperatureInc != nil {
+		return *x.TemperatureInc
+	}
+	return 0
+}
+
+func (x *WhisperDecodingParams) GetEntropyThreshold() float32 {
+	if x != nil && x.EntropyThreshold != nil {
+		return *x.EntropyThreshold
+	}
+	return 0
+}
+
+func (x *WhisperDecodingParams) GetLogprobThreshold() float32 {
+	if x != nil && x.LogprobThreshold != nil {
+		return *x.LogprobThreshold
+	}
+	return 0
+}
+
+func (x *WhisperDecodingParams) GetNoSpeechThreshold() float32 {
+	if x != nil && x.NoSpeechThreshold != nil {
+		return *x.NoSpeechThreshold
+	}
+	return 0
+}
+
+func (x *WhisperDecodingParams) GetSuppressBlank() bool {
+	if x != nil && x.SuppressBlank != nil {
+		return *x.SuppressBlank
+	}
+	return false
+}
+
+func (x *WhisperDecodingParams) GetSuppressNonSpeechTokens() bool {
+	if x != nil && x.SuppressNonSpeechTokens != nil {
+		return *x.SuppressNonSpeechTokens
+	}
+	return false
+}
+
+func (x *WhisperDecodingParams) GetMaxSegmentLength() uint32 {
+	if x != nil && x.MaxSegmentLength != nil {
+		return *x.MaxSegmentLength
+	}
+	return 0
+}
+
+func (x *WhisperDecodingParams) GetSplitOnWord() bool {
+	if x != nil && x.SplitOnWord != nil {
+		return *x.SplitOnWord
+	}
+	return false
+}
+
+func (x *WhisperDecodingParams) GetThreads() uint32 {
+	if x != nil && x.Threads != nil {
+		return *x.Threads
+	}
+	return 0
+}
+
+func (x *WhisperDecodingParams) GetNoContext() bool {
+	if x != nil && x.NoContext != nil {
+		return *x.NoContext
+	}
+	return false
+}
+
+type WhisperOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SamplingStrategy           WhisperSamplingStrategy      `protobuf:"varint,3,opt,name=samplingStrategy,proto3,enum=speechtotext.WhisperSamplingStrategy" json:"samplingStrategy,omitempty"`
+	AlignmentAheadsPreset      WhisperAlignmentAheadsPreset `protobuf:"varint,5,opt,name=alignmentAheadsPreset,proto3,enum=speechtotext.WhisperAlignmentAheadsPreset" json:"alignmentAheadsPreset,omitempty"`
+	Diarize                    bool                         `protobuf:"varint,6,opt,name=diarize,proto3" json:"diarize,omitempty"`
+	OverrideHallucinationRules bool                         `protobuf:"varint,7,opt,name=overrideHallucinationRules,proto3" json:"overrideHallucinationRules,omitempty"`
+	HallucinationRules         []*HallucinationRule         `protobuf:"bytes,8,rep,name=hallucinationRules,proto3" json:"hallucinationRules,omitempty"`
+	EmitAnnotations            bool                         `protobuf:"varint,9,opt,name=emitAnnotations,proto3" json:"emitAnnotations,omitempty"`
+	DecodingParams             *WhisperDecodingParams       `protobuf:"bytes,10,opt,name=decodingParams,proto3" json:"decodingParams,omitempty"`
+	CommitPolicyPreset         WhisperCommitPolicyPreset    `protobuf:"varint,11,opt,name=commitPolicyPreset,proto3,enum=speechtotext.WhisperCommitPolicyPreset" json:"commitPolicyPreset,omitempty"`
+	VadParams                  *WhisperVADParams            `protobuf:"bytes,12,opt,name=vadParams,proto3" json:"vadParams,omitempty"`
+	Endpointing                *WhisperEndpointingParams    `protobuf:"bytes,13,opt,name=endpointing,proto3" json:"endpointing,omitempty"`
+	AlignmentHeads             []*WhisperAlignmentHead      `protobuf:"bytes,14,rep,name=alignmentHeads,proto3" json:"alignmentHeads,omitempty"`
+	AlignmentHeadsNTop         uint32                       `protobuf:"varint,15,opt,name=alignmentHeadsNTop,proto3" json:"alignmentHeadsNTop,omitempty"`
+	WordTimestamps             bool                         `protobuf:"varint,16,opt,name=wordTimestamps,proto3" json:"wordTimestamps,omitempty"`
 }
 
-func (x *PingRequest) Reset() {
-	*x = PingRequest{}
+func (x *WhisperOptions) Reset() {
+	*x = WhisperOptions{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_speechtotext_proto_msgTypes[0]
+		mi := &file_speechtotext_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *PingRequest) String() string {
+func (x *WhisperOptions) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PingRequest) ProtoMessage() {}
+func (*WhisperOptions) ProtoMessage() {}
 
-func (x *PingRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_speechtotext_proto_msgTypes[0]
+func (x *WhisperOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_speechtotext_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -188,57 +896,128 @@ func (x *PingRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
-func (*PingRequest) Descriptor() ([]byte, []int) {
-	return file_speechtotext_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use WhisperOptions.ProtoReflect.Descriptor instead.
+func (*WhisperOptions) Descriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *PingRequest) GetPayloadToReturn() string {
+func (x *WhisperOptions) GetSamplingStrategy() WhisperSamplingStrategy {
 	if x != nil {
-		return x.PayloadToReturn
+		return x.SamplingStrategy
 	}
-	return ""
+	return WhisperSamplingStrategy_WhisperSamplingStrategyUndefined
 }
 
-func (x *PingRequest) GetPayloadToIgnore() string {
+func (x *WhisperOptions) GetAlignmentAheadsPreset() WhisperAlignmentAheadsPreset {
 	if x != nil {
-		return x.PayloadToIgnore
+		return x.AlignmentAheadsPreset
 	}
-	return ""
+	return WhisperAlignmentAheadsPreset_WhisperAlignmentAheadsPresetNone
 }
 
-func (x *PingRequest) GetRequestExtraPayloadSize() int32 {
+func (x *WhisperOptions) GetDiarize() bool {
 	if x != nil {
-		return x.RequestExtraPayloadSize
+		return x.Diarize
+	}
+	return false
+}
+
+func (x *WhisperOptions) GetOverrideHallucinationRules() bool {
+	if x != nil {
+		return x.OverrideHallucinationRules
+	}
+	return false
+}
+
+func (x *WhisperOptions) GetHallucinationRules() []*HallucinationRule {
+	if x != nil {
+		return x.HallucinationRules
+	}
+	return nil
+}
+
+func (x *WhisperOptions) GetEmitAnnotations() bool {
+	if x != nil {
+		return x.EmitAnnotations
+	}
+	return false
+}
+
+func (x *WhisperOptions) GetDecodingParams() *WhisperDecodingParams {
+	if x != nil {
+		return x.DecodingParams
+	}
+	return nil
+}
+
+func (x *WhisperOptions) GetCommitPolicyPreset() WhisperCommitPolicyPreset {
+	if x != nil {
+		return x.CommitPolicyPreset
+	}
+	return WhisperCommitPolicyPreset_WhisperCommitPolicyPresetBalanced
+}
+
+func (x *WhisperOptions) GetVadParams() *WhisperVADParams {
+	if x != nil {
+		return x.VadParams
+	}
+	return nil
+}
+
+func (x *WhisperOptions) GetEndpointing() *WhisperEndpointingParams {
+	if x != nil {
+		return x.Endpointing
+	}
+	return nil
+}
+
+func (x *WhisperOptions) GetAlignmentHeads() []*WhisperAlignmentHead {
+	if x != nil {
+		return x.AlignmentHeads
+	}
+	return nil
+}
+
+func (x *WhisperOptions) GetAlignmentHeadsNTop() uint32 {
+	if x != nil {
+		return x.AlignmentHeadsNTop
 	}
 	return 0
 }
 
-type PingReply struct {
+func (x *WhisperOptions) GetWordTimestamps() bool {
+	if x != nil {
+		return x.WordTimestamps
+	}
+	return false
+}
+
+type WhisperAlignmentHead struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Payload string `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+	TextLayer uint32 `protobuf:"varint,1,opt,name=textLayer,proto3" json:"textLayer,omitempty"`
+	Head      uint32 `protobuf:"varint,2,opt,name=head,proto3" json:"head,omitempty"`
 }
 
-func (x *PingReply) Reset() {
-	*x = PingReply{}
+func (x *WhisperAlignmentHead) Reset() {
+	*x = WhisperAlignmentHead{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_speechtotext_proto_msgTypes[1]
+		mi := &file_speechtotext_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *PingReply) String() string {
+func (x *WhisperAlignmentHead) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PingReply) ProtoMessage() {}
+func (*WhisperAlignmentHead) ProtoMessage() {}
 
-func (x *PingReply) ProtoReflect() protoreflect.Message {
-	mi := &file_speechtotext_proto_msgTypes[1]
+func (x *WhisperAlignmentHead) ProtoReflect() protoreflect.Message {
+	mi := &file_speechtotext_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -249,44 +1028,53 @@ func (x *PingReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PingReply.ProtoReflect.Descriptor instead.
-func (*PingReply) Descriptor() ([]byte, []int) {
-	return file_speechtotext_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use WhisperAlignmentHead.ProtoReflect.Descriptor instead.
+func (*WhisperAlignmentHead) Descriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *PingReply) GetPayload() string {
+func (x *WhisperAlignmentHead) GetTextLayer() uint32 {
 	if x != nil {
-		return x.Payload
+		return x.TextLayer
 	}
-	return ""
+	return 0
 }
 
-type WhisperOptions struct {
+func (x *WhisperAlignmentHead) GetHead() uint32 {
+	if x != nil {
+		return x.Head
+	}
+	return 0
+}
+
+type PreprocessingParams struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	SamplingStrategy      WhisperSamplingStrategy      `protobuf:"varint,3,opt,name=samplingStrategy,proto3,enum=speechtotext.WhisperSamplingStrategy" json:"samplingStrategy,omitempty"`
-	AlignmentAheadsPreset WhisperAlignmentAheadsPreset `protobuf:"varint,5,opt,name=alignmentAheadsPreset,proto3,enum=speechtotext.WhisperAlignmentAheadsPreset" json:"alignmentAheadsPreset,omitempty"`
+	RemoveDC              bool     `protobuf:"varint,1,opt,name=removeDC,proto3" json:"removeDC,omitempty"`
+	HighPassCutoffHz      float32  `protobuf:"fixed32,2,opt,name=highPassCutoffHz,proto3" json:"highPassCutoffHz,omitempty"`
+	Denoise               bool     `protobuf:"varint,3,opt,name=denoise,proto3" json:"denoise,omitempty"`
+	NormalizeLoudnessDBFS *float32 `protobuf:"fixed32,4,opt,name=normalizeLoudnessDBFS,proto3,oneof" json:"normalizeLoudnessDBFS,omitempty"`
 }
 
-func (x *WhisperOptions) Reset() {
-	*x = WhisperOptions{}
+func (x *PreprocessingParams) Reset() {
+	*x = PreprocessingParams{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_speechtotext_proto_msgTypes[2]
+		mi := &file_speechtotext_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *WhisperOptions) String() string {
+func (x *PreprocessingParams) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WhisperOptions) ProtoMessage() {}
+func (*PreprocessingParams) ProtoMessage() {}
 
-func (x *WhisperOptions) ProtoReflect() protoreflect.Message {
-	mi := &file_speechtotext_proto_msgTypes[2]
+func (x *PreprocessingParams) ProtoReflect() protoreflect.Message {
+	mi := &file_speechtotext_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -297,23 +1085,37 @@ func (x *WhisperOptions) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WhisperOptions.ProtoReflect.Descriptor instead.
-func (*WhisperOptions) Descriptor() ([]byte, []int) {
-	return file_speechtotext_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use PreprocessingParams.ProtoReflect.Descriptor instead.
+func (*PreprocessingParams) Descriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *WhisperOptions) GetSamplingStrategy() WhisperSamplingStrategy {
+func (x *PreprocessingParams) GetRemoveDC() bool {
 	if x != nil {
-		return x.SamplingStrategy
+		return x.RemoveDC
 	}
-	return WhisperSamplingStrategy_WhisperSamplingStrategyUndefined
+	return false
 }
 
-func (x *WhisperOptions) GetAlignmentAheadsPreset() WhisperAlignmentAheadsPreset {
+func (x *PreprocessingParams) GetHighPassCutoffHz() float32 {
 	if x != nil {
-		return x.AlignmentAheadsPreset
+		return x.HighPassCutoffHz
 	}
-	return WhisperAlignmentAheadsPreset_WhisperAlignmentAheadsPresetNone
+	return 0
+}
+
+func (x *PreprocessingParams) GetDenoise() bool {
+	if x != nil {
+		return x.Denoise
+	}
+	return false
+}
+
+func (x *PreprocessingParams) GetNormalizeLoudnessDBFS() float32 {
+	if x != nil && x.NormalizeLoudnessDBFS != nil {
+		return *x.NormalizeLoudnessDBFS
+	}
+	return 0
 }
 
 type NewContextRequest struct {
@@ -326,15 +1128,20 @@ type NewContextRequest struct {
 	ShouldTranslate bool    `protobuf:"varint,3,opt,name=shouldTranslate,proto3" json:"shouldTranslate,omitempty"`
 	VadThreshold    float32 `protobuf:"fixed32,4,opt,name=vadThreshold,proto3" json:"vadThreshold,omitempty"`
 	// Types that are assignable to Backend:
-	//
 	//	*NewContextRequest_Whisper
-	Backend isNewContextRequest_Backend `protobuf_oneof:"Backend"`
+	Backend            isNewContextRequest_Backend `protobuf_oneof:"Backend"`
+	InitialPrompt      string                      `protobuf:"bytes,6,opt,name=initialPrompt,proto3" json:"initialPrompt,omitempty"`
+	BoostPhrases       []string                    `protobuf:"bytes,7,rep,name=boostPhrases,proto3" json:"boostPhrases,omitempty"`
+	CarryForwardPrompt bool                        `protobuf:"varint,8,opt,name=carryForwardPrompt,proto3" json:"carryForwardPrompt,omitempty"`
+	AllowedLanguages   []string                    `protobuf:"bytes,9,rep,name=allowedLanguages,proto3" json:"allowedLanguages,omitempty"`
+	EmitTranslation    bool                        `protobuf:"varint,10,opt,name=emitTranslation,proto3" json:"emitTranslation,omitempty"`
+	Preprocessing      *PreprocessingParams        `protobuf:"bytes,11,opt,name=preprocessing,proto3" json:"preprocessing,omitempty"`
 }
 
 func (x *NewContextRequest) Reset() {
 	*x = NewContextRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_speechtotext_proto_msgTypes[3]
+		mi := &file_speechtotext_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -347,7 +1154,7 @@ func (x *NewContextRequest) String() string {
 func (*NewContextRequest) ProtoMessage() {}
 
 func (x *NewContextRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_speechtotext_proto_msgTypes[3]
+	mi := &file_speechtotext_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -360,7 +1167,7 @@ func (x *NewContextRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NewContextRequest.ProtoReflect.Descriptor instead.
 func (*NewContextRequest) Descriptor() ([]byte, []int) {
-	return file_speechtotext_proto_rawDescGZIP(), []int{3}
+	return file_speechtotext_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *NewContextRequest) GetModelBytes() []byte {
@@ -405,6 +1212,48 @@ func (x *NewContextRequest) GetWhisper() *WhisperOptions {
 	return nil
 }
 
+func (x *NewContextRequest) GetInitialPrompt() string {
+	if x != nil {
+		return x.InitialPrompt
+	}
+	return ""
+}
+
+func (x *NewContextRequest) GetBoostPhrases() []string {
+	if x != nil {
+		return x.BoostPhrases
+	}
+	return nil
+}
+
+func (x *NewContextRequest) GetCarryForwardPrompt() bool {
+	if x != nil {
+		return x.CarryForwardPrompt
+	}
+	return false
+}
+
+func (x *NewContextRequest) GetAllowedLanguages() []string {
+	if x != nil {
+		return x.AllowedLanguages
+	}
+	return nil
+}
+
+func (x *NewContextRequest) GetEmitTranslation() bool {
+	if x != nil {
+		return x.EmitTranslation
+	}
+	return false
+}
+
+func (x *NewContextRequest) GetPreprocessing() *PreprocessingParams {
+	if x != nil {
+		return x.Preprocessing
+	}
+	return nil
+}
+
 type isNewContextRequest_Backend interface {
 	isNewContextRequest_Backend()
 }
@@ -426,7 +1275,7 @@ type NewContextReply struct {
 func (x *NewContextReply) Reset() {
 	*x = NewContextReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_speechtotext_proto_msgTypes[4]
+		mi := &file_speechtotext_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -439,7 +1288,7 @@ func (x *NewContextReply) String() string {
 func (*NewContextReply) ProtoMessage() {}
 
 func (x *NewContextReply) ProtoReflect() protoreflect.Message {
-	mi := &file_speechtotext_proto_msgTypes[4]
+	mi := &file_speechtotext_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -452,7 +1301,7 @@ func (x *NewContextReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NewContextReply.ProtoReflect.Descriptor instead.
 func (*NewContextReply) Descriptor() ([]byte, []int) {
-	return file_speechtotext_proto_rawDescGZIP(), []int{4}
+	return file_speechtotext_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *NewContextReply) GetContextID() uint64 {
@@ -474,7 +1323,7 @@ type WriteAudioRequest struct {
 func (x *WriteAudioRequest) Reset() {
 	*x = WriteAudioRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_speechtotext_proto_msgTypes[5]
+		mi := &file_speechtotext_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -487,7 +1336,7 @@ func (x *WriteAudioRequest) String() string {
 func (*WriteAudioRequest) ProtoMessage() {}
 
 func (x *WriteAudioRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_speechtotext_proto_msgTypes[5]
+	mi := &file_speechtotext_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -500,7 +1349,7 @@ func (x *WriteAudioRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WriteAudioRequest.ProtoReflect.Descriptor instead.
 func (*WriteAudioRequest) Descriptor() ([]byte, []int) {
-	return file_speechtotext_proto_rawDescGZIP(), []int{5}
+	return file_speechtotext_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *WriteAudioRequest) GetContextID() uint64 {
@@ -526,7 +1375,7 @@ type WriteAudioReply struct {
 func (x *WriteAudioReply) Reset() {
 	*x = WriteAudioReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_speechtotext_proto_msgTypes[6]
+		mi := &file_speechtotext_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -539,7 +1388,7 @@ func (x *WriteAudioReply) String() string {
 func (*WriteAudioReply) ProtoMessage() {}
 
 func (x *WriteAudioReply) ProtoReflect() protoreflect.Message {
-	mi := &file_speechtotext_proto_msgTypes[6]
+	mi := &file_speechtotext_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -552,7 +1401,7 @@ func (x *WriteAudioReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WriteAudioReply.ProtoReflect.Descriptor instead.
 func (*WriteAudioReply) Descriptor() ([]byte, []int) {
-	return file_speechtotext_proto_rawDescGZIP(), []int{6}
+	return file_speechtotext_proto_rawDescGZIP(), []int{12}
 }
 
 type OutputChanRequest struct {
@@ -566,7 +1415,7 @@ type OutputChanRequest struct {
 func (x *OutputChanRequest) Reset() {
 	*x = OutputChanRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_speechtotext_proto_msgTypes[7]
+		mi := &file_speechtotext_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -579,7 +1428,7 @@ func (x *OutputChanRequest) String() string {
 func (*OutputChanRequest) ProtoMessage() {}
 
 func (x *OutputChanRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_speechtotext_proto_msgTypes[7]
+	mi := &file_speechtotext_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -592,7 +1441,7 @@ func (x *OutputChanRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OutputChanRequest.ProtoReflect.Descriptor instead.
 func (*OutputChanRequest) Descriptor() ([]byte, []int) {
-	return file_speechtotext_proto_rawDescGZIP(), []int{7}
+	return file_speechtotext_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *OutputChanRequest) GetContextID() uint64 {
@@ -613,7 +1462,7 @@ type OutputChanReply struct {
 func (x *OutputChanReply) Reset() {
 	*x = OutputChanReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_speechtotext_proto_msgTypes[8]
+		mi := &file_speechtotext_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -626,7 +1475,7 @@ func (x *OutputChanReply) String() string {
 func (*OutputChanReply) ProtoMessage() {}
 
 func (x *OutputChanReply) ProtoReflect() protoreflect.Message {
-	mi := &file_speechtotext_proto_msgTypes[8]
+	mi := &file_speechtotext_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -639,7 +1488,7 @@ func (x *OutputChanReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OutputChanReply.ProtoReflect.Descriptor instead.
 func (*OutputChanReply) Descriptor() ([]byte, []int) {
-	return file_speechtotext_proto_rawDescGZIP(), []int{8}
+	return file_speechtotext_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *OutputChanReply) GetTranscript() *Transcript {
@@ -654,17 +1503,23 @@ type Transcript struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Variants        []*TranscriptVariant `protobuf:"bytes,1,rep,name=variants,proto3" json:"variants,omitempty"`
-	Stability       float32              `protobuf:"fixed32,2,opt,name=stability,proto3" json:"stability,omitempty"`
-	AudioChannelNum uint32               `protobuf:"varint,3,opt,name=audioChannelNum,proto3" json:"audioChannelNum,omitempty"`
-	Language        string               `protobuf:"bytes,4,opt,name=language,proto3" json:"language,omitempty"`
-	IsFinal         bool                 `protobuf:"varint,5,opt,name=isFinal,proto3" json:"isFinal,omitempty"`
+	Variants           []*TranscriptVariant `protobuf:"bytes,1,rep,name=variants,proto3" json:"variants,omitempty"`
+	Stability          float32              `protobuf:"fixed32,2,opt,name=stability,proto3" json:"stability,omitempty"`
+	AudioChannelNum    uint32               `protobuf:"varint,3,opt,name=audioChannelNum,proto3" json:"audioChannelNum,omitempty"`
+	Language           string               `protobuf:"bytes,4,opt,name=language,proto3" json:"language,omitempty"`
+	IsFinal            bool                 `protobuf:"varint,5,opt,name=isFinal,proto3" json:"isFinal,omitempty"`
+	Kind               TranscriptKind       `protobuf:"varint,6,opt,name=kind,proto3,enum=speechtotext.TranscriptKind" json:"kind,omitempty"`
+	SegmentID          uint64               `protobuf:"varint,7,opt,name=segmentID,proto3" json:"segmentID,omitempty"`
+	Revision           uint64               `protobuf:"varint,8,opt,name=revision,proto3" json:"revision,omitempty"`
+	IsRetracted        bool                 `protobuf:"varint,9,opt,name=isRetracted,proto3" json:"isRetracted,omitempty"`
+	LanguageConfidence float32              `protobuf:"fixed32,10,opt,name=languageConfidence,proto3" json:"languageConfidence,omitempty"`
+	IsTranslation      bool                 `protobuf:"varint,11,opt,name=isTranslation,proto3" json:"isTranslation,omitempty"`
 }
 
 func (x *Transcript) Reset() {
 	*x = Transcript{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_speechtotext_proto_msgTypes[9]
+		mi := &file_speechtotext_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -677,7 +1532,7 @@ func (x *Transcript) String() string {
 func (*Transcript) ProtoMessage() {}
 
 func (x *Transcript) ProtoReflect() protoreflect.Message {
-	mi := &file_speechtotext_proto_msgTypes[9]
+	mi := &file_speechtotext_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -690,7 +1545,7 @@ func (x *Transcript) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Transcript.ProtoReflect.Descriptor instead.
 func (*Transcript) Descriptor() ([]byte, []int) {
-	return file_speechtotext_proto_rawDescGZIP(), []int{9}
+	return file_speechtotext_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *Transcript) GetVariants() []*TranscriptVariant {
@@ -716,14 +1571,56 @@ func (x *Transcript) GetAudioChannelNum() uint32 {
 
 func (x *Transcript) GetLanguage() string {
 	if x != nil {
-		return x.Language
+		return x.Language
+	}
+	return ""
+}
+
+func (x *Transcript) GetIsFinal() bool {
+	if x != nil {
+		return x.IsFinal
+	}
+	return false
+}
+
+func (x *Transcript) GetKind() TranscriptKind {
+	if x != nil {
+		return x.Kind
+	}
+	return TranscriptKind_TranscriptKindSpeech
+}
+
+func (x *Transcript) GetSegmentID() uint64 {
+	if x != nil {
+		return x.SegmentID
+	}
+	return 0
+}
+
+func (x *Transcript) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *Transcript) GetIsRetracted() bool {
+	if x != nil {
+		return x.IsRetracted
+	}
+	return false
+}
+
+func (x *Transcript) GetLanguageConfidence() float32 {
+	if x != nil {
+		return x.LanguageConfidence
 	}
-	return ""
+	return 0
 }
 
-func (x *Transcript) GetIsFinal() bool {
+func (x *Transcript) GetIsTranslation() bool {
 	if x != nil {
-		return x.IsFinal
+		return x.IsTranslation
 	}
 	return false
 }
@@ -741,7 +1638,7 @@ type TranscriptVariant struct {
 func (x *TranscriptVariant) Reset() {
 	*x = TranscriptVariant{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_speechtotext_proto_msgTypes[10]
+		mi := &file_speechtotext_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -754,7 +1651,7 @@ func (x *TranscriptVariant) String() string {
 func (*TranscriptVariant) ProtoMessage() {}
 
 func (x *TranscriptVariant) ProtoReflect() protoreflect.Message {
-	mi := &file_speechtotext_proto_msgTypes[10]
+	mi := &file_speechtotext_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -767,7 +1664,7 @@ func (x *TranscriptVariant) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TranscriptVariant.ProtoReflect.Descriptor instead.
 func (*TranscriptVariant) Descriptor() ([]byte, []int) {
-	return file_speechtotext_proto_rawDescGZIP(), []int{10}
+	return file_speechtotext_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *TranscriptVariant) GetText() string {
@@ -801,12 +1698,14 @@ type TranscriptToken struct {
 	Text          string  `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
 	Confidence    float32 `protobuf:"fixed32,4,opt,name=confidence,proto3" json:"confidence,omitempty"`
 	Speaker       string  `protobuf:"bytes,5,opt,name=Speaker,proto3" json:"Speaker,omitempty"`
+	Stability     float32 `protobuf:"fixed32,6,opt,name=stability,proto3" json:"stability,omitempty"`
+	IsDTWAligned  bool    `protobuf:"varint,7,opt,name=isDTWAligned,proto3" json:"isDTWAligned,omitempty"`
 }
 
 func (x *TranscriptToken) Reset() {
 	*x = TranscriptToken{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_speechtotext_proto_msgTypes[11]
+		mi := &file_speechtotext_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -819,7 +1718,7 @@ func (x *TranscriptToken) String() string {
 func (*TranscriptToken) ProtoMessage() {}
 
 func (x *TranscriptToken) ProtoReflect() protoreflect.Message {
-	mi := &file_speechtotext_proto_msgTypes[11]
+	mi := &file_speechtotext_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -832,7 +1731,7 @@ func (x *TranscriptToken) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TranscriptToken.ProtoReflect.Descriptor instead.
 func (*TranscriptToken) Descriptor() ([]byte, []int) {
-	return file_speechtotext_proto_rawDescGZIP(), []int{11}
+	return file_speechtotext_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *TranscriptToken) GetStartTimeNano() int64 {
@@ -870,6 +1769,177 @@ func (x *TranscriptToken) GetSpeaker() string {
 	return ""
 }
 
+func (x *TranscriptToken) GetStability() float32 {
+	if x != nil {
+		return x.Stability
+	}
+	return 0
+}
+
+func (x *TranscriptToken) GetIsDTWAligned() bool {
+	if x != nil {
+		return x.IsDTWAligned
+	}
+	return false
+}
+
+type DetectLanguageRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ContextID uint64 `protobuf:"varint,1,opt,name=contextID,proto3" json:"contextID,omitempty"`
+	Audio     []byte `protobuf:"bytes,2,opt,name=audio,proto3" json:"audio,omitempty"`
+}
+
+func (x *DetectLanguageRequest) Reset() {
+	*x = DetectLanguageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_speechtotext_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DetectLanguageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectLanguageRequest) ProtoMessage() {}
+
+func (x *DetectLanguageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_speechtotext_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectLanguageRequest.ProtoReflect.Descriptor instead.
+func (*DetectLanguageRequest) Descriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DetectLanguageRequest) GetContextID() uint64 {
+	if x != nil {
+		return x.ContextID
+	}
+	return 0
+}
+
+func (x *DetectLanguageRequest) GetAudio() []byte {
+	if x != nil {
+		return x.Audio
+	}
+	return nil
+}
+
+type DetectLanguageReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Languages []*LanguageProbability `protobuf:"bytes,1,rep,name=languages,proto3" json:"languages,omitempty"`
+}
+
+func (x *DetectLanguageReply) Reset() {
+	*x = DetectLanguageReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_speechtotext_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DetectLanguageReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectLanguageReply) ProtoMessage() {}
+
+func (x *DetectLanguageReply) ProtoReflect() protoreflect.Message {
+	mi := &file_speechtotext_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectLanguageReply.ProtoReflect.Descriptor instead.
+func (*DetectLanguageReply) Descriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DetectLanguageReply) GetLanguages() []*LanguageProbability {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+type LanguageProbability struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Language    string  `protobuf:"bytes,1,opt,name=language,proto3" json:"language,omitempty"`
+	Probability float32 `protobuf:"fixed32,2,opt,name=probability,proto3" json:"probability,omitempty"`
+}
+
+func (x *LanguageProbability) Reset() {
+	*x = LanguageProbability{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_speechtotext_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LanguageProbability) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LanguageProbability) ProtoMessage() {}
+
+func (x *LanguageProbability) ProtoReflect() protoreflect.Message {
+	mi := &file_speechtotext_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LanguageProbability.ProtoReflect.Descriptor instead.
+func (*LanguageProbability) Descriptor() ([]byte, []int) {
+	return file_speechtotext_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *LanguageProbability) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *LanguageProbability) GetProbability() float32 {
+	if x != nil {
+		return x.Probability
+	}
+	return 0
+}
+
 type CloseContextRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -879,7 +1949,7 @@ type CloseContextRequest struct {
 func (x *CloseContextRequest) Reset() {
 	*x = CloseContextRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_speechtotext_proto_msgTypes[12]
+		mi := &file_speechtotext_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -892,7 +1962,7 @@ func (x *CloseContextRequest) String() string {
 func (*CloseContextRequest) ProtoMessage() {}
 
 func (x *CloseContextRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_speechtotext_proto_msgTypes[12]
+	mi := &file_speechtotext_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -905,7 +1975,7 @@ func (x *CloseContextRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CloseContextRequest.ProtoReflect.Descriptor instead.
 func (*CloseContextRequest) Descriptor() ([]byte, []int) {
-	return file_speechtotext_proto_rawDescGZIP(), []int{12}
+	return file_speechtotext_proto_rawDescGZIP(), []int{21}
 }
 
 type CloseContextReply struct {
@@ -917,7 +1987,7 @@ type CloseContextReply struct {
 func (x *CloseContextReply) Reset() {
 	*x = CloseContextReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_speechtotext_proto_msgTypes[13]
+		mi := &file_speechtotext_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -930,7 +2000,7 @@ func (x *CloseContextReply) String() string {
 func (*CloseContextReply) ProtoMessage() {}
 
 func (x *CloseContextReply) ProtoReflect() protoreflect.Message {
-	mi := &file_speechtotext_proto_msgTypes[13]
+	mi := &file_speechtotext_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -943,7 +2013,7 @@ func (x *CloseContextReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CloseContextReply.ProtoReflect.Descriptor instead.
 func (*CloseContextReply) Descriptor() ([]byte, []int) {
-	return file_speechtotext_proto_rawDescGZIP(), []int{13}
+	return file_speechtotext_proto_rawDescGZIP(), []int{22}
 }
 
 var File_speechtotext_proto protoreflect.FileDescriptor
@@ -963,33 +2033,209 @@ var file_speechtotext_proto_rawDesc = []byte{
 	0x45, 0x78, 0x74, 0x72, 0x61, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x53, 0x69, 0x7a, 0x65,
 	0x22, 0x25, 0x0a, 0x09, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x18, 0x0a,
 	0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
-	0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0xc5, 0x01, 0x0a, 0x0e, 0x57, 0x68, 0x69, 0x73,
-	0x70, 0x65, 0x72, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x51, 0x0a, 0x10, 0x73, 0x61,
-	0x6d, 0x70, 0x6c, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x25, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74,
-	0x65, 0x78, 0x74, 0x2e, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x53, 0x61, 0x6d, 0x70, 0x6c,
-	0x69, 0x6e, 0x67, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x52, 0x10, 0x73, 0x61, 0x6d,
-	0x70, 0x6c, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x12, 0x60, 0x0a,
+	0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0xbe, 0x02, 0x0a, 0x11, 0x48, 0x61, 0x6c, 0x6c,
+	0x75, 0x63, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x75, 0x6c, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x37, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x23, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x48,
+	0x61, 0x6c, 0x6c, 0x75, 0x63, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x75, 0x6c, 0x65,
+	0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61,
+	0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74,
+	0x74, 0x65, 0x72, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x69, 0x6e, 0x4c, 0x65, 0x6e, 0x67, 0x74,
+	0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6d, 0x69, 0x6e, 0x4c, 0x65, 0x6e, 0x67,
+	0x74, 0x68, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x6b, 0x69, 0x70, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x6b, 0x69, 0x70, 0x50, 0x72, 0x65, 0x66,
+	0x69, 0x78, 0x12, 0x1e, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x45, 0x6e, 0x74, 0x72, 0x6f, 0x70, 0x79,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x6d, 0x61, 0x78, 0x45, 0x6e, 0x74, 0x72, 0x6f,
+	0x70, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x48, 0x61, 0x73, 0x68, 0x65,
+	0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x48, 0x61,
+	0x73, 0x68, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x46, 0x61, 0x6d,
+	0x69, 0x6c, 0x69, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x6d, 0x6f, 0x64,
+	0x65, 0x6c, 0x46, 0x61, 0x6d, 0x69, 0x6c, 0x69, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x61,
+	0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x6c,
+	0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x73, 0x22, 0x8d, 0x02, 0x0a, 0x10, 0x57, 0x68, 0x69,
+	0x73, 0x70, 0x65, 0x72, 0x56, 0x41, 0x44, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x39, 0x0a,
+	0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f,
+	0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x57, 0x68,
+	0x69, 0x73, 0x70, 0x65, 0x72, 0x56, 0x41, 0x44, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52,
+	0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12, 0x25, 0x0a, 0x0b, 0x6c, 0x69, 0x62, 0x46,
+	0x56, 0x41, 0x44, 0x4d, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52,
+	0x0b, 0x6c, 0x69, 0x62, 0x46, 0x56, 0x41, 0x44, 0x4d, 0x6f, 0x64, 0x65, 0x88, 0x01, 0x01, 0x12,
+	0x33, 0x0a, 0x12, 0x6d, 0x69, 0x6e, 0x56, 0x6f, 0x69, 0x63, 0x65, 0x44, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x4d, 0x53, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x01, 0x52, 0x12, 0x6d,
+	0x69, 0x6e, 0x56, 0x6f, 0x69, 0x63, 0x65, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d,
+	0x53, 0x88, 0x01, 0x01, 0x12, 0x29, 0x0a, 0x0d, 0x6b, 0x65, 0x65, 0x70, 0x43, 0x6f, 0x6e, 0x74,
+	0x65, 0x78, 0x74, 0x4d, 0x53, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x02, 0x52, 0x0d, 0x6b,
+	0x65, 0x65, 0x70, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x4d, 0x53, 0x88, 0x01, 0x01, 0x42,
+	0x0e, 0x0a, 0x0c, 0x5f, 0x6c, 0x69, 0x62, 0x46, 0x56, 0x41, 0x44, 0x4d, 0x6f, 0x64, 0x65, 0x42,
+	0x15, 0x0a, 0x13, 0x5f, 0x6d, 0x69, 0x6e, 0x56, 0x6f, 0x69, 0x63, 0x65, 0x44, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x53, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x6b, 0x65, 0x65, 0x70, 0x43,
+	0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x4d, 0x53, 0x22, 0xa6, 0x01, 0x0a, 0x18, 0x57, 0x68, 0x69,
+	0x73, 0x70, 0x65, 0x72, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x69, 0x6e, 0x67, 0x50,
+	0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x27, 0x0a, 0x0c, 0x6d, 0x69, 0x6e, 0x53, 0x69, 0x6c, 0x65,
+	0x6e, 0x63, 0x65, 0x4d, 0x53, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x00, 0x52, 0x0c, 0x6d,
+	0x69, 0x6e, 0x53, 0x69, 0x6c, 0x65, 0x6e, 0x63, 0x65, 0x4d, 0x53, 0x88, 0x01, 0x01, 0x12, 0x37,
+	0x0a, 0x14, 0x6d, 0x61, 0x78, 0x55, 0x74, 0x74, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x4c, 0x65,
+	0x6e, 0x67, 0x74, 0x68, 0x4d, 0x53, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x01, 0x52, 0x14,
+	0x6d, 0x61, 0x78, 0x55, 0x74, 0x74, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x4c, 0x65, 0x6e, 0x67,
+	0x74, 0x68, 0x4d, 0x53, 0x88, 0x01, 0x01, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x6d, 0x69, 0x6e, 0x53,
+	0x69, 0x6c, 0x65, 0x6e, 0x63, 0x65, 0x4d, 0x53, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x6d, 0x61, 0x78,
+	0x55, 0x74, 0x74, 0x65, 0x72, 0x61, 0x6e, 0x63, 0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x4d,
+	0x53, 0x22, 0xaa, 0x06, 0x0a, 0x15, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x44, 0x65, 0x63,
+	0x6f, 0x64, 0x69, 0x6e, 0x67, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x1f, 0x0a, 0x08, 0x62,
+	0x65, 0x61, 0x6d, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x00, 0x52,
+	0x08, 0x62, 0x65, 0x61, 0x6d, 0x53, 0x69, 0x7a, 0x65, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06,
+	0x62, 0x65, 0x73, 0x74, 0x4f, 0x66, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x01, 0x52, 0x06,
+	0x62, 0x65, 0x73, 0x74, 0x4f, 0x66, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x0b, 0x74, 0x65, 0x6d,
+	0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x48, 0x02,
+	0x52, 0x0b, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x88, 0x01, 0x01,
+	0x12, 0x2b, 0x0a, 0x0e, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x49,
+	0x6e, 0x63, 0x18, 0x04, 0x20, 0x01, 0x28, 0x02, 0x48, 0x03, 0x52, 0x0e, 0x74, 0x65, 0x6d, 0x70,
+	0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x49, 0x6e, 0x63, 0x88, 0x01, 0x01, 0x12, 0x2f, 0x0a,
+	0x10, 0x65, 0x6e, 0x74, 0x72, 0x6f, 0x70, 0x79, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c,
+	0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x02, 0x48, 0x04, 0x52, 0x10, 0x65, 0x6e, 0x74, 0x72, 0x6f,
+	0x70, 0x79, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x88, 0x01, 0x01, 0x12, 0x2f,
+	0x0a, 0x10, 0x6c, 0x6f, 0x67, 0x70, 0x72, 0x6f, 0x62, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f,
+	0x6c, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x02, 0x48, 0x05, 0x52, 0x10, 0x6c, 0x6f, 0x67, 0x70,
+	0x72, 0x6f, 0x62, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x88, 0x01, 0x01, 0x12,
+	0x31, 0x0a, 0x11, 0x6e, 0x6f, 0x53, 0x70, 0x65, 0x65, 0x63, 0x68, 0x54, 0x68, 0x72, 0x65, 0x73,
+	0x68, 0x6f, 0x6c, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x02, 0x48, 0x06, 0x52, 0x11, 0x6e, 0x6f,
+	0x53, 0x70, 0x65, 0x65, 0x63, 0x68, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x88,
+	0x01, 0x01, 0x12, 0x29, 0x0a, 0x0d, 0x73, 0x75, 0x70, 0x70, 0x72, 0x65, 0x73, 0x73, 0x42, 0x6c,
+	0x61, 0x6e, 0x6b, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x48, 0x07, 0x52, 0x0d, 0x73, 0x75, 0x70,
+	0x70, 0x72, 0x65, 0x73, 0x73, 0x42, 0x6c, 0x61, 0x6e, 0x6b, 0x88, 0x01, 0x01, 0x12, 0x3d, 0x0a,
+	0x17, 0x73, 0x75, 0x70, 0x70, 0x72, 0x65, 0x73, 0x73, 0x4e, 0x6f, 0x6e, 0x53, 0x70, 0x65, 0x65,
+	0x63, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x48, 0x08,
+	0x52, 0x17, 0x73, 0x75, 0x70, 0x70, 0x72, 0x65, 0x73, 0x73, 0x4e, 0x6f, 0x6e, 0x53, 0x70, 0x65,
+	0x65, 0x63, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x88, 0x01, 0x01, 0x12, 0x2f, 0x0a, 0x10,
+	0x6d, 0x61, 0x78, 0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x09, 0x52, 0x10, 0x6d, 0x61, 0x78, 0x53, 0x65, 0x67,
+	0x6d, 0x65, 0x6e, 0x74, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a,
+	0x0b, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x4f, 0x6e, 0x57, 0x6f, 0x72, 0x64, 0x18, 0x0c, 0x20, 0x01,
+	0x28, 0x08, 0x48, 0x0a, 0x52, 0x0b, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x4f, 0x6e, 0x57, 0x6f, 0x72,
+	0x64, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x07, 0x74, 0x68, 0x72, 0x65, 0x61, 0x64, 0x73, 0x18,
+	0x0d, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x0b, 0x52, 0x07, 0x74, 0x68, 0x72, 0x65, 0x61, 0x64, 0x73,
+	0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09, 0x6e, 0x6f, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74,
+	0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x48, 0x0c, 0x52, 0x09, 0x6e, 0x6f, 0x43, 0x6f, 0x6e, 0x74,
+	0x65, 0x78, 0x74, 0x88, 0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x62, 0x65, 0x61, 0x6d, 0x53,
+	0x69, 0x7a, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x62, 0x65, 0x73, 0x74, 0x4f, 0x66, 0x42, 0x0e,
+	0x0a, 0x0c, 0x5f, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x42, 0x11,
+	0x0a, 0x0f, 0x5f, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x49, 0x6e,
+	0x63, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x6f, 0x70, 0x79, 0x54, 0x68, 0x72,
+	0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x6c, 0x6f, 0x67, 0x70, 0x72,
+	0x6f, 0x62, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x42, 0x14, 0x0a, 0x12, 0x5f,
+	0x6e, 0x6f, 0x53, 0x70, 0x65, 0x65, 0x63, 0x68, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c,
+	0x64, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x73, 0x75, 0x70, 0x70, 0x72, 0x65, 0x73, 0x73, 0x42, 0x6c,
+	0x61, 0x6e, 0x6b, 0x42, 0x1a, 0x0a, 0x18, 0x5f, 0x73, 0x75, 0x70, 0x70, 0x72, 0x65, 0x73, 0x73,
+	0x4e, 0x6f, 0x6e, 0x53, 0x70, 0x65, 0x65, 0x63, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x42,
+	0x13, 0x0a, 0x11, 0x5f, 0x6d, 0x61, 0x78, 0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x4c, 0x65,
+	0x6e, 0x67, 0x74, 0x68, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x4f, 0x6e,
+	0x57, 0x6f, 0x72, 0x64, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x61, 0x64, 0x73,
+	0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x6e, 0x6f, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x22, 0xec,
+	0x06, 0x0a, 0x0e, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x12, 0x51, 0x0a, 0x10, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x72,
+	0x61, 0x74, 0x65, 0x67, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x25, 0x2e, 0x73, 0x70,
+	0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x57, 0x68, 0x69, 0x73, 0x70,
+	0x65, 0x72, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65,
+	0x67, 0x79, 0x52, 0x10, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x69, 0x6e, 0x67, 0x53, 0x74, 0x72, 0x61,
+	0x74, 0x65, 0x67, 0x79, 0x12, 0x60, 0x0a, 0x15, 0x61, 0x6c, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e,
+	0x74, 0x41, 0x68, 0x65, 0x61, 0x64, 0x73, 0x50, 0x72, 0x65, 0x73, 0x65, 0x74, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x2a, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65,
+	0x78, 0x74, 0x2e, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x41, 0x6c, 0x69, 0x67, 0x6e, 0x6d,
+	0x65, 0x6e, 0x74, 0x41, 0x68, 0x65, 0x61, 0x64, 0x73, 0x50, 0x72, 0x65, 0x73, 0x65, 0x74, 0x52,
 	0x15, 0x61, 0x6c, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x41, 0x68, 0x65, 0x61, 0x64, 0x73,
-	0x50, 0x72, 0x65, 0x73, 0x65, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2a, 0x2e, 0x73,
-	0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x57, 0x68, 0x69, 0x73,
-	0x70, 0x65, 0x72, 0x41, 0x6c, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x41, 0x68, 0x65, 0x61,
-	0x64, 0x73, 0x50, 0x72, 0x65, 0x73, 0x65, 0x74, 0x52, 0x15, 0x61, 0x6c, 0x69, 0x67, 0x6e, 0x6d,
-	0x65, 0x6e, 0x74, 0x41, 0x68, 0x65, 0x61, 0x64, 0x73, 0x50, 0x72, 0x65, 0x73, 0x65, 0x74, 0x22,
-	0xe2, 0x01, 0x0a, 0x11, 0x4e, 0x65, 0x77, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x42, 0x79,
-	0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x6d, 0x6f, 0x64, 0x65, 0x6c,
-	0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67,
-	0x65, 0x12, 0x28, 0x0a, 0x0f, 0x73, 0x68, 0x6f, 0x75, 0x6c, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73,
-	0x6c, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x73, 0x68, 0x6f, 0x75,
-	0x6c, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x76,
-	0x61, 0x64, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x02, 0x52, 0x0c, 0x76, 0x61, 0x64, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12,
-	0x38, 0x0a, 0x07, 0x77, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x1c, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e,
-	0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x48, 0x00,
-	0x52, 0x07, 0x77, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x42, 0x09, 0x0a, 0x07, 0x42, 0x61, 0x63,
+	0x50, 0x72, 0x65, 0x73, 0x65, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x69, 0x61, 0x72, 0x69, 0x7a,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x69, 0x61, 0x72, 0x69, 0x7a, 0x65,
+	0x12, 0x3e, 0x0a, 0x1a, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x48, 0x61, 0x6c, 0x6c,
+	0x75, 0x63, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x1a, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x48, 0x61,
+	0x6c, 0x6c, 0x75, 0x63, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x75, 0x6c, 0x65, 0x73,
+	0x12, 0x4f, 0x0a, 0x12, 0x68, 0x61, 0x6c, 0x6c, 0x75, 0x63, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x73,
+	0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x48, 0x61, 0x6c, 0x6c,
+	0x75, 0x63, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x12, 0x68,
+	0x61, 0x6c, 0x6c, 0x75, 0x63, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x75, 0x6c, 0x65,
+	0x73, 0x12, 0x28, 0x0a, 0x0f, 0x65, 0x6d, 0x69, 0x74, 0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x65, 0x6d, 0x69, 0x74,
+	0x41, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x4b, 0x0a, 0x0e, 0x64,
+	0x65, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65,
+	0x78, 0x74, 0x2e, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x69,
+	0x6e, 0x67, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x0e, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x69,
+	0x6e, 0x67, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x57, 0x0a, 0x12, 0x63, 0x6f, 0x6d, 0x6d,
+	0x69, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x50, 0x72, 0x65, 0x73, 0x65, 0x74, 0x18, 0x0b,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x27, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74,
+	0x65, 0x78, 0x74, 0x2e, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x69,
+	0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x50, 0x72, 0x65, 0x73, 0x65, 0x74, 0x52, 0x12, 0x63,
+	0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x50, 0x72, 0x65, 0x73, 0x65,
+	0x74, 0x12, 0x3c, 0x0a, 0x09, 0x76, 0x61, 0x64, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x0c,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74,
+	0x65, 0x78, 0x74, 0x2e, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x56, 0x41, 0x44, 0x50, 0x61,
+	0x72, 0x61, 0x6d, 0x73, 0x52, 0x09, 0x76, 0x61, 0x64, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12,
+	0x48, 0x0a, 0x0b, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74,
+	0x65, 0x78, 0x74, 0x2e, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x45, 0x6e, 0x64, 0x70, 0x6f,
+	0x69, 0x6e, 0x74, 0x69, 0x6e, 0x67, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x0b, 0x65, 0x6e,
+	0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x4a, 0x0a, 0x0e, 0x61, 0x6c, 0x69,
+	0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x48, 0x65, 0x61, 0x64, 0x73, 0x18, 0x0e, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x22, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74,
+	0x2e, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x41, 0x6c, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e,
+	0x74, 0x48, 0x65, 0x61, 0x64, 0x52, 0x0e, 0x61, 0x6c, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74,
+	0x48, 0x65, 0x61, 0x64, 0x73, 0x12, 0x2e, 0x0a, 0x12, 0x61, 0x6c, 0x69, 0x67, 0x6e, 0x6d, 0x65,
+	0x6e, 0x74, 0x48, 0x65, 0x61, 0x64, 0x73, 0x4e, 0x54, 0x6f, 0x70, 0x18, 0x0f, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x12, 0x61, 0x6c, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x48, 0x65, 0x61, 0x64,
+	0x73, 0x4e, 0x54, 0x6f, 0x70, 0x12, 0x26, 0x0a, 0x0e, 0x77, 0x6f, 0x72, 0x64, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x73, 0x18, 0x10, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x77,
+	0x6f, 0x72, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x73, 0x22, 0x48, 0x0a,
+	0x14, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x41, 0x6c, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e,
+	0x74, 0x48, 0x65, 0x61, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x65, 0x78, 0x74, 0x4c, 0x61, 0x79,
+	0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x74, 0x65, 0x78, 0x74, 0x4c, 0x61,
+	0x79, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x65, 0x61, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x04, 0x68, 0x65, 0x61, 0x64, 0x22, 0xcc, 0x01, 0x0a, 0x13, 0x50, 0x72, 0x65, 0x70,
+	0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12,
+	0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x44, 0x43, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x08, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x44, 0x43, 0x12, 0x2a, 0x0a, 0x10, 0x68,
+	0x69, 0x67, 0x68, 0x50, 0x61, 0x73, 0x73, 0x43, 0x75, 0x74, 0x6f, 0x66, 0x66, 0x48, 0x7a, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x02, 0x52, 0x10, 0x68, 0x69, 0x67, 0x68, 0x50, 0x61, 0x73, 0x73, 0x43,
+	0x75, 0x74, 0x6f, 0x66, 0x66, 0x48, 0x7a, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x6e, 0x6f, 0x69,
+	0x73, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x65, 0x6e, 0x6f, 0x69, 0x73,
+	0x65, 0x12, 0x39, 0x0a, 0x15, 0x6e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x4c, 0x6f,
+	0x75, 0x64, 0x6e, 0x65, 0x73, 0x73, 0x44, 0x42, 0x46, 0x53, 0x18, 0x04, 0x20, 0x01, 0x28, 0x02,
+	0x48, 0x00, 0x52, 0x15, 0x6e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x4c, 0x6f, 0x75,
+	0x64, 0x6e, 0x65, 0x73, 0x73, 0x44, 0x42, 0x46, 0x53, 0x88, 0x01, 0x01, 0x42, 0x18, 0x0a, 0x16,
+	0x5f, 0x6e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x4c, 0x6f, 0x75, 0x64, 0x6e, 0x65,
+	0x73, 0x73, 0x44, 0x42, 0x46, 0x53, 0x22, 0xfb, 0x03, 0x0a, 0x11, 0x4e, 0x65, 0x77, 0x43, 0x6f,
+	0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a,
+	0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x42, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x0a, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08,
+	0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x73, 0x68, 0x6f, 0x75,
+	0x6c, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0f, 0x73, 0x68, 0x6f, 0x75, 0x6c, 0x64, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61,
+	0x74, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x76, 0x61, 0x64, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f,
+	0x6c, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0c, 0x76, 0x61, 0x64, 0x54, 0x68, 0x72,
+	0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x38, 0x0a, 0x07, 0x77, 0x68, 0x69, 0x73, 0x70, 0x65,
+	0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68,
+	0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x48, 0x00, 0x52, 0x07, 0x77, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72,
+	0x12, 0x24, 0x0a, 0x0d, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x50, 0x72, 0x6f, 0x6d, 0x70,
+	0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c,
+	0x50, 0x72, 0x6f, 0x6d, 0x70, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x62, 0x6f, 0x6f, 0x73, 0x74, 0x50,
+	0x68, 0x72, 0x61, 0x73, 0x65, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x62, 0x6f,
+	0x6f, 0x73, 0x74, 0x50, 0x68, 0x72, 0x61, 0x73, 0x65, 0x73, 0x12, 0x2e, 0x0a, 0x12, 0x63, 0x61,
+	0x72, 0x72, 0x79, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x50, 0x72, 0x6f, 0x6d, 0x70, 0x74,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12, 0x63, 0x61, 0x72, 0x72, 0x79, 0x46, 0x6f, 0x72,
+	0x77, 0x61, 0x72, 0x64, 0x50, 0x72, 0x6f, 0x6d, 0x70, 0x74, 0x12, 0x2a, 0x0a, 0x10, 0x61, 0x6c,
+	0x6c, 0x6f, 0x77, 0x65, 0x64, 0x4c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x73, 0x18, 0x09,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x4c, 0x61, 0x6e,
+	0x67, 0x75, 0x61, 0x67, 0x65, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x65, 0x6d, 0x69, 0x74, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0f, 0x65, 0x6d, 0x69, 0x74, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x47, 0x0a, 0x0d, 0x70, 0x72, 0x65, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e,
+	0x67, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68,
+	0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x50, 0x72, 0x65, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73,
+	0x73, 0x69, 0x6e, 0x67, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x0d, 0x70, 0x72, 0x65, 0x70,
+	0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x42, 0x09, 0x0a, 0x07, 0x42, 0x61, 0x63,
 	0x6b, 0x65, 0x6e, 0x64, 0x22, 0x2f, 0x0a, 0x0f, 0x4e, 0x65, 0x77, 0x43, 0x6f, 0x6e, 0x74, 0x65,
 	0x78, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x74, 0x65,
 	0x78, 0x74, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x74,
@@ -1007,7 +2253,7 @@ var file_speechtotext_proto_rawDesc = []byte{
 	0x63, 0x72, 0x69, 0x70, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x70,
 	0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73,
 	0x63, 0x72, 0x69, 0x70, 0x74, 0x52, 0x0a, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70,
-	0x74, 0x22, 0xc7, 0x01, 0x0a, 0x0a, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x74, 0x22, 0xab, 0x03, 0x0a, 0x0a, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
 	0x12, 0x3b, 0x0a, 0x08, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03,
 	0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78,
 	0x74, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x56, 0x61, 0x72, 0x69,
@@ -1019,27 +2265,61 @@ var file_speechtotext_proto_rawDesc = []byte{
 	0x65, 0x6c, 0x4e, 0x75, 0x6d, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67,
 	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67,
 	0x65, 0x12, 0x18, 0x0a, 0x07, 0x69, 0x73, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x07, 0x69, 0x73, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x22, 0x92, 0x01, 0x0a, 0x11,
-	0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e,
-	0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x49, 0x0a, 0x10, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72,
-	0x69, 0x70, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x1d, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x54,
-	0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x10,
-	0x74, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73,
-	0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x02, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65,
-	0x22, 0xa7, 0x01, 0x0a, 0x0f, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x54,
-	0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x24, 0x0a, 0x0d, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d,
-	0x65, 0x4e, 0x61, 0x6e, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x73, 0x74, 0x61,
-	0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x4e, 0x61, 0x6e, 0x6f, 0x12, 0x20, 0x0a, 0x0b, 0x65, 0x6e,
-	0x64, 0x54, 0x69, 0x6d, 0x65, 0x4e, 0x61, 0x6e, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
-	0x0b, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x4e, 0x61, 0x6e, 0x6f, 0x12, 0x12, 0x0a, 0x04,
-	0x74, 0x65, 0x78, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74,
-	0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x02, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65,
-	0x12, 0x18, 0x0a, 0x07, 0x53, 0x70, 0x65, 0x61, 0x6b, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x07, 0x53, 0x70, 0x65, 0x61, 0x6b, 0x65, 0x72, 0x22, 0x15, 0x0a, 0x13, 0x43, 0x6c,
+	0x28, 0x08, 0x52, 0x07, 0x69, 0x73, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x12, 0x30, 0x0a, 0x04, 0x6b,
+	0x69, 0x6e, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x73, 0x70, 0x65, 0x65,
+	0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x1c, 0x0a,
+	0x09, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x09, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x12, 0x1a, 0x0a, 0x08, 0x72,
+	0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x72,
+	0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x69, 0x73, 0x52, 0x65, 0x74,
+	0x72, 0x61, 0x63, 0x74, 0x65, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x69, 0x73,
+	0x52, 0x65, 0x74, 0x72, 0x61, 0x63, 0x74, 0x65, 0x64, 0x12, 0x2e, 0x0a, 0x12, 0x6c, 0x61, 0x6e,
+	0x67, 0x75, 0x61, 0x67, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x02, 0x52, 0x12, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x69, 0x73, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0d, 0x69, 0x73, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22,
+	0x92, 0x01, 0x0a, 0x11, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x56, 0x61,
+	0x72, 0x69, 0x61, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x65, 0x78, 0x74, 0x12, 0x49, 0x0a, 0x10, 0x74, 0x72, 0x61,
+	0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65,
+	0x78, 0x74, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x52, 0x10, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e,
+	0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64,
+	0x65, 0x6e, 0x63, 0x65, 0x22, 0xe9, 0x01, 0x0a, 0x0f, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x24, 0x0a, 0x0d, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x54, 0x69, 0x6d, 0x65, 0x4e, 0x61, 0x6e, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0d, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x4e, 0x61, 0x6e, 0x6f, 0x12, 0x20,
+	0x0a, 0x0b, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x4e, 0x61, 0x6e, 0x6f, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0b, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x4e, 0x61, 0x6e, 0x6f,
+	0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x78, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x74, 0x65, 0x78, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e,
+	0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64,
+	0x65, 0x6e, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x70, 0x65, 0x61, 0x6b, 0x65, 0x72, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x53, 0x70, 0x65, 0x61, 0x6b, 0x65, 0x72, 0x12, 0x1c,
+	0x0a, 0x09, 0x73, 0x74, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x02, 0x52, 0x09, 0x73, 0x74, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x22, 0x0a, 0x0c,
+	0x69, 0x73, 0x44, 0x54, 0x57, 0x41, 0x6c, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0c, 0x69, 0x73, 0x44, 0x54, 0x57, 0x41, 0x6c, 0x69, 0x67, 0x6e, 0x65, 0x64,
+	0x22, 0x4b, 0x0a, 0x15, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x4c, 0x61, 0x6e, 0x67, 0x75, 0x61,
+	0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e,
+	0x74, 0x65, 0x78, 0x74, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x63, 0x6f,
+	0x6e, 0x74, 0x65, 0x78, 0x74, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x75, 0x64, 0x69, 0x6f,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x22, 0x56, 0x0a,
+	0x13, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x4c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x12, 0x3f, 0x0a, 0x09, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68,
+	0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x4c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x50,
+	0x72, 0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x52, 0x09, 0x6c, 0x61, 0x6e, 0x67,
+	0x75, 0x61, 0x67, 0x65, 0x73, 0x22, 0x53, 0x0a, 0x13, 0x4c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67,
+	0x65, 0x50, 0x72, 0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x1a, 0x0a, 0x08,
+	0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x70, 0x72, 0x6f, 0x62,
+	0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x02, 0x52, 0x0b, 0x70,
+	0x72, 0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x22, 0x15, 0x0a, 0x13, 0x43, 0x6c,
 	0x6f, 0x73, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
 	0x74, 0x22, 0x13, 0x0a, 0x11, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78,
 	0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x2a, 0x8a, 0x01, 0x0a, 0x17, 0x57, 0x68, 0x69, 0x73, 0x70,
@@ -1088,29 +2368,73 @@ var file_speechtotext_proto_rawDesc = []byte{
 	0x73, 0x65, 0x74, 0x4c, 0x61, 0x72, 0x67, 0x65, 0x56, 0x32, 0x10, 0x0d, 0x12, 0x27, 0x0a, 0x23,
 	0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x41, 0x6c, 0x69, 0x67, 0x6e, 0x6d, 0x65, 0x6e, 0x74,
 	0x41, 0x68, 0x65, 0x61, 0x64, 0x73, 0x50, 0x72, 0x65, 0x73, 0x65, 0x74, 0x4c, 0x61, 0x72, 0x67,
-	0x65, 0x56, 0x33, 0x10, 0x0e, 0x32, 0xc2, 0x02, 0x0a, 0x0c, 0x53, 0x70, 0x65, 0x65, 0x63, 0x68,
-	0x54, 0x6f, 0x54, 0x65, 0x78, 0x74, 0x12, 0x3c, 0x0a, 0x04, 0x50, 0x69, 0x6e, 0x67, 0x12, 0x19,
-	0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x50, 0x69,
-	0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x73, 0x70, 0x65, 0x65,
-	0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x70,
-	0x6c, 0x79, 0x22, 0x00, 0x12, 0x50, 0x0a, 0x0a, 0x4e, 0x65, 0x77, 0x43, 0x6f, 0x6e, 0x74, 0x65,
-	0x78, 0x74, 0x12, 0x1f, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78,
-	0x74, 0x2e, 0x4e, 0x65, 0x77, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65,
-	0x78, 0x74, 0x2e, 0x4e, 0x65, 0x77, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x70,
-	0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x12, 0x50, 0x0a, 0x0a, 0x57, 0x72, 0x69, 0x74, 0x65, 0x41,
-	0x75, 0x64, 0x69, 0x6f, 0x12, 0x1f, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74,
-	0x65, 0x78, 0x74, 0x2e, 0x57, 0x72, 0x69, 0x74, 0x65, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f,
-	0x74, 0x65, 0x78, 0x74, 0x2e, 0x57, 0x72, 0x69, 0x74, 0x65, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x52,
-	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x28, 0x01, 0x12, 0x50, 0x0a, 0x0a, 0x4f, 0x75, 0x74, 0x70,
-	0x75, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x12, 0x1f, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74,
-	0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x43, 0x68, 0x61, 0x6e,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68,
-	0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x43, 0x68, 0x61,
-	0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x42, 0x16, 0x5a, 0x14, 0x67, 0x6f,
-	0x2f, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x5f, 0x67, 0x72,
-	0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x65, 0x56, 0x33, 0x10, 0x0e, 0x2a, 0x9e, 0x01, 0x0a, 0x15, 0x48, 0x61, 0x6c, 0x6c, 0x75, 0x63,
+	0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x75, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x1e, 0x0a, 0x1a, 0x48, 0x61, 0x6c, 0x6c, 0x75, 0x63, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x75, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x45, 0x78, 0x61, 0x63, 0x74, 0x10, 0x00, 0x12,
+	0x23, 0x0a, 0x1f, 0x48, 0x61, 0x6c, 0x6c, 0x75, 0x63, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x75, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x4e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a,
+	0x65, 0x64, 0x10, 0x01, 0x12, 0x1e, 0x0a, 0x1a, 0x48, 0x61, 0x6c, 0x6c, 0x75, 0x63, 0x69, 0x6e,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x75, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x65, 0x67,
+	0x65, 0x78, 0x10, 0x02, 0x12, 0x20, 0x0a, 0x1c, 0x48, 0x61, 0x6c, 0x6c, 0x75, 0x63, 0x69, 0x6e,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x75, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x45, 0x6e, 0x74,
+	0x72, 0x6f, 0x70, 0x79, 0x10, 0x03, 0x2a, 0x92, 0x01, 0x0a, 0x19, 0x57, 0x68, 0x69, 0x73, 0x70,
+	0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x50, 0x72,
+	0x65, 0x73, 0x65, 0x74, 0x12, 0x25, 0x0a, 0x21, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x43,
+	0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x50, 0x72, 0x65, 0x73, 0x65,
+	0x74, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x64, 0x10, 0x00, 0x12, 0x27, 0x0a, 0x23, 0x57,
+	0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x50, 0x72, 0x65, 0x73, 0x65, 0x74, 0x4c, 0x6f, 0x77, 0x4c, 0x61, 0x74, 0x65, 0x6e,
+	0x63, 0x79, 0x10, 0x01, 0x12, 0x25, 0x0a, 0x21, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x43,
+	0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x50, 0x72, 0x65, 0x73, 0x65,
+	0x74, 0x41, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x10, 0x02, 0x2a, 0xa2, 0x01, 0x0a, 0x11,
+	0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x56, 0x41, 0x44, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x12, 0x19, 0x0a, 0x15, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x56, 0x41, 0x44, 0x42,
+	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x41, 0x75, 0x74, 0x6f, 0x10, 0x00, 0x12, 0x19, 0x0a, 0x15,
+	0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x56, 0x41, 0x44, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x4e, 0x6f, 0x6e, 0x65, 0x10, 0x01, 0x12, 0x1b, 0x0a, 0x17, 0x57, 0x68, 0x69, 0x73, 0x70,
+	0x65, 0x72, 0x56, 0x41, 0x44, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x45, 0x6e, 0x65, 0x72,
+	0x67, 0x79, 0x10, 0x02, 0x12, 0x1c, 0x0a, 0x18, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x56,
+	0x41, 0x44, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x4c, 0x69, 0x62, 0x46, 0x56, 0x41, 0x44,
+	0x10, 0x03, 0x12, 0x1c, 0x0a, 0x18, 0x57, 0x68, 0x69, 0x73, 0x70, 0x65, 0x72, 0x56, 0x41, 0x44,
+	0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x4e, 0x4e, 0x6f, 0x69, 0x73, 0x65, 0x10, 0x04,
+	0x2a, 0x8b, 0x01, 0x0a, 0x0e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x4b,
+	0x69, 0x6e, 0x64, 0x12, 0x18, 0x0a, 0x14, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70,
+	0x74, 0x4b, 0x69, 0x6e, 0x64, 0x53, 0x70, 0x65, 0x65, 0x63, 0x68, 0x10, 0x00, 0x12, 0x1c, 0x0a,
+	0x18, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x4b, 0x69, 0x6e, 0x64, 0x53,
+	0x6f, 0x75, 0x6e, 0x64, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x10, 0x01, 0x12, 0x17, 0x0a, 0x13, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x4b, 0x69, 0x6e, 0x64, 0x4d, 0x75, 0x73,
+	0x69, 0x63, 0x10, 0x02, 0x12, 0x28, 0x0a, 0x24, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x63, 0x72, 0x69,
+	0x70, 0x74, 0x4b, 0x69, 0x6e, 0x64, 0x53, 0x75, 0x73, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x48,
+	0x61, 0x6c, 0x6c, 0x75, 0x63, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x10, 0x03, 0x32, 0x9e,
+	0x03, 0x0a, 0x0c, 0x53, 0x70, 0x65, 0x65, 0x63, 0x68, 0x54, 0x6f, 0x54, 0x65, 0x78, 0x74, 0x12,
+	0x3c, 0x0a, 0x04, 0x50, 0x69, 0x6e, 0x67, 0x12, 0x19, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68,
+	0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x17, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78,
+	0x74, 0x2e, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x50, 0x0a,
+	0x0a, 0x4e, 0x65, 0x77, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x1f, 0x2e, 0x73, 0x70,
+	0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x4e, 0x65, 0x77, 0x43, 0x6f,
+	0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x73,
+	0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x4e, 0x65, 0x77, 0x43,
+	0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x30, 0x01, 0x12,
+	0x50, 0x0a, 0x0a, 0x57, 0x72, 0x69, 0x74, 0x65, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x12, 0x1f, 0x2e,
+	0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x57, 0x72, 0x69,
+	0x74, 0x65, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d,
+	0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x57, 0x72,
+	0x69, 0x74, 0x65, 0x41, 0x75, 0x64, 0x69, 0x6f, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x28,
+	0x01, 0x12, 0x50, 0x0a, 0x0a, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x12,
+	0x1f, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x4f,
+	0x75, 0x74, 0x70, 0x75, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1d, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e,
+	0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
+	0x00, 0x30, 0x01, 0x12, 0x5a, 0x0a, 0x0e, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x4c, 0x61, 0x6e,
+	0x67, 0x75, 0x61, 0x67, 0x65, 0x12, 0x23, 0x2e, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f,
+	0x74, 0x65, 0x78, 0x74, 0x2e, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x4c, 0x61, 0x6e, 0x67, 0x75,
+	0x61, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x73, 0x70, 0x65,
+	0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74,
+	0x4c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42,
+	0x16, 0x5a, 0x14, 0x67, 0x6f, 0x2f, 0x73, 0x70, 0x65, 0x65, 0x63, 0x68, 0x74, 0x6f, 0x74, 0x65,
+	0x78, 0x74, 0x5f, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1125,46 +2449,72 @@ func file_speechtotext_proto_rawDescGZIP() []byte {
 	return file_speechtotext_proto_rawDescData
 }
 
-var file_speechtotext_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_speechtotext_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_speechtotext_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_speechtotext_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
 var file_speechtotext_proto_goTypes = []interface{}{
 	(WhisperSamplingStrategy)(0),      // 0: speechtotext.WhisperSamplingStrategy
 	(WhisperAlignmentAheadsPreset)(0), // 1: speechtotext.WhisperAlignmentAheadsPreset
-	(*PingRequest)(nil),               // 2: speechtotext.PingRequest
-	(*PingReply)(nil),                 // 3: speechtotext.PingReply
-	(*WhisperOptions)(nil),            // 4: speechtotext.WhisperOptions
-	(*NewContextRequest)(nil),         // 5: speechtotext.NewContextRequest
-	(*NewContextReply)(nil),           // 6: speechtotext.NewContextReply
-	(*WriteAudioRequest)(nil),         // 7: speechtotext.WriteAudioRequest
-	(*WriteAudioReply)(nil),           // 8: speechtotext.WriteAudioReply
-	(*OutputChanRequest)(nil),         // 9: speechtotext.OutputChanRequest
-	(*OutputChanReply)(nil),           // 10: speechtotext.OutputChanReply
-	(*Transcript)(nil),                // 11: speechtotext.Transcript
-	(*TranscriptVariant)(nil),         // 12: speechtotext.TranscriptVariant
-	(*TranscriptToken)(nil),           // 13: speechtotext.TranscriptToken
-	(*CloseContextRequest)(nil),       // 14: speechtotext.CloseContextRequest
-	(*CloseContextReply)(nil),         // 15: speechtotext.CloseContextReply
+	(HallucinationRuleType)(0),        // 2: speechtotext.HallucinationRuleType
+	(WhisperCommitPolicyPreset)(0),    // 3: speechtotext.WhisperCommitPolicyPreset
+	(WhisperVADBackend)(0),            // 4: speechtotext.WhisperVADBackend
+	(TranscriptKind)(0),               // 5: speechtotext.TranscriptKind
+	(*PingRequest)(nil),               // 6: speechtotext.PingRequest
+	(*PingReply)(nil),                 // 7: speechtotext.PingReply
+	(*HallucinationRule)(nil),         // 8: speechtotext.HallucinationRule
+	(*WhisperVADParams)(nil),          // 9: speechtotext.WhisperVADParams
+	(*WhisperEndpointingParams)(nil),  // 10: speechtotext.WhisperEndpointingParams
+	(*WhisperDecodingParams)(nil),     // 11: speechtotext.WhisperDecodingParams
+	(*WhisperOptions)(nil),            // 12: speechtotext.WhisperOptions
+	(*WhisperAlignmentHead)(nil),      // 13: speechtotext.WhisperAlignmentHead
+	(*PreprocessingParams)(nil),       // 14: speechtotext.PreprocessingParams
+	(*NewContextRequest)(nil),         // 15: speechtotext.NewContextRequest
+	(*NewContextReply)(nil),           // 16: speechtotext.NewContextReply
+	(*WriteAudioRequest)(nil),         // 17: speechtotext.WriteAudioRequest
+	(*WriteAudioReply)(nil),           // 18: speechtotext.WriteAudioReply
+	(*OutputChanRequest)(nil),         // 19: speechtotext.OutputChanRequest
+	(*OutputChanReply)(nil),           // 20: speechtotext.OutputChanReply
+	(*Transcript)(nil),                // 21: speechtotext.Transcript
+	(*TranscriptVariant)(nil),         // 22: speechtotext.TranscriptVariant
+	(*TranscriptToken)(nil),           // 23: speechtotext.TranscriptToken
+	(*DetectLanguageRequest)(nil),     // 24: speechtotext.DetectLanguageRequest
+	(*DetectLanguageReply)(nil),       // 25: speechtotext.DetectLanguageReply
+	(*LanguageProbability)(nil),       // 26: speechtotext.LanguageProbability
+	(*CloseContextRequest)(nil),       // 27: speechtotext.CloseContextRequest
+	(*CloseContextReply)(nil),         // 28: speechtotext.CloseContextReply
 }
 var file_speechtotext_proto_depIdxs = []int32{
-	0,  // 0: speechtotext.WhisperOptions.samplingStrategy:type_name -> speechtotext.WhisperSamplingStrategy
-	1,  // 1: speechtotext.WhisperOptions.alignmentAheadsPreset:type_name -> speechtotext.WhisperAlignmentAheadsPreset
-	4,  // 2: speechtotext.NewContextRequest.whisper:type_name -> speechtotext.WhisperOptions
-	11, // 3: speechtotext.OutputChanReply.transcript:type_name -> speechtotext.Transcript
-	12, // 4: speechtotext.Transcript.variants:type_name -> speechtotext.TranscriptVariant
-	13, // 5: speechtotext.TranscriptVariant.transcriptTokens:type_name -> speechtotext.TranscriptToken
-	2,  // 6: speechtotext.SpeechToText.Ping:input_type -> speechtotext.PingRequest
-	5,  // 7: speechtotext.SpeechToText.NewContext:input_type -> speechtotext.NewContextRequest
-	7,  // 8: speechtotext.SpeechToText.WriteAudio:input_type -> speechtotext.WriteAudioRequest
-	9,  // 9: speechtotext.SpeechToText.OutputChan:input_type -> speechtotext.OutputChanRequest
-	3,  // 10: speechtotext.SpeechToText.Ping:output_type -> speechtotext.PingReply
-	6,  // 11: speechtotext.SpeechToText.NewContext:output_type -> speechtotext.NewContextReply
-	8,  // 12: speechtotext.SpeechToText.WriteAudio:output_type -> speechtotext.WriteAudioReply
-	10, // 13: speechtotext.SpeechToText.OutputChan:output_type -> speechtotext.OutputChanReply
-	10, // [10:14] is the sub-list for method output_type
-	6,  // [6:10] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	2,  // 0: speechtotext.HallucinationRule.type:type_name -> speechtotext.HallucinationRuleType
+	4,  // 1: speechtotext.WhisperVADParams.backend:type_name -> speechtotext.WhisperVADBackend
+	0,  // 2: speechtotext.WhisperOptions.samplingStrategy:type_name -> speechtotext.WhisperSamplingStrategy
+	1,  // 3: speechtotext.WhisperOptions.alignmentAheadsPreset:type_name -> speechtotext.WhisperAlignmentAheadsPreset
+	8,  // 4: speechtotext.WhisperOptions.hallucinationRules:type_name -> speechtotext.HallucinationRule
+	11, // 5: speechtotext.WhisperOptions.decodingParams:type_name -> speechtotext.WhisperDecodingParams
+	3,  // 6: speechtotext.WhisperOptions.commitPolicyPreset:type_name -> speechtotext.WhisperCommitPolicyPreset
+	9,  // 7: speechtotext.WhisperOptions.vadParams:type_name -> speechtotext.WhisperVADParams
+	10, // 8: speechtotext.WhisperOptions.endpointing:type_name -> speechtotext.WhisperEndpointingParams
+	13, // 9: speechtotext.WhisperOptions.alignmentHeads:type_name -> speechtotext.WhisperAlignmentHead
+	12, // 10: speechtotext.NewContextRequest.whisper:type_name -> speechtotext.WhisperOptions
+	14, // 11: speechtotext.NewContextRequest.preprocessing:type_name -> speechtotext.PreprocessingParams
+	21, // 12: speechtotext.OutputChanReply.transcript:type_name -> speechtotext.Transcript
+	22, // 13: speechtotext.Transcript.variants:type_name -> speechtotext.TranscriptVariant
+	5,  // 14: speechtotext.Transcript.kind:type_name -> speechtotext.TranscriptKind
+	23, // 15: speechtotext.TranscriptVariant.transcriptTokens:type_name -> speechtotext.TranscriptToken
+	26, // 16: speechtotext.DetectLanguageReply.languages:type_name -> speechtotext.LanguageProbability
+	6,  // 17: speechtotext.SpeechToText.Ping:input_type -> speechtotext.PingRequest
+	15, // 18: speechtotext.SpeechToText.NewContext:input_type -> speechtotext.NewContextRequest
+	17, // 19: speechtotext.SpeechToText.WriteAudio:input_type -> speechtotext.WriteAudioRequest
+	19, // 20: speechtotext.SpeechToText.OutputChan:input_type -> speechtotext.OutputChanRequest
+	24, // 21: speechtotext.SpeechToText.DetectLanguage:input_type -> speechtotext.DetectLanguageRequest
+	7,  // 22: speechtotext.SpeechToText.Ping:output_type -> speechtotext.PingReply
+	16, // 23: speechtotext.SpeechToText.NewContext:output_type -> speechtotext.NewContextReply
+	18, // 24: speechtotext.SpeechToText.WriteAudio:output_type -> speechtotext.WriteAudioReply
+	20, // 25: speechtotext.SpeechToText.OutputChan:output_type -> speechtotext.OutputChanReply
+	25, // 26: speechtotext.SpeechToText.DetectLanguage:output_type -> speechtotext.DetectLanguageReply
+	22, // [22:27] is the sub-list for method output_type
+	17, // [17:22] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_speechtotext_proto_init() }
@@ -1198,7 +2548,7 @@ func file_speechtotext_proto_init() {
 			}
 		}
 		file_speechtotext_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WhisperOptions); i {
+			switch v := v.(*HallucinationRule); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1210,7 +2560,7 @@ func file_speechtotext_proto_init() {
 			}
 		}
 		file_speechtotext_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NewContextRequest); i {
+			switch v := v.(*WhisperVADParams); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1222,7 +2572,7 @@ func file_speechtotext_proto_init() {
 			}
 		}
 		file_speechtotext_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NewContextReply); i {
+			switch v := v.(*WhisperEndpointingParams); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1234,7 +2584,7 @@ func file_speechtotext_proto_init() {
 			}
 		}
 		file_speechtotext_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WriteAudioRequest); i {
+			switch v := v.(*WhisperDecodingParams); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1246,7 +2596,7 @@ func file_speechtotext_proto_init() {
 			}
 		}
 		file_speechtotext_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WriteAudioReply); i {
+			switch v := v.(*WhisperOptions); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1258,7 +2608,7 @@ func file_speechtotext_proto_init() {
 			}
 		}
 		file_speechtotext_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*OutputChanRequest); i {
+			switch v := v.(*WhisperAlignmentHead); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1270,7 +2620,7 @@ func file_speechtotext_proto_init() {
 			}
 		}
 		file_speechtotext_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*OutputChanReply); i {
+			switch v := v.(*PreprocessingParams); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1282,7 +2632,7 @@ func file_speechtotext_proto_init() {
 			}
 		}
 		file_speechtotext_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Transcript); i {
+			switch v := v.(*NewContextRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1294,7 +2644,7 @@ func file_speechtotext_proto_init() {
 			}
 		}
 		file_speechtotext_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TranscriptVariant); i {
+			switch v := v.(*NewContextReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1306,7 +2656,7 @@ func file_speechtotext_proto_init() {
 			}
 		}
 		file_speechtotext_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*TranscriptToken); i {
+			switch v := v.(*WriteAudioRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1318,7 +2668,7 @@ func file_speechtotext_proto_init() {
 			}
 		}
 		file_speechtotext_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CloseContextRequest); i {
+			switch v := v.(*WriteAudioReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1330,6 +2680,114 @@ func file_speechtotext_proto_init() {
 			}
 		}
 		file_speechtotext_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OutputChanRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_speechtotext_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OutputChanReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_speechtotext_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Transcript); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_speechtotext_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TranscriptVariant); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_speechtotext_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TranscriptToken); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_speechtotext_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DetectLanguageRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_speechtotext_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DetectLanguageReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_speechtotext_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LanguageProbability); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_speechtotext_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CloseContextRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_speechtotext_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CloseContextReply); i {
 			case 0:
 				return &v.state
@@ -1342,7 +2800,11 @@ func file_speechtotext_proto_init() {
 			}
 		}
 	}
-	file_speechtotext_proto_msgTypes[3].OneofWrappers = []interface{}{
+	file_speechtotext_proto_msgTypes[3].OneofWrappers = []interface{}{}
+	file_speechtotext_proto_msgTypes[4].OneofWrappers = []interface{}{}
+	file_speechtotext_proto_msgTypes[5].OneofWrappers = []interface{}{}
+	file_speechtotext_proto_msgTypes[8].OneofWrappers = []interface{}{}
+	file_speechtotext_proto_msgTypes[9].OneofWrappers = []interface{}{
 		(*NewContextRequest_Whisper)(nil),
 	}
 	type x struct{}
@@ -1350,8 +2812,8 @@ func file_speechtotext_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_speechtotext_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   14,
+			NumEnums:      6,
+			NumMessages:   23,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
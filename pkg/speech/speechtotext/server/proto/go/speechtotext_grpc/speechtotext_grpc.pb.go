@@ -21,6 +21,7 @@ type SpeechToTextClient interface {
 	NewContext(ctx context.Context, in *NewContextRequest, opts ...grpc.CallOption) (SpeechToText_NewContextClient, error)
 	WriteAudio(ctx context.Context, opts ...grpc.CallOption) (SpeechToText_WriteAudioClient, error)
 	OutputChan(ctx context.Context, in *OutputChanRequest, opts ...grpc.CallOption) (SpeechToText_OutputChanClient, error)
+	DetectLanguage(ctx context.Context, in *DetectLanguageRequest, opts ...grpc.CallOption) (*DetectLanguageReply, error)
 }
 
 type speechToTextClient struct {
@@ -138,6 +139,15 @@ func (x *speechToTextOutputChanClient) Recv() (*OutputChanReply, error) {
 	return m, nil
 }
 
+func (c *speechToTextClient) DetectLanguage(ctx context.Context, in *DetectLanguageRequest, opts ...grpc.CallOption) (*DetectLanguageReply, error) {
+	out := new(DetectLanguageReply)
+	err := c.cc.Invoke(ctx, "/speechtotext.SpeechToText/DetectLanguage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SpeechToTextServer is the server API for SpeechToText service.
 // All implementations must embed UnimplementedSpeechToTextServer
 // for forward compatibility
@@ -146,6 +156,7 @@ type SpeechToTextServer interface {
 	NewContext(*NewContextRequest, SpeechToText_NewContextServer) error
 	WriteAudio(SpeechToText_WriteAudioServer) error
 	OutputChan(*OutputChanRequest, SpeechToText_OutputChanServer) error
+	DetectLanguage(context.Context, *DetectLanguageRequest) (*DetectLanguageReply, error)
 	mustEmbedUnimplementedSpeechToTextServer()
 }
 
@@ -165,6 +176,9 @@ func (UnimplementedSpeechToTextServer) WriteAudio(SpeechToText_WriteAudioServer)
 func (UnimplementedSpeechToTextServer) OutputChan(*OutputChanRequest, SpeechToText_OutputChanServer) error {
 	return status.Errorf(codes.Unimplemented, "method OutputChan not implemented")
 }
+func (UnimplementedSpeechToTextServer) DetectLanguage(context.Context, *DetectLanguageRequest) (*DetectLanguageReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DetectLanguage not implemented")
+}
 func (UnimplementedSpeechToTextServer) mustEmbedUnimplementedSpeechToTextServer() {}
 
 // UnsafeSpeechToTextServer may be embedded to opt out of forward compatibility for this service.
@@ -264,6 +278,24 @@ func (x *speechToTextOutputChanServer) Send(m *OutputChanReply) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _SpeechToText_DetectLanguage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DetectLanguageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SpeechToTextServer).DetectLanguage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/speechtotext.SpeechToText/DetectLanguage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SpeechToTextServer).DetectLanguage(ctx, req.(*DetectLanguageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _SpeechToText_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "speechtotext.SpeechToText",
 	HandlerType: (*SpeechToTextServer)(nil),
@@ -272,6 +304,10 @@ var _SpeechToText_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Ping",
 			Handler:    _SpeechToText_Ping_Handler,
 		},
+		{
+			MethodName: "DetectLanguage",
+			Handler:    _SpeechToText_DetectLanguage_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -5,21 +5,29 @@ import (
 	"context"
 	"crypto/sha1"
 	"crypto/sha512"
+	"errors"
 	"fmt"
 	"net"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/facebookincubator/go-belt"
 	"github.com/facebookincubator/go-belt/tool/logger"
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/xaionaro-go/object"
 	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/speech/pkg/speech/diarization/mfcc"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/preprocessing"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/hallucination"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/consts"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/goconv"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/session"
 	"github.com/xaionaro-go/xcontext"
 	"github.com/xaionaro-go/xsync"
 	"google.golang.org/grpc"
@@ -149,8 +157,12 @@ func (srv *Server) NewContext(
 		modelBytes = srv.DefaultModel
 	}
 
+	// a diarizer is stateful (it remembers the speakers), thus
+	// a diarizing context is not reused by another session
+	isCacheable := srv.STTInitCacheSize > 0 && !req.GetWhisper().GetDiarize()
+
 	var requestHash objectHash
-	if srv.STTInitCacheSize > 0 {
+	if isCacheable {
 		logger.Debugf(ctx, "calculating the hash of the request")
 		req.ModelBytes = nil
 		requestHashValue, err := object.CalcCryptoHash(req, sha1.Sum(modelBytes))
@@ -162,7 +174,7 @@ func (srv *Server) NewContext(
 	}
 
 	stt := xsync.DoR1(ctx, &srv.STTInitCacheLocker, func() speech.ToText {
-		if srv.STTInitCacheSize <= 0 {
+		if !isCacheable {
 			return nil
 		}
 		var zeroHash objectHash
@@ -186,7 +198,59 @@ func (srv *Server) NewContext(
 		backend := req.GetBackend()
 		switch backend := backend.(type) {
 		case *speechtotext_grpc.NewContextRequest_Whisper:
-			var err error
+			opts := cfg.WhisperOptions
+			if backend.Whisper.GetDiarize() {
+				// a diarizer is stateful, so it is created per context (and such contexts are not cached)
+				opts = append(opts[:len(opts):len(opts)], whisper.OptionDiarizer{Diarizer: mfcc.New()})
+			}
+			if rules := goconv.HallucinationRulesFromGRPC(backend.Whisper.GetHallucinationRules()); backend.Whisper.GetOverrideHallucinationRules() {
+				opts = append(opts[:len(opts):len(opts)], whisper.OptionHallucinationRules(rules))
+			} else if len(rules) > 0 {
+				opts = append(opts[:len(opts):len(opts)], whisper.OptionExtraHallucinationRules(rules))
+			}
+			if backend.Whisper.GetEmitAnnotations() {
+				opts = append(opts[:len(opts):len(opts)], whisper.OptionEmitAnnotations(true))
+			}
+			opts = append(opts[:len(opts):len(opts)], goconv.DecodingParamsFromGRPC(backend.Whisper.GetDecodingParams())...)
+			commitPolicy, err := whisper.CommitPolicyFromPreset(goconv.CommitPolicyPresetFromGRPC(backend.Whisper.GetCommitPolicyPreset()))
+			if err != nil {
+				return status.Errorf(codes.InvalidArgument, "%v", err)
+			}
+			vadOpts, err := goconv.VADParamsFromGRPC(backend.Whisper.GetVadParams(), commitPolicy)
+			if err != nil {
+				return status.Errorf(codes.InvalidArgument, "%v", err)
+			}
+			opts = append(opts, vadOpts...)
+			opts = append(opts, goconv.EndpointingParamsFromGRPC(backend.Whisper.GetEndpointing())...)
+			opts = append(opts, whisper.OptionCommitPolicy(commitPolicy))
+			if req.GetInitialPrompt() != "" {
+				opts = append(opts, whisper.OptionInitialPrompt(req.GetInitialPrompt()))
+			}
+			if len(req.GetBoostPhrases()) > 0 {
+				opts = append(opts, whisper.OptionBoostPhrases(req.GetBoostPhrases()))
+			}
+			if req.GetCarryForwardPrompt() {
+				opts = append(opts, whisper.OptionCarryForwardPrompt(true))
+			}
+			if len(req.GetAllowedLanguages()) > 0 {
+				var allowedLanguages []speech.Language
+				for _, lang := range req.GetAllowedLanguages() {
+					allowedLanguages = append(allowedLanguages, speech.Language(lang))
+				}
+				opts = append(opts, whisper.OptionAllowedLanguages(allowedLanguages))
+			}
+			if req.GetEmitTranslation() {
+				opts = append(opts, whisper.OptionEmitTranslation(true))
+			}
+			if heads := backend.Whisper.GetAlignmentHeads(); len(heads) > 0 {
+				opts = append(opts, whisper.OptionAlignmentHeads(goconv.AlignmentHeadsFromGRPC(heads)))
+			}
+			if nTop := backend.Whisper.GetAlignmentHeadsNTop(); nTop > 0 {
+				opts = append(opts, whisper.OptionAlignmentHeadsNTop(nTop))
+			}
+			if backend.Whisper.GetWordTimestamps() {
+				opts = append(opts, whisper.OptionWordTimestamps(true))
+			}
 			stt, err = whisper.New(
 				xcontext.DetachDone(ctx),
 				modelBytes,
@@ -195,22 +259,50 @@ func (srv *Server) NewContext(
 				req.GetShouldTranslate(),
 				goconv.AlignmentAheadsPresetFromGRPC(backend.Whisper.GetAlignmentAheadsPreset()),
 				float64(req.GetVadThreshold()),
-				cfg.WhisperOptions...,
+				opts...,
 			)
 			if err != nil {
-				return status.Errorf(codes.Unknown, "unable to initialize a whisper instance: %v", err)
+				code := codes.Unknown
+				if isInvalidWhisperArgument(err) {
+					code = codes.InvalidArgument
+				}
+				return status.Errorf(code, "unable to initialize a whisper instance: %v", err)
 			}
 		default:
 			return status.Errorf(codes.InvalidArgument, "backend type %T is not supported, yet", backend)
 		}
+		var err error
+		stt, err = wrapPreprocessing(ctx, stt, req.GetPreprocessing())
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "%v", err)
+		}
 	}
 
 	contextID := srv.NextContextID.Add(1)
-	srv.ContextMap.Store(contextID, stt)
+	contextSTT := stt
+	var recorder *session.Recorder
+	if recordDir := srv.Options.config().RecordDir; recordDir != "" {
+		var err error
+		recorder, err = srv.newRecorder(ctx, stt, recordDir, contextID, req)
+		if err != nil {
+			logger.Errorf(ctx, "unable to start recording context %d: %v", contextID, err)
+		} else {
+			contextSTT = recorder
+		}
+	}
+	srv.ContextMap.Store(contextID, contextSTT)
 	defer func() {
 		logger.Debugf(ctx, "closing context %d", contextID)
 		srv.ContextMap.Delete(contextID)
-		if srv.STTInitCacheSize <= 0 {
+		if recorder != nil {
+			if err := recorder.Finish(); err != nil {
+				logger.Errorf(ctx, "unable to finish recording context %d: %v", contextID, err)
+			}
+		}
+		if stats, ok := hallucinationStats(ctx, stt); ok {
+			logger.Infof(ctx, "context %d hallucination stats: %+v", contextID, stats)
+		}
+		if !isCacheable {
 			logger.Debugf(ctx, "closing STT")
 			stt.Close()
 			return
@@ -243,6 +335,77 @@ func (srv *Server) NewContext(
 	return ctx.Err()
 }
 
+// isInvalidWhisperArgument returns true if whisper.New failed
+// because of the invalid parameters of the request.
+func isInvalidWhisperArgument(err error) bool {
+	return errors.As(err, &whisper.ErrInvalidEndpointing{}) ||
+		errors.As(err, &whisper.ErrEndpointingRequiresVAD{}) ||
+		errors.As(err, &whisper.ErrInvalidVADKeepContext{})
+}
+
+// wrapPreprocessing wraps the stt by the preprocessing enabled by the params
+// (if any); the stt is closed on failure.
+func wrapPreprocessing(
+	ctx context.Context,
+	stt speech.ToText,
+	params *speechtotext_grpc.PreprocessingParams,
+) (speech.ToText, error) {
+	opts := goconv.PreprocessingParamsFromGRPC(params)
+	if len(opts) == 0 {
+		return stt, nil
+	}
+	preprocessed, err := preprocessing.New(xcontext.DetachDone(ctx), stt, opts...)
+	if err != nil {
+		stt.Close()
+		return nil, fmt.Errorf("unable to initialize the preprocessing: %w", err)
+	}
+	return preprocessed, nil
+}
+
+// hallucinationStats returns the hallucination statistics of the stt
+// or of the backend wrapped by it (like by preprocessing).
+func hallucinationStats(
+	ctx context.Context,
+	stt speech.ToText,
+) (hallucination.Stats, bool) {
+	for {
+		if stt, ok := stt.(interface {
+			HallucinationStats(context.Context) hallucination.Stats
+		}); ok {
+			return stt.HallucinationStats(ctx), true
+		}
+		wrapper, ok := stt.(interface{ Backend() speech.ToText })
+		if !ok {
+			return hallucination.Stats{}, false
+		}
+		stt = wrapper.Backend()
+	}
+}
+
+func (srv *Server) newRecorder(
+	ctx context.Context,
+	stt speech.ToText,
+	recordDir string,
+	contextID uint64,
+	req *speechtotext_grpc.NewContextRequest,
+) (*session.Recorder, error) {
+	path := filepath.Join(recordDir, fmt.Sprintf("%s-%d%s", time.Now().Format("20060102-150405"), contextID, session.FileExtension))
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the session file '%s': %w", path, err)
+	}
+	recorder, err := session.NewRecorder(xcontext.DetachDone(ctx), stt, f, session.OptionMetadata{
+		"context_id": fmt.Sprint(contextID),
+		"language":   req.GetLanguage(),
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	logger.Infof(ctx, "recording context %d into '%s'", contextID, path)
+	return recorder, nil
+}
+
 func (srv *Server) WriteAudio(
 	reqSrv speechtotext_grpc.SpeechToText_WriteAudioServer,
 ) error {
@@ -310,3 +473,28 @@ func (srv *Server) OutputChan(
 		}
 	}
 }
+
+func (srv *Server) DetectLanguage(
+	ctx context.Context,
+	req *speechtotext_grpc.DetectLanguageRequest,
+) (*speechtotext_grpc.DetectLanguageReply, error) {
+	ctx = srv.ctx(ctx)
+
+	contextID := req.GetContextID()
+	sttI, ok := srv.ContextMap.Load(contextID)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "there is no open context with ID %d", contextID)
+	}
+	detector, ok := sttI.(speech.LanguageDetector)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "the backend of context %d does not support language detection", contextID)
+	}
+
+	langs, err := detector.DetectLanguage(ctx, req.GetAudio())
+	if err != nil {
+		return nil, status.Errorf(codes.Unknown, "unable to detect the language: %v", err)
+	}
+	return &speechtotext_grpc.DetectLanguageReply{
+		Languages: goconv.LanguageProbabilitiesToGRPC(langs),
+	}, nil
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/preprocessing"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/hallucination"
+	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+)
+
+type fakeBackend struct {
+	detectedAudio []byte
+}
+
+var (
+	_ speech.ToText           = (*fakeBackend)(nil)
+	_ speech.LanguageDetector = (*fakeBackend)(nil)
+)
+
+func (b *fakeBackend) AudioEncoding(context.Context) (audio.Encoding, error) {
+	return audio.EncodingPCM{PCMFormat: audio.PCMFormatFloat32LE, SampleRate: 16000}, nil
+}
+
+func (b *fakeBackend) AudioChannels(context.Context) (audio.Channel, error) {
+	return 1, nil
+}
+
+func (b *fakeBackend) WriteAudio(context.Context, []byte) error {
+	return nil
+}
+
+func (b *fakeBackend) OutputChan(context.Context) (<-chan *speech.Transcript, error) {
+	return nil, nil
+}
+
+func (b *fakeBackend) Close() error {
+	return nil
+}
+
+func (b *fakeBackend) DetectLanguage(_ context.Context, audio []byte) (speech.LanguageProbabilities, error) {
+	b.detectedAudio = audio
+	return speech.LanguageProbabilities{{Language: "de", Probability: 0.9}}, nil
+}
+
+func (b *fakeBackend) HallucinationStats(context.Context) hallucination.Stats {
+	return hallucination.Stats{Checked: 1}
+}
+
+func TestDetectLanguageWithPreprocessing(t *testing.T) {
+	ctx := context.Background()
+	backend := &fakeBackend{}
+	stt, err := wrapPreprocessing(ctx, backend, &speechtotext_grpc.PreprocessingParams{
+		RemoveDC:         true,
+		HighPassCutoffHz: 80,
+	})
+	require.NoError(t, err)
+	require.IsType(t, &preprocessing.SpeechToText{}, stt)
+
+	srv := NewServer(nil, 1, 0)
+	srv.ContextMap.Store(uint64(1), stt)
+	reply, err := srv.DetectLanguage(ctx, &speechtotext_grpc.DetectLanguageRequest{
+		ContextID: 1,
+		Audio:     []byte{1, 2, 3, 4},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []*speechtotext_grpc.LanguageProbability{{Language: "de", Probability: 0.9}}, reply.GetLanguages())
+	require.Equal(t, []byte{1, 2, 3, 4}, backend.detectedAudio)
+
+	stats, ok := hallucinationStats(ctx, stt)
+	require.True(t, ok)
+	require.Equal(t, uint64(1), stats.Checked)
+}
+
+func TestIsInvalidWhisperArgument(t *testing.T) {
+	require.True(t, isInvalidWhisperArgument(whisper.ErrInvalidEndpointing{}))
+	require.True(t, isInvalidWhisperArgument(fmt.Errorf("wrapped: %w", whisper.ErrInvalidVADKeepContext{})))
+	require.False(t, isInvalidWhisperArgument(whisper.ErrInitContext{}))
+}
@@ -0,0 +1,40 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/xaionaro-go/audio/pkg/audio"
+)
+
+type ErrUnsupportedVersion struct {
+	Version int
+}
+
+func (e ErrUnsupportedVersion) Error() string {
+	return fmt.Sprintf("unsupported session format version %d (expected %d)", e.Version, FormatVersion)
+}
+
+type ErrWriterClosed struct{}
+
+func (ErrWriterClosed) Error() string {
+	return "the session writer is closed"
+}
+
+type ErrInvalidSpeed struct {
+	Speed float64
+}
+
+func (e ErrInvalidSpeed) Error() string {
+	return fmt.Sprintf("the replay speed should be positive, but it is %v (speech.ToText does not report its capacity, so the audio written faster would be dropped)", e.Speed)
+}
+
+type ErrAudioFormatMismatch struct {
+	RecordedEncoding *audio.EncodingPCM
+	RecordedChannels audio.Channel
+	Encoding         audio.Encoding
+	Channels         audio.Channel
+}
+
+func (e ErrAudioFormatMismatch) Error() string {
+	return fmt.Sprintf("the session is recorded in %v (%d channels), but %v (%d channels) is expected", e.RecordedEncoding, e.RecordedChannels, e.Encoding, e.Channels)
+}
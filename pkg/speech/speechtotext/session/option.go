@@ -0,0 +1,60 @@
+package session
+
+import "time"
+
+type config struct {
+	Speed           float64
+	TrailingSilence time.Duration
+	Metadata        map[string]string
+}
+
+func defaultConfig() config {
+	return config{
+		Speed: 1,
+	}
+}
+
+type Option interface {
+	apply(*config)
+}
+
+type Options []Option
+
+func (opts Options) apply(cfg *config) {
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+}
+
+func (opts Options) config() config {
+	cfg := defaultConfig()
+	opts.apply(&cfg)
+	return cfg
+}
+
+// OptionSpeed is the speed of Replay relatively to the original one
+// (for example, 2 replays twice faster); it should be positive.
+//
+// Keep in mind that the backends drop the audio they could not keep up
+// with (for example, whisper drops the audio beyond its buffer limit).
+type OptionSpeed float64
+
+func (opt OptionSpeed) apply(cfg *config) {
+	cfg.Speed = float64(opt)
+}
+
+// OptionTrailingSilence makes Replay to write the given duration of
+// silence after the recorded audio (with the same speed), so that
+// the backend could finalize the transcript of the tail of the session.
+type OptionTrailingSilence time.Duration
+
+func (opt OptionTrailingSilence) apply(cfg *config) {
+	cfg.TrailingSilence = time.Duration(opt)
+}
+
+// OptionMetadata sets Header.Metadata of the session recorded by Recorder.
+type OptionMetadata map[string]string
+
+func (opt OptionMetadata) apply(cfg *config) {
+	cfg.Metadata = opt
+}
@@ -0,0 +1,189 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/hashicorp/go-multierror"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/observability"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+// Recorder is a speech.ToText, which records the audio passed to the
+// backend (with the arrival timestamps) and the transcripts emitted
+// by it into a session file.
+//
+// The recording errors are logged, but do not interrupt the transcription.
+type Recorder struct {
+	Backend speech.ToText
+
+	writer    *Writer
+	startedAt time.Time
+	now       func() time.Time
+
+	ctx            context.Context
+	cancelFn       context.CancelFunc
+	outputChanOnce sync.Once
+	outputChan     chan *speech.Transcript
+	outputChanErr  error
+	forwarderWG    sync.WaitGroup
+	finishOnce     sync.Once
+	finishErr      error
+}
+
+var (
+	_ speech.ToText           = (*Recorder)(nil)
+	_ speech.LanguageDetector = (*Recorder)(nil)
+)
+
+// NewRecorder returns a Recorder of the backend into the output
+// (which is closed by Finish or Close).
+func NewRecorder(
+	ctx context.Context,
+	backend speech.ToText,
+	output io.WriteCloser,
+	opts ...Option,
+) (*Recorder, error) {
+	cfg := Options(opts).config()
+	header := Header{
+		StartedAt: time.Now(),
+		Metadata:  cfg.Metadata,
+	}
+	encoding, err := backend.AudioEncoding(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the audio encoding of the backend: %w", err)
+	}
+	if encodingPCM, ok := encoding.(audio.EncodingPCM); ok {
+		header.AudioEncoding = &encodingPCM
+	}
+	header.AudioChannels, err = backend.AudioChannels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the amount of audio channels of the backend: %w", err)
+	}
+
+	writer, err := NewWriter(output, header)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancelFn := context.WithCancel(ctx)
+	return &Recorder{
+		Backend:   backend,
+		writer:    writer,
+		startedAt: header.StartedAt,
+		now:       time.Now,
+		ctx:       ctx,
+		cancelFn:  cancelFn,
+	}, nil
+}
+
+func (r *Recorder) record(ctx context.Context, record Record) {
+	record.Time = r.now().Sub(r.startedAt)
+	if err := r.writer.WriteRecord(ctx, record); err != nil {
+		logger.Errorf(ctx, "unable to record the session: %v", err)
+	}
+}
+
+func (r *Recorder) AudioEncoding(ctx context.Context) (audio.Encoding, error) {
+	return r.Backend.AudioEncoding(ctx)
+}
+
+func (r *Recorder) AudioChannels(ctx context.Context) (audio.Channel, error) {
+	return r.Backend.AudioChannels(ctx)
+}
+
+func (r *Recorder) WriteAudio(ctx context.Context, audio []byte) error {
+	if r.ctx.Err() == nil {
+		r.record(ctx, Record{Audio: audio})
+	}
+	return r.Backend.WriteAudio(ctx, audio)
+}
+
+// OutputChan returns the transcripts of the backend; they are recorded
+// while they are passing through.
+func (r *Recorder) OutputChan(ctx context.Context) (<-chan *speech.Transcript, error) {
+	r.outputChanOnce.Do(func() {
+		input, err := r.Backend.OutputChan(ctx)
+		if err != nil {
+			r.outputChanErr = err
+			return
+		}
+		r.outputChan = make(chan *speech.Transcript, 1024)
+		r.forwarderWG.Add(1)
+		observability.Go(r.ctx, func() {
+			defer r.forwarderWG.Done()
+			defer close(r.outputChan)
+			r.forwardTranscripts(r.ctx, input)
+		})
+	})
+	return r.outputChan, r.outputChanErr
+}
+
+func (r *Recorder) forwardTranscripts(
+	ctx context.Context,
+	input <-chan *speech.Transcript,
+) {
+	logger.Debugf(ctx, "recording transcripts")
+	defer func() { logger.Debugf(ctx, "/recording transcripts") }()
+	for {
+		var (
+			t  *speech.Transcript
+			ok bool
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case t, ok = <-input:
+			if !ok {
+				return
+			}
+		}
+		r.record(ctx, Record{Transcript: t})
+		select {
+		case <-ctx.Done():
+			return
+		case r.outputChan <- t:
+		}
+	}
+}
+
+// DetectLanguage implements speech.LanguageDetector,
+// if the backend implements it.
+func (r *Recorder) DetectLanguage(ctx context.Context, audio []byte) (speech.LanguageProbabilities, error) {
+	detector, ok := r.Backend.(speech.LanguageDetector)
+	if !ok {
+		return nil, fmt.Errorf("the backend %T cannot detect the language", r.Backend)
+	}
+	return detector.DetectLanguage(ctx, audio)
+}
+
+// Finish stops the recording and closes the session file, but keeps
+// the backend open (to be reused); the channel returned by OutputChan
+// is closed.
+func (r *Recorder) Finish() error {
+	r.finishOnce.Do(func() {
+		r.cancelFn()
+		r.forwarderWG.Wait()
+		if err := r.writer.Close(r.ctx); err != nil {
+			r.finishErr = fmt.Errorf("unable to close the session file: %w", err)
+		}
+	})
+	return r.finishErr
+}
+
+// Close finishes the recording (see Finish) and closes the backend.
+func (r *Recorder) Close() error {
+	var mErr *multierror.Error
+	if err := r.Finish(); err != nil {
+		mErr = multierror.Append(mErr, err)
+	}
+	if err := r.Backend.Close(); err != nil {
+		mErr = multierror.Append(mErr, fmt.Errorf("unable to close the backend: %w", err))
+	}
+	return mErr.ErrorOrNil()
+}
@@ -0,0 +1,141 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+// Replay writes the recorded audio of the session into the stt, keeping
+// the original intervals between the pieces of audio (scaled by
+// OptionSpeed). The recorded transcripts are skipped.
+//
+// The audio format of the stt should match the recorded one.
+func Replay(
+	ctx context.Context,
+	session *Reader,
+	stt speech.ToText,
+	opts ...Option,
+) (_err error) {
+	cfg := Options(opts).config()
+	logger.Debugf(ctx, "Replay(speed: %v)", cfg.Speed)
+	defer func() { logger.Debugf(ctx, "/Replay(speed: %v): %v", cfg.Speed, _err) }()
+
+	if cfg.Speed <= 0 {
+		return ErrInvalidSpeed{Speed: cfg.Speed}
+	}
+	if err := checkAudioFormat(ctx, session.Header, stt); err != nil {
+		return err
+	}
+
+	startedAt := time.Now()
+	write := func(ts time.Duration, audio []byte) error {
+		writeAt := startedAt.Add(time.Duration(float64(ts) / cfg.Speed))
+		if err := sleepUntil(ctx, writeAt); err != nil {
+			return err
+		}
+		if err := stt.WriteAudio(ctx, audio); err != nil {
+			return fmt.Errorf("unable to write the audio at %v: %w", ts, err)
+		}
+		return nil
+	}
+
+	var endTime time.Duration
+	for {
+		record, err := session.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return err
+		}
+		if len(record.Audio) == 0 {
+			continue
+		}
+		if err := write(record.Time, record.Audio); err != nil {
+			return err
+		}
+		endTime = max(endTime, record.Time+audioDuration(session.Header, len(record.Audio)))
+	}
+
+	if cfg.TrailingSilence <= 0 {
+		return nil
+	}
+	if session.Header.AudioEncoding == nil {
+		logger.Warnf(ctx, "unable to write the trailing silence: the recorded audio is not PCM")
+		return nil
+	}
+	for ts := time.Duration(0); ts < cfg.TrailingSilence; ts += silenceChunkDuration {
+		chunkDuration := min(silenceChunkDuration, cfg.TrailingSilence-ts)
+		if err := write(endTime+ts, make([]byte, audioSize(session.Header, chunkDuration))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// silenceChunkDuration is the duration of a single piece of the trailing silence.
+const silenceChunkDuration = 100 * time.Millisecond
+
+func audioSize(header Header, duration time.Duration) uint64 {
+	return header.AudioEncoding.BytesForDuration(duration) * uint64(header.AudioChannels)
+}
+
+func audioDuration(header Header, size int) time.Duration {
+	if header.AudioEncoding == nil || header.AudioChannels == 0 {
+		return 0
+	}
+	bytesPerSecond := uint64(header.AudioEncoding.BytesForSecond()) * uint64(header.AudioChannels)
+	if bytesPerSecond == 0 {
+		return 0
+	}
+	return time.Duration(uint64(size) * uint64(time.Second) / bytesPerSecond)
+}
+
+func checkAudioFormat(
+	ctx context.Context,
+	header Header,
+	stt speech.ToText,
+) error {
+	encoding, err := stt.AudioEncoding(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get the audio encoding: %w", err)
+	}
+	channels, err := stt.AudioChannels(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get the amount of audio channels: %w", err)
+	}
+	encodingPCM, isPCM := encoding.(audio.EncodingPCM)
+	if header.AudioChannels != channels ||
+		(header.AudioEncoding != nil) != isPCM ||
+		(isPCM && *header.AudioEncoding != encodingPCM) {
+		return ErrAudioFormatMismatch{
+			RecordedEncoding: header.AudioEncoding,
+			RecordedChannels: header.AudioChannels,
+			Encoding:         encoding,
+			Channels:         channels,
+		}
+	}
+	return nil
+}
+
+func sleepUntil(ctx context.Context, t time.Time) error {
+	duration := time.Until(t)
+	if duration <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
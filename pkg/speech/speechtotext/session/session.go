@@ -0,0 +1,149 @@
+// Package session records the audio passed to a speech.ToText together
+// with the emitted transcripts (see Recorder), and replays the recorded
+// audio into a speech.ToText (see Replay); this allows reproducing
+// the issues happened on a live stream.
+//
+// A session file is a sequence of JSON values: a Header followed by Record-s.
+package session
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/xsync"
+)
+
+const (
+	FormatVersion = 1
+
+	// FileExtension is the recommended extension of the session files.
+	FileExtension = ".stt-session"
+)
+
+// Header describes a session.
+type Header struct {
+	Version   int
+	StartedAt time.Time
+
+	// AudioEncoding is the encoding of the recorded audio;
+	// nil means it is not PCM.
+	AudioEncoding *audio.EncodingPCM
+	AudioChannels audio.Channel
+
+	// Metadata is an arbitrary information about the session
+	// (like the parameters of the speech.ToText).
+	Metadata map[string]string `json:",omitempty"`
+}
+
+// Record is an event of a session: either a piece of audio
+// passed to WriteAudio, or an emitted transcript.
+type Record struct {
+	// Time is the time since the start of the session.
+	Time time.Duration
+
+	Audio      []byte             `json:",omitempty"`
+	Transcript *speech.Transcript `json:",omitempty"`
+}
+
+// Writer writes a session file.
+type Writer struct {
+	locker   xsync.Mutex
+	output   io.Writer
+	buffer   *bufio.Writer
+	encoder  *json.Encoder
+	isClosed bool
+}
+
+// NewWriter writes the header and returns a Writer of the records.
+//
+// The output is buffered, the buffer is flushed by Close.
+func NewWriter(
+	output io.Writer,
+	header Header,
+) (*Writer, error) {
+	header.Version = FormatVersion
+	buffer := bufio.NewWriter(output)
+	w := &Writer{
+		output:  output,
+		buffer:  buffer,
+		encoder: json.NewEncoder(buffer),
+	}
+	if err := w.encoder.Encode(header); err != nil {
+		return nil, fmt.Errorf("unable to write the header: %w", err)
+	}
+	return w, nil
+}
+
+// WriteRecord writes the record; it could be used concurrently.
+func (w *Writer) WriteRecord(
+	ctx context.Context,
+	record Record,
+) error {
+	return xsync.DoR1(xsync.WithNoLogging(ctx, true), &w.locker, func() error {
+		if w.isClosed {
+			return ErrWriterClosed{}
+		}
+		return w.encoder.Encode(record)
+	})
+}
+
+// Close flushes the buffer and closes the output (if it is an io.Closer);
+// the records could not be written after that.
+func (w *Writer) Close(ctx context.Context) error {
+	return xsync.DoR1(xsync.WithNoLogging(ctx, true), &w.locker, func() error {
+		if w.isClosed {
+			return nil
+		}
+		w.isClosed = true
+		var mErr *multierror.Error
+		if err := w.buffer.Flush(); err != nil {
+			mErr = multierror.Append(mErr, fmt.Errorf("unable to flush the buffer: %w", err))
+		}
+		if closer, ok := w.output.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				mErr = multierror.Append(mErr, fmt.Errorf("unable to close the output: %w", err))
+			}
+		}
+		return mErr.ErrorOrNil()
+	})
+}
+
+// Reader reads a session file.
+type Reader struct {
+	Header  Header
+	decoder *json.Decoder
+}
+
+// NewReader reads the header and returns a Reader of the records.
+func NewReader(input io.Reader) (*Reader, error) {
+	r := &Reader{
+		decoder: json.NewDecoder(input),
+	}
+	if err := r.decoder.Decode(&r.Header); err != nil {
+		return nil, fmt.Errorf("unable to read the header: %w", err)
+	}
+	if r.Header.Version != FormatVersion {
+		return nil, ErrUnsupportedVersion{Version: r.Header.Version}
+	}
+	return r, nil
+}
+
+// Next returns the next record, or io.EOF if there are no more records.
+func (r *Reader) Next() (*Record, error) {
+	var record Record
+	if err := r.decoder.Decode(&record); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("unable to read a record: %w", err)
+	}
+	return &record, nil
+}
@@ -0,0 +1,196 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+type fakeBackend struct {
+	channels  audio.Channel
+	written   [][]byte
+	writtenAt []time.Time
+	out       chan *speech.Transcript
+	isClosed  bool
+}
+
+var _ speech.ToText = (*fakeBackend)(nil)
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{
+		channels: 1,
+		out:      make(chan *speech.Transcript, 10),
+	}
+}
+
+func (b *fakeBackend) AudioEncoding(context.Context) (audio.Encoding, error) {
+	return audio.EncodingPCM{PCMFormat: audio.PCMFormatFloat32LE, SampleRate: 16000}, nil
+}
+
+func (b *fakeBackend) AudioChannels(context.Context) (audio.Channel, error) {
+	return b.channels, nil
+}
+
+func (b *fakeBackend) WriteAudio(_ context.Context, audio []byte) error {
+	b.written = append(b.written, audio)
+	b.writtenAt = append(b.writtenAt, time.Now())
+	return nil
+}
+
+func (b *fakeBackend) OutputChan(context.Context) (<-chan *speech.Transcript, error) {
+	return b.out, nil
+}
+
+func (b *fakeBackend) Close() error {
+	b.isClosed = true
+	return nil
+}
+
+type nopCloser struct {
+	bytes.Buffer
+	isClosed bool
+}
+
+func (c *nopCloser) Close() error {
+	c.isClosed = true
+	return nil
+}
+
+func readRecords(t *testing.T, r *Reader) []Record {
+	var records []Record
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			return records
+		}
+		require.NoError(t, err)
+		records = append(records, *record)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	backend := newFakeBackend()
+	var file nopCloser
+	rec, err := NewRecorder(ctx, backend, &file, OptionMetadata{"language": "en"})
+	require.NoError(t, err)
+	now := rec.startedAt
+	rec.now = func() time.Time { return now }
+
+	out, err := rec.OutputChan(ctx)
+	require.NoError(t, err)
+
+	now = now.Add(time.Second)
+	require.NoError(t, rec.WriteAudio(ctx, []byte{1, 2, 3, 4}))
+	transcript := &speech.Transcript{
+		Variants: speech.TranscriptVariants{{Text: " hello"}},
+		IsFinal:  true,
+		Kind:     speech.TranscriptKindSpeech,
+	}
+	backend.out <- transcript
+	require.Equal(t, transcript, <-out)
+	now = now.Add(time.Second)
+	require.NoError(t, rec.WriteAudio(ctx, []byte{5, 6, 7, 8}))
+
+	require.NoError(t, rec.Finish())
+	require.True(t, file.isClosed)
+	require.False(t, backend.isClosed)
+	_, ok := <-out
+	require.False(t, ok)
+	// the audio is passed through, but not recorded anymore
+	require.NoError(t, rec.WriteAudio(ctx, []byte{9, 10, 11, 12}))
+	require.Len(t, backend.written, 3)
+
+	r, err := NewReader(&file.Buffer)
+	require.NoError(t, err)
+	require.Equal(t, &audio.EncodingPCM{PCMFormat: audio.PCMFormatFloat32LE, SampleRate: 16000}, r.Header.AudioEncoding)
+	require.Equal(t, audio.Channel(1), r.Header.AudioChannels)
+	require.Equal(t, map[string]string{"language": "en"}, r.Header.Metadata)
+	require.Equal(t, []Record{
+		{Time: time.Second, Audio: []byte{1, 2, 3, 4}},
+		{Time: time.Second, Transcript: transcript},
+		{Time: 2 * time.Second, Audio: []byte{5, 6, 7, 8}},
+	}, readRecords(t, r))
+
+	require.NoError(t, rec.Close())
+	require.True(t, backend.isClosed)
+}
+
+func writeSession(t *testing.T, header Header, records ...Record) *Reader {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, header)
+	require.NoError(t, err)
+	for _, record := range records {
+		require.NoError(t, w.WriteRecord(context.Background(), record))
+	}
+	require.NoError(t, w.Close(context.Background()))
+	require.ErrorAs(t, w.WriteRecord(context.Background(), Record{}), &ErrWriterClosed{})
+
+	r, err := NewReader(&buf)
+	require.NoError(t, err)
+	return r
+}
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	header := Header{
+		AudioEncoding: &audio.EncodingPCM{PCMFormat: audio.PCMFormatFloat32LE, SampleRate: 16000},
+		AudioChannels: 1,
+	}
+	records := []Record{
+		{Time: 0, Audio: []byte{1, 2, 3, 4}},
+		{Time: 100 * time.Millisecond, Transcript: &speech.Transcript{}},
+		{Time: 200 * time.Millisecond, Audio: []byte{5, 6, 7, 8}},
+	}
+
+	backend := newFakeBackend()
+	err := Replay(ctx, writeSession(t, header, records...), backend, OptionSpeed(0))
+	require.ErrorAs(t, err, &ErrInvalidSpeed{})
+	require.Empty(t, backend.written)
+
+	backend = newFakeBackend()
+	require.NoError(t, Replay(ctx, writeSession(t, header, records...), backend, OptionSpeed(4)))
+	require.Equal(t, [][]byte{{1, 2, 3, 4}, {5, 6, 7, 8}}, backend.written)
+	require.GreaterOrEqual(t, backend.writtenAt[1].Sub(backend.writtenAt[0]), 40*time.Millisecond)
+
+	backend = newFakeBackend()
+	require.NoError(t, Replay(ctx, writeSession(t, header, records...), backend, OptionSpeed(100), OptionTrailingSilence(250*time.Millisecond)))
+	require.Len(t, backend.written, 5)
+	var silence []byte
+	for _, chunk := range backend.written[2:] {
+		silence = append(silence, chunk...)
+	}
+	// 250ms of mono Float32LE 16kHz
+	require.Equal(t, make([]byte, 16000), silence)
+
+	backend = newFakeBackend()
+	backend.channels = 2
+	err = Replay(ctx, writeSession(t, header, records...), backend)
+	require.ErrorAs(t, err, &ErrAudioFormatMismatch{})
+	require.Empty(t, backend.written)
+}
+
+func TestWriterFlushesOnClose(t *testing.T) {
+	var file nopCloser
+	w, err := NewWriter(&file, Header{})
+	require.NoError(t, err)
+	require.NoError(t, w.WriteRecord(context.Background(), Record{Audio: []byte{1, 2, 3, 4}}))
+	require.Zero(t, file.Len())
+	require.NoError(t, w.Close(context.Background()))
+	require.True(t, file.isClosed)
+
+	r, err := NewReader(&file.Buffer)
+	require.NoError(t, err)
+	require.Equal(t, []Record{{Audio: []byte{1, 2, 3, 4}}}, readRecords(t, r))
+}
+
+func TestNewReaderUnsupportedVersion(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte(`{"Version": 100}`)))
+	require.ErrorAs(t, err, &ErrUnsupportedVersion{})
+}
@@ -28,6 +28,15 @@ type TranscriptToken struct {
 	Text       Text
 	Confidence float32
 	Speaker    string
+
+	// Stability is an estimate of the likelihood that the token will not
+	// be changed in the next results: 0 means it is likely to change,
+	// 1 means it will not change.
+	Stability float32
+
+	// IsDTWAligned tells that the timestamps are aligned by DTW
+	// on the attention heads of the model (thus, they are more accurate).
+	IsDTWAligned bool
 }
 
 func (t *TranscriptToken) ContainsAlphaNum() bool {
@@ -74,8 +83,35 @@ func (v *TranscriptVariant) EndTime() time.Duration {
 	return v.TranscriptTokens.EndTime()
 }
 
+// StableTextLength returns the length (in bytes) of the prefix of Text
+// covered by the leading tokens with the Stability of at least minStability.
+// The tokens not found in Text (like special tokens) are ignored.
+func (v *TranscriptVariant) StableTextLength(minStability float32) int {
+	text := string(v.Text)
+	length := 0
+	for _, token := range v.TranscriptTokens {
+		tokenText := strings.TrimSpace(string(token.Text))
+		if tokenText == "" {
+			continue
+		}
+		idx := strings.Index(text[length:], tokenText)
+		if idx < 0 {
+			continue
+		}
+		if token.Stability < minStability {
+			break
+		}
+		length += idx + len(tokenText)
+	}
+	return length
+}
+
 type TranscriptVariants []TranscriptVariant
 
+// SegmentID identifies a segment of the speech (within an audio channel)
+// across the transcripts of it; zero means the backend does not track segments.
+type SegmentID uint64
+
 type Transcript struct {
 	Variants            TranscriptVariants
 	Stability           float32
@@ -83,6 +119,29 @@ type Transcript struct {
 	AudioChannelNum     audio.Channel
 	Language            Language
 	IsFinal             bool
+	Kind                TranscriptKind
+
+	// LanguageConfidence is the probability of Language being the language
+	// of the segment; zero means it is unknown.
+	LanguageConfidence float32
+
+	// SegmentID tells which segment the transcript describes: a transcript
+	// replaces the previous transcript with the same SegmentID and IsTranslation.
+	SegmentID SegmentID
+
+	// Revision increases with each update of the segment,
+	// so a transcript with a lower Revision is outdated.
+	Revision uint64
+
+	// IsRetracted tells that the segment does not exist anymore
+	// (e.g. the audio was re-segmented differently), so it should be removed;
+	// such a transcript has no Variants.
+	IsRetracted bool
+
+	// IsTranslation tells that the transcript is the translation (to Language)
+	// of the transcript with the same SegmentID; a retraction of the segment
+	// retracts its translation as well.
+	IsTranslation bool
 }
 
 type ToText interface {
@@ -92,3 +151,11 @@ type ToText interface {
 	WriteAudio(context.Context, []byte) error
 	OutputChan(context.Context) (<-chan *Transcript, error)
 }
+
+// LanguageDetector is implemented by the ToText-s,
+// which can detect the language of an arbitrary piece of audio.
+type LanguageDetector interface {
+	// DetectLanguage returns the probabilities of the languages spoken in
+	// the audio (in the format returned by ToText.AudioEncoding and ToText.AudioChannels).
+	DetectLanguage(ctx context.Context, audio []byte) (LanguageProbabilities, error)
+}
@@ -0,0 +1,38 @@
+package speech
+
+import (
+	"fmt"
+)
+
+// TranscriptKind tells what a Transcript describes.
+type TranscriptKind uint
+
+const (
+	// TranscriptKindSpeech is a transcription of speech.
+	TranscriptKindSpeech = TranscriptKind(iota)
+
+	// TranscriptKindSoundEvent is a non-speech sound annotation,
+	// e.g.: "[door opens]", "(clicking)", "*thump*".
+	TranscriptKindSoundEvent
+
+	// TranscriptKindMusic is a music annotation, e.g.: "[music]", "♪ ♪".
+	TranscriptKindMusic
+
+	// TranscriptKindSuspectedHallucination is a transcription that is
+	// likely not in the audio (e.g.: "Thanks for watching!" on silence).
+	TranscriptKindSuspectedHallucination
+)
+
+func (k TranscriptKind) String() string {
+	switch k {
+	case TranscriptKindSpeech:
+		return "speech"
+	case TranscriptKindSoundEvent:
+		return "sound-event"
+	case TranscriptKindMusic:
+		return "music"
+	case TranscriptKindSuspectedHallucination:
+		return "suspected-hallucination"
+	}
+	return fmt.Sprintf("unknown_%d", uint(k))
+}
@@ -0,0 +1,60 @@
+// Package fake implements a speech.Translator, which does not
+// really translate anything; it is useful for tests and debugging.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/xsync"
+)
+
+// Translator "translates" the texts by Dictionary, and those missing in it
+// by prefixing them with the target language, like "[de] Hello".
+type Translator struct {
+	Dictionary map[speech.Text]speech.Text
+
+	locker xsync.Mutex
+	calls  int
+}
+
+var _ speech.Translator = (*Translator)(nil)
+
+func New(
+	dictionary map[speech.Text]speech.Text,
+) *Translator {
+	return &Translator{
+		Dictionary: dictionary,
+	}
+}
+
+func (t *Translator) Translate(
+	ctx context.Context,
+	text speech.Text,
+	from speech.Language,
+	to speech.Language,
+) (speech.Text, error) {
+	t.locker.Do(ctx, func() {
+		t.calls++
+	})
+	if to == "" {
+		return "", fmt.Errorf("the target language is not set")
+	}
+	if translation, ok := t.Dictionary[speech.Text(strings.TrimSpace(string(text)))]; ok {
+		return translation, nil
+	}
+	return speech.Text(fmt.Sprintf("[%s] %s", to.Family(), strings.TrimSpace(string(text)))), nil
+}
+
+// Calls returns how many times Translate was called.
+func (t *Translator) Calls() int {
+	return xsync.DoR1(context.Background(), &t.locker, func() int {
+		return t.calls
+	})
+}
+
+func (t *Translator) Close() error {
+	return nil
+}
@@ -0,0 +1,56 @@
+package libretranslate
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultHTTPTimeout is the timeout of the default HTTP client, so that
+// a stuck endpoint does not block the caller forever.
+const DefaultHTTPTimeout = 10 * time.Second
+
+type config struct {
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+func defaultConfig() config {
+	return config{
+		HTTPClient: &http.Client{Timeout: DefaultHTTPTimeout},
+	}
+}
+
+type Option interface {
+	apply(*config)
+}
+
+type Options []Option
+
+func (opts Options) apply(cfg *config) {
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+}
+
+func (opts Options) config() config {
+	cfg := defaultConfig()
+	opts.apply(&cfg)
+	return cfg
+}
+
+// OptionAPIKey is the API key of the endpoint (not needed by a local instance).
+type OptionAPIKey string
+
+func (opt OptionAPIKey) apply(cfg *config) {
+	cfg.APIKey = string(opt)
+}
+
+// OptionHTTPClient overrides the HTTP client (which by default
+// has the timeout DefaultHTTPTimeout).
+type OptionHTTPClient struct {
+	*http.Client
+}
+
+func (opt OptionHTTPClient) apply(cfg *config) {
+	cfg.HTTPClient = opt.Client
+}
@@ -0,0 +1,112 @@
+// Package libretranslate implements speech.Translator using
+// a LibreTranslate (https://libretranslate.com) HTTP endpoint.
+package libretranslate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+const (
+	// DefaultURL is the address of a LibreTranslate instance
+	// run locally with the default settings.
+	DefaultURL = "http://localhost:5000"
+
+	languageAuto = "auto"
+)
+
+type Translator struct {
+	url    string
+	config config
+}
+
+var _ speech.Translator = (*Translator)(nil)
+
+// New returns a Translator using the LibreTranslate instance at the URL
+// (e.g. DefaultURL).
+func New(
+	url string,
+	opts ...Option,
+) *Translator {
+	return &Translator{
+		url:    strings.TrimRight(url, "/"),
+		config: Options(opts).config(),
+	}
+}
+
+type translateRequest struct {
+	Q      string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+type translateResponse struct {
+	TranslatedText string `json:"translatedText"`
+	Error          string `json:"error"`
+}
+
+func (t *Translator) Translate(
+	ctx context.Context,
+	text speech.Text,
+	from speech.Language,
+	to speech.Language,
+) (_ret speech.Text, _err error) {
+	logger.Tracef(ctx, "Translate(ctx, '%s', '%s', '%s')", text, from, to)
+	defer func() { logger.Tracef(ctx, "/Translate(ctx, '%s', '%s', '%s'): '%s' %v", text, from, to, _ret, _err) }()
+
+	if to == "" {
+		return "", fmt.Errorf("the target language is not set")
+	}
+	source := languageAuto
+	if from != "" {
+		source = string(from.Family())
+	}
+	reqBody, err := json.Marshal(translateRequest{
+		Q:      string(text),
+		Source: source,
+		Target: string(to.Family()),
+		Format: "text",
+		APIKey: t.config.APIKey,
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to serialize the request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url+"/translate", bytes.NewReader(reqBody))
+	if err != nil {
+		return "", fmt.Errorf("unable to build the request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.config.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to send the request to '%s': %w", req.URL, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the response: %w", err)
+	}
+	var result translateResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("unable to parse the response (status %d) '%s': %w", resp.StatusCode, respBody, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("received status %d: %s", resp.StatusCode, result.Error)
+	}
+	return speech.Text(result.TranslatedText), nil
+}
+
+func (t *Translator) Close() error {
+	return nil
+}
@@ -0,0 +1,52 @@
+package libretranslate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+func TestTranslate(t *testing.T) {
+	var requests []translateRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/translate", r.URL.Path)
+		var req translateRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+		if req.Target == "xx" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(translateResponse{Error: "xx is not supported"})
+			return
+		}
+		json.NewEncoder(w).Encode(translateResponse{TranslatedText: "Hallo Welt"})
+	}))
+	defer srv.Close()
+
+	translator := New(srv.URL+"/", OptionAPIKey("secret"))
+	defer translator.Close()
+	ctx := context.Background()
+
+	result, err := translator.Translate(ctx, "Hello world", "en-US", "de-DE")
+	require.NoError(t, err)
+	require.Equal(t, speech.Text("Hallo Welt"), result)
+
+	_, err = translator.Translate(ctx, "Hello world", "", "xx")
+	require.ErrorContains(t, err, "xx is not supported")
+
+	_, err = translator.Translate(ctx, "Hello world", "en-US", "")
+	require.Error(t, err)
+
+	require.Equal(t, []translateRequest{
+		{Q: "Hello world", Source: "en", Target: "de", Format: "text", APIKey: "secret"},
+		{Q: "Hello world", Source: "auto", Target: "xx", Format: "text", APIKey: "secret"},
+	}, requests)
+}
+
+func TestDefaultHTTPTimeout(t *testing.T) {
+	require.Equal(t, DefaultHTTPTimeout, New(DefaultURL).config.HTTPClient.Timeout)
+}
@@ -0,0 +1,55 @@
+package translation
+
+import (
+	"time"
+)
+
+// DefaultTimeout is the default deadline of a single translation request.
+const DefaultTimeout = 10 * time.Second
+
+type config struct {
+	TranslatePartials bool
+	Timeout           time.Duration
+}
+
+func defaultConfig() config {
+	return config{
+		Timeout: DefaultTimeout,
+	}
+}
+
+type Option interface {
+	apply(*config)
+}
+
+type Options []Option
+
+func (opts Options) apply(cfg *config) {
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+}
+
+func (opts Options) config() config {
+	cfg := defaultConfig()
+	opts.apply(&cfg)
+	return cfg
+}
+
+// OptionTranslatePartials enables translating the non-final transcripts
+// as well; otherwise only the final ones are translated (a translation
+// request per partial result might be too much for a Translator).
+type OptionTranslatePartials bool
+
+func (opt OptionTranslatePartials) apply(cfg *config) {
+	cfg.TranslatePartials = bool(opt)
+}
+
+// OptionTimeout is the deadline of a single translation request; the
+// translation is done inline, so a stuck request delays all the following
+// transcripts. A non-positive value disables the deadline.
+type OptionTimeout time.Duration
+
+func (opt OptionTimeout) apply(cfg *config) {
+	cfg.Timeout = time.Duration(opt)
+}
@@ -0,0 +1,142 @@
+// Package translation translates transcripts to an arbitrary language
+// by a speech.Translator.
+package translation
+
+import (
+	"context"
+	"time"
+
+	"github.com/facebookincubator/go-belt/tool/logger"
+	"github.com/xaionaro-go/observability"
+	"github.com/xaionaro-go/speech/pkg/speech"
+)
+
+// Translate forwards the transcripts from the input to the returned channel,
+// each (if it is worth translating) followed by its translation to the
+// language `to` (see speech.Transcript.IsTranslation). The translation keeps
+// the timing of the transcript, but not of the individual tokens, since
+// the words of a translation do not match the words of the speech.
+// A transcript already in the language `to` is forwarded as its own translation.
+//
+// The returned channel is closed when the input is closed or ctx is done.
+func Translate(
+	ctx context.Context,
+	translator speech.Translator,
+	input <-chan *speech.Transcript,
+	to speech.Language,
+	opts ...Option,
+) <-chan *speech.Transcript {
+	cfg := Options(opts).config()
+	output := make(chan *speech.Transcript, 1024)
+	observability.Go(ctx, func() {
+		defer close(output)
+		logger.Debugf(ctx, "translation loop to '%s'", to)
+		defer func() { logger.Debugf(ctx, "/translation loop to '%s'", to) }()
+
+		send := func(t *speech.Transcript) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case output <- t:
+				return true
+			}
+		}
+		for {
+			var (
+				t  *speech.Transcript
+				ok bool
+			)
+			select {
+			case <-ctx.Done():
+				return
+			case t, ok = <-input:
+				if !ok {
+					return
+				}
+			}
+			if !send(t) {
+				return
+			}
+			if !shouldTranslate(t, cfg) {
+				continue
+			}
+			translation, err := translate(ctx, translator, t, to, cfg.Timeout)
+			if err != nil {
+				logger.Errorf(ctx, "unable to translate '%s' from '%s' to '%s': %v", t.Variants[0].Text, t.Language, to, err)
+				continue
+			}
+			if !send(translation) {
+				return
+			}
+		}
+	})
+	return output
+}
+
+func shouldTranslate(
+	t *speech.Transcript,
+	cfg config,
+) bool {
+	switch {
+	case t.IsRetracted:
+		// the retraction of the segment retracts its translation as well
+		return false
+	case t.IsTranslation:
+		return false
+	case t.Kind != speech.TranscriptKindSpeech:
+		return false
+	case !t.IsFinal && !cfg.TranslatePartials:
+		return false
+	case len(t.Variants) == 0:
+		return false
+	}
+	return t.Variants[0].Text.ContainsAlphaNum()
+}
+
+// translate returns the translation of the best variant of the transcript.
+func translate(
+	ctx context.Context,
+	translator speech.Translator,
+	t *speech.Transcript,
+	to speech.Language,
+	timeout time.Duration,
+) (*speech.Transcript, error) {
+	variant := t.Variants[0]
+	text := variant.Text
+	if t.Language == "" || t.Language.Family() != to.Family() {
+		if timeout > 0 {
+			var cancelFn context.CancelFunc
+			ctx, cancelFn = context.WithTimeout(ctx, timeout)
+			defer cancelFn()
+		}
+		var err error
+		text, err = translator.Translate(ctx, variant.Text, t.Language, to)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var tokens speech.TranscriptTokens
+	if len(variant.TranscriptTokens) > 0 {
+		first := variant.TranscriptTokens[0]
+		tokens = speech.TranscriptTokens{{
+			StartTime:  variant.StartTime(),
+			EndTime:    variant.EndTime(),
+			Text:       text,
+			Confidence: variant.Confidence,
+			Speaker:    first.Speaker,
+			Stability:  t.Stability,
+		}}
+	}
+
+	translation := *t
+	translation.Variants = speech.TranscriptVariants{{
+		Text:             text,
+		TranscriptTokens: tokens,
+		Confidence:       variant.Confidence,
+	}}
+	translation.Language = to
+	translation.LanguageConfidence = 0
+	translation.IsTranslation = true
+	return &translation, nil
+}
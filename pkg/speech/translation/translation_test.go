@@ -0,0 +1,142 @@
+package translation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xaionaro-go/speech/pkg/speech"
+	"github.com/xaionaro-go/speech/pkg/speech/translation/implementations/fake"
+)
+
+func transcript(
+	text speech.Text,
+	lang speech.Language,
+	segmentID speech.SegmentID,
+	isFinal bool,
+) *speech.Transcript {
+	return &speech.Transcript{
+		Variants: speech.TranscriptVariants{{
+			Text: text,
+			TranscriptTokens: speech.TranscriptTokens{
+				{StartTime: time.Second, EndTime: 2 * time.Second, Text: "Hallo", Speaker: "S1"},
+				{StartTime: 2 * time.Second, EndTime: 3 * time.Second, Text: " Welt", Speaker: "S1"},
+			},
+			Confidence: 0.9,
+		}},
+		Language:  lang,
+		IsFinal:   isFinal,
+		Kind:      speech.TranscriptKindSpeech,
+		SegmentID: segmentID,
+		Revision:  1,
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	translator := fake.New(map[speech.Text]speech.Text{"Hallo Welt": "Hello world"})
+	input := make(chan *speech.Transcript, 10)
+	input <- transcript("Hallo Welt", "de-DE", 1, true)
+	input <- transcript("Hallo", "de-DE", 2, false)
+	input <- transcript("Hi there", "en-GB", 3, true)
+	input <- &speech.Transcript{SegmentID: 2, Revision: 2, IsRetracted: true}
+	annotation := transcript("[Musik]", "de-DE", 4, true)
+	annotation.Kind = speech.TranscriptKindMusic
+	input <- annotation
+	close(input)
+
+	var result []*speech.Transcript
+	for t := range Translate(ctx, translator, input, "en-US") {
+		result = append(result, t)
+	}
+	require.Len(t, result, 7)
+	require.Equal(t, 1, translator.Calls())
+
+	translation := result[1]
+	require.True(t, translation.IsTranslation)
+	require.Equal(t, speech.Language("en-US"), translation.Language)
+	require.Equal(t, speech.SegmentID(1), translation.SegmentID)
+	require.Equal(t, uint64(1), translation.Revision)
+	require.True(t, translation.IsFinal)
+	require.Equal(t, speech.TranscriptVariants{{
+		Text: "Hello world",
+		TranscriptTokens: speech.TranscriptTokens{
+			{StartTime: time.Second, EndTime: 3 * time.Second, Text: "Hello world", Confidence: 0.9, Speaker: "S1"},
+		},
+		Confidence: 0.9,
+	}}, translation.Variants)
+	require.False(t, result[0].IsTranslation)
+
+	// the partial result is not translated
+	require.Equal(t, speech.SegmentID(2), result[2].SegmentID)
+	require.False(t, result[2].IsTranslation)
+
+	// the text already in the target language is not sent to the translator
+	require.Equal(t, speech.SegmentID(3), result[4].SegmentID)
+	require.True(t, result[4].IsTranslation)
+	require.Equal(t, speech.Text("Hi there"), result[4].Variants[0].Text)
+
+	require.True(t, result[5].IsRetracted)
+	require.Equal(t, speech.TranscriptKindMusic, result[6].Kind)
+	require.False(t, result[6].IsTranslation)
+}
+
+func TestTranslatePartials(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	translator := fake.New(nil)
+	input := make(chan *speech.Transcript, 1)
+	input <- transcript("Hallo", "de-DE", 1, false)
+	close(input)
+
+	var result []*speech.Transcript
+	for t := range Translate(ctx, translator, input, "fr-FR", OptionTranslatePartials(true)) {
+		result = append(result, t)
+	}
+	require.Len(t, result, 2)
+	require.Equal(t, speech.Text("[fr] Hallo"), result[1].Variants[0].Text)
+	require.False(t, result[1].IsFinal)
+}
+
+// stuckTranslator never replies until the context is done.
+type stuckTranslator struct{}
+
+func (stuckTranslator) Translate(
+	ctx context.Context,
+	text speech.Text,
+	from speech.Language,
+	to speech.Language,
+) (speech.Text, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (stuckTranslator) Close() error {
+	return nil
+}
+
+func TestTranslateTimeout(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+
+	input := make(chan *speech.Transcript, 2)
+	input <- transcript("Hallo Welt", "de-DE", 1, true)
+	input <- transcript("Tschüss", "de-DE", 2, true)
+	close(input)
+
+	var result []*speech.Transcript
+	for t := range Translate(ctx, stuckTranslator{}, input, "en-US", OptionTimeout(10*time.Millisecond)) {
+		result = append(result, t)
+	}
+
+	// the stuck translations are dropped, but the transcripts are still forwarded
+	require.Len(t, result, 2)
+	require.Equal(t, speech.SegmentID(1), result[0].SegmentID)
+	require.Equal(t, speech.SegmentID(2), result[1].SegmentID)
+	require.False(t, result[0].IsTranslation)
+	require.False(t, result[1].IsTranslation)
+}
@@ -0,0 +1,15 @@
+package speech
+
+import (
+	"context"
+	"io"
+)
+
+// Translator translates texts from one language to another.
+type Translator interface {
+	io.Closer
+
+	// Translate translates the text from the language `from` (empty
+	// means it should be detected by the Translator) to the language `to`.
+	Translate(ctx context.Context, text Text, from, to Language) (Text, error)
+}
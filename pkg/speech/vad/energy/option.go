@@ -0,0 +1,59 @@
+package energy
+
+import (
+	"time"
+)
+
+const (
+	// DefaultThreshold is the default minimal RMS level (in dBFS)
+	// of a frame to consider it a voice.
+	DefaultThreshold = -40
+
+	// DefaultFrameDuration is the default duration of a frame
+	// the RMS level is measured on.
+	DefaultFrameDuration = 20 * time.Millisecond
+)
+
+type config struct {
+	Threshold     float64
+	FrameDuration time.Duration
+}
+
+func defaultConfig() config {
+	return config{
+		Threshold:     DefaultThreshold,
+		FrameDuration: DefaultFrameDuration,
+	}
+}
+
+type Option interface {
+	apply(*config)
+}
+
+type Options []Option
+
+func (opts Options) apply(cfg *config) {
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+}
+
+func (opts Options) config() config {
+	cfg := defaultConfig()
+	opts.apply(&cfg)
+	return cfg
+}
+
+// OptionThreshold is the minimal RMS level (in dBFS, e.g. -40)
+// of a frame to consider it a voice.
+type OptionThreshold float64
+
+func (opt OptionThreshold) apply(cfg *config) {
+	cfg.Threshold = float64(opt)
+}
+
+type OptionFrameDuration time.Duration
+
+func (opt OptionFrameDuration) apply(cfg *config) {
+	cfg.FrameDuration = time.Duration(opt)
+}
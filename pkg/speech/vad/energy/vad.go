@@ -0,0 +1,98 @@
+// Package energy implements a simple VAD, which considers a voice
+// any audio loud enough; it is pure-Go, so it works without cgo.
+package energy
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"time"
+
+	"github.com/xaionaro-go/audio/pkg/audio"
+	"github.com/xaionaro-go/audio/pkg/vad"
+)
+
+type VAD struct {
+	SampleRate    audio.SampleRate
+	Threshold     float64
+	FrameDuration time.Duration
+}
+
+var _ vad.VAD = (*VAD)(nil)
+
+// New returns a VAD consuming PCM F32LE mono audio of the given sample rate.
+func New(
+	sampleRate audio.SampleRate,
+	opts ...Option,
+) *VAD {
+	cfg := Options(opts).config()
+	return &VAD{
+		SampleRate:    sampleRate,
+		Threshold:     cfg.Threshold,
+		FrameDuration: cfg.FrameDuration,
+	}
+}
+
+func (v *VAD) Close() error {
+	return nil
+}
+
+func (v *VAD) Encoding(context.Context) (audio.Encoding, error) {
+	return audio.EncodingPCM{
+		PCMFormat:  audio.PCMFormatFloat32LE,
+		SampleRate: v.SampleRate,
+	}, nil
+}
+
+func (v *VAD) Channels(context.Context) (audio.Channel, error) {
+	return 1, nil
+}
+
+// FindNextVoice returns 1 and the position of the first voiced frame if
+// the voiced frames last at least minDuration; otherwise it returns 0 and
+// the position of the first voiced frame (or -1 if there is none).
+//
+// The energy is either above the threshold or not, so confidenceThreshold is ignored.
+func (v *VAD) FindNextVoice(
+	_ context.Context,
+	samples []byte,
+	_ float64,
+	minDuration time.Duration,
+) (float64, time.Duration, error) {
+	const sampleSize = 4
+	frameSamples := int(uint64(v.SampleRate) * uint64(v.FrameDuration) / uint64(time.Second))
+	frameSize := max(frameSamples, 1) * sampleSize
+
+	var foundVoiceFor time.Duration
+	firstVoiceDetection := time.Duration(-1)
+	for pos := 0; len(samples) >= frameSize; pos++ {
+		frame := samples[:frameSize]
+		samples = samples[frameSize:]
+		if Level(frame) < v.Threshold {
+			continue
+		}
+		foundVoiceFor += v.FrameDuration
+		if firstVoiceDetection < 0 {
+			firstVoiceDetection = v.FrameDuration * time.Duration(pos)
+		}
+		if foundVoiceFor >= minDuration {
+			return 1, firstVoiceDetection, nil
+		}
+	}
+	return 0, firstVoiceDetection, nil
+}
+
+// Level returns the RMS level (in dBFS) of PCM F32LE samples.
+func Level(samples []byte) float64 {
+	const sampleSize = 4
+	count := len(samples) / sampleSize
+	if count == 0 {
+		return math.Inf(-1)
+	}
+	var sum float64
+	for idx := 0; idx < count; idx++ {
+		sample := float64(math.Float32frombits(binary.LittleEndian.Uint32(samples[idx*sampleSize:])))
+		sum += sample * sample
+	}
+	return 10 * math.Log10(sum/float64(count))
+}
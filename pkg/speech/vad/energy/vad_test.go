@@ -0,0 +1,57 @@
+package energy
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func samples(amplitude float32, duration time.Duration) []byte {
+	count := int(16000 * duration / time.Second)
+	buf := make([]byte, count*4)
+	for idx := 0; idx < count; idx++ {
+		sample := amplitude
+		if idx%2 == 1 {
+			sample = -amplitude
+		}
+		binary.LittleEndian.PutUint32(buf[idx*4:], math.Float32bits(sample))
+	}
+	return buf
+}
+
+func TestLevel(t *testing.T) {
+	require.InDelta(t, 0, Level(samples(1, 10*time.Millisecond)), 0.001)
+	require.InDelta(t, -20, Level(samples(0.1, 10*time.Millisecond)), 0.001)
+	require.True(t, math.IsInf(Level(samples(0, 10*time.Millisecond)), -1))
+	require.True(t, math.IsInf(Level(nil), -1))
+}
+
+func TestFindNextVoice(t *testing.T) {
+	ctx := context.Background()
+	v := New(16000)
+
+	confidence, foundAt, err := v.FindNextVoice(ctx, samples(0.001, time.Second), 0.5, time.Nanosecond)
+	require.NoError(t, err)
+	require.Equal(t, float64(0), confidence)
+	require.Equal(t, time.Duration(-1), foundAt)
+
+	buf := append(samples(0.001, 100*time.Millisecond), samples(0.1, 100*time.Millisecond)...)
+	confidence, foundAt, err = v.FindNextVoice(ctx, buf, 0.5, 60*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, float64(1), confidence)
+	require.Equal(t, 100*time.Millisecond, foundAt)
+
+	// the voice is too short
+	confidence, foundAt, err = v.FindNextVoice(ctx, buf, 0.5, 200*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, float64(0), confidence)
+	require.Equal(t, 100*time.Millisecond, foundAt)
+
+	confidence, _, err = New(16000, OptionThreshold(-10)).FindNextVoice(ctx, buf, 0.5, time.Nanosecond)
+	require.NoError(t, err)
+	require.Equal(t, float64(0), confidence)
+}
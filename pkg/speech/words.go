@@ -0,0 +1,245 @@
+package speech
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// IsSpecial returns true for tokens like "[_BEG_]" or "[_TT_150]"
+// (used by Whisper), which are not a part of the text.
+func (t *TranscriptToken) IsSpecial() bool {
+	return strings.HasPrefix(string(t.Text), "[_") && strings.HasSuffix(string(t.Text), "]")
+}
+
+// Words merges the tokens (which could be sub-word pieces, like " Hel",
+// "lo", ",") into words:
+//
+//   - a token starting with a space begins a new word;
+//   - the trailing punctuation (like "," or "。") is attached to the preceding word;
+//   - each CJK character is a separate word, since these languages have no spaces;
+//   - the special tokens (see IsSpecial) are dropped;
+//   - a token with an incomplete UTF-8 character is merged with the next one.
+//
+// The text of a word has no surrounding spaces; the time range of a word
+// covers its tokens, the Confidence is the mean of them, the Stability is
+// the minimal one (since the word changes if any of its tokens changes),
+// and IsDTWAligned is true only if all of the tokens are aligned.
+// If a token consists of multiple words, its time range is split
+// between them proportionally to their lengths.
+func (s TranscriptTokens) Words() TranscriptTokens {
+	var b wordsBuilder
+	for _, token := range s {
+		b.Add(token)
+	}
+	return b.Finish()
+}
+
+// Words returns the words of the variant (see TranscriptTokens.Words);
+// if the variant has no tokens, the words are taken from Text
+// (without timestamps, and with the Confidence of the variant).
+func (v *TranscriptVariant) Words() TranscriptTokens {
+	if len(v.TranscriptTokens) > 0 {
+		return v.TranscriptTokens.Words()
+	}
+	return TranscriptTokens{{Text: v.Text, Confidence: v.Confidence}}.Words()
+}
+
+// JoinWords returns the text of the words (see Words): they are separated
+// by spaces, except between the CJK characters.
+func (s TranscriptTokens) JoinWords() Text {
+	var result strings.Builder
+	var prev rune
+	for _, word := range s {
+		first, _ := utf8.DecodeRuneInString(string(word.Text))
+		if result.Len() > 0 && !(isCJK(first) && (isCJK(prev) || isCJKPunctuation(prev))) {
+			result.WriteByte(' ')
+		}
+		result.WriteString(string(word.Text))
+		prev, _ = utf8.DecodeLastRuneInString(string(word.Text))
+	}
+	return Text(result.String())
+}
+
+type wordPieceKind int
+
+const (
+	wordPieceKindWord = wordPieceKind(iota)
+	wordPieceKindCJK
+	wordPieceKindPunctuation
+)
+
+// wordPiece is a part of a token belonging to a single word.
+type wordPiece struct {
+	Text              string
+	Kind              wordPieceKind
+	IsPrecededBySpace bool
+	RuneCount         int
+}
+
+type wordsBuilder struct {
+	Words TranscriptTokens
+
+	// IsOpen tells that the last word could be continued by the next token.
+	IsOpen bool
+
+	// WordTokenCount is the amount of tokens of the last word.
+	WordTokenCount int
+
+	// Pending is the token with an incomplete UTF-8 character.
+	Pending *TranscriptToken
+}
+
+func (b *wordsBuilder) Add(token TranscriptToken) {
+	if token.IsSpecial() {
+		return
+	}
+	if b.Pending != nil {
+		token = mergePendingToken(*b.Pending, token)
+		b.Pending = nil
+	}
+	if hasIncompleteRune(string(token.Text)) {
+		b.Pending = &token
+		return
+	}
+
+	pieces, endsWithSpace := splitWordPieces(string(token.Text))
+	totalRunes := 0
+	for _, piece := range pieces {
+		totalRunes += piece.RuneCount
+	}
+
+	runesBefore := 0
+	isTokenCounted := false
+	for _, piece := range pieces {
+		start := proportionalTime(token.StartTime, token.EndTime, runesBefore, totalRunes)
+		runesBefore += piece.RuneCount
+		end := proportionalTime(token.StartTime, token.EndTime, runesBefore, totalRunes)
+
+		shouldAttach := len(b.Words) > 0 && (piece.Kind == wordPieceKindPunctuation ||
+			(piece.Kind == wordPieceKindWord && !piece.IsPrecededBySpace && b.IsOpen))
+		if !shouldAttach {
+			b.Words = append(b.Words, TranscriptToken{
+				StartTime:    start,
+				EndTime:      end,
+				Text:         Text(piece.Text),
+				Confidence:   token.Confidence,
+				Speaker:      token.Speaker,
+				Stability:    token.Stability,
+				IsDTWAligned: token.IsDTWAligned,
+			})
+			b.WordTokenCount = 1
+			isTokenCounted = true
+			b.IsOpen = piece.Kind != wordPieceKindCJK
+			continue
+		}
+
+		word := &b.Words[len(b.Words)-1]
+		word.Text += Text(piece.Text)
+		word.EndTime = max(word.EndTime, end)
+		if !isTokenCounted {
+			b.WordTokenCount++
+			word.Confidence += (token.Confidence - word.Confidence) / float32(b.WordTokenCount)
+			word.Stability = min(word.Stability, token.Stability)
+			word.IsDTWAligned = word.IsDTWAligned && token.IsDTWAligned
+			isTokenCounted = true
+		}
+	}
+	if endsWithSpace {
+		b.IsOpen = false
+	}
+}
+
+func (b *wordsBuilder) Finish() TranscriptTokens {
+	if b.Pending != nil {
+		pending := *b.Pending
+		b.Pending = nil
+		pending.Text = Text(strings.ToValidUTF8(string(pending.Text), string(utf8.RuneError)))
+		b.Add(pending)
+	}
+	return b.Words
+}
+
+// splitWordPieces splits the text of a token into the parts belonging to
+// different words; the spaces are dropped.
+func splitWordPieces(text string) ([]wordPiece, bool) {
+	var pieces []wordPiece
+	isAfterSpace := false
+	for _, r := range text {
+		var last *wordPiece
+		if len(pieces) > 0 {
+			last = &pieces[len(pieces)-1]
+		}
+		switch {
+		case unicode.IsSpace(r):
+			isAfterSpace = true
+			continue
+		case isTrailingPunctuation(r):
+			if last == nil {
+				pieces = append(pieces, wordPiece{Kind: wordPieceKindPunctuation})
+				last = &pieces[len(pieces)-1]
+			}
+		case isCJK(r):
+			pieces = append(pieces, wordPiece{Kind: wordPieceKindCJK, IsPrecededBySpace: isAfterSpace})
+			last = &pieces[len(pieces)-1]
+		default:
+			if last == nil || isAfterSpace || last.Kind == wordPieceKindCJK {
+				pieces = append(pieces, wordPiece{Kind: wordPieceKindWord, IsPrecededBySpace: isAfterSpace})
+				last = &pieces[len(pieces)-1]
+			}
+		}
+		last.Text += string(r)
+		last.RuneCount++
+		isAfterSpace = false
+	}
+	return pieces, isAfterSpace
+}
+
+func isTrailingPunctuation(r rune) bool {
+	return strings.ContainsRune(".,!?;:…)]}»”’%。、，！？；：）」』】》〉", r)
+}
+
+// isCJK returns true for the characters of the languages without
+// spaces between words (Chinese and Japanese).
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+// isCJKPunctuation returns true for the CJK symbols and the full-width
+// forms (like "。" or "，").
+func isCJKPunctuation(r rune) bool {
+	return (r >= 0x3000 && r <= 0x303F) || (r >= 0xFF00 && r <= 0xFFEF)
+}
+
+// hasIncompleteRune returns true if the text ends with a part of
+// a multi-byte UTF-8 character (a BPE token could split a character).
+func hasIncompleteRune(text string) bool {
+	for idx := len(text) - 1; idx >= 0 && idx >= len(text)-utf8.UTFMax; idx-- {
+		if utf8.RuneStart(text[idx]) {
+			return !utf8.FullRuneInString(text[idx:])
+		}
+	}
+	return false
+}
+
+func mergePendingToken(pending, token TranscriptToken) TranscriptToken {
+	return TranscriptToken{
+		StartTime:    pending.StartTime,
+		EndTime:      max(pending.EndTime, token.EndTime),
+		Text:         pending.Text + token.Text,
+		Confidence:   (pending.Confidence + token.Confidence) / 2,
+		Speaker:      pending.Speaker,
+		Stability:    min(pending.Stability, token.Stability),
+		IsDTWAligned: pending.IsDTWAligned && token.IsDTWAligned,
+	}
+}
+
+// proportionalTime returns the time after the part of the total
+// (in runes) of the text spoken from start to end.
+func proportionalTime(start, end time.Duration, part, total int) time.Duration {
+	if total == 0 {
+		return start
+	}
+	return start + (end-start)*time.Duration(part)/time.Duration(total)
+}
@@ -0,0 +1,165 @@
+package speech
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscriptTokensWords(t *testing.T) {
+	const ms = time.Millisecond
+	token := func(text Text, t0, t1 time.Duration, confidence float32) TranscriptToken {
+		return TranscriptToken{
+			StartTime:    t0 * ms,
+			EndTime:      t1 * ms,
+			Text:         text,
+			Confidence:   confidence,
+			Stability:    confidence,
+			IsDTWAligned: true,
+		}
+	}
+
+	for _, tc := range []struct {
+		Name   string
+		Tokens TranscriptTokens
+		Words  TranscriptTokens
+	}{
+		{
+			Name: "empty",
+		},
+		{
+			Name: "sub_words",
+			Tokens: TranscriptTokens{
+				token(" Hel", 0, 100, 0.75),
+				token("lo", 100, 200, 0.25),
+				token(" world", 200, 500, 0.9),
+			},
+			Words: TranscriptTokens{
+				{StartTime: 0, EndTime: 200 * ms, Text: "Hello", Confidence: 0.5, Stability: 0.25, IsDTWAligned: true},
+				token("world", 200, 500, 0.9),
+			},
+		},
+		{
+			Name: "no_leading_space",
+			Tokens: TranscriptTokens{
+				token("Un", 0, 100, 0.5),
+				token("believ", 100, 200, 0.5),
+				token("able", 200, 300, 0.5),
+			},
+			Words: TranscriptTokens{
+				token("Unbelievable", 0, 300, 0.5),
+			},
+		},
+		{
+			Name: "punctuation",
+			Tokens: TranscriptTokens{
+				token(" Yes", 0, 100, 0.5),
+				token(",", 100, 150, 0.5),
+				token(" (", 150, 160, 0.5),
+				token("sure", 160, 300, 0.5),
+				token(")", 300, 310, 0.5),
+				token(" !", 310, 320, 0.5),
+			},
+			Words: TranscriptTokens{
+				token("Yes,", 0, 150, 0.5),
+				token("(sure)!", 150, 320, 0.5),
+			},
+		},
+		{
+			Name: "special_tokens",
+			Tokens: TranscriptTokens{
+				{Text: "[_BEG_]", Confidence: 1},
+				token(" hi", 0, 100, 0.5),
+				{Text: "[_TT_10]", StartTime: 100 * ms, EndTime: 100 * ms, Confidence: 1},
+			},
+			Words: TranscriptTokens{
+				token("hi", 0, 100, 0.5),
+			},
+		},
+		{
+			Name: "multiple_words_in_a_token",
+			Tokens: TranscriptTokens{
+				token(" ab cd", 0, 400, 0.5),
+			},
+			Words: TranscriptTokens{
+				token("ab", 0, 200, 0.5),
+				token("cd", 200, 400, 0.5),
+			},
+		},
+		{
+			Name: "cjk",
+			Tokens: TranscriptTokens{
+				token("你好", 0, 200, 0.5),
+				token("。", 200, 250, 0.5),
+				token("世界", 250, 450, 0.5),
+			},
+			Words: TranscriptTokens{
+				token("你", 0, 100, 0.5),
+				token("好。", 100, 250, 0.5),
+				token("世", 250, 350, 0.5),
+				token("界", 350, 450, 0.5),
+			},
+		},
+		{
+			Name: "split_utf8_character",
+			Tokens: TranscriptTokens{
+				token(" caf\xc3", 0, 100, 0.25),
+				token("\xa9", 100, 200, 0.75),
+			},
+			Words: TranscriptTokens{
+				{StartTime: 0, EndTime: 200 * ms, Text: "café", Confidence: 0.5, Stability: 0.25, IsDTWAligned: true},
+			},
+		},
+		{
+			Name: "incomplete_utf8_character_at_the_end",
+			Tokens: TranscriptTokens{
+				token(" caf\xc3", 0, 100, 0.5),
+			},
+			Words: TranscriptTokens{
+				token("caf�", 0, 100, 0.5),
+			},
+		},
+		{
+			Name: "not_aligned_token",
+			Tokens: TranscriptTokens{
+				token(" Hel", 0, 100, 0.5),
+				{StartTime: 100 * ms, EndTime: 200 * ms, Text: "lo", Confidence: 0.5, Stability: 0.5},
+			},
+			Words: TranscriptTokens{
+				{StartTime: 0, EndTime: 200 * ms, Text: "Hello", Confidence: 0.5, Stability: 0.5},
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			require.Equal(t, tc.Words, tc.Tokens.Words())
+		})
+	}
+}
+
+func TestTranscriptVariantWords(t *testing.T) {
+	v := TranscriptVariant{Text: " The cat, sat. ", Confidence: 0.3}
+	require.Equal(t, TranscriptTokens{
+		{Text: "The", Confidence: 0.3},
+		{Text: "cat,", Confidence: 0.3},
+		{Text: "sat.", Confidence: 0.3},
+	}, v.Words())
+
+	v.TranscriptTokens = TranscriptTokens{{Text: " meow", Confidence: 0.9}}
+	require.Equal(t, TranscriptTokens{{Text: "meow", Confidence: 0.9}}, v.Words())
+}
+
+func TestTranscriptTokensJoinWords(t *testing.T) {
+	for _, tc := range []struct {
+		Text Text
+		Join Text
+	}{
+		{Text: "", Join: ""},
+		{Text: " Hello,  world! ", Join: "Hello, world!"},
+		{Text: "你好。世界 and you", Join: "你好。世界 and you"},
+	} {
+		t.Run(string(tc.Text), func(t *testing.T) {
+			require.Equal(t, tc.Join, TranscriptTokens{{Text: tc.Text}}.Words().JoinWords())
+		})
+	}
+}
@@ -36,7 +36,7 @@ func main() {
 	})
 	app := app.New()
 	r, _ := io.Pipe()
-	w, err := subtitleswindow.New(ctx, app, "Fake Subtitles", fyne.TextAlignCenter, r, listener.Addr().String(), 0, nil, "", false, nil, 0)
+	w, err := subtitleswindow.New(ctx, app, "Fake Subtitles", fyne.TextAlignCenter, r, listener.Addr().String(), 0, nil, "", false, nil, "", 0, false, false)
 	if err != nil {
 		panic(err)
 	}
@@ -18,12 +18,17 @@ import (
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/implementations/whisper/types"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/goconv"
 	"github.com/xaionaro-go/speech/pkg/speech/speechtotext/server/proto/go/speechtotext_grpc"
+	"github.com/xaionaro-go/speech/pkg/speech/translation"
 	"github.com/xaionaro-go/xsync"
 )
 
 const (
 	maxLines = 5
 	timeout  = time.Second * 30
+
+	// minStability is the minimal stability of a token
+	// to be rendered as already stable.
+	minStability = 0.5
 )
 
 type subtitlePiece struct {
@@ -31,20 +36,38 @@ type subtitlePiece struct {
 	Language speech.Language
 	Text     string
 	IsFinal  bool
+	Kind     speech.TranscriptKind
+
+	// SegmentID and Revision identify the transcript the piece is built
+	// from (see speech.Transcript); zero SegmentID means it is not tracked.
+	SegmentID speech.SegmentID
+	Revision  uint64
+
+	// StableLength is the length of the prefix of Text, which is
+	// not expected to change anymore.
+	StableLength int
+
+	// Translation is the translation of Text (see speech.Transcript.IsTranslation)
+	// to TranslationLanguage; it is shown under the Text.
+	Translation         string
+	TranslationLanguage speech.Language
 }
 
 type speechRecognizer struct {
-	ctx               context.Context
-	textAlignment     fyne.TextAlign
-	cancelFunc        context.CancelFunc
-	renderLocker      xsync.Gorex
-	window            *SubtitlesWindow
-	audioInput        io.Reader
-	whisper           speech.ToText
-	subtitles         []subtitlePiece
-	shouldTranslate   bool
-	translateOnlyFrom []speech.Language
-	onceCloser        onceCloser
+	ctx             context.Context
+	textAlignment   fyne.TextAlign
+	cancelFunc      context.CancelFunc
+	renderLocker    xsync.Gorex
+	window          *SubtitlesWindow
+	audioInput      io.Reader
+	whisper         speech.ToText
+	translator      speech.Translator
+	translateTo     speech.Language
+	subtitles       []subtitlePiece
+	shouldTranslate bool
+	showAnnotations bool
+	bilingual       bool
+	onceCloser      onceCloser
 }
 
 // audioInput is supposed to be PCM Float32LE 16000Hz 1ch
@@ -57,14 +80,19 @@ func newSpeechRecognizer(
 	whisperModel []byte,
 	language speech.Language,
 	shouldTranslate bool,
-	translateOnlyFrom []speech.Language,
+	translator speech.Translator,
+	translateTo speech.Language,
 	vadThreshold float64,
+	showAnnotations bool,
+	bilingual bool,
 	window *SubtitlesWindow,
 ) (*speechRecognizer, error) {
 	var (
 		stt speech.ToText
 		err error
 	)
+	// if there is a translator, it provides the translations instead of whisper
+	emitTranslation := bilingual && translator == nil
 	if remoteAddrWhisper == "" {
 		logger.Debugf(ctx, "initializing a local context")
 		stt, err = initLocalSTT(
@@ -74,6 +102,8 @@ func newSpeechRecognizer(
 			language,
 			shouldTranslate,
 			vadThreshold,
+			showAnnotations,
+			emitTranslation,
 		)
 	} else {
 		logger.Debugf(ctx, "initializing a remote context")
@@ -82,10 +112,12 @@ func newSpeechRecognizer(
 			Language:        string(language),
 			ShouldTranslate: shouldTranslate,
 			VadThreshold:    float32(vadThreshold),
+			EmitTranslation: emitTranslation,
 			Backend: &speechtotext_grpc.NewContextRequest_Whisper{
 				Whisper: &speechtotext_grpc.WhisperOptions{
 					SamplingStrategy:      goconv.SamplingStrategyToGRPC(types.SamplingStrategyGreedy),
 					AlignmentAheadsPreset: speechtotext_grpc.WhisperAlignmentAheadsPreset_WhisperAlignmentAheadsPresetNone,
+					EmitAnnotations:       showAnnotations,
 				},
 			},
 		})
@@ -96,18 +128,21 @@ func newSpeechRecognizer(
 
 	ctx, cancelFn := context.WithCancel(ctx)
 	r := &speechRecognizer{
-		ctx:               ctx,
-		textAlignment:     textAlignment,
-		cancelFunc:        cancelFn,
-		window:            window,
-		audioInput:        audioInput,
-		whisper:           stt,
-		shouldTranslate:   shouldTranslate,
-		translateOnlyFrom: translateOnlyFrom,
+		ctx:             ctx,
+		textAlignment:   textAlignment,
+		cancelFunc:      cancelFn,
+		window:          window,
+		audioInput:      audioInput,
+		whisper:         stt,
+		translator:      translator,
+		translateTo:     translateTo,
+		shouldTranslate: shouldTranslate,
+		showAnnotations: showAnnotations,
+		bilingual:       bilingual,
 	}
 	observability.Go(ctx, func() {
 		defer r.Close()
-		err := r.transcriptLoop(ctx, language)
+		err := r.transcriptLoop(ctx)
 		if err != nil && err != context.Canceled {
 			select {
 			case <-ctx.Done():
@@ -168,10 +203,9 @@ func (r *speechRecognizer) audioWriterLoop(
 
 func (r *speechRecognizer) transcriptLoop(
 	ctx context.Context,
-	language speech.Language,
 ) (_err error) {
-	logger.Debugf(ctx, "transcriptLoop('%s')", language)
-	defer func() { logger.Debugf(ctx, "/transcriptLoop('%s'): %v", language, _err) }()
+	logger.Debugf(ctx, "transcriptLoop()")
+	defer func() { logger.Debugf(ctx, "/transcriptLoop(): %v", _err) }()
 
 	t := time.NewTicker(time.Second)
 	defer t.Stop()
@@ -179,6 +213,9 @@ func (r *speechRecognizer) transcriptLoop(
 	if err != nil {
 		return fmt.Errorf("unable to get the output chan: %w", err)
 	}
+	if r.translator != nil {
+		ch = translation.Translate(ctx, r.translator, ch, r.translateTo)
+	}
 	for {
 		select {
 		case <-t.C:
@@ -187,40 +224,6 @@ func (r *speechRecognizer) transcriptLoop(
 			if !ok {
 				return fmt.Errorf("the whisper client is closed")
 			}
-
-			logger.Debugf(ctx, "r.shouldTranslate == %t", r.shouldTranslate)
-			if r.shouldTranslate {
-				if r.translateOnlyFrom != nil {
-					found := false
-					for _, lang := range r.translateOnlyFrom {
-						logger.Debugf(
-							ctx,
-							"transcript.Language.Family() == language.Family(): %v == %v: %t",
-							transcript.Language.Family(), language.Family(),
-							transcript.Language.Family() == language.Family(),
-						)
-						if transcript.Language.Family() == lang.Family() {
-							found = true
-							break
-						}
-					}
-					if !found {
-						logger.Debugf(ctx, "we are expected to print only translations from %v, but this is '%s'", r.translateOnlyFrom, transcript.Language)
-						continue
-					}
-				} else {
-					logger.Debugf(
-						ctx,
-						"transcript.Language.Family() == language.Family(): %v == %v: %t",
-						transcript.Language.Family(), language.Family(),
-						transcript.Language.Family() == language.Family(),
-					)
-					if transcript.Language.Family() == language.Family() {
-						logger.Debugf(ctx, "we are expected to print only translations, but this is already '%s'", transcript.Language)
-						continue
-					}
-				}
-			}
 			err := r.addTranscript(ctx, transcript)
 			if err != nil {
 				logger.Errorf(ctx, "unable to render the transcript: %v", err)
@@ -231,6 +234,12 @@ func (r *speechRecognizer) transcriptLoop(
 	}
 }
 
+// showsTranslationsOnly returns true if the subtitles are
+// the translations by the translator (instead of the speech).
+func (r *speechRecognizer) showsTranslationsOnly() bool {
+	return r.translator != nil && !r.bilingual
+}
+
 func (r *speechRecognizer) addTranscript(
 	ctx context.Context,
 	transcript *speech.Transcript,
@@ -238,34 +247,120 @@ func (r *speechRecognizer) addTranscript(
 	logger.Debugf(ctx, "addTranscript(ctx, %#+v)", *transcript)
 	defer func() { logger.Debugf(ctx, "/addTranscript(ctx, %#+v): %v", *transcript, _err) }()
 
+	if transcript.IsRetracted {
+		r.renderLocker.Do(ctx, func() {
+			if r.removeSegment(transcript.SegmentID, transcript.Revision) {
+				r.render(ctx)
+			}
+		})
+		return nil
+	}
 	if len(transcript.Variants) == 0 {
 		return fmt.Errorf("no variants provided")
 	}
+	if transcript.IsTranslation {
+		r.renderLocker.Do(ctx, func() {
+			idx := r.findSegment(transcript.SegmentID)
+			if idx < 0 || r.subtitles[idx].Revision > transcript.Revision {
+				logger.Debugf(ctx, "no subtitle for the translation of segment %d revision %d", transcript.SegmentID, transcript.Revision)
+				return
+			}
+			r.subtitles[idx].Translation = string(transcript.Variants[0].Text)
+			r.subtitles[idx].TranslationLanguage = transcript.Language
+			r.render(ctx)
+		})
+		return nil
+	}
+	switch transcript.Kind {
+	case speech.TranscriptKindSpeech:
+	case speech.TranscriptKindSuspectedHallucination:
+		logger.Debugf(ctx, "skipping a suspected hallucination")
+		r.renderLocker.Do(ctx, func() {
+			// the segment might have been shown before it became suspected
+			if r.removeSegment(transcript.SegmentID, transcript.Revision) {
+				r.render(ctx)
+			}
+		})
+		return nil
+	default:
+		if !r.showAnnotations {
+			logger.Debugf(ctx, "skipping an annotation of kind %s", transcript.Kind)
+			return nil
+		}
+	}
 	r.renderLocker.Do(ctx, func() {
 		variant := transcript.Variants[0]
 		text := variant.Text
 		resultingPiece := subtitlePiece{
-			TS:       time.Now(),
-			Language: transcript.Language,
-			Text:     string(text),
-			IsFinal:  transcript.IsFinal,
+			TS:        time.Now(),
+			Language:  transcript.Language,
+			Text:      string(text),
+			IsFinal:   transcript.IsFinal,
+			Kind:      transcript.Kind,
+			SegmentID: transcript.SegmentID,
+			Revision:  transcript.Revision,
 		}
-
-		if len(r.subtitles) > 0 && !r.subtitles[len(r.subtitles)-1].IsFinal {
-			r.subtitles[len(r.subtitles)-1] = resultingPiece
+		if transcript.IsFinal {
+			resultingPiece.StableLength = len(text)
 		} else {
-			if len(r.subtitles) >= maxLines {
-				r.subtitles = r.subtitles[1:]
+			resultingPiece.StableLength = variant.StableTextLength(minStability)
+		}
+
+		if transcript.SegmentID != 0 {
+			if idx := r.findSegment(transcript.SegmentID); idx >= 0 {
+				if r.subtitles[idx].Revision > transcript.Revision {
+					logger.Debugf(ctx, "skipping an outdated revision %d of segment %d", transcript.Revision, transcript.SegmentID)
+					return
+				}
+				// the translation is updated separately
+				resultingPiece.Translation = r.subtitles[idx].Translation
+				resultingPiece.TranslationLanguage = r.subtitles[idx].TranslationLanguage
+				r.subtitles[idx] = resultingPiece
+				r.render(ctx)
+				return
 			}
-			r.subtitles = append(r.subtitles, resultingPiece)
+		} else if len(r.subtitles) > 0 && !r.subtitles[len(r.subtitles)-1].IsFinal && r.subtitles[len(r.subtitles)-1].SegmentID == 0 {
+			// the backend does not track segments, so a non-final
+			// transcript is assumed to be updated by the next one
+			r.subtitles[len(r.subtitles)-1] = resultingPiece
+			r.render(ctx)
+			return
 		}
 
+		if len(r.subtitles) >= maxLines {
+			r.subtitles = r.subtitles[1:]
+		}
+		r.subtitles = append(r.subtitles, resultingPiece)
 		r.render(ctx)
 	})
 
 	return nil
 }
 
+// findSegment returns the index of the piece of the given segment, or -1.
+func (r *speechRecognizer) findSegment(segmentID speech.SegmentID) int {
+	if segmentID == 0 {
+		return -1
+	}
+	for idx := range r.subtitles {
+		if r.subtitles[idx].SegmentID == segmentID {
+			return idx
+		}
+	}
+	return -1
+}
+
+// removeSegment removes the piece of the given segment unless it has
+// a newer revision; it returns true if anything was removed.
+func (r *speechRecognizer) removeSegment(segmentID speech.SegmentID, revision uint64) bool {
+	idx := r.findSegment(segmentID)
+	if idx < 0 || r.subtitles[idx].Revision > revision {
+		return false
+	}
+	r.subtitles = append(r.subtitles[:idx], r.subtitles[idx+1:]...)
+	return true
+}
+
 func (r *speechRecognizer) render(
 	ctx context.Context,
 ) {
@@ -279,10 +374,20 @@ func (r *speechRecognizer) render(
 				continue
 			}
 			logger.Debugf(ctx, "resultText[%d] = '%s'", len(cObjs), piece.Text)
-			cObjs = append(cObjs, r.generateLine(ctx, piece.Language, piece.Text, piece.IsFinal)...)
+			if !r.showsTranslationsOnly() || piece.Kind != speech.TranscriptKindSpeech {
+				cObjs = append(cObjs, r.generateLine(ctx, piece.Language, piece.Text, piece.StableLength, piece.Kind)...)
+			}
+			if piece.Translation != "" {
+				var stableLength int
+				if piece.IsFinal {
+					stableLength = len(piece.Translation)
+				}
+				cObjs = append(cObjs, r.generateLine(ctx, piece.TranslationLanguage, piece.Translation, stableLength, piece.Kind)...)
+			}
 		}
-		if r.shouldTranslate && len(cObjs) > 0 {
-			cObjs = append(r.generateLine(ctx, "", "Auto-translation:", true), cObjs...)
+		if (r.shouldTranslate || r.showsTranslationsOnly()) && len(cObjs) > 0 {
+			const header = "Auto-translation:"
+			cObjs = append(r.generateLine(ctx, "", header, len(header), speech.TranscriptKindSpeech), cObjs...)
 		}
 
 		textContainer := container.NewVBox(cObjs...)
@@ -297,31 +402,44 @@ func (r *speechRecognizer) render(
 	})
 }
 
+// generateLine renders the text; the first stableLength bytes of it
+// are rendered as stable, and the rest as still changing.
 func (r *speechRecognizer) generateLine(
 	ctx context.Context,
 	language speech.Language,
 	text string,
-	isFinal bool,
+	stableLength int,
+	kind speech.TranscriptKind,
 ) []fyne.CanvasObject {
-	foregroundColor := color.RGBA{255, 255, 255, 255}
-	if !isFinal {
-		foregroundColor = color.RGBA{255, 0, 128, 255}
-	}
+	stableColor := color.RGBA{255, 255, 255, 255}
+	unstableColor := color.RGBA{255, 0, 128, 255}
 
 	langFamily := language.Family()
 	if len(langFamily) > 0 {
+		isStable := stableLength >= len(text)
 		text = fmt.Sprintf("%s [%s]", text, langFamily)
+		if isStable {
+			stableLength = len(text)
+		}
 	}
 
 	const fontSize = 32
 
+	newText := func(text string, color color.Color) *canvas.Text {
+		t := canvas.NewText(text, color)
+		t.TextSize = fontSize
+		if kind != speech.TranscriptKindSpeech {
+			// sound annotations are rendered in italic, as it is common in SDH
+			t.TextStyle.Italic = true
+		}
+		return t
+	}
+
 	var layouts []fyne.CanvasObject
 	for text != "" {
-		var objs []fyne.CanvasObject
 		nextLineText := ""
-		fgText := canvas.NewText(text, foregroundColor)
+		fgText := newText(text, stableColor)
 		for {
-			fgText.TextSize = fontSize
 			if fgText.MinSize().Width <= r.window.Canvas().Size().Width-20 {
 				break
 			}
@@ -329,11 +447,26 @@ func (r *speechRecognizer) generateLine(
 			fgText.Text = fgText.Text[:len(fgText.Text)-1]
 		}
 		logger.Tracef(ctx, "text = '%s', nextLineText = '%s'", fgText.Text, nextLineText)
+
+		lineStableLength := min(stableLength, len(fgText.Text))
+		stableLength -= lineStableLength
+		var texts []*canvas.Text
+		if lineStableLength > 0 {
+			texts = append(texts, newText(fgText.Text[:lineStableLength], stableColor))
+		}
+		if lineStableLength < len(fgText.Text) {
+			texts = append(texts, newText(fgText.Text[lineStableLength:], unstableColor))
+		}
+
 		background := canvas.NewRectangle(color.RGBA{255, 0, 128, 64})
 		background.Resize(fgText.MinSize())
-
-		objs = append(objs, background)
-		objs = append(objs, fgText)
+		objs := []fyne.CanvasObject{background}
+		var x float32
+		for _, t := range texts {
+			t.Move(fyne.NewPos(x, 0))
+			x += t.MinSize().Width
+			objs = append(objs, t)
+		}
 		layout := container.NewWithoutLayout(objs...)
 		layouts = append(layouts, layout)
 		text = nextLineText
@@ -349,6 +482,11 @@ func (r *speechRecognizer) Close() error {
 		if err := r.whisper.Close(); err != nil {
 			mErr = multierror.Append(mErr, fmt.Errorf("whisperClient.Close(): %w", err))
 		}
+		if r.translator != nil {
+			if err := r.translator.Close(); err != nil {
+				mErr = multierror.Append(mErr, fmt.Errorf("translator.Close(): %w", err))
+			}
+		}
 		if err := r.window.Close(); err != nil {
 			mErr = multierror.Append(mErr, fmt.Errorf("windowCloser.Close(): %w", err))
 		}
@@ -17,6 +17,8 @@ func initLocalSTT(
 	language speech.Language,
 	shouldTranslate bool,
 	vadThreshold float64,
+	emitAnnotations bool,
+	emitTranslation bool,
 ) (speech.ToText, error) {
 	return nil, fmt.Errorf("built without whisper")
 }
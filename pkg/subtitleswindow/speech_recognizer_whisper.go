@@ -19,11 +19,15 @@ func initLocalSTT(
 	language speech.Language,
 	shouldTranslate bool,
 	vadThreshold float64,
+	emitAnnotations bool,
+	emitTranslation bool,
 ) (speech.ToText, error) {
 	var opts whisper.Options
 	if gpu >= 0 {
 		opts = append(opts, whisper.OptionGPUDeviceID(gpu))
 	}
+	opts = append(opts, whisper.OptionEmitAnnotations(emitAnnotations))
+	opts = append(opts, whisper.OptionEmitTranslation(emitTranslation))
 	return whisper.New(
 		ctx,
 		whisperModel,
@@ -20,7 +20,10 @@ type SubtitlesWindow struct {
 	onceCloser       onceCloser
 }
 
-// audioInput is supposed to be PCM Float32LE 16000Hz 1ch
+// audioInput is supposed to be PCM Float32LE 16000Hz 1ch.
+//
+// If translator is not nil, the subtitles are translated by it to translateTo
+// (and shown under the speech if bilingual); the translator is closed with the window.
 func New(
 	ctx context.Context,
 	app fyne.App,
@@ -32,12 +35,15 @@ func New(
 	whisperModel []byte,
 	language speech.Language,
 	shouldTranslate bool,
-	translateOnlyFrom []speech.Language,
+	translator speech.Translator,
+	translateTo speech.Language,
 	vadThreshold float64,
+	showAnnotations bool,
+	bilingual bool,
 ) (_ret *SubtitlesWindow, _err error) {
-	logger.Debugf(ctx, "New(ctx, app, '%s', audioInput, len:%d, translateOnlyFrom:%v)", title, len(whisperModel), translateOnlyFrom)
+	logger.Debugf(ctx, "New(ctx, app, '%s', audioInput, len:%d, translateTo:'%s')", title, len(whisperModel), translateTo)
 	defer func() {
-		logger.Debugf(ctx, "/New(ctx, app, '%s', audioInput, len:%d, translateOnlyFrom:%v): %#+v %#+v", title, len(whisperModel), translateOnlyFrom, _ret, _err)
+		logger.Debugf(ctx, "/New(ctx, app, '%s', audioInput, len:%d, translateTo:'%s'): %#+v %#+v", title, len(whisperModel), translateTo, _ret, _err)
 	}()
 
 	w := &SubtitlesWindow{}
@@ -46,7 +52,7 @@ func New(
 	w.Window.Resize(fyne.NewSize(960, 600))
 
 	var err error
-	w.speechRecognizer, err = newSpeechRecognizer(ctx, textAlignment, audioInput, remoteAddrWhisper, gpu, whisperModel, language, shouldTranslate, translateOnlyFrom, vadThreshold, w)
+	w.speechRecognizer, err = newSpeechRecognizer(ctx, textAlignment, audioInput, remoteAddrWhisper, gpu, whisperModel, language, shouldTranslate, translator, translateTo, vadThreshold, showAnnotations, bilingual, w)
 	logger.Debugf(ctx, "newSpeechRecognizer(): %#+v %#+v", w.speechRecognizer, err)
 	if err != nil {
 		w.Window.Close()